@@ -0,0 +1,93 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+)
+
+// AsyncOptions configures the asynchronous mode of NewLogHandler.
+type AsyncOptions struct {
+	// BufferSize bounds the number of records waiting to be written.
+	// Default is 1024.
+	BufferSize int
+	// Backpressure selects what happens to a record when the buffer is
+	// full. Default is BackpressureDropNewest. BackpressureSpillToDisk is
+	// not supported, as there is no spool to spill to; NewLogHandler panics
+	// on it.
+	Backpressure Backpressure
+	// OnError is called when a write fails. Default writes the error to os.Stderr.
+	OnError func(error)
+}
+
+// asyncLine is a formatted record waiting to be written.
+type asyncLine struct {
+	out       []byte
+	transient bool
+}
+
+// startAsync starts the goroutine writing the records of h and the
+// handlers derived from it.
+func (h *logHandler) startAsync(o AsyncOptions) {
+	if o.Backpressure == BackpressureSpillToDisk {
+		panic("sloghandler: AsyncOptions.Backpressure: BackpressureSpillToDisk is not supported")
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = 1024
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("async writer")
+	}
+	h.async = newBatcher(o.BufferSize, 1, 0, func(lines []asyncLine) {
+		for _, l := range lines {
+			if err := h.writeLine(l.out, l.transient); err != nil {
+				o.OnError(err)
+			}
+		}
+	})
+	h.async.policy = o.Backpressure
+}
+
+// write writes a formatted record, or queues a copy of it in async mode.
+func (h *logHandler) write(ctx context.Context, out []byte, transient bool) error {
+	if h.async != nil {
+		return h.async.add(ctx, asyncLine{out: bytes.Clone(out), transient: transient})
+	}
+	return h.writeLine(out, transient)
+}
+
+// writeLine writes a formatted record to the underlying writer.
+func (h *logHandler) writeLine(out []byte, transient bool) error {
+	// Each record is a single Write, so the lock is held only for the write itself.
+	if h.mu != nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+	}
+	if h.term != nil {
+		out = h.term.frame(out, transient)
+	}
+	_, err := h.w.Write(out)
+	return err
+}
+
+// Flush waits until the records queued in async mode have been written,
+// then flushes the underlying writer if it has a Flush method, such as a
+// CoalescingWriter.
+func (h *logHandler) Flush() error {
+	if h.async != nil {
+		h.async.Flush()
+	}
+	if f, ok := h.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close writes the records queued in async mode and stops the background
+// goroutine; later records are rejected with ErrClosed. The underlying
+// writer is not closed. Without HandlerOptions.Async it does nothing.
+func (h *logHandler) Close() error {
+	if h.async != nil {
+		h.async.close()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package sloghandler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter blocks writes until open is closed.
+type gateWriter struct {
+	open chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.open
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestLogHandlerAsync(t *testing.T) {
+	w := &gateWriter{open: make(chan struct{})}
+	h := NewLogHandler(w, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Async:          &AsyncOptions{},
+	})
+	logger := slog.New(h)
+	logger.Info("first")
+	logger.With("k", "v").Info("second")
+	if got := w.String(); got != "" {
+		t.Fatalf("written before the writer was ready: %q", got)
+	}
+
+	close(w.open)
+	if err := Flush(h); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "[INFO] first") || !strings.HasSuffix(lines[1], "[INFO] [k:v] second") {
+		t.Errorf("unexpected output %q", w.String())
+	}
+
+	if err := h.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Handle after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestLogHandlerAsyncQueueFull(t *testing.T) {
+	w := &gateWriter{open: make(chan struct{})}
+	h := NewLogHandler(w, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Async:          &AsyncOptions{BufferSize: 1},
+	})
+	var full bool
+	for range 10 {
+		if err := h.Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)); errors.Is(err, ErrQueueFull) {
+			full = true
+			break
+		}
+	}
+	if !full {
+		t.Error("no record dropped with a blocked writer")
+	}
+	close(w.open)
+	h.(io.Closer).Close()
+}
+
+func TestLogHandlerFlushWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := NewCoalescingWriter(&buf, time.Hour, 0)
+	logger := slog.New(NewLogHandler(cw, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Async:          &AsyncOptions{},
+	}))
+	logger.Info("hello")
+	if err := FlushLogger(logger); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "[INFO] hello\n") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestLogHandlerAsyncSpillToDisk(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewLogHandler accepted BackpressureSpillToDisk")
+		}
+	}()
+	NewLogHandler(io.Discard, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Async:          &AsyncOptions{Backpressure: BackpressureSpillToDisk},
+	})
+}
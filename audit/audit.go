@@ -0,0 +1,196 @@
+// Package audit defines a typed audit event, so that security-relevant
+// logs have the same shape across services:
+//
+//	err := audit.Emit(ctx, logger, audit.Event{
+//		Actor:   "alice",
+//		Action:  "user.delete",
+//		Target:  "user:42",
+//		Outcome: audit.Denied,
+//		Reason:  "missing role admin",
+//	})
+//	// [WARN] audit user.delete [audit:[actor=alice action=user.delete target=user:42 outcome=denied reason=missing role admin]]
+//
+// Events are logged as an "audit" group, which the text and JSON handlers
+// of sloghandler, and any other slog handler, render like other groups.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+// Key is the attribute key of events.
+const Key = "audit"
+
+// Outcome is the result of an audited action.
+type Outcome string
+
+const (
+	// Success means the action was performed.
+	Success Outcome = "success"
+	// Failure means the action was attempted and failed.
+	Failure Outcome = "failure"
+	// Denied means the actor was not allowed to perform the action.
+	Denied Outcome = "denied"
+)
+
+// Event is an audit event.
+type Event struct {
+	// Actor is who performed the action, such as a user or service account.
+	Actor string
+	// Action is what was done, such as "user.delete".
+	Action string
+	// Target is what the action was done to, such as "user:42".
+	Target string
+	// Outcome is the result.
+	Outcome Outcome
+	// Reason explains the outcome. It is required unless the outcome is Success.
+	Reason string
+}
+
+// ValidationError reports the fields of an event violating the schema.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "audit: invalid event: " + strings.Join(e.Problems, ", ")
+}
+
+// Validate checks e against the schema: Actor, Action, Target and Outcome
+// are required, Outcome must be one of the defined outcomes, and Reason is
+// required for failures and denials. It returns a *ValidationError.
+func (e Event) Validate() error {
+	var problems []string
+	for _, f := range []struct{ name, value string }{
+		{"actor", e.Actor},
+		{"action", e.Action},
+		{"target", e.Target},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			problems = append(problems, f.name+" is required")
+		}
+	}
+	switch e.Outcome {
+	case Success:
+	case Failure, Denied:
+		if strings.TrimSpace(e.Reason) == "" {
+			problems = append(problems, fmt.Sprintf("reason is required for outcome %s", e.Outcome))
+		}
+	case "":
+		problems = append(problems, "outcome is required")
+	default:
+		problems = append(problems, fmt.Sprintf("unknown outcome %q", e.Outcome))
+	}
+	if problems != nil {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// LogValue returns the fields of e as a group, omitting an empty reason.
+func (e Event) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("actor", e.Actor),
+		slog.String("action", e.Action),
+		slog.String("target", e.Target),
+		slog.String("outcome", string(e.Outcome)),
+	}
+	if e.Reason != "" {
+		attrs = append(attrs, slog.String("reason", e.Reason))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Level returns the level e is logged at: INFO for successes and WARN
+// otherwise.
+func (e Event) Level() slog.Level {
+	if e.Outcome == Success {
+		return slog.LevelInfo
+	}
+	return slog.LevelWarn
+}
+
+// Emit logs e with the message "audit <action>", followed by args. An
+// event violating the schema is logged anyway, at ERROR with an
+// "audit_error" attribute, so it is not lost, and the validation error
+// is returned.
+func Emit(ctx context.Context, logger *slog.Logger, e Event, args ...any) error {
+	level := e.Level()
+	err := e.Validate()
+	if err != nil {
+		level = slog.LevelError
+		args = append(args, slog.String("audit_error", err.Error()))
+	}
+	logger.Log(ctx, level, "audit "+e.Action, append([]any{slog.Any(Key, e)}, args...)...)
+	return err
+}
+
+// FromRecord returns the event in the "audit" attribute of r, if any,
+// whether logged with Emit or as a group with the same fields.
+func FromRecord(r slog.Record) (Event, bool) {
+	var e Event
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != Key {
+			return true
+		}
+		found = true
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			return false
+		}
+		for _, f := range v.Group() {
+			s := f.Value.String()
+			switch f.Key {
+			case "actor":
+				e.Actor = s
+			case "action":
+				e.Action = s
+			case "target":
+				e.Target = s
+			case "outcome":
+				e.Outcome = Outcome(s)
+			case "reason":
+				e.Reason = s
+			}
+		}
+		return false
+	})
+	return e, found
+}
+
+// ValidateRecords returns a Transformer that validates the events of
+// records logged without Emit, e.g. by code building the "audit" group by
+// hand, and raises those violating the schema to ERROR with an
+// "audit_error" attribute:
+//
+//	h := sloghandler.NewTransformHandler(handler, audit.ValidateRecords())
+func ValidateRecords() sloghandler.Transformer {
+	return sloghandler.TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		e, ok := FromRecord(r)
+		if !ok {
+			return r, true
+		}
+		err := e.Validate()
+		if err == nil {
+			return r, true
+		}
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			found = a.Key == "audit_error"
+			return !found
+		})
+		if found {
+			return r, true // already reported by Emit
+		}
+		r = r.Clone()
+		r.Level = max(r.Level, slog.LevelError)
+		r.AddAttrs(slog.String("audit_error", err.Error()))
+		return r, true
+	})
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  []string
+	}{
+		{"valid", Event{Actor: "alice", Action: "login", Target: "console", Outcome: Success}, nil},
+		{"denied with reason", Event{Actor: "bob", Action: "user.delete", Target: "user:42", Outcome: Denied, Reason: "not admin"}, nil},
+		{"empty", Event{}, []string{"actor is required", "action is required", "target is required", "outcome is required"}},
+		{"failure without reason", Event{Actor: "a", Action: "b", Target: "c", Outcome: Failure}, []string{"reason is required for outcome failure"}},
+		{"unknown outcome", Event{Actor: "a", Action: "b", Target: "c", Outcome: "ok"}, []string{`unknown outcome "ok"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.event.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("got %v, want a ValidationError", err)
+			}
+			if strings.Join(verr.Problems, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", verr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmitText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(sloghandler.NewLogHandler(&buf, &sloghandler.HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+	}))
+	err := Emit(context.Background(), logger, Event{
+		Actor: "alice", Action: "user.delete", Target: "user:42", Outcome: Denied, Reason: "missing role admin",
+	}, "request_id", "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[WARN] audit user.delete [audit:[actor=alice action=user.delete target=user:42 outcome=denied reason=missing role admin]] [request_id:r1]\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("got  %q\nwant suffix %q", got, want)
+	}
+}
+
+func TestEmitJSONInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := Emit(context.Background(), logger, Event{Actor: "alice", Action: "login", Outcome: Success})
+	if err == nil {
+		t.Fatal("no error for an event without target")
+	}
+	var m struct {
+		Level      string
+		Audit      map[string]string
+		AuditError string `json:"audit_error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Level != "ERROR" || m.Audit["actor"] != "alice" || m.Audit["outcome"] != "success" ||
+		m.AuditError != "audit: invalid event: target is required" {
+		t.Errorf("got %s", buf.String())
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(sloghandler.NewTransformHandler(slog.NewJSONHandler(&buf, nil), ValidateRecords()))
+	logger.Info("hand-made", slog.Group(Key, "actor", "svc", "action", "key.rotate", "outcome", "success"))
+	logger.Info("unrelated")
+	Emit(context.Background(), logger, Event{Actor: "svc"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	if !strings.Contains(lines[0], `"level":"ERROR"`) || !strings.Contains(lines[0], `"audit_error":"audit: invalid event: target is required"`) {
+		t.Errorf("hand-made event not flagged: %s", lines[0])
+	}
+	if strings.Contains(lines[1], "audit_error") {
+		t.Errorf("unrelated record flagged: %s", lines[1])
+	}
+	if n := strings.Count(lines[2], "audit_error"); n != 1 {
+		t.Errorf("emitted event flagged %d times: %s", n, lines[2])
+	}
+}
@@ -0,0 +1,178 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AWSOptions configures AWSMetadata.
+type AWSOptions struct {
+	// Client is used for the metadata requests. Default is a client with
+	// a 1 second timeout, so that resolving off AWS fails fast.
+	Client *http.Client
+	// IMDSEndpoint is the EC2 instance metadata service.
+	// Default is http://169.254.169.254.
+	IMDSEndpoint string
+	// ECSEndpoint is the ECS task metadata endpoint. Default is the value
+	// of the ECS_CONTAINER_METADATA_URI_V4 environment variable.
+	ECSEndpoint string
+	// DisableIMDS skips the instance metadata service, e.g. on hosts known
+	// not to be EC2 instances.
+	DisableIMDS bool
+}
+
+// AWSMetadata returns a Metadata describing the AWS compute the process
+// runs on. In an ECS task, including Fargate, it reads the task metadata
+// endpoint:
+//
+//	cloud.provider, cloud.platform, cloud.region, cloud.availability_zone,
+//	aws.ecs.cluster.arn, aws.ecs.task.arn, aws.ecs.task.family,
+//	aws.ecs.task.revision, aws.ecs.launchtype
+//
+// Otherwise it asks the EC2 instance metadata service (IMDSv2) for
+// host.id (the instance ID), host.type, cloud.region and
+// cloud.availability_zone. Nothing is attached when neither answers.
+// The requests are made once, when AWSMetadata is called, and on Refresh.
+func AWSMetadata(opts *AWSOptions) *Metadata {
+	o := AWSOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: time.Second}
+	}
+	if o.IMDSEndpoint == "" {
+		o.IMDSEndpoint = "http://169.254.169.254"
+	}
+	if o.ECSEndpoint == "" {
+		o.ECSEndpoint = os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	}
+	return NewMetadata(func() []slog.Attr {
+		ctx := context.Background()
+		if o.ECSEndpoint != "" {
+			if attrs, err := ecsTaskAttrs(ctx, o.Client, o.ECSEndpoint); err == nil {
+				return attrs
+			}
+		}
+		if !o.DisableIMDS {
+			if attrs, err := ec2Attrs(ctx, o.Client, o.IMDSEndpoint); err == nil {
+				return attrs
+			}
+		}
+		return nil
+	})
+}
+
+// ecsTask is the part of the ECS task metadata v4 response that is logged.
+type ecsTask struct {
+	Cluster          string
+	TaskARN          string
+	Family           string
+	Revision         string
+	AvailabilityZone string
+	LaunchType       string
+}
+
+func ecsTaskAttrs(ctx context.Context, client *http.Client, endpoint string) ([]slog.Attr, error) {
+	b, err := awsGet(ctx, client, strings.TrimSuffix(endpoint, "/")+"/task", nil)
+	if err != nil {
+		return nil, err
+	}
+	var task ecsTask
+	if err := json.Unmarshal(b, &task); err != nil {
+		return nil, err
+	}
+	// arn:aws:ecs:<region>:<account>:task/...
+	var region string
+	if parts := strings.Split(task.TaskARN, ":"); len(parts) > 3 {
+		region = parts[3]
+	}
+	attrs := []slog.Attr{
+		slog.String("cloud.provider", "aws"),
+		slog.String("cloud.platform", "aws_ecs"),
+	}
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	add("cloud.region", region)
+	add("cloud.availability_zone", task.AvailabilityZone)
+	add("aws.ecs.cluster.arn", task.Cluster)
+	add("aws.ecs.task.arn", task.TaskARN)
+	add("aws.ecs.task.family", task.Family)
+	add("aws.ecs.task.revision", task.Revision)
+	add("aws.ecs.launchtype", strings.ToLower(task.LaunchType))
+	return attrs, nil
+}
+
+func ec2Attrs(ctx context.Context, client *http.Client, endpoint string) ([]slog.Attr, error) {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := awsDo(client, req)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"X-aws-ec2-metadata-token": {string(token)}}
+	get := func(path string) (string, error) {
+		b, err := awsGet(ctx, client, endpoint+"/latest/meta-data/"+path, header)
+		return string(b), err
+	}
+	id, err := get("instance-id")
+	if err != nil {
+		return nil, err
+	}
+	attrs := []slog.Attr{
+		slog.String("cloud.provider", "aws"),
+		slog.String("cloud.platform", "aws_ec2"),
+		slog.String("host.id", id),
+	}
+	for _, f := range []struct{ key, path string }{
+		{"host.type", "instance-type"},
+		{"cloud.region", "placement/region"},
+		{"cloud.availability_zone", "placement/availability-zone"},
+	} {
+		if v, err := get(f.path); err == nil && v != "" {
+			attrs = append(attrs, slog.String(f.key, v))
+		}
+	}
+	return attrs, nil
+}
+
+func awsGet(ctx context.Context, client *http.Client, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	return awsDo(client, req)
+}
+
+func awsDo(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sloghandler: %s %s returned %s", req.Method, req.URL, resp.Status)
+	}
+	return b, nil
+}
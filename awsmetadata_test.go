@@ -0,0 +1,91 @@
+package sloghandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAWSMetadataECS(t *testing.T) {
+	ecs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v4/abc/task" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"Cluster": "arn:aws:ecs:ap-northeast-1:123456789012:cluster/prod",
+			"TaskARN": "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/0123456789abcdef",
+			"Family": "api",
+			"Revision": "42",
+			"AvailabilityZone": "ap-northeast-1a",
+			"LaunchType": "FARGATE"
+		}`))
+	}))
+	defer ecs.Close()
+
+	meta := AWSMetadata(&AWSOptions{ECSEndpoint: ecs.URL + "/v4/abc", DisableIMDS: true})
+	got := attrMap(meta.Attrs())
+	want := map[string]string{
+		"cloud.provider":          "aws",
+		"cloud.platform":          "aws_ecs",
+		"cloud.region":            "ap-northeast-1",
+		"cloud.availability_zone": "ap-northeast-1a",
+		"aws.ecs.cluster.arn":     "arn:aws:ecs:ap-northeast-1:123456789012:cluster/prod",
+		"aws.ecs.task.arn":        "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/0123456789abcdef",
+		"aws.ecs.task.family":     "api",
+		"aws.ecs.task.revision":   "42",
+		"aws.ecs.launchtype":      "fargate",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %v", got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestAWSMetadataEC2(t *testing.T) {
+	const token = "secret-token"
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(token))
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/instance-id":
+			w.Write([]byte("i-0123456789abcdef0"))
+		case "/latest/meta-data/instance-type":
+			w.Write([]byte("m7g.large"))
+		case "/latest/meta-data/placement/region":
+			w.Write([]byte("us-east-1"))
+		case "/latest/meta-data/placement/availability-zone":
+			w.Write([]byte("us-east-1b"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer imds.Close()
+
+	meta := AWSMetadata(&AWSOptions{IMDSEndpoint: imds.URL, ECSEndpoint: imds.URL + "/no-ecs"})
+	want := "cloud.provider=aws,cloud.platform=aws_ec2,host.id=i-0123456789abcdef0,host.type=m7g.large," +
+		"cloud.region=us-east-1,cloud.availability_zone=us-east-1b"
+	if got := meta.ResourceAttributes(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	imds.Close()
+	meta.Refresh()
+	if attrs := meta.Attrs(); len(attrs) != 0 {
+		t.Errorf("attributes off AWS: %v", attrs)
+	}
+}
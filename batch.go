@@ -0,0 +1,209 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrClosed is returned by Handle when the handler has already been closed.
+	ErrClosed = errors.New("sloghandler: handler closed")
+
+	// ErrQueueFull is returned by Handle when a record is dropped because the
+	// handler's queue is full.
+	ErrQueueFull = errors.New("sloghandler: queue full")
+)
+
+// Backpressure selects what a buffered handler does with a record when
+// its queue is full, e.g. because the destination is slow or down.
+type Backpressure int
+
+const (
+	// BackpressureDropNewest drops the incoming record; Handle returns
+	// ErrQueueFull. This is the default.
+	BackpressureDropNewest Backpressure = iota
+	// BackpressureDropOldest discards the oldest queued record to make room
+	// for the incoming one.
+	BackpressureDropOldest
+	// BackpressureBlock makes Handle wait for queue space until its context
+	// is done, slowing callers down to the rate of the destination.
+	BackpressureBlock
+	// BackpressureSpillToDisk writes the incoming record to the handler's
+	// Spool, from which it is replayed with the next delivery. Spilled
+	// records may be delivered ahead of records still in the queue. Handlers
+	// without a Spool drop the record as with BackpressureDropNewest.
+	BackpressureSpillToDisk
+)
+
+// batcher collects items from Handle calls and delivers them to flush from a
+// single background goroutine, either when maxSize items are buffered or
+// when interval elapses.
+type batcher[T any] struct {
+	ch       chan T
+	flushReq chan chan struct{}
+	done     chan struct{}
+	mu       sync.RWMutex
+	closed   bool
+	maxSize  int
+	interval time.Duration
+	flush    func([]T)
+	dropped  atomic.Int64
+	buffered atomic.Int64 // items taken from ch but not yet flushed
+	policy   Backpressure
+	// spill stores an item outside the queue for BackpressureSpillToDisk.
+	spill func(T) error
+}
+
+// newBatcher starts a batcher. A maxSize less than 1 is treated as 1 and an
+// interval of 0 disables time-based flushing.
+func newBatcher[T any](queueSize, maxSize int, interval time.Duration, flush func([]T)) *batcher[T] {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	b := &batcher[T]{
+		ch:       make(chan T, queueSize),
+		flushReq: make(chan chan struct{}),
+		done:     make(chan struct{}),
+		maxSize:  maxSize,
+		interval: interval,
+		flush:    flush,
+	}
+	go b.run()
+	return b
+}
+
+// add enqueues item. When the queue is full it applies the batcher's
+// backpressure policy.
+func (b *batcher[T]) add(ctx context.Context, item T) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.ch <- item:
+		return nil
+	default:
+	}
+	switch b.policy {
+	case BackpressureBlock:
+		select {
+		case b.ch <- item:
+			return nil
+		case <-ctx.Done():
+			b.dropped.Add(1)
+			return ctx.Err()
+		}
+	case BackpressureDropOldest:
+		for {
+			select {
+			case <-b.ch:
+				b.dropped.Add(1)
+			default:
+			}
+			select {
+			case b.ch <- item:
+				return nil
+			default:
+			}
+		}
+	case BackpressureSpillToDisk:
+		if b.spill != nil {
+			if err := b.spill(item); err != nil {
+				b.dropped.Add(1)
+				return err
+			}
+			return nil
+		}
+	}
+	b.dropped.Add(1)
+	return ErrQueueFull
+}
+
+// depth returns the number of items waiting to be flushed, including
+// those being flushed now.
+func (b *batcher[T]) depth() int {
+	return len(b.ch) + int(b.buffered.Load())
+}
+
+func (b *batcher[T]) dropCount() int64 {
+	return b.dropped.Load()
+}
+
+// Flush blocks until every item enqueued before the call has been passed to flush.
+func (b *batcher[T]) Flush() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	ack := make(chan struct{})
+	b.flushReq <- ack
+	<-ack
+}
+
+// close flushes the remaining items and stops the background goroutine.
+func (b *batcher[T]) close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		<-b.done
+		return
+	}
+	b.closed = true
+	close(b.ch)
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *batcher[T]) run() {
+	defer close(b.done)
+	var buf []T
+	flush := func() {
+		if len(buf) > 0 {
+			b.flush(buf)
+			buf = nil
+			b.buffered.Store(0)
+		}
+	}
+	var tick <-chan time.Time
+	if b.interval > 0 {
+		t := time.NewTicker(b.interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case item, ok := <-b.ch:
+			if !ok {
+				flush()
+				return
+			}
+			buf = append(buf, item)
+			b.buffered.Store(int64(len(buf)))
+			if len(buf) >= b.maxSize {
+				flush()
+			}
+		case <-tick:
+			flush()
+		case ack := <-b.flushReq:
+			for drained := false; !drained; {
+				select {
+				case item := <-b.ch:
+					buf = append(buf, item)
+					b.buffered.Store(int64(len(buf)))
+					if len(buf) >= b.maxSize {
+						flush()
+					}
+				default:
+					drained = true
+				}
+			}
+			flush()
+			close(ack)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package sloghandler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	b := newBatcher(10, 3, 0, func(items []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, append([]int(nil), items...))
+	})
+	for i := range 4 {
+		if err := b.add(t.Context(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Flush()
+	mu.Lock()
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+		t.Errorf("unexpected batches after Flush: %v", batches)
+	}
+	mu.Unlock()
+
+	b.add(t.Context(), 4)
+	b.close()
+	b.close() // closing twice is safe
+	if err := b.add(t.Context(), 5); err != ErrClosed {
+		t.Errorf("add after close = %v, want ErrClosed", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 3 || batches[2][0] != 4 {
+		t.Errorf("close did not flush remaining items: %v", batches)
+	}
+}
+
+func TestBatcherBackpressure(t *testing.T) {
+	tests := []struct {
+		policy  Backpressure
+		wantErr error
+		want    []int
+		dropped int64
+		spilled []int
+	}{
+		{BackpressureDropNewest, ErrQueueFull, []int{0, 1, 2}, 1, nil},
+		{BackpressureDropOldest, nil, []int{0, 2, 3}, 1, nil},
+		{BackpressureSpillToDisk, nil, []int{0, 1, 2}, 0, []int{3}},
+	}
+	for _, tt := range tests {
+		var mu sync.Mutex
+		var got, spilled []int
+		started := make(chan struct{})
+		release := make(chan struct{})
+		b := newBatcher(2, 1, 0, func(items []int) {
+			if items[0] == 0 {
+				close(started)
+				<-release
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, items...)
+		})
+		b.policy = tt.policy
+		b.spill = func(i int) error {
+			spilled = append(spilled, i)
+			return nil
+		}
+		b.add(t.Context(), 0)
+		<-started // the queue is empty and the consumer is stuck
+		b.add(t.Context(), 1)
+		b.add(t.Context(), 2)
+		if err := b.add(t.Context(), 3); err != tt.wantErr {
+			t.Errorf("policy %d: add to full queue = %v, want %v", tt.policy, err, tt.wantErr)
+		}
+		close(release)
+		b.close()
+		if !slices.Equal(got, tt.want) || !slices.Equal(spilled, tt.spilled) || b.dropped.Load() != tt.dropped {
+			t.Errorf("policy %d: delivered %v, spilled %v, dropped %d", tt.policy, got, spilled, b.dropped.Load())
+		}
+	}
+}
+
+func TestBatcherBlock(t *testing.T) {
+	release := make(chan struct{})
+	b := newBatcher(1, 1, 0, func([]int) { <-release })
+	b.policy = BackpressureBlock
+	b.add(t.Context(), 0)
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	var err error
+	for i := 1; err == nil && i < 3; i++ {
+		err = b.add(ctx, i)
+	}
+	if err != context.DeadlineExceeded || b.dropped.Load() != 1 {
+		t.Errorf("blocked add = %v, dropped %d", err, b.dropped.Load())
+	}
+	close(release)
+	b.close()
+}
@@ -0,0 +1,215 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ErrUnavailable marks a delivery error after which the remote may accept
+// the same batch later, such as a connection failure or a 503 response
+// that outlasted the retries. Batches failing with it are kept in the
+// sink's Spool, if any; other errors drop the batch.
+var ErrUnavailable = errors.New("sloghandler: remote unavailable")
+
+// unavailableError wraps the last error of a request whose retries were
+// exhausted. It matches ErrUnavailable.
+type unavailableError struct{ err error }
+
+func (e *unavailableError) Error() string        { return e.err.Error() }
+func (e *unavailableError) Unwrap() error        { return e.err }
+func (e *unavailableError) Is(target error) bool { return target == ErrUnavailable }
+
+// BatchSender delivers encoded batches to a remote. It is the delivery
+// contract shared by the network sinks:
+//
+//   - SendBatch returning nil acknowledges the batch; only then is it
+//     committed, i.e. removed from the spool.
+//   - An error matching ErrUnavailable leaves the batch for a later attempt.
+//     With a Spool it is checkpointed on disk and replayed in order, giving
+//     at-least-once delivery: a crash after the remote accepted a batch but
+//     before the commit sends it again.
+//   - Any other error rejects the batch, which is dropped and reported to OnError.
+//
+// Without a Spool, unavailable batches are dropped as well, so delivery is at most once.
+type BatchSender interface {
+	SendBatch(ctx context.Context, batch []byte) error
+}
+
+// BatchSenderFunc adapts a function to a BatchSender.
+type BatchSenderFunc func(ctx context.Context, batch []byte) error
+
+func (f BatchSenderFunc) SendBatch(ctx context.Context, batch []byte) error {
+	return f(ctx, batch)
+}
+
+// httpSender POSTs batches with retries. It is the BatchSender of the
+// HTTP and Loki sinks.
+type httpSender struct {
+	name   string
+	url    string
+	header http.Header
+	client *http.Client
+	retry  *RetryPolicy
+}
+
+func (s *httpSender) SendBatch(ctx context.Context, batch []byte) error {
+	return s.retry.do(func() (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(batch))
+		if err != nil {
+			return false, err
+		}
+		for k, vs := range s.header {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return true, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return s.retry.retryable(resp.StatusCode), fmt.Errorf("sloghandler: %s returned %s", s.name, resp.Status)
+		}
+		return false, nil
+	})
+}
+
+// BatchOptions configures a BatchHandler.
+type BatchOptions struct {
+	// Level is the minimum level shipped. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// Encode turns a batch of entries into the payload passed to the sender.
+	// Default is newline-delimited JSON.
+	Encode func([]*Entry) ([]byte, error)
+	// JSON selects the type-preserving encoding for the default Encode,
+	// see HTTPOptions.JSON.
+	JSON *JSONOptions
+	// Binary, if set, makes the default Encode write a sequence of entries
+	// in the format instead of NDJSON. JSON is then ignored.
+	Binary BinaryFormat
+	// BatchSize is the maximum number of records per batch. Default is 500.
+	BatchSize int
+	// Interval is the maximum time a record waits before being sent. Default is 5 seconds.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be sent. Default is 10000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// OnError is called when a batch cannot be delivered. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+	// Spool keeps batches on disk while the sender reports ErrUnavailable.
+	// Default is no spool. The handler does not close it.
+	Spool *Spool
+}
+
+// BatchHandler is a slog.Handler that ships encoded batches of records
+// through a BatchSender, for destinations without a built-in sink such as
+// Fluentd or CloudWatch Logs.
+type BatchHandler struct {
+	sender BatchSender
+	opts   BatchOptions
+	queue  *batcher[*Entry]
+	health *sinkHealth
+	scope  attrScope
+}
+
+// NewBatchHandler creates a handler that delivers records through sender.
+func NewBatchHandler(sender BatchSender, opts *BatchOptions) *BatchHandler {
+	o := BatchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.Encode == nil {
+		jsonOpts, format := o.JSON, o.Binary
+		o.Encode = func(entries []*Entry) ([]byte, error) {
+			if format != 0 {
+				return encodeBinary(entries, format), nil
+			}
+			return encodeNDJSON(entries, jsonOpts)
+		}
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("batch")
+	}
+	h := &BatchHandler{sender: sender, opts: o}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(entries []*Entry) {
+		h.health.report(h.ship(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	h.queue.spill = o.Spool.spill(o.Encode)
+	return h
+}
+
+func (h *BatchHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *BatchHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.queue.add(ctx, newEntry(record, h.scope))
+}
+
+func (h *BatchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *BatchHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush sends all queued records and replays the spool, if any.
+func (h *BatchHandler) Flush() error {
+	h.queue.Flush()
+	return h.opts.Spool.replay(context.Background(), h.sender)
+}
+
+// Close sends pending records and stops the handler.
+func (h *BatchHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *BatchHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *BatchHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+func (h *BatchHandler) ship(entries []*Entry) error {
+	batch, err := h.opts.Encode(entries)
+	if err != nil {
+		return err
+	}
+	return h.opts.Spool.deliver(context.Background(), batch, h.sender)
+}
@@ -0,0 +1,111 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSender records acknowledged batches and fails with err while it is set.
+type fakeSender struct {
+	mu    sync.Mutex
+	err   error
+	acked []string
+}
+
+func (s *fakeSender) SendBatch(ctx context.Context, batch []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.acked = append(s.acked, strings.TrimSpace(string(batch)))
+	return nil
+}
+
+func (s *fakeSender) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func TestBatchHandlerAtLeastOnce(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+	sender := &fakeSender{err: fmt.Errorf("dial: %w", ErrUnavailable)}
+	var errs []error
+	h := NewBatchHandler(sender, &BatchOptions{
+		Encode: func(entries []*Entry) ([]byte, error) {
+			var msgs []string
+			for _, e := range entries {
+				msgs = append(msgs, e.Message)
+			}
+			return []byte(strings.Join(msgs, ",")), nil
+		},
+		Interval: time.Hour,
+		Spool:    spool,
+		OnError:  func(err error) { errs = append(errs, err) },
+	})
+	defer h.Close()
+	logger := slog.New(h)
+
+	logger.Info("a")
+	h.Flush()
+	logger.Info("b")
+	h.Flush()
+	if len(sender.acked) != 0 || spool.Empty() {
+		t.Fatalf("acked %v while unavailable", sender.acked)
+	}
+
+	sender.setErr(nil)
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("c")
+	h.Flush()
+	if got := strings.Join(sender.acked, " "); got != "a b c" {
+		t.Errorf("acked %q, want %q", got, "a b c")
+	}
+
+	// A rejected batch is dropped and reported, not spooled.
+	sender.setErr(errors.New("bad request"))
+	logger.Info("d")
+	h.Flush()
+	if !spool.Empty() || len(errs) != 1 {
+		t.Errorf("spool empty = %v, errors = %v", spool.Empty(), errs)
+	}
+}
+
+func TestBatchHandlerWithoutSpool(t *testing.T) {
+	sender := &fakeSender{err: ErrUnavailable}
+	var errs []error
+	h := NewBatchHandler(sender, &BatchOptions{
+		Interval: time.Hour,
+		OnError:  func(err error) { errs = append(errs, err) },
+	})
+	slog.New(h).Info("lost")
+	h.Close()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnavailable) {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestRetryPolicyUnavailable(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 1}
+	err := p.do(func() (bool, error) { return true, errors.New("503") })
+	if !errors.Is(err, ErrUnavailable) || err.Error() != "503" {
+		t.Errorf("exhausted retries = %v, want ErrUnavailable", err)
+	}
+	err = p.do(func() (bool, error) { return false, errors.New("400") })
+	if errors.Is(err, ErrUnavailable) {
+		t.Errorf("permanent error %v matches ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,87 @@
+package sloghandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// syncDiscard is a writer that needs a lock, unlike io.Discard.
+type syncDiscard struct{ n int }
+
+func (w *syncDiscard) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+// BenchmarkLogHandlerParallel measures lock contention when many goroutines log at once.
+func BenchmarkLogHandlerParallel(b *testing.B) {
+	opts := &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}
+	b.Run("shared-writer", func(b *testing.B) {
+		logger := slog.New(NewLogHandler(&syncDiscard{}, opts))
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			l := logger.With("worker", 1)
+			for pb.Next() {
+				l.Info("request handled", "status", 200, "path", "/api/items")
+			}
+		})
+	})
+	b.Run("writer-per-goroutine", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			logger := slog.New(NewLogHandler(&syncDiscard{}, opts))
+			for pb.Next() {
+				logger.Info("request handled", "status", 200, "path", "/api/items")
+			}
+		})
+	})
+	b.Run("unlocked-writer", func(b *testing.B) {
+		logger := slog.New(NewLogHandler(io.Discard, opts))
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info("request handled", "status", 200, "path", "/api/items")
+			}
+		})
+	})
+}
+
+// BenchmarkLogHandler measures the cost and allocations of formatting one record.
+func BenchmarkLogHandler(b *testing.B) {
+	defer func(v bool) { color.NoColor = v }(color.NoColor)
+	color.NoColor = false
+
+	base := HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}
+	colored := base
+	colored.Color = true
+	ruled := colored
+	ruled.ColorRules = []ColorRule{{Key: "status", Match: AtLeast(slog.IntValue(500)), Color: color.FgMagenta}}
+	json := base
+	json.Format = FormatJSON
+	for _, bc := range []struct {
+		name  string
+		opts  *HandlerOptions
+		level slog.Level
+		args  []any
+	}{
+		{"message", &base, slog.LevelInfo, nil},
+		{"attrs", &base, slog.LevelInfo, []any{"status", 200, "path", "/api/items", "ok", true, "ratio", 0.25, "elapsed", 1500 * time.Microsecond}},
+		{"color", &colored, slog.LevelWarn, []any{"status", 200, "path", "/api/items"}},
+		{"rule", &ruled, slog.LevelInfo, []any{"status", 503, "path", "/api/items"}},
+		{"json", &json, slog.LevelInfo, []any{"status", 200, "path", "/api/items"}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			logger := slog.New(NewLogHandler(io.Discard, bc.opts)).With("worker", 1)
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				logger.Log(ctx, bc.level, "request handled", bc.args...)
+			}
+		})
+	}
+}
@@ -0,0 +1,820 @@
+package sloghandler
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// BinaryFormat selects a binary encoding of entries, smaller and cheaper
+// to parse than JSON for high-volume internal pipelines. Entries are maps
+// with the fields of MarshalJSON: "time", "level", "msg", an optional
+// "source" map and the attributes, with native numbers, booleans and times.
+// Durations are encoded as integer nanoseconds, as slog.JSONHandler does.
+type BinaryFormat int
+
+const (
+	// MessagePack encodes entries as MessagePack maps, with times as
+	// timestamp extensions.
+	MessagePack BinaryFormat = iota + 1
+	// CBOR encodes entries as CBOR maps (RFC 8949), with times as RFC 3339
+	// strings tagged 0. A stream of entries is a CBOR sequence (RFC 8742).
+	CBOR
+)
+
+func (f BinaryFormat) String() string {
+	switch f {
+	case MessagePack:
+		return "msgpack"
+	case CBOR:
+		return "cbor"
+	}
+	return fmt.Sprintf("BinaryFormat(%d)", int(f))
+}
+
+// ContentType returns the media type of a sequence of entries in f.
+func (f BinaryFormat) ContentType() string {
+	if f == CBOR {
+		return "application/cbor-seq"
+	}
+	return "application/vnd.msgpack"
+}
+
+// Append appends e to dst in the format.
+func (f BinaryFormat) Append(dst []byte, e *Entry) []byte {
+	enc := binaryEncoder{cbor: f == CBOR, buf: dst}
+	n := 3 + len(e.Attrs)
+	if e.Source != nil {
+		n++
+	}
+	enc.head(majorMap, uint64(n))
+	enc.str("time")
+	enc.time(e.Time)
+	enc.str("level")
+	enc.str(LevelName(e.Level))
+	enc.str("msg")
+	enc.str(e.Message)
+	if e.Source != nil {
+		enc.str("source")
+		enc.head(majorMap, 3)
+		enc.str("function")
+		enc.str(e.Source.Function)
+		enc.str("file")
+		enc.str(e.Source.File)
+		enc.str("line")
+		enc.int(int64(e.Source.Line))
+	}
+	for _, a := range e.Attrs {
+		enc.str(a.Key)
+		enc.value(a.Value.Resolve())
+	}
+	return enc.buf
+}
+
+// encodeBinary encodes entries as a sequence in f.
+func encodeBinary(entries []*Entry, f BinaryFormat) []byte {
+	var b []byte
+	for _, e := range entries {
+		b = f.Append(b, e)
+	}
+	return b
+}
+
+// CBOR major types, also used to select the MessagePack type of a head.
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+type binaryEncoder struct {
+	cbor bool
+	buf  []byte
+}
+
+// head appends the header of a string, array or map of length n.
+func (e *binaryEncoder) head(major byte, n uint64) {
+	if e.cbor {
+		e.cborHead(major, n)
+		return
+	}
+	var fix, b8, b16, b32 byte
+	switch major {
+	case majorText:
+		if n < 32 {
+			e.buf = append(e.buf, 0xa0|byte(n))
+			return
+		}
+		fix, b8, b16, b32 = 0, 0xd9, 0xda, 0xdb
+	case majorBytes:
+		fix, b8, b16, b32 = 0, 0xc4, 0xc5, 0xc6
+	case majorArray:
+		fix, b16, b32 = 0x90, 0xdc, 0xdd
+	case majorMap:
+		fix, b16, b32 = 0x80, 0xde, 0xdf
+	}
+	switch {
+	case fix != 0 && n < 16:
+		e.buf = append(e.buf, fix|byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		e.buf = append(e.buf, b8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, b16), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, b32), uint32(n))
+	}
+}
+
+func (e *binaryEncoder) cborHead(major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, m|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, m|26), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, m|27), n)
+	}
+}
+
+func (e *binaryEncoder) str(s string) {
+	e.head(majorText, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *binaryEncoder) bytes(b []byte) {
+	e.head(majorBytes, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *binaryEncoder) int(n int64) {
+	if n >= 0 {
+		e.uint(uint64(n))
+		return
+	}
+	if e.cbor {
+		e.cborHead(majorNegint, uint64(^n))
+		return
+	}
+	switch {
+	case n >= -32:
+		e.buf = append(e.buf, byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xd2), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xd3), uint64(n))
+	}
+}
+
+func (e *binaryEncoder) uint(n uint64) {
+	if e.cbor {
+		e.cborHead(majorUint, n)
+		return
+	}
+	switch {
+	case n < 128:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xce), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), n)
+	}
+}
+
+func (e *binaryEncoder) float(f float64) {
+	if e.cbor {
+		e.buf = append(e.buf, 0xfb)
+	} else {
+		e.buf = append(e.buf, 0xcb)
+	}
+	e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+func (e *binaryEncoder) bool(b bool) {
+	switch {
+	case e.cbor && b:
+		e.buf = append(e.buf, 0xf5)
+	case e.cbor:
+		e.buf = append(e.buf, 0xf4)
+	case b:
+		e.buf = append(e.buf, 0xc3)
+	default:
+		e.buf = append(e.buf, 0xc2)
+	}
+}
+
+func (e *binaryEncoder) null() {
+	if e.cbor {
+		e.buf = append(e.buf, 0xf6)
+	} else {
+		e.buf = append(e.buf, 0xc0)
+	}
+}
+
+func (e *binaryEncoder) time(t time.Time) {
+	if e.cbor {
+		e.cborHead(majorTag, 0)
+		e.str(t.Format(time.RFC3339Nano))
+		return
+	}
+	// timestamp 96: ext8, length 12, type -1, nanoseconds, seconds
+	e.buf = append(e.buf, 0xc7, 12, 0xff)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+// value appends v, which must be resolved.
+func (e *binaryEncoder) value(v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		e.str(v.String())
+	case slog.KindInt64:
+		e.int(v.Int64())
+	case slog.KindUint64:
+		e.uint(v.Uint64())
+	case slog.KindFloat64:
+		e.float(v.Float64())
+	case slog.KindBool:
+		e.bool(v.Bool())
+	case slog.KindTime:
+		e.time(v.Time())
+	case slog.KindDuration:
+		e.int(int64(v.Duration()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		e.head(majorMap, uint64(len(attrs)))
+		for _, a := range attrs {
+			e.str(a.Key)
+			e.value(a.Value.Resolve())
+		}
+	case slog.KindAny:
+		e.any(v.Any())
+	default:
+		e.str(v.String())
+	}
+}
+
+// any appends x as the value of its JSON encoding, or its string form if
+// it cannot be encoded.
+func (e *binaryEncoder) any(x any) {
+	switch x := x.(type) {
+	case nil:
+		e.null()
+		return
+	case error:
+		e.str(x.Error())
+		return
+	case []byte:
+		e.bytes(x)
+		return
+	}
+	b, err := json.Marshal(x)
+	var j any
+	if err == nil {
+		err = json.Unmarshal(b, &j)
+	}
+	if err != nil {
+		e.str(slog.AnyValue(x).String())
+		return
+	}
+	e.json(j)
+}
+
+// json appends a value decoded by encoding/json.
+func (e *binaryEncoder) json(j any) {
+	switch j := j.(type) {
+	case nil:
+		e.null()
+	case bool:
+		e.bool(j)
+	case float64:
+		if j == math.Trunc(j) && math.Abs(j) < 1<<53 {
+			e.int(int64(j))
+		} else {
+			e.float(j)
+		}
+	case string:
+		e.str(j)
+	case []any:
+		e.head(majorArray, uint64(len(j)))
+		for _, v := range j {
+			e.json(v)
+		}
+	case map[string]any:
+		e.head(majorMap, uint64(len(j)))
+		for k, v := range j {
+			e.str(k)
+			e.json(v)
+		}
+	}
+}
+
+// BinaryDecoder reads entries written in a BinaryFormat, e.g. by a
+// BinaryHandler:
+//
+//	dec := sloghandler.NewBinaryDecoder(f, sloghandler.MessagePack)
+//	for {
+//		e, err := dec.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// Attributes decode to the slog kinds they were written from, except
+// durations, which decode as int64 nanoseconds, and non-negative integers,
+// which decode as int64 when they fit. Arrays and maps decode as []any and
+// map[string]any.
+type BinaryDecoder struct {
+	r     *bufio.Reader
+	cbor  bool
+	depth int // of nested arrays and maps
+}
+
+// NewBinaryDecoder returns a decoder reading entries in f from r.
+func NewBinaryDecoder(r io.Reader, f BinaryFormat) *BinaryDecoder {
+	return &BinaryDecoder{r: bufio.NewReader(r), cbor: f == CBOR}
+}
+
+var errBinaryEntry = errors.New("sloghandler: binary entry is not a map")
+
+// Decode reads the next entry. It returns io.EOF at the end of the input.
+func (d *BinaryDecoder) Decode() (*Entry, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	v, err := d.value()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	m, ok := v.(binaryMap)
+	if !ok {
+		return nil, errBinaryEntry
+	}
+	e := &Entry{}
+	for _, kv := range m {
+		switch kv.key {
+		case "time":
+			if t, ok := kv.value.(time.Time); ok {
+				e.Time = t
+				continue
+			}
+		case "level":
+			if s, ok := kv.value.(string); ok {
+				l, err := ParseLevel(s)
+				if err != nil {
+					return nil, fmt.Errorf("sloghandler: invalid level: %w", err)
+				}
+				e.Level = l
+				continue
+			}
+		case "msg":
+			if s, ok := kv.value.(string); ok {
+				e.Message = s
+				continue
+			}
+		case "source":
+			if src, ok := kv.value.(binaryMap); ok {
+				e.Source = &slog.Source{}
+				for _, f := range src {
+					switch f.key {
+					case "function":
+						e.Source.Function, _ = f.value.(string)
+					case "file":
+						e.Source.File, _ = f.value.(string)
+					case "line":
+						n, _ := f.value.(int64)
+						e.Source.Line = int(n)
+					}
+				}
+				continue
+			}
+		}
+		e.Attrs = append(e.Attrs, slog.Attr{Key: kv.key, Value: binaryValue(kv.value)})
+	}
+	return e, nil
+}
+
+// binaryMap is a decoded map, in order.
+type binaryMap []struct {
+	key   string
+	value any
+}
+
+// binaryValue converts a decoded value to a slog.Value.
+func binaryValue(v any) slog.Value {
+	switch v := v.(type) {
+	case binaryMap:
+		m := make(map[string]any, len(v))
+		for _, kv := range v {
+			m[kv.key] = plainValue(kv.value)
+		}
+		return slog.AnyValue(m)
+	case []any:
+		return slog.AnyValue(plainValue(v))
+	}
+	return slog.AnyValue(v)
+}
+
+// plainValue converts the maps nested in v to map[string]any.
+func plainValue(v any) any {
+	switch v := v.(type) {
+	case binaryMap:
+		m := make(map[string]any, len(v))
+		for _, kv := range v {
+			m[kv.key] = plainValue(kv.value)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = plainValue(v[i])
+		}
+	}
+	return v
+}
+
+// noEOF turns an io.EOF in the middle of an entry into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// maxBinaryLen and maxBinaryDepth bound the length of decoded strings and
+// containers and their nesting, so that corrupt input cannot make the
+// decoder allocate or recurse without bound.
+const (
+	maxBinaryLen   = 64 << 20
+	maxBinaryDepth = 100
+)
+
+func (d *BinaryDecoder) value() (any, error) {
+	if d.cbor {
+		return d.cborValue()
+	}
+	return d.msgpackValue()
+}
+
+func (d *BinaryDecoder) enter() error {
+	if d.depth++; d.depth > maxBinaryDepth {
+		return errors.New("sloghandler: binary value nested too deeply")
+	}
+	return nil
+}
+
+func (d *BinaryDecoder) uintN(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func (d *BinaryDecoder) read(n uint64) ([]byte, error) {
+	if n > maxBinaryLen {
+		return nil, fmt.Errorf("sloghandler: binary value of %d bytes is too long", n)
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *BinaryDecoder) array(n uint64) (any, error) {
+	if n > maxBinaryLen {
+		return nil, fmt.Errorf("sloghandler: binary array of %d items is too long", n)
+	}
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	a := make([]any, 0, min(n, 1024))
+	for range n {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *BinaryDecoder) mapN(n uint64) (any, error) {
+	if n > maxBinaryLen {
+		return nil, fmt.Errorf("sloghandler: binary map of %d items is too long", n)
+	}
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	m := make(binaryMap, 0, min(n, 1024))
+	for range n {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, struct {
+			key   string
+			value any
+		}{fmt.Sprint(k), v})
+	}
+	return m, nil
+}
+
+// uintValue returns n as an int64 if it fits.
+func uintValue(n uint64) any {
+	if n <= math.MaxInt64 {
+		return int64(n)
+	}
+	return n
+}
+
+func (d *BinaryDecoder) msgpackValue() (any, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		b, err := d.read(uint64(c & 0x1f))
+		return string(b), err
+	case c&0xf0 == 0x90:
+		return d.array(uint64(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.mapN(uint64(c & 0x0f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uintN(1 << (c - 0xcc))
+		return uintValue(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.uintN(size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xca:
+		n, err := d.uintN(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.uintN(8)
+		return math.Float64frombits(n), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uintN(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		return string(b), err
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uintN(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.read(n)
+	case 0xdc, 0xdd:
+		n, err := d.uintN(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.uintN(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapN(n)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(uint64(1) << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uintN(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	}
+	return nil, fmt.Errorf("sloghandler: invalid msgpack byte %#x", c)
+}
+
+// ext reads an extension value of n bytes, decoding timestamps.
+func (d *BinaryDecoder) ext(n uint64) (any, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.read(n)
+	if err != nil || int8(typ) != -1 {
+		return b, err
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), nil
+	}
+	return nil, fmt.Errorf("sloghandler: invalid msgpack timestamp of %d bytes", n)
+}
+
+func (d *BinaryDecoder) cborValue() (any, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := c>>5, c&0x1f
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27 && (major != majorSimple || info >= 25):
+		if n, err = d.uintN(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	case info == 31:
+		return nil, errors.New("sloghandler: indefinite-length CBOR items are not supported")
+	default:
+		return nil, fmt.Errorf("sloghandler: invalid CBOR byte %#x", c)
+	}
+	switch major {
+	case majorUint:
+		return uintValue(n), nil
+	case majorNegint:
+		if n > math.MaxInt64 {
+			return nil, errors.New("sloghandler: CBOR integer out of range")
+		}
+		return -1 - int64(n), nil
+	case majorBytes:
+		return d.read(n)
+	case majorText:
+		b, err := d.read(n)
+		return string(b), err
+	case majorArray:
+		return d.array(n)
+	case majorMap:
+		return d.mapN(n)
+	case majorTag:
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		v, err := d.cborValue()
+		d.depth--
+		if err != nil {
+			return nil, err
+		}
+		switch x := v.(type) {
+		case string:
+			if n == 0 {
+				return time.Parse(time.RFC3339Nano, x)
+			}
+		case int64:
+			if n == 1 {
+				return time.Unix(x, 0), nil
+			}
+		case float64:
+			if n == 1 {
+				sec, frac := math.Modf(x)
+				return time.Unix(int64(sec), int64(frac*1e9)), nil
+			}
+		}
+		return v, nil
+	}
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float16(uint16(n)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	}
+	return nil, fmt.Errorf("sloghandler: unsupported CBOR simple value %d", n)
+}
+
+// float16 converts an IEEE 754 half-precision number.
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// BinaryHandlerOptions configures a BinaryHandler.
+type BinaryHandlerOptions struct {
+	// Level is the minimum level written. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// AddSource writes the source location of records.
+	AddSource bool
+}
+
+// BinaryHandler is a slog.Handler writing records to an io.Writer in a
+// BinaryFormat, one Write per record, for compact local storage. It works
+// with RotatingFile and DailyFile; read the files with BinaryDecoder.
+type BinaryHandler struct {
+	w      io.Writer
+	format BinaryFormat
+	opts   BinaryHandlerOptions
+	mu     *sync.Mutex // guards w; nil if w is safe for concurrent use
+	scope  attrScope
+}
+
+// NewBinaryHandler creates a handler writing records to w in f.
+func NewBinaryHandler(w io.Writer, f BinaryFormat, opts *BinaryHandlerOptions) *BinaryHandler {
+	o := BinaryHandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	return &BinaryHandler{w: w, format: f, opts: o, mu: lockFor(w)}
+}
+
+func (h *BinaryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *BinaryHandler) Handle(ctx context.Context, record slog.Record) error {
+	e := newEntry(record, h.scope)
+	if !h.opts.AddSource {
+		e.Source = nil
+	}
+	b := h.format.Append(nil, e)
+	if h.mu != nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+	}
+	_, err := h.w.Write(b)
+	return err
+}
+
+func (h *BinaryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *BinaryHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
@@ -0,0 +1,198 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBinaryEncoding(t *testing.T) {
+	ts := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		cbor bool
+		v    slog.Value
+		want string
+	}{
+		// RFC 8949 Appendix A
+		{true, slog.Int64Value(0), "00"},
+		{true, slog.Int64Value(23), "17"},
+		{true, slog.Int64Value(24), "1818"},
+		{true, slog.Int64Value(1000), "1903e8"},
+		{true, slog.Int64Value(-1), "20"},
+		{true, slog.Int64Value(-1000), "3903e7"},
+		{true, slog.Uint64Value(18446744073709551615), "1bffffffffffffffff"},
+		{true, slog.Float64Value(1.1), "fb3ff199999999999a"},
+		{true, slog.BoolValue(true), "f5"},
+		{true, slog.AnyValue(nil), "f6"},
+		{true, slog.StringValue("a"), "6161"},
+		{true, slog.TimeValue(ts), "c074323031332d30332d32315432303a30343a30305a"},
+		{true, slog.AnyValue([]int{1, 2}), "820102"},
+		// MessagePack specification
+		{false, slog.Int64Value(1), "01"},
+		{false, slog.Int64Value(-1), "ff"},
+		{false, slog.Int64Value(-33), "d0df"},
+		{false, slog.Int64Value(128), "cc80"},
+		{false, slog.Int64Value(256), "cd0100"},
+		{false, slog.Int64Value(-70000), "d2fffeee90"},
+		{false, slog.Float64Value(1.5), "cb3ff8000000000000"},
+		{false, slog.BoolValue(false), "c2"},
+		{false, slog.AnyValue(nil), "c0"},
+		{false, slog.StringValue("a"), "a161"},
+		{false, slog.StringValue(strings.Repeat("x", 32)), "d920" + strings.Repeat("78", 32)},
+		{false, slog.TimeValue(ts), "c70cff0000000000000000514b67b0"},
+		{false, slog.DurationValue(time.Second), "ce3b9aca00"},
+		{false, slog.AnyValue(map[string]int{"a": 1}), "81a16101"},
+	} {
+		enc := binaryEncoder{cbor: tc.cbor}
+		enc.value(tc.v)
+		if got := hex.EncodeToString(enc.buf); got != tc.want {
+			t.Errorf("cbor=%v %v: got %s, want %s", tc.cbor, tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 6, 1, 12, 34, 56, 789_000_001, time.UTC)
+	entries := []*Entry{
+		{
+			Time:    ts,
+			Level:   slog.LevelWarn,
+			Message: "hello",
+			Source:  &slog.Source{Function: "main.main", File: "/app/main.go", Line: 42},
+			Attrs: []slog.Attr{
+				slog.String("s", "x"),
+				slog.String("long", strings.Repeat("y", 70000)),
+				slog.Int("neg", -100000),
+				slog.Int64("min", math.MinInt64),
+				slog.Uint64("big", math.MaxUint64),
+				slog.Float64("f", 0.25),
+				slog.Bool("b", true),
+				slog.Time("at", ts.Add(time.Hour)),
+				slog.Duration("d", 1500*time.Millisecond),
+				slog.Any("err", errors.New("boom")),
+				slog.Any("obj", map[string]any{"k": []any{"v", true}}),
+				slog.Any("nil", nil),
+				slog.Any("raw", []byte{1, 2, 3}),
+			},
+		},
+		{Time: ts, Level: LevelTrace, Message: ""},
+	}
+	for _, f := range []BinaryFormat{MessagePack, CBOR} {
+		t.Run(f.String(), func(t *testing.T) {
+			dec := NewBinaryDecoder(bytes.NewReader(encodeBinary(entries, f)), f)
+			for _, want := range entries {
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !got.Time.Equal(want.Time) || got.Level != want.Level || got.Message != want.Message ||
+					!reflect.DeepEqual(got.Source, want.Source) || len(got.Attrs) != len(want.Attrs) {
+					t.Fatalf("got %v, want %v", got, want)
+				}
+				for i, a := range got.Attrs {
+					w := want.Attrs[i]
+					var wv any
+					switch w.Value.Kind() {
+					case slog.KindDuration:
+						wv = int64(w.Value.Duration())
+					case slog.KindAny:
+						wv = w.Value.Any()
+						if err, ok := wv.(error); ok {
+							wv = err.Error()
+						}
+					default:
+						wv = w.Value.Any()
+					}
+					gv := a.Value.Any()
+					if gt, ok := gv.(time.Time); ok && gt.Equal(wv.(time.Time)) {
+						continue
+					}
+					if a.Key != w.Key || !reflect.DeepEqual(gv, wv) {
+						t.Errorf("%s: got %#v, want %#v", w.Key, gv, wv)
+					}
+				}
+			}
+			if _, err := dec.Decode(); err != io.EOF {
+				t.Errorf("expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBinaryDecoderErrors(t *testing.T) {
+	e := &Entry{Time: time.Now(), Message: "hello"}
+	for _, f := range []BinaryFormat{MessagePack, CBOR} {
+		b := f.Append(nil, e)
+		if _, err := NewBinaryDecoder(bytes.NewReader(b[:len(b)-2]), f).Decode(); err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: truncated entry: got %v", f, err)
+		}
+		if _, err := NewBinaryDecoder(bytes.NewReader([]byte{0x01}), f).Decode(); err == nil {
+			t.Errorf("%s: expected an error for a non-map entry", f)
+		}
+	}
+}
+
+func TestBinaryHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewBinaryHandler(&buf, CBOR, nil)).With("app", "api").WithGroup("req")
+	logger.Debug("dropped")
+	logger.Info("served", "status", 200)
+
+	e, err := NewBinaryDecoder(&buf, CBOR).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Message != "served" || e.Source != nil || len(e.Attrs) != 2 ||
+		e.Attrs[0].Value.String() != "api" || e.Attrs[1].Key != "req.status" || e.Attrs[1].Value.Int64() != 200 {
+		t.Errorf("unexpected entry %v", e)
+	}
+}
+
+func TestHTTPHandlerBinary(t *testing.T) {
+	var got []*Entry
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/vnd.msgpack" {
+			t.Errorf("unexpected Content-Type %q", ct)
+		}
+		dec := NewBinaryDecoder(r.Body, MessagePack)
+		for {
+			e, err := dec.Decode()
+			if err != nil {
+				if err != io.EOF {
+					t.Error(err)
+				}
+				break
+			}
+			got = append(got, e)
+		}
+	}))
+	defer ts.Close()
+
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{Binary: MessagePack, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("one", "n", 1)
+	logger.Info("two", "n", 2)
+	h.Close()
+	if len(got) != 2 || got[1].Message != "two" || got[1].Attrs[0].Value.Int64() != 2 {
+		t.Errorf("unexpected entries %v", got)
+	}
+}
+
+func TestBinaryDecoderDepth(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x91}, 1000) // nested msgpack arrays
+	if _, err := NewBinaryDecoder(bytes.NewReader(deep), MessagePack).Decode(); err == nil || err == io.ErrUnexpectedEOF {
+		t.Errorf("expected a nesting error, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ io.Writer = (*LineWriter)(nil)
+
+// LineWriter is an io.Writer that turns each line written to it into a log
+// record, so output of the standard library log package or io.Writer-based
+// libraries gets the same formatting, colors and metrics as slog output.
+// Partial lines are buffered until the newline arrives or Flush is called.
+type LineWriter struct {
+	handler slog.Handler
+	level   slog.Level
+	// classify, if set, chooses the level of a line and may trim it.
+	classify func(line []byte, def slog.Level) (slog.Level, []byte)
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewLineWriter returns a writer that sends each line to h as a record at level.
+func NewLineWriter(h slog.Handler, level slog.Level) *LineWriter {
+	return &LineWriter{handler: h, level: level}
+}
+
+// Write emits a record for each complete line in p.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := w.buf[:i]
+		w.buf = w.buf[i+1:]
+		if err := w.emit(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush emits any buffered partial line as a record.
+func (w *LineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := w.buf
+	w.buf = nil
+	return w.emit(line)
+}
+
+func (w *LineWriter) emit(line []byte) error {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return nil
+	}
+	level := w.level
+	if w.classify != nil {
+		level, line = w.classify(line, level)
+	}
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return nil
+	}
+	return w.handler.Handle(ctx, slog.NewRecord(time.Now(), level, string(line), 0))
+}
+
+// cutLevelPrefix removes a level prefix of the form "[WARN]" or "WARN:"
+// from line, returning its level, or def and line when there is none.
+func cutLevelPrefix(line []byte, def slog.Level) (slog.Level, []byte) {
+	s := bytes.TrimLeft(line, " \t")
+	var name, rest []byte
+	if r, ok := bytes.CutPrefix(s, []byte("[")); ok {
+		name, rest, ok = bytes.Cut(r, []byte("]"))
+		if !ok {
+			return def, line
+		}
+	} else if i := bytes.IndexByte(s, ':'); i > 0 {
+		name, rest = s[:i], s[i+1:]
+	} else {
+		return def, line
+	}
+	for _, c := range name {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return def, line
+		}
+	}
+	var level slog.Level
+	switch strings.ToUpper(string(name)) {
+	case "WARNING":
+		level = slog.LevelWarn
+	case "ERR":
+		level = slog.LevelError
+	default:
+		l, err := ParseLevel(string(name))
+		if err != nil {
+			return def, line
+		}
+		level = l
+	}
+	return level, bytes.TrimLeft(rest, " \t")
+}
+
+// RedirectStdLog routes the output of the standard library log package to h
+// as records at level, and returns a function that restores the previous
+// output and flags. The log prefix is kept as part of the message.
+// h must not write to the log package itself, e.g. slog's built-in default handler.
+func RedirectStdLog(h slog.Handler, level slog.Level) func() {
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(NewLineWriter(h, level))
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	}
+}
+
+// NewStdlibLogger returns a *log.Logger for dependencies that only accept
+// one, such as http.Server.ErrorLog. Each line it writes becomes a record
+// sent to h at level, unless the line starts with a level prefix such as
+// "ERROR:", "[WARN]" or "debug:", which sets the level of the record and is
+// removed from the message. Unlike slog.NewLogLogger, lines of one library
+// can thus be logged at different levels.
+func NewStdlibLogger(h slog.Handler, level slog.Level) *log.Logger {
+	return log.New(&LineWriter{handler: h, level: level, classify: cutLevelPrefix}, "", 0)
+}
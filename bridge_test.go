@@ -0,0 +1,70 @@
+package sloghandler
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	w := NewLineWriter(store, slog.LevelWarn)
+	fmt.Fprint(w, "first line\nsecond ")
+	fmt.Fprint(w, "line\r\n\npartial")
+
+	msgs := func() []string {
+		var out []string
+		for _, e := range store.Records(Query{}) {
+			if e.Level != slog.LevelWarn {
+				t.Errorf("record level = %v, want WARN", e.Level)
+			}
+			out = append(out, e.Message)
+		}
+		return out
+	}
+	if got := strings.Join(msgs(), "|"); got != "first line|second line" {
+		t.Errorf("records = %q", got)
+	}
+	w.Flush()
+	if got := strings.Join(msgs(), "|"); got != "first line|second line|partial" {
+		t.Errorf("records after Flush = %q", got)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewLogHandler(buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}})
+	restore := RedirectStdLog(h, slog.LevelInfo)
+	log.Printf("legacy %d", 42)
+	restore()
+
+	if !strings.HasSuffix(buf.String(), " [INFO] legacy 42\n") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+	if log.Flags() != log.LstdFlags {
+		t.Errorf("flags not restored: %d", log.Flags())
+	}
+}
+
+func TestNewStdlibLogger(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	logger := NewStdlibLogger(store, slog.LevelInfo)
+	logger.Print("ERROR: disk full")
+	logger.Print("[WARN] retrying")
+	logger.Print("  warning: deprecated")
+	logger.Print("debug:cache miss")
+	logger.Print("http: TLS handshake error")
+	logger.Print("[req-1] plain")
+
+	var got []string
+	for _, e := range store.Records(Query{}) {
+		got = append(got, LevelName(e.Level)+" "+e.Message)
+	}
+	want := "ERROR disk full|WARN retrying|WARN deprecated|DEBUG cache miss|INFO http: TLS handshake error|INFO [req-1] plain"
+	if s := strings.Join(got, "|"); s != want {
+		t.Errorf("records = %q, want %q", s, want)
+	}
+}
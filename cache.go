@@ -0,0 +1,84 @@
+package sloghandler
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheStats reports the state of a cache, such as a handler's source
+// location cache.
+type CacheStats struct {
+	// Hits and Misses count lookups since the cache was created.
+	Hits, Misses uint64
+	// Evictions counts entries dropped to stay within the size limit.
+	Evictions uint64
+	// Len is the current number of entries; Cap is the limit.
+	Len, Cap int
+}
+
+// lruCache is a bounded, concurrency-safe LRU cache counting its hits,
+// misses and evictions.
+type lruCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	cap   int
+	items map[K]*list.Element
+	lru   list.List // front is most recently used
+
+	hits, misses, evictions atomic.Uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRUCache returns a cache holding up to size entries.
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{cap: size, items: make(map[K]*list.Element, size)}
+}
+
+// get returns the value of key, marking it as recently used.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+	c.hits.Add(1)
+	c.lru.MoveToFront(e)
+	return e.Value.(*lruEntry[K, V]).value, true
+}
+
+// add stores value under key unless key is present, evicting the least
+// recently used entry when the cache is full.
+func (c *lruCache[K, V]) add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	c.items[key] = c.lru.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.lru.Len() > c.cap {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *lruCache[K, V]) stats() CacheStats {
+	c.mu.Lock()
+	n := c.lru.Len()
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Len:       n,
+		Cap:       c.cap,
+	}
+}
@@ -0,0 +1,38 @@
+package sloghandler
+
+import "runtime"
+
+// CallerPC returns the program counter of the function skip frames above
+// the caller of CallerPC, for logging helpers that build records themselves:
+//
+//	func logf(logger *slog.Logger, format string, args ...any) {
+//		r := slog.NewRecord(time.Now(), slog.LevelInfo, fmt.Sprintf(format, args...), sloghandler.CallerPC(1))
+//		logger.Handler().Handle(context.Background(), r)
+//	}
+//
+// With skip 0 it returns the caller of CallerPC itself.
+func CallerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:]) // skip [Callers, CallerPC]
+	return pcs[0]
+}
+
+// maxCallerDepth bounds the stack searched by skipCallers.
+const maxCallerDepth = 64
+
+// skipCallers returns the program counter skip frames above pc on the
+// current goroutine's stack, or pc if it is not found, e.g. because the
+// record is handled asynchronously.
+func skipCallers(pc uintptr, skip int) uintptr {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip [Callers, skipCallers]
+	for i, p := range pcs[:n] {
+		if p == pc {
+			if i+skip < n {
+				return pcs[i+skip]
+			}
+			break
+		}
+	}
+	return pc
+}
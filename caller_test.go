@@ -0,0 +1,48 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func logViaHelper(logger *slog.Logger, msg string) {
+	logger.Info(msg)
+}
+
+func recordViaHelper(h slog.Handler, msg string) {
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, CallerPC(1))
+	h.Handle(context.Background(), r)
+}
+
+func TestCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true},
+		CallerSkip:     1,
+	})).With("k", "v")
+	_, _, line, _ := runtime.Caller(0)
+	logViaHelper(logger, "wrapped")
+	want := " [caller_test.go:" + strconv.Itoa(line+1) + "]"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("got %q, want source %q", got, want)
+	}
+}
+
+func TestCallerPC(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true},
+	})
+	_, _, line, _ := runtime.Caller(0)
+	recordViaHelper(h, "built")
+	want := " [caller_test.go:" + strconv.Itoa(line+1) + "]"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("got %q, want source %q", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CanonicalLine accumulates the attributes of one request, to be logged as
+// a single summary record when it ends, a "canonical log line". Code deep in
+// the request adds to it through the context instead of logging records of
+// its own:
+//
+//	ctx, line := sloghandler.WithCanonicalLine(ctx)
+//	...
+//	sloghandler.AddCanonical(ctx, "user", userID, "plan", "pro")
+//	line.Inc("db_queries", 1)
+//	...
+//	line.Emit(ctx, logger, slog.LevelInfo, "request finished")
+//
+// httplog.Options.Canonical and grpcinterceptor.Options.Begin integrate it
+// with the access log middleware. It is safe for concurrent use.
+type CanonicalLine struct {
+	mu      sync.Mutex
+	attrs   []slog.Attr
+	emitted bool
+}
+
+type canonicalLineKey struct{}
+
+// WithCanonicalLine returns a context carrying a new CanonicalLine.
+func WithCanonicalLine(ctx context.Context) (context.Context, *CanonicalLine) {
+	c := &CanonicalLine{}
+	return context.WithValue(ctx, canonicalLineKey{}, c), c
+}
+
+// CanonicalLineFromContext returns the CanonicalLine of ctx, if any.
+func CanonicalLineFromContext(ctx context.Context) (*CanonicalLine, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(canonicalLineKey{}).(*CanonicalLine)
+	return c, ok
+}
+
+// AddCanonical adds args, as in slog.Logger.Info, to the CanonicalLine of
+// ctx. It does nothing if ctx has none, so library code can call it freely.
+func AddCanonical(ctx context.Context, args ...any) {
+	if c, ok := CanonicalLineFromContext(ctx); ok {
+		c.Add(args...)
+	}
+}
+
+// BeginCanonical starts a CanonicalLine for a request and returns the
+// context to handle it with and a function returning the accumulated
+// attributes when it ends. Its signature fits the hooks of middleware that
+// write the summary record themselves, such as grpcinterceptor.Options.Begin.
+func BeginCanonical(ctx context.Context) (context.Context, func() []slog.Attr) {
+	ctx, c := WithCanonicalLine(ctx)
+	return ctx, c.Attrs
+}
+
+// Add adds args, as in slog.Logger.Info. A key added again replaces the
+// earlier value in place.
+func (c *CanonicalLine) Add(args ...any) {
+	var r slog.Record
+	r.Add(args...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r.Attrs(func(a slog.Attr) bool {
+		c.setLocked(a)
+		return true
+	})
+}
+
+func (c *CanonicalLine) setLocked(a slog.Attr) {
+	if i := slices.IndexFunc(c.attrs, func(b slog.Attr) bool { return b.Key == a.Key }); i >= 0 {
+		c.attrs[i] = a
+	} else {
+		c.attrs = append(c.attrs, a)
+	}
+}
+
+// Inc adds delta to the integer attribute key, such as a count of database
+// queries, starting from zero.
+func (c *CanonicalLine) Inc(key string, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int64
+	if i := slices.IndexFunc(c.attrs, func(b slog.Attr) bool { return b.Key == key }); i >= 0 &&
+		c.attrs[i].Value.Kind() == slog.KindInt64 {
+		n = c.attrs[i].Value.Int64()
+	}
+	c.setLocked(slog.Int64(key, n+delta))
+}
+
+// Attrs returns a copy of the accumulated attributes.
+func (c *CanonicalLine) Attrs() []slog.Attr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.attrs)
+}
+
+// Emit logs the accumulated attributes followed by args as one record. Only
+// the first call logs; it reports whether this call did.
+func (c *CanonicalLine) Emit(ctx context.Context, logger *slog.Logger, level slog.Level, msg string, args ...any) bool {
+	c.mu.Lock()
+	if c.emitted {
+		c.mu.Unlock()
+		return false
+	}
+	c.emitted = true
+	attrs := slices.Clone(c.attrs)
+	c.mu.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !logger.Enabled(ctx, level) {
+		return true
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [Callers, Emit]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	r.Add(args...)
+	_ = logger.Handler().Handle(ctx, r)
+	return true
+}
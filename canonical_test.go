@@ -0,0 +1,48 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCanonicalLine(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+	}))
+
+	AddCanonical(context.Background(), "ignored", true) // no line in the context
+
+	ctx, line := WithCanonicalLine(context.Background())
+	AddCanonical(ctx, "user", "alice", "plan", "free")
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() { line.Inc("db_queries", 1) })
+	}
+	wg.Wait()
+	AddCanonical(ctx, "plan", "pro")
+
+	if !line.Emit(ctx, logger, slog.LevelInfo, "request finished", "status", 200) {
+		t.Error("first Emit did not log")
+	}
+	if line.Emit(ctx, logger, slog.LevelInfo, "request finished") {
+		t.Error("second Emit logged")
+	}
+	want := "[INFO] request finished [user:alice] [plan:pro] [db_queries:10] [status:200]\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) || strings.Count(got, "\n") != 1 {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestBeginCanonical(t *testing.T) {
+	ctx, end := BeginCanonical(context.Background())
+	AddCanonical(ctx, "tenant", "acme")
+	attrs := end()
+	if len(attrs) != 1 || attrs[0].Key != "tenant" || attrs[0].Value.String() != "acme" {
+		t.Errorf("got %v", attrs)
+	}
+}
@@ -0,0 +1,29 @@
+package sloghandler
+
+import (
+	"sync"
+	"time"
+)
+
+// FrozenClock returns a clock for HandlerOptions.Now that always reports t,
+// for deterministic output in tests:
+//
+//	opts.Now = sloghandler.FrozenClock(time.Date(2023, 5, 9, 12, 34, 56, 789_000_000, time.UTC))
+func FrozenClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// SteppingClock returns a clock for HandlerOptions.Now that reports start on
+// its first call and advances by step on every following call, for tests
+// that need distinct but predictable timestamps.
+func SteppingClock(start time.Time, step time.Duration) func() time.Time {
+	var mu sync.Mutex
+	next := start
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		t := next
+		next = next.Add(step)
+		return t
+	}
+}
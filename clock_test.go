@@ -0,0 +1,34 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestFrozenClock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Now:            FrozenClock(time.Date(2023, 5, 9, 12, 34, 56, 789_000_000, time.UTC)),
+	}))
+	logger.Info("hello", "n", 1)
+	logger.Info("again")
+
+	want := "2023-05-09T12:34:56.789Z [INFO] hello [n:1]\n" +
+		"2023-05-09T12:34:56.789Z [INFO] again\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSteppingClock(t *testing.T) {
+	start := time.Date(2023, 5, 9, 0, 0, 0, 0, time.UTC)
+	now := SteppingClock(start, time.Second)
+	for i := range 3 {
+		if got, want := now(), start.Add(time.Duration(i)*time.Second); !got.Equal(want) {
+			t.Errorf("call %d: got %v, want %v", i, got, want)
+		}
+	}
+}
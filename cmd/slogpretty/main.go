@@ -0,0 +1,118 @@
+// Command slogpretty reads slog JSON or logfmt lines from stdin and prints
+// them in the colored bracket format of sloghandler.
+//
+//	kubectl logs deploy/api | slogpretty -level warn -attrs status,path
+//
+// Lines that are not log records are printed unchanged. With -scrub,
+// personal data is pseudonymized so that the output can be shared:
+//
+//	slogpretty -scrub < app.log > excerpt.log
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/fujiwara/sloghandler"
+	"github.com/fujiwara/sloghandler/internal/logline"
+)
+
+type config struct {
+	level slog.Level
+	attrs []string
+	color string
+	scrub bool
+	// scrubKey is the pseudonymization key; nil uses a random key.
+	scrubKey []byte
+}
+
+func main() {
+	var (
+		cfg   config
+		level string
+		attrs string
+	)
+	flag.StringVar(&level, "level", "trace", "minimum level to print")
+	flag.StringVar(&attrs, "attrs", "", "comma-separated attribute keys to print (default all)")
+	flag.StringVar(&cfg.color, "color", "auto", "colorize output: auto, always or never")
+	flag.BoolVar(&cfg.scrub, "scrub", false, "pseudonymize personal data, keyed by $SLOGPRETTY_SCRUB_KEY (default random)")
+	flag.Parse()
+	if k := os.Getenv("SLOGPRETTY_SCRUB_KEY"); k != "" {
+		cfg.scrubKey = []byte(k)
+	}
+
+	l, err := sloghandler.ParseLevel(level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "slogpretty:", err)
+		os.Exit(2)
+	}
+	cfg.level = l
+	if attrs != "" {
+		cfg.attrs = strings.Split(attrs, ",")
+	}
+	if err := run(os.Stdin, os.Stdout, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "slogpretty:", err)
+		os.Exit(1)
+	}
+}
+
+func run(r io.Reader, w io.Writer, cfg config) error {
+	switch cfg.color {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	case "auto":
+	default:
+		return fmt.Errorf("invalid -color %q", cfg.color)
+	}
+	var h slog.Handler = sloghandler.NewLogHandler(w, &sloghandler.HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: cfg.level},
+		Color:          !color.NoColor,
+	})
+	if cfg.scrub {
+		h = sloghandler.NewTransformHandler(h, sloghandler.Pseudonymize(cfg.scrubKey, sloghandler.PIIPseudonymProfile))
+	}
+	ctx := context.Background()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		rec, err := logline.Decode(line)
+		if err != nil {
+			fmt.Fprintln(w, line)
+			continue
+		}
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, selectAttrs(rec, cfg.attrs)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// selectAttrs returns r with only the attributes named in keys, or r itself
+// when keys is empty.
+func selectAttrs(r slog.Record, keys []string) slog.Record {
+	if len(keys) == 0 {
+		return r
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if slices.Contains(keys, a.Key) {
+			r2.AddAttrs(a)
+		}
+		return true
+	})
+	return r2
+}
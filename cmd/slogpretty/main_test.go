@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+func TestRun(t *testing.T) {
+	sloghandler.TimeFormat = "15:04:05"
+	input := strings.Join([]string{
+		`{"time":"2023-05-09T12:34:56.789Z","level":"INFO","msg":"request","status":200,"path":"/","req":{"id":7}}`,
+		`{"time":"2023-05-09T12:34:57Z","level":"DEBUG","msg":"noise"}`,
+		`time=2023-05-09T12:34:58.000Z level=WARN msg="slow request" status=200 path=/search latency=1.5`,
+		`panic: something went wrong`,
+		`{"time":"2023-05-09T12:34:59Z","level":"ERROR","msg":"failed","source":{"function":"main.f","file":"/src/main.go","line":12},"status":500}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	if err := run(strings.NewReader(input), out, config{level: slog.LevelInfo, color: "never"}); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`12:34:56 [INFO] request [status:200] [path:/] [req:[id=7]]`,
+		`12:34:58 [WARN] slow request [status:200] [path:/search] [latency:1.5]`,
+		`panic: something went wrong`,
+		`12:34:59 [ERROR] failed [source:main.go:12] [status:500]`,
+	}, "\n") + "\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := run(strings.NewReader(input), out, config{level: slog.LevelWarn, attrs: []string{"path"}, color: "never"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "[WARN] slow request [path:/search]\n") || strings.Contains(out.String(), "status") {
+		t.Errorf("attrs not selected:\n%s", out.String())
+	}
+}
+
+func TestRunScrub(t *testing.T) {
+	sloghandler.TimeFormat = "15:04:05"
+	input := `{"time":"2023-05-09T12:34:56Z","level":"INFO","msg":"login by bob@example.com","user_id":42,"client_ip":"192.0.2.1"}
+{"time":"2023-05-09T12:34:57Z","level":"INFO","msg":"logout","user_id":42,"note":"from 192.0.2.1"}`
+	out := &bytes.Buffer{}
+	key := []byte("secret")
+	if err := run(strings.NewReader(input), out, config{color: "never", scrub: true, scrubKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	user, ip := sloghandler.Pseudonym(key, "42"), sloghandler.Pseudonym(key, "192.0.2.1")
+	want := "12:34:56 [INFO] login by " + sloghandler.Pseudonym(key, "bob@example.com") + " [user_id:" + user + "] [client_ip:" + ip + "]\n" +
+		"12:34:57 [INFO] logout [user_id:" + user + "] [note:from " + ip + "]\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
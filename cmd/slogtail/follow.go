@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// scanLines calls emit for each line of r as it is read.
+func scanLines(r io.Reader, emit func(string)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		emit(sc.Text())
+	}
+	return sc.Err()
+}
+
+// lastLines returns the last n lines of r, or all lines when n is negative.
+func lastLines(r io.Reader, n int) ([]string, error) {
+	var lines []string
+	err := scanLines(r, func(l string) {
+		lines = append(lines, l)
+		if n >= 0 && len(lines) > n {
+			lines = lines[1:]
+		}
+	})
+	return lines, err
+}
+
+// follow sends lines appended to path after offset until ctx is done.
+// It reopens the file when it is truncated or replaced, e.g. by log rotation,
+// after sending what was left in the old file.
+func follow(ctx context.Context, path string, offset int64, interval time.Duration, emit func(string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	var partial []byte
+	buf := make([]byte, 32*1024)
+	// drain sends the complete lines read from f up to its end.
+	drain := func() error {
+		for {
+			n, err := f.Read(buf)
+			partial = append(partial, buf[:n]...)
+			for {
+				i := bytes.IndexByte(partial, '\n')
+				if i < 0 {
+					break
+				}
+				emit(string(bytes.TrimSuffix(partial[:i], []byte("\r"))))
+				partial = partial[i+1:]
+			}
+			if err == io.EOF || n == 0 {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := drain(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if reopened, err := reopenIfRotated(f, path); err != nil {
+			return err
+		} else if reopened != nil {
+			// Lines written between the last read and the rotation, and an
+			// unterminated last line, are only in the old file.
+			err := drain()
+			if len(partial) > 0 {
+				emit(string(bytes.TrimSuffix(partial, []byte("\r"))))
+			}
+			f.Close()
+			f, partial = reopened, nil
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reopenIfRotated returns a new handle on path when the file behind f was
+// truncated or replaced, and nil otherwise.
+func reopenIfRotated(f *os.File, path string) (*os.File, error) {
+	cur, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, nil // replaced but not recreated yet
+	}
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if os.SameFile(cur, st) && st.Size() >= pos {
+		return nil, nil
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,194 @@
+// Command slogtail prints and follows log files (or stdin) written in the
+// sloghandler bracket format, slog JSON or logfmt, with filters, re-rendering
+// every record in the colored bracket format.
+//
+//	slogtail -f -level warn -attr status=500 -grep timeout /var/log/app.log
+//
+// Lines that are not log records are printed unless an -attr filter is set.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fujiwara/sloghandler"
+	"github.com/fujiwara/sloghandler/internal/logline"
+)
+
+type attrFilters []string
+
+func (f *attrFilters) String() string     { return strings.Join(*f, ",") }
+func (f *attrFilters) Set(s string) error { *f = append(*f, s); return nil }
+
+// filter selects the lines to print.
+type filter struct {
+	level slog.Level
+	attrs map[string]string
+	re    *regexp.Regexp
+}
+
+// match reports whether a line and its decoded record (nil if the line is
+// not a log record) pass the filter.
+func (f *filter) match(line string, r *slog.Record) bool {
+	if f.re != nil && !f.re.MatchString(sloghandler.StripANSI(line)) {
+		return false
+	}
+	if r == nil {
+		return len(f.attrs) == 0
+	}
+	if r.Level < f.level {
+		return false
+	}
+	if len(f.attrs) == 0 {
+		return true
+	}
+	matched := 0
+	r.Attrs(func(a slog.Attr) bool {
+		if v, ok := f.attrs[a.Key]; ok && a.Value.String() == v {
+			matched++
+		}
+		return true
+	})
+	return matched == len(f.attrs)
+}
+
+// printer renders lines from several files to one writer.
+type printer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	h      slog.Handler
+	filter *filter
+	label  bool
+}
+
+func (p *printer) print(file, line string) {
+	var rec *slog.Record
+	if r, err := logline.Decode(line); err == nil {
+		rec = &r
+	}
+	if !p.filter.match(line, rec) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rec == nil {
+		fmt.Fprintln(p.w, line)
+		return
+	}
+	if p.label {
+		rec.AddAttrs(slog.String("file", file))
+	}
+	p.h.Handle(context.Background(), *rec)
+}
+
+func main() {
+	var (
+		followFlag bool
+		lines      int
+		level      string
+		grep       string
+		colorMode  string
+		attrs      attrFilters
+	)
+	flag.BoolVar(&followFlag, "f", false, "keep reading as files grow, reopening them when rotated")
+	flag.IntVar(&lines, "n", 10, "number of trailing lines to print first; negative prints whole files")
+	flag.StringVar(&level, "level", "trace", "minimum level to print")
+	flag.StringVar(&grep, "grep", "", "print only lines matching this regular expression")
+	flag.Var(&attrs, "attr", "print only records with this key=value attribute (repeatable)")
+	flag.StringVar(&colorMode, "color", "auto", "colorize output: auto, always or never")
+	flag.Parse()
+
+	p, err := newPrinter(os.Stdout, level, grep, colorMode, attrs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "slogtail:", err)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, p, flag.Args(), lines, followFlag, 250*time.Millisecond); err != nil {
+		fmt.Fprintln(os.Stderr, "slogtail:", err)
+		os.Exit(1)
+	}
+}
+
+func newPrinter(w io.Writer, level, grep, colorMode string, attrs []string) (*printer, error) {
+	f := &filter{attrs: map[string]string{}}
+	l, err := sloghandler.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	f.level = l
+	if grep != "" {
+		if f.re, err = regexp.Compile(grep); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range attrs {
+		k, v, ok := strings.Cut(a, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -attr %q, want key=value", a)
+		}
+		f.attrs[k] = v
+	}
+	switch colorMode {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	case "auto":
+	default:
+		return nil, fmt.Errorf("invalid -color %q", colorMode)
+	}
+	h := sloghandler.NewLogHandler(w, &sloghandler.HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: sloghandler.LevelTrace},
+		Color:          !color.NoColor,
+	})
+	return &printer{w: w, h: h, filter: f}, nil
+}
+
+func run(ctx context.Context, p *printer, files []string, n int, followFiles bool, interval time.Duration) error {
+	if len(files) == 0 {
+		return scanLines(os.Stdin, func(l string) { p.print("-", l) })
+	}
+	p.label = len(files) > 1
+	var wg sync.WaitGroup
+	errs := make(chan error, len(files))
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		ls, err := lastLines(f, n)
+		offset, _ := f.Seek(0, io.SeekCurrent)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		for _, l := range ls {
+			p.print(path, l)
+		}
+		if !followFiles {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := follow(ctx, path, offset, interval, func(l string) { p.print(path, l) }); err != nil {
+				errs <- fmt.Errorf("%s: %w", path, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
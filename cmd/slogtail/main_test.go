@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+const sample = `2023-05-09T12:34:56.789Z [INFO] request [status:200] [path:/]
+{"time":"2023-05-09T12:34:57Z","level":"ERROR","msg":"request failed","status":500,"path":"/api"}
+time=2023-05-09T12:34:58Z level=DEBUG msg="cache miss" key=user:1
+goroutine 1 [running]:
+2023-05-09T12:34:59.000Z [WARN] request timeout [status:504] [path:/slow]
+`
+
+func TestFilters(t *testing.T) {
+	sloghandler.TimeFormat = "15:04:05"
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(sample), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		level, grep string
+		attrs       []string
+		n           int
+		want        string
+	}{
+		{"info", "", nil, -1, "12:34:56 [INFO] request [status:200] [path:/]\n" +
+			"12:34:57 [ERROR] request failed [status:500] [path:/api]\n" +
+			"goroutine 1 [running]:\n" +
+			"12:34:59 [WARN] request timeout [status:504] [path:/slow]\n"},
+		{"trace", "", []string{"path=/api"}, -1, "12:34:57 [ERROR] request failed [status:500] [path:/api]\n"},
+		{"trace", "time ?out", nil, -1, "12:34:59 [WARN] request timeout [status:504] [path:/slow]\n"},
+		{"trace", "", nil, 2, "goroutine 1 [running]:\n" +
+			"12:34:59 [WARN] request timeout [status:504] [path:/slow]\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		p, err := newPrinter(out, tt.level, tt.grep, "never", tt.attrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := run(context.Background(), p, []string{path}, tt.n, false, time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("level=%s grep=%q attrs=%v n=%d\ngot:\n%s\nwant:\n%s", tt.level, tt.grep, tt.attrs, tt.n, out.String(), tt.want)
+		}
+	}
+}
+
+func TestFollowRotation(t *testing.T) {
+	sloghandler.TimeFormat = "15:04:05"
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("2023-05-09T12:00:00.000Z [INFO] old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := &syncBuffer{}
+	p, err := newPrinter(out, "trace", "", "never", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- run(ctx, p, []string{path}, 1, true, 5*time.Millisecond) }()
+
+	waitFor := func(s string) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if strings.Contains(out.String(), s) {
+				return
+			}
+		}
+		t.Fatalf("timed out waiting for %q, got:\n%s", s, out.String())
+	}
+	waitFor("old") // existing lines are printed before following starts
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("2023-05-09T12:00:01.000Z [INFO] appended\n2023-05-09T12:00:02.000Z [INFO] par")
+	f.Close()
+	waitFor("appended")
+	f, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("tial\n")
+	f.Close()
+	waitFor("[INFO] partial")
+
+	// Rotate: replace the file with a new one.
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("2023-05-09T12:00:03.000Z [INFO] rotated\n"), 0o644)
+	waitFor("rotated")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "appended") != 1 {
+		t.Errorf("lines should be printed once:\n%s", out.String())
+	}
+}
+
+func TestFollowDrainsRotatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := &syncBuffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- follow(ctx, path, 0, 200*time.Millisecond, func(l string) { out.Write([]byte(l + "\n")) })
+	}()
+	for deadline := time.Now().Add(2 * time.Second); !strings.Contains(out.String(), "first"); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the first line")
+		}
+	}
+
+	// Write to the old file and rotate it before the next poll.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("late\nunterminated")
+	f.Close()
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("rotated\n"), 0o644)
+
+	want := "first\nlate\nunterminated\nrotated\n"
+	for deadline := time.Now().Add(2 * time.Second); out.String() != want && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package sloghandler
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// CoalescingWriter is an io.Writer that collects writes arriving within a
+// short window and passes them to the underlying writer in a single Write,
+// in order. It reduces syscall overhead when logging thousands of lines per
+// second to files or pipes, at the cost of delaying output by up to the window.
+//
+// Do not use it in front of a DatagramWriter, which relies on one record per Write.
+type CoalescingWriter struct {
+	w        io.Writer
+	window   time.Duration
+	maxBytes int
+
+	mu     sync.Mutex
+	buf    []byte
+	timer  *time.Timer
+	err    error
+	closed bool
+}
+
+// NewCoalescingWriter returns a writer that delays writes to w by up to window,
+// writing early once maxBytes are pending. A maxBytes of 0 or less defaults to 64 KiB.
+func NewCoalescingWriter(w io.Writer, window time.Duration, maxBytes int) *CoalescingWriter {
+	if maxBytes <= 0 {
+		maxBytes = 64 << 10
+	}
+	return &CoalescingWriter{w: w, window: window, maxBytes: maxBytes}
+}
+
+// Write queues p. It returns the error of a failed background write, if any,
+// without queuing p.
+func (c *CoalescingWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, os.ErrClosed
+	}
+	if err := c.err; err != nil {
+		c.err = nil
+		return 0, err
+	}
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.maxBytes || c.window <= 0 {
+		if err := c.flushLocked(); err != nil {
+			c.err = nil
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.window, c.flushTimer)
+	}
+	return len(p), nil
+}
+
+func (c *CoalescingWriter) flushTimer() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timer = nil
+	c.flushLocked()
+}
+
+// flushLocked writes the pending bytes, keeping a write error for the next caller.
+func (c *CoalescingWriter) flushLocked() error {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.w.Write(c.buf)
+	c.buf = c.buf[:0]
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return err
+}
+
+// Flush writes pending bytes now. It returns the first write error since
+// the previous report.
+func (c *CoalescingWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushLocked()
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// Close flushes pending bytes and stops accepting writes.
+// It does not close the underlying writer.
+func (c *CoalescingWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushLocked()
+	c.closed = true
+	err := c.err
+	c.err = nil
+	return err
+}
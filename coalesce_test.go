@@ -0,0 +1,75 @@
+package sloghandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes []string
+	err    error
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordingWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestCoalescingWriter(t *testing.T) {
+	rw := &recordingWriter{}
+	cw := NewCoalescingWriter(rw, time.Hour, 16)
+	for i := range 5 {
+		fmt.Fprintf(cw, "line%d\n", i)
+	}
+	// 3 lines of 6 bytes reach the 16 byte limit.
+	if got := rw.Writes(); len(got) != 1 || got[0] != "line0\nline1\nline2\n" {
+		t.Errorf("writes = %q", got)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rw.Writes(), ""); got != "line0\nline1\nline2\nline3\nline4\n" {
+		t.Errorf("output = %q", got)
+	}
+	if _, err := cw.Write([]byte("x")); err == nil {
+		t.Error("expected an error after Close")
+	}
+}
+
+func TestCoalescingWriterWindow(t *testing.T) {
+	rw := &recordingWriter{}
+	cw := NewCoalescingWriter(rw, 20*time.Millisecond, 0)
+	cw.Write([]byte("a\n"))
+	cw.Write([]byte("b\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rw.Writes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := rw.Writes(); len(got) != 1 || got[0] != "a\nb\n" {
+		t.Errorf("writes = %q", got)
+	}
+
+	rw.mu.Lock()
+	rw.err = errors.New("disk full")
+	rw.mu.Unlock()
+	cw.Write([]byte("c\n"))
+	if err := cw.Flush(); err == nil {
+		t.Error("expected the write error from Flush")
+	}
+}
@@ -0,0 +1,94 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// ColorRule colors a whole log line when an attribute matches, regardless of
+// its level. Rules are evaluated in order against the record's attributes and
+// the first matching rule wins over the level color.
+//
+//	opts.ColorRules = []sloghandler.ColorRule{
+//		{Key: "status", Match: sloghandler.AtLeast(slog.IntValue(500)), Color: color.FgRed},
+//		{Key: "latency", Match: sloghandler.AtLeast(slog.DurationValue(time.Second)), Color: color.FgYellow},
+//	}
+type ColorRule struct {
+	// Key is the attribute key tested by Match.
+	Key string
+	// Match reports whether the attribute value selects Color.
+	Match func(v slog.Value) bool
+	// Color is applied to the line when Match returns true.
+	Color color.Attribute
+}
+
+// AtLeast returns a ColorRule or SeverityRule predicate that matches values greater than or
+// equal to threshold. Durations are compared with durations; integers,
+// unsigned integers and floats are compared numerically with each other.
+// Other values never match.
+func AtLeast(threshold slog.Value) func(slog.Value) bool {
+	return func(v slog.Value) bool {
+		c, ok := compareValues(v, threshold)
+		return ok && c >= 0
+	}
+}
+
+// Equals returns a ColorRule or SeverityRule predicate that matches values whose string form is s.
+func Equals(s string) func(slog.Value) bool {
+	return func(v slog.Value) bool {
+		return v.String() == s
+	}
+}
+
+// compareValues compares a and b, reporting false when they are not comparable.
+func compareValues(a, b slog.Value) (int, bool) {
+	if a.Kind() == slog.KindDuration || b.Kind() == slog.KindDuration {
+		if a.Kind() != b.Kind() {
+			return 0, false
+		}
+		return compareOrdered(a.Duration(), b.Duration()), true
+	}
+	x, ok := numericValue(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := numericValue(b)
+	if !ok {
+		return 0, false
+	}
+	return compareOrdered(x, y), true
+}
+
+func numericValue(v slog.Value) (float64, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return float64(v.Int64()), true
+	case slog.KindUint64:
+		return float64(v.Uint64()), true
+	case slog.KindFloat64:
+		return v.Float64(), true
+	}
+	return 0, false
+}
+
+func compareOrdered[T float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// matchColorRule returns the index of the first rule matching a, or -1.
+func matchColorRule(rules []ColorRule, a slog.Attr) int {
+	for i, rule := range rules {
+		if rule.Key == a.Key && rule.Match != nil && rule.Match(a.Value.Resolve()) {
+			return i
+		}
+	}
+	return -1
+}
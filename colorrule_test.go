@@ -0,0 +1,67 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestColorRules(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Color:          true,
+		ColorRules: []ColorRule{
+			{Key: "status", Match: AtLeast(slog.IntValue(500)), Color: color.FgRed},
+			{Key: "latency", Match: AtLeast(slog.DurationValue(time.Second)), Color: color.FgYellow},
+			{Key: "user", Match: Equals("admin"), Color: color.FgMagenta},
+		},
+	}))
+
+	tests := []struct {
+		args []any
+		want string
+	}{
+		{[]any{"status", 503}, "\033[31m"},
+		{[]any{"status", 200}, ""},
+		{[]any{"latency", 1500 * time.Millisecond}, "\033[33m"},
+		{[]any{"latency", 10 * time.Millisecond}, ""},
+		{[]any{"latency", 2 * time.Second, "status", 500}, "\033[31m"}, // first rule wins
+		{[]any{"status", "500"}, ""},                                   // strings are not numbers
+		{[]any{"user", "admin"}, "\033[35m"},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		logger.Info("request", tt.args...)
+		out := buf.String()
+		if tt.want == "" {
+			if strings.Contains(out, "\033[") {
+				t.Errorf("%v: unexpected color in %q", tt.args, out)
+			}
+		} else if !strings.HasPrefix(out, tt.want) {
+			t.Errorf("%v: expected prefix %q in %q", tt.args, tt.want, out)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		threshold, v slog.Value
+		want         bool
+	}{
+		{slog.IntValue(500), slog.Uint64Value(500), true},
+		{slog.IntValue(500), slog.Float64Value(499.5), false},
+		{slog.Float64Value(0.9), slog.IntValue(1), true},
+		{slog.DurationValue(time.Second), slog.IntValue(2), false},
+		{slog.IntValue(1), slog.BoolValue(true), false},
+	}
+	for _, tt := range tests {
+		if got := AtLeast(tt.threshold)(tt.v); got != tt.want {
+			t.Errorf("AtLeast(%v)(%v) = %v, want %v", tt.threshold, tt.v, got, tt.want)
+		}
+	}
+}
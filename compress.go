@@ -0,0 +1,79 @@
+package sloghandler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// Compressor compresses the output of the file and network sinks, so that
+// CPU can be traded for bandwidth and disk space the same way everywhere.
+// GzipCompression and NoCompression are built in; the zstdlog module
+// provides zstd.
+type Compressor interface {
+	// Encoding is the name of the format as an HTTP Content-Encoding, e.g.
+	// "gzip", or "" for no compression.
+	Encoding() string
+	// Extension is appended to the names of compressed files, e.g. ".gz".
+	Extension() string
+	// NewWriter returns a writer compressing to w. Closing it flushes the
+	// compressed data without closing w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	// NoCompression writes data as is.
+	NoCompression Compressor = noCompressor{}
+	// GzipCompression compresses with gzip at the default level.
+	GzipCompression Compressor = GzipCompressor{}
+)
+
+type noCompressor struct{}
+
+func (noCompressor) Encoding() string  { return "" }
+func (noCompressor) Extension() string { return "" }
+
+func (noCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// GzipCompressor compresses with gzip.
+type GzipCompressor struct {
+	// Level is the compression level, e.g. gzip.BestSpeed. Default (0) is
+	// gzip.DefaultCompression; use NoCompression not to compress.
+	Level int
+}
+
+func (GzipCompressor) Encoding() string  { return "gzip" }
+func (GzipCompressor) Extension() string { return ".gz" }
+
+func (c GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// compress returns p compressed with c. A nil c does not compress.
+func compress(c Compressor, p []byte) ([]byte, error) {
+	if c == nil || c.Encoding() == "" {
+		return p, nil
+	}
+	var buf bytes.Buffer
+	zw, err := c.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+package sloghandler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCompress(t *testing.T) {
+	data := bytes.Repeat([]byte("2024-01-01T00:00:00Z [INFO] hello\n"), 100)
+	for _, c := range []Compressor{nil, NoCompression} {
+		got, err := compress(c, data)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("compress(%v) changed the data: %v", c, err)
+		}
+	}
+	for _, c := range []Compressor{GzipCompression, GzipCompressor{Level: gzip.BestSpeed}} {
+		got, err := compress(c, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) >= len(data) {
+			t.Errorf("compressed to %d bytes from %d", len(got), len(data))
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(got))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, err := io.ReadAll(zr); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+}
+
+func TestLokiHandlerCompressor(t *testing.T) {
+	var got map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected Content-Encoding %q", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewDecoder(zr).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	h, err := NewLokiHandler(ts.URL, &LokiOptions{Compressor: GzipCompression, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(h).Info("hello")
+	h.Close()
+	if streams, _ := got["streams"].([]any); len(streams) != 1 {
+		t.Errorf("unexpected push %v", got)
+	}
+}
@@ -0,0 +1,252 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Config describes a handler chain as a list of sink DSNs, for example:
+//
+//	{"level": "debug", "sinks": ["console://stderr?color=auto", "file:///var/log/app.log?rotate=100MB"]}
+//
+// See ParseDSN for the DSN syntax.
+type Config struct {
+	// Level is the default level for sinks whose DSN has no level parameter.
+	Level string `json:"level,omitempty"`
+	// Sinks lists the DSNs of the handlers records are sent to.
+	Sinks []string `json:"sinks"`
+}
+
+// ParseConfig decodes a JSON Config.
+func ParseConfig(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("sloghandler: invalid config: %w", err)
+	}
+	return &c, nil
+}
+
+// Handler builds the configured handler chain. If it implements io.Closer,
+// closing it closes every sink.
+func (c *Config) Handler() (slog.Handler, error) {
+	if len(c.Sinks) == 0 {
+		return nil, fmt.Errorf("sloghandler: config has no sinks")
+	}
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			return nil, fmt.Errorf("sloghandler: invalid config level: %w", err)
+		}
+	}
+	var handlers []slog.Handler
+	for _, dsn := range c.Sinks {
+		if c.Level != "" {
+			dsn = withDefaultLevel(dsn, c.Level)
+		}
+		h, err := ParseDSN(dsn)
+		if err != nil {
+			NewFanoutHandler(handlers...).Close()
+			return nil, err
+		}
+		handlers = append(handlers, h)
+	}
+	if len(handlers) == 1 {
+		return handlers[0], nil
+	}
+	return NewFanoutHandler(handlers...), nil
+}
+
+// withDefaultLevel adds a level parameter to dsn unless it has one.
+func withDefaultLevel(dsn, level string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn // reported by ParseDSN
+	}
+	q := u.Query()
+	if q.Has("level") {
+		return dsn
+	}
+	q.Set("level", level)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// WatchOptions configures a ConfigWatcher.
+type WatchOptions struct {
+	// Interval is how often the file is checked for changes. Default is 5 seconds.
+	// A negative value disables polling, leaving reloads to signals and Reload.
+	Interval time.Duration
+	// Signals trigger a reload. Default is SIGHUP on Unix; an empty non-nil slice disables signals.
+	Signals []os.Signal
+	// OnReload is called with the new config after a successful reload.
+	OnReload func(*Config)
+	// OnError is called when a reload fails; the running handlers are kept.
+	// Default writes the error to os.Stderr.
+	OnError func(error)
+}
+
+// ConfigWatcher keeps a ReloadableHandler in sync with a JSON config file.
+// When the file changes or a signal arrives, it builds the new handler
+// chain, swaps it in, and closes the old one after its pending records
+// have been handled.
+type ConfigWatcher struct {
+	path    string
+	opts    WatchOptions
+	handler *ReloadableHandler
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// WatchConfig loads the config file at path and starts watching it.
+// It returns an error if the initial config cannot be loaded.
+func WatchConfig(path string, opts *WatchOptions) (*ConfigWatcher, error) {
+	o := WatchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval == 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.Signals == nil {
+		o.Signals = defaultReloadSignals
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("config")
+	}
+	w := &ConfigWatcher{
+		path: path,
+		opts: o,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	h, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.handler = NewReloadableHandler(h)
+	go w.run()
+	return w, nil
+}
+
+// Handler returns the handler that follows the config.
+func (w *ConfigWatcher) Handler() *ReloadableHandler {
+	return w.handler
+}
+
+// Reload re-reads the config file and swaps in the new handler chain.
+// On error the running handlers are kept.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("sloghandler: config watcher is closed")
+	}
+	h, err := w.load()
+	if err != nil {
+		return err
+	}
+	closeHandler(w.handler.Swap(h))
+	return nil
+}
+
+// load reads and builds the config. The caller must hold w.mu once the watcher runs.
+func (w *ConfigWatcher) load() (slog.Handler, error) {
+	st, err := os.Stat(w.path)
+	if err != nil {
+		return nil, err
+	}
+	// Remember this version even if it is invalid, so polling reports it once.
+	w.modTime, w.size = st.ModTime(), st.Size()
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	h, err := c.Handler()
+	if err != nil {
+		return nil, err
+	}
+	if w.opts.OnReload != nil {
+		w.opts.OnReload(c)
+	}
+	return h, nil
+}
+
+// changed reports whether the file differs from the last loaded version.
+func (w *ConfigWatcher) changed() bool {
+	st, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !st.ModTime().Equal(w.modTime) || st.Size() != w.size
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+	sig := make(chan os.Signal, 1)
+	if len(w.opts.Signals) > 0 {
+		signal.Notify(sig, w.opts.Signals...)
+		defer signal.Stop(sig)
+	}
+	var tick <-chan time.Time
+	if w.opts.Interval > 0 {
+		t := time.NewTicker(w.opts.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-sig:
+		case <-tick:
+			if !w.changed() {
+				continue
+			}
+		}
+		if err := w.Reload(); err != nil {
+			w.opts.OnError(err)
+		}
+	}
+}
+
+// Close stops watching and closes the current handler chain.
+func (w *ConfigWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stop)
+	<-w.done
+	return closeHandler(w.handler.Handler())
+}
+
+// closeHandler closes h if it implements io.Closer.
+func closeHandler(h slog.Handler) error {
+	if c, ok := h.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
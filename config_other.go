@@ -0,0 +1,8 @@
+//go:build !unix
+
+package sloghandler
+
+import "os"
+
+// defaultReloadSignals is empty where SIGHUP is not delivered.
+var defaultReloadSignals = []os.Signal{}
@@ -0,0 +1,106 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigHandler(t *testing.T) {
+	dir := t.TempDir()
+	c, err := ParseConfig([]byte(`{"level": "warn", "sinks": [
+		"file://` + filepath.Join(dir, "a.log") + `",
+		"file://` + filepath.Join(dir, "b.log") + `?level=debug"
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := c.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("info")
+	logger.Warn("warn")
+	if err := closeHandler(h); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := os.ReadFile(filepath.Join(dir, "a.log"))
+	b, _ := os.ReadFile(filepath.Join(dir, "b.log"))
+	if strings.Contains(string(a), "info") || !strings.Contains(string(a), "warn") {
+		t.Errorf("a.log = %q", a)
+	}
+	if !strings.Contains(string(b), "info") || !strings.Contains(string(b), "warn") {
+		t.Errorf("b.log = %q", b)
+	}
+
+	for _, data := range []string{`{"sinks": []}`, `{"sinks": ["console://"], "colour": 1}`, `{"level": "x", "sinks": ["console://"]}`} {
+		c, err := ParseConfig([]byte(data))
+		if err == nil {
+			_, err = c.Handler()
+		}
+		if err == nil {
+			t.Errorf("config %s: expected an error", data)
+		}
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	logPath := filepath.Join(dir, "app.log")
+	write := func(level string) {
+		data := `{"level": "` + level + `", "sinks": ["file://` + logPath + `"]}`
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("info")
+	reloaded := make(chan string, 10)
+	errs := make(chan error, 10)
+	w, err := WatchConfig(path, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		OnReload: func(c *Config) { reloaded <- c.Level },
+		OnError:  func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-reloaded
+	logger := slog.New(w.Handler())
+	logger.Debug("before")
+
+	// The size changes too, in case the file system has coarse modification times.
+	write("debug")
+	select {
+	case err := <-errs:
+		t.Fatal(err)
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+	logger.Debug("after")
+
+	os.WriteFile(path, []byte("{"), 0o644)
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalid config was not reported")
+	}
+	logger.Debug("kept")
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	if strings.Contains(got, "before") || !strings.Contains(got, "after") || !strings.Contains(got, "kept") {
+		t.Errorf("unexpected log %q", got)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build unix
+
+package sloghandler
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,118 @@
+//go:build js && wasm
+
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"syscall/js"
+	"time"
+)
+
+// ConsoleOptions configures a ConsoleHandler.
+type ConsoleOptions struct {
+	// Level is the minimum level logged. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// AddSource adds a "source" attribute of the form "file:line".
+	AddSource bool
+}
+
+// ConsoleHandler is a slog.Handler for Go programs running in a browser,
+// or another JavaScript host, with GOOS=js GOARCH=wasm. It logs records to
+// the JavaScript console, so they can be filtered by level and inspected
+// in the developer tools: TRACE and DEBUG with console.debug, INFO with
+// console.info, WARN with console.warn and ERROR with console.error.
+//
+// The message is the first argument, followed by an object holding the
+// attributes, with group names joined to keys by dots. Numbers, booleans
+// and strings keep their JavaScript types and times become Date objects.
+type ConsoleHandler struct {
+	console js.Value
+	opts    ConsoleOptions
+	scope   attrScope
+}
+
+// NewConsoleHandler creates a handler logging to the global console object.
+func NewConsoleHandler(opts *ConsoleOptions) *ConsoleHandler {
+	o := ConsoleOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	return &ConsoleHandler{console: js.Global().Get("console"), opts: o}
+}
+
+func (h *ConsoleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *ConsoleHandler) Handle(ctx context.Context, record slog.Record) error {
+	e := newEntry(record, h.scope)
+	method := "info"
+	switch {
+	case e.Level < slog.LevelInfo:
+		method = "debug"
+	case e.Level >= slog.LevelError:
+		method = "error"
+	case e.Level >= slog.LevelWarn:
+		method = "warn"
+	}
+	if !h.opts.AddSource {
+		e.Source = nil
+	}
+	args := []any{e.Message}
+	if e.Source != nil || len(e.Attrs) > 0 {
+		attrs := js.Global().Get("Object").New()
+		if e.Source != nil {
+			attrs.Set(slog.SourceKey, fmt.Sprintf("%s:%d", e.Source.File, e.Source.Line))
+		}
+		for _, a := range e.Attrs {
+			attrs.Set(a.Key, consoleValue(a.Value))
+		}
+		args = append(args, attrs)
+	}
+	h.console.Call(method, args...)
+	return nil
+}
+
+// maxSafeInteger is the largest integer a JavaScript number holds exactly.
+const maxSafeInteger = 1<<53 - 1
+
+// consoleValue converts v to a JavaScript value. Integers beyond the range
+// of JavaScript numbers are kept exact as strings.
+func consoleValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		if n := v.Int64(); n >= -maxSafeInteger && n <= maxSafeInteger {
+			return float64(n)
+		}
+	case slog.KindUint64:
+		if n := v.Uint64(); n <= maxSafeInteger {
+			return float64(n)
+		}
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindTime:
+		return js.Global().Get("Date").New(float64(v.Time().UnixNano()) / float64(time.Millisecond))
+	}
+	return v.String()
+}
+
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
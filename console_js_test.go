@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	type call struct {
+		method string
+		args   []js.Value
+	}
+	var calls []call
+	fake := js.Global().Get("Object").New()
+	for _, m := range []string{"debug", "info", "warn", "error"} {
+		fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+			calls = append(calls, call{m, args})
+			return nil
+		})
+		defer fn.Release()
+		fake.Set(m, fn)
+	}
+	orig := js.Global().Get("console")
+	js.Global().Set("console", fake)
+	h := NewConsoleHandler(&ConsoleOptions{Level: LevelTrace})
+	js.Global().Set("console", orig)
+
+	ts := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	logger := slog.New(h).With("app", "web").WithGroup("req")
+	logger.Log(context.Background(), LevelTrace, "trace")
+	logger.Info("served", "status", 200, "ok", true, "at", ts, "big", uint64(math.MaxUint64))
+	slog.New(h).Warn("slow")
+	logger.Error("failed", "err", "timeout")
+
+	if len(calls) != 4 {
+		t.Fatalf("got %d calls, want 4", len(calls))
+	}
+	for i, want := range []string{"debug", "info", "warn", "error"} {
+		if calls[i].method != want {
+			t.Errorf("call %d: got console.%s, want console.%s", i, calls[i].method, want)
+		}
+	}
+	if len(calls[2].args) != 1 || calls[2].args[0].String() != "slow" {
+		t.Errorf("a record without attributes should log only its message")
+	}
+	args := calls[1].args
+	if len(args) != 2 || args[0].String() != "served" {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+	attrs := args[1]
+	if attrs.Get("app").String() != "web" || attrs.Get("req.status").Int() != 200 || !attrs.Get("req.ok").Bool() {
+		t.Errorf("unexpected attributes %v", js.Global().Get("JSON").Call("stringify", attrs))
+	}
+	if at := attrs.Get("req.at"); !at.InstanceOf(js.Global().Get("Date")) || at.Call("toISOString").String() != "2024-06-01T00:00:00.000Z" {
+		t.Errorf("times should be Date objects, got %v", at)
+	}
+	if big := attrs.Get("req.big"); big.Type() != js.TypeString || big.String() != "18446744073709551615" {
+		t.Errorf("unsafe integers should be strings, got %v", big)
+	}
+}
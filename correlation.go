@@ -0,0 +1,86 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// CorrelationIDKey is the attribute key of correlation IDs.
+const CorrelationIDKey = "correlation_id"
+
+// CorrelationIDHeader is the HTTP header read and set by CorrelationMiddleware.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying the correlation ID id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of ctx, if any.
+func CorrelationID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// EnsureCorrelationID returns ctx and its correlation ID, generating one
+// with NewUUIDv7 if ctx has none. Call it where work enters the service,
+// such as a queue consumer; CorrelationMiddleware does so for HTTP.
+func EnsureCorrelationID(ctx context.Context) (context.Context, string) {
+	if id, ok := CorrelationID(ctx); ok {
+		return ctx, id
+	}
+	id := NewUUIDv7()
+	return WithCorrelationID(ctx, id), id
+}
+
+// CorrelationMiddleware returns net/http middleware that takes the
+// correlation ID of each request from the X-Correlation-ID header, or
+// generates one, stores it in the request context and echoes it in the
+// response header, so that calls to other services can forward it.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if id := r.Header.Get(CorrelationIDHeader); id != "" {
+			ctx = WithCorrelationID(ctx, id)
+		}
+		ctx, id := EnsureCorrelationID(ctx)
+		w.Header().Set(CorrelationIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AddCorrelationID returns a Transformer that adds a "correlation_id"
+// attribute with the correlation ID of the context to each record logged
+// with one, unless the record already has the attribute at the top level.
+func AddCorrelationID() Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		id, ok := CorrelationID(ctx)
+		if !ok {
+			return r, true
+		}
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			found = a.Key == CorrelationIDKey
+			return !found
+		})
+		if !found {
+			r = r.Clone()
+			r.AddAttrs(slog.String(CorrelationIDKey, id))
+		}
+		return r, true
+	})
+}
+
+// NewCorrelationHandler returns a handler that applies AddCorrelationID
+// before passing records to h. Combined with CorrelationMiddleware or
+// EnsureCorrelationID, every record of a request carries the ID, so logs
+// can be joined across services.
+func NewCorrelationHandler(h slog.Handler) *TransformHandler {
+	return NewTransformHandler(h, AddCorrelationID())
+}
@@ -0,0 +1,64 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCorrelationHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewCorrelationHandler(slog.NewTextHandler(buf, nil)))
+
+	ctx, id := EnsureCorrelationID(context.Background())
+	if got, ok := CorrelationID(ctx); !ok || got != id || !uuidv7Pattern.MatchString(id) {
+		t.Fatalf("CorrelationID = %q, %v", got, ok)
+	}
+	if ctx2, id2 := EnsureCorrelationID(ctx); ctx2 != ctx || id2 != id {
+		t.Error("EnsureCorrelationID replaced an existing ID")
+	}
+	logger.InfoContext(ctx, "in request")
+	logger.WithGroup("g").InfoContext(ctx, "in group")
+	logger.InfoContext(ctx, "explicit", CorrelationIDKey, "given")
+	logger.With(CorrelationIDKey, "given").InfoContext(ctx, "with")
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"msg=\"in request\" correlation_id=" + id,
+		"msg=\"in group\" correlation_id=" + id,
+		"msg=explicit correlation_id=given",
+		"msg=with correlation_id=given",
+		"msg=\"no context\"",
+	}
+	for i, w := range want {
+		if i >= len(lines) || !strings.Contains(lines[i], w) || strings.Count(lines[i], "correlation_id") > 1 {
+			t.Errorf("line %d: want %q in:\n%s", i, w, buf.String())
+		}
+	}
+}
+
+func TestCorrelationMiddleware(t *testing.T) {
+	var seen string
+	srv := CorrelationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = CorrelationID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(CorrelationIDHeader, "abc")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if seen != "abc" || rec.Header().Get(CorrelationIDHeader) != "abc" {
+		t.Errorf("forwarded ID: seen %q, response %q", seen, rec.Header().Get(CorrelationIDHeader))
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !uuidv7Pattern.MatchString(seen) || rec.Header().Get(CorrelationIDHeader) != seen {
+		t.Errorf("generated ID: seen %q, response %q", seen, rec.Header().Get(CorrelationIDHeader))
+	}
+}
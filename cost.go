@@ -0,0 +1,257 @@
+package sloghandler
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CostOptions configures a CostHandler.
+type CostOptions struct {
+	// Key is the attribute naming the service or component whose volume is
+	// reported separately, in a record or added with WithAttrs outside
+	// groups. Default is "service".
+	Key string
+	// Interval is how often the volume is reported. Default is one minute.
+	// A negative interval disables the periodic report; call Report instead.
+	Interval time.Duration
+	// PricePerGB is the ingestion price per GB (10^9 bytes) of the logging
+	// backend, used to estimate the cost. Default is 0 (no estimate).
+	PricePerGB float64
+	// Message is the message of the report records. Default is "log volume".
+	Message string
+}
+
+// CostStat is the log volume of a service at a level.
+type CostStat struct {
+	Service string
+	Level   slog.Level
+	Records int64
+	Bytes   int64
+}
+
+// CostHandler wraps a handler and estimates the volume it logs per service
+// and level, to help teams find their noisiest loggers and what they cost.
+// Every Interval it writes one record per service and level:
+//
+//	h := sloghandler.NewCostHandler(handler, &sloghandler.CostOptions{PricePerGB: 0.50})
+//	defer h.Close()
+//	// [INFO] log volume [service:api] [log_level:DEBUG] [records:120000] [bytes:36000000]
+//	// [records_per_minute:120000] [bytes_per_minute:36000000] [estimated_cost:0.018] [estimated_monthly_cost:777.6]
+//
+// Bytes are estimated as the length of the message and of the keys and
+// values of the attributes, which is close to the size of text and JSON
+// output. The estimated cost is that of the reported interval; the monthly
+// cost extrapolates it to 30 days.
+type CostHandler struct {
+	base     slog.Handler
+	state    *costState
+	service  string // from WithAttrs, if any
+	attrSize int    // size of the attributes added with WithAttrs
+	groups   bool   // a group was opened with WithGroup
+}
+
+type costState struct {
+	opts   CostOptions
+	root   slog.Handler
+	mu     sync.Mutex
+	start  time.Time
+	stats  map[costKey]*CostStat
+	stop   chan struct{} // nil without periodic reports
+	done   chan struct{}
+	closed bool
+}
+
+type costKey struct {
+	service string
+	level   slog.Level
+}
+
+// NewCostHandler creates a CostHandler passing records to h and writing the
+// reports to h. Call Close to stop the reports.
+func NewCostHandler(h slog.Handler, opts *CostOptions) *CostHandler {
+	o := CostOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Key == "" {
+		o.Key = "service"
+	}
+	if o.Interval == 0 {
+		o.Interval = time.Minute
+	}
+	if o.Message == "" {
+		o.Message = "log volume"
+	}
+	s := &costState{
+		opts:  o,
+		root:  h,
+		start: time.Now(),
+		stats: make(map[costKey]*CostStat),
+	}
+	if o.Interval > 0 {
+		s.stop, s.done = make(chan struct{}), make(chan struct{})
+		go s.run()
+	}
+	return &CostHandler{base: h, state: s}
+}
+
+func (s *costState) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.report(time.Now())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *CostHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *CostHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *CostHandler) Handle(ctx context.Context, record slog.Record) error {
+	service := h.service
+	if !h.groups {
+		if v, ok := recordString(record, h.state.opts.Key); ok {
+			service = v
+		}
+	}
+	size := int64(h.attrSize + recordSize(record))
+	s := h.state
+	s.mu.Lock()
+	k := costKey{service: service, level: record.Level}
+	st := s.stats[k]
+	if st == nil {
+		st = &CostStat{Service: service, Level: record.Level}
+		s.stats[k] = st
+	}
+	st.Records++
+	st.Bytes += size
+	s.mu.Unlock()
+	return h.base.Handle(ctx, record)
+}
+
+func (h *CostHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	for _, a := range attrs {
+		h2.attrSize += len(a.Key) + len(a.Value.String())
+		if !h.groups && a.Key == h.state.opts.Key {
+			h2.service = a.Value.Resolve().String()
+		}
+	}
+	return &h2
+}
+
+func (h *CostHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.groups = true
+	return &h2
+}
+
+// Stats returns the volume logged since the last report, by service and
+// level, for exporting as metrics.
+func (h *CostHandler) Stats() []CostStat {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedStats()
+}
+
+func (s *costState) sortedStats() []CostStat {
+	stats := make([]CostStat, 0, len(s.stats))
+	for _, st := range s.stats {
+		stats = append(stats, *st)
+	}
+	slices.SortFunc(stats, func(a, b CostStat) int {
+		return cmp.Or(cmp.Compare(a.Service, b.Service), cmp.Compare(a.Level, b.Level))
+	})
+	return stats
+}
+
+// Report writes the volume logged since the last report and starts a new
+// reporting interval.
+func (h *CostHandler) Report() {
+	h.state.report(time.Now())
+}
+
+func (s *costState) report(now time.Time) {
+	s.mu.Lock()
+	stats := s.sortedStats()
+	elapsed := now.Sub(s.start)
+	s.start = now
+	clear(s.stats)
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	if elapsed <= 0 || !s.root.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	minutes := elapsed.Minutes()
+	for _, st := range stats {
+		r := slog.NewRecord(now, slog.LevelInfo, s.opts.Message, 0)
+		if st.Service != "" {
+			r.AddAttrs(slog.String(s.opts.Key, st.Service))
+		}
+		r.AddAttrs(
+			slog.String("log_level", LevelName(st.Level)),
+			slog.Int64("records", st.Records),
+			slog.Int64("bytes", st.Bytes),
+			slog.Float64("records_per_minute", float64(st.Records)/minutes),
+			slog.Float64("bytes_per_minute", float64(st.Bytes)/minutes),
+		)
+		if s.opts.PricePerGB > 0 {
+			cost := float64(st.Bytes) / 1e9 * s.opts.PricePerGB
+			r.AddAttrs(
+				slog.Float64("estimated_cost", cost),
+				slog.Float64("estimated_monthly_cost", cost*float64(30*24*time.Hour)/float64(elapsed)),
+			)
+		}
+		s.root.Handle(ctx, r)
+	}
+}
+
+// Close stops the periodic reports and writes a final one.
+func (h *CostHandler) Close() error {
+	s := h.state
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+	s.report(time.Now())
+	return nil
+}
+
+// recordSize estimates the size of r in text or JSON output.
+func recordSize(r slog.Record) int {
+	size := len(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		size += len(a.Key) + len(a.Value.String())
+		return true
+	})
+	return size
+}
@@ -0,0 +1,60 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCostHandler(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	h := NewCostHandler(mem, &CostOptions{Interval: -1, PricePerGB: 1e6})
+	start := time.Now()
+	h.state.start = start.Add(-2 * time.Minute)
+
+	api := slog.New(h).With("service", "api")
+	api.Info("hello")          // 5 + len("service") + len("api") = 15 bytes
+	api.Info("hello", "k", 10) // 15 + 3 = 18 bytes
+	api.Warn("slow")
+	slog.New(h).Info("hi", "service", "batch")
+
+	stats := h.Stats()
+	want := []CostStat{
+		{Service: "api", Level: slog.LevelInfo, Records: 2, Bytes: 33},
+		{Service: "api", Level: slog.LevelWarn, Records: 1, Bytes: 14},
+		{Service: "batch", Level: slog.LevelInfo, Records: 1, Bytes: 14},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+
+	h.Close()
+	reports := mem.Records(Query{Attrs: map[string]string{"log_level": "INFO", "service": "api"}})
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports for api INFO", len(reports))
+	}
+	r := reports[0]
+	if r.Message != "log volume" {
+		t.Errorf("message = %q", r.Message)
+	}
+	v, _ := r.Attr("records_per_minute")
+	if rpm := v.Float64(); rpm < 0.9 || rpm > 1.0 {
+		t.Errorf("records_per_minute = %v, want about 1", rpm)
+	}
+	v, _ = r.Attr("estimated_cost")
+	if cost := v.Float64(); cost < 0.0329 || cost > 0.0331 {
+		t.Errorf("estimated_cost = %v, want 0.033", cost)
+	}
+	if len(h.Stats()) != 0 {
+		t.Errorf("stats not reset after the report")
+	}
+	h.Close()
+	if n := len(mem.Records(Query{})); n != 4+3 {
+		t.Errorf("got %d records after closing twice, want 7", n)
+	}
+}
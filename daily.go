@@ -0,0 +1,143 @@
+package sloghandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DailyFileOptions configures a DailyFile.
+type DailyFileOptions struct {
+	// Location is the time zone in which days start. Default is time.Local.
+	Location *time.Location
+	// MaxAge removes files whose date is more than this many days before
+	// the current one when a new file is opened. Default is 0 (keep all).
+	MaxAge int
+	// Now returns the current time. Default is time.Now.
+	Now func() time.Time
+}
+
+// DailyFile is an io.WriteCloser that appends to a file named after the
+// current date and switches to a new file at midnight, as an alternative
+// to size-based rotation with RotatingFile.
+type DailyFile struct {
+	prefix, layout, suffix string
+	opts                   DailyFileOptions
+
+	mu   sync.Mutex
+	f    *os.File
+	name string
+}
+
+// OpenDailyFile opens the file for the current date. The date is written
+// in path as a Go time layout in braces, e.g. "/var/log/app-{2006-01-02}.log"
+// for app-2024-06-01.log.
+func OpenDailyFile(path string, opts *DailyFileOptions) (*DailyFile, error) {
+	start := strings.IndexByte(path, '{')
+	end := strings.LastIndexByte(path, '}')
+	if start < 0 || end < start+2 || strings.ContainsAny(path[end+1:], "{}") {
+		return nil, fmt.Errorf("sloghandler: daily file path %q needs one {layout} placeholder", path)
+	}
+	d := &DailyFile{prefix: path[:start], layout: path[start+1 : end], suffix: path[end+1:]}
+	if opts != nil {
+		d.opts = *opts
+	}
+	if d.opts.Location == nil {
+		d.opts.Location = time.Local
+	}
+	if d.opts.Now == nil {
+		d.opts.Now = time.Now
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.open(d.opts.Now()); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Name returns the name of the current file.
+func (d *DailyFile) Name() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.name
+}
+
+func (d *DailyFile) nameFor(t time.Time) string {
+	return d.prefix + t.In(d.opts.Location).Format(d.layout) + d.suffix
+}
+
+// open switches to the file for t if it is not already open.
+func (d *DailyFile) open(t time.Time) error {
+	name := d.nameFor(t)
+	if d.f != nil && name == d.name {
+		return nil
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if d.f != nil {
+		d.f.Close()
+	}
+	d.f, d.name = f, name
+	if d.opts.MaxAge > 0 {
+		d.prune(t)
+	}
+	return nil
+}
+
+// prune removes files dated more than MaxAge days before t.
+// Only files in the same directory as the current one are considered.
+func (d *DailyFile) prune(t time.Time) {
+	dir, base := filepath.Split(d.prefix + "x")
+	base = strings.TrimSuffix(base, "x")
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return
+	}
+	y, m, day := t.In(d.opts.Location).Date()
+	cutoff := time.Date(y, m, day-d.opts.MaxAge, 0, 0, 0, 0, d.opts.Location)
+	for _, e := range entries {
+		date, ok := strings.CutPrefix(e.Name(), base)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if date, ok = strings.CutSuffix(date, d.suffix); !ok {
+			continue
+		}
+		ft, err := time.ParseInLocation(d.layout, date, d.opts.Location)
+		if err != nil || !ft.Before(cutoff) {
+			continue
+		}
+		os.Remove(filepath.Join(dir, e.Name()))
+	}
+}
+
+// Write appends p to the file for the current date.
+func (d *DailyFile) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.f == nil {
+		return 0, os.ErrClosed
+	}
+	if err := d.open(d.opts.Now()); err != nil {
+		return 0, err
+	}
+	return d.f.Write(p)
+}
+
+// Close closes the current file.
+func (d *DailyFile) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.f == nil {
+		return nil
+	}
+	err := d.f.Close()
+	d.f = nil
+	return err
+}
@@ -0,0 +1,63 @@
+package sloghandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyFile(t *testing.T) {
+	dir := t.TempDir()
+	tokyo := time.FixedZone("JST", 9*60*60)
+	for _, name := range []string{"app-2024-05-01.log", "app-2024-05-29.log", "app-notadate.log", "other-2024-01-01.log"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+	}
+	now := time.Date(2024, 5, 31, 14, 59, 0, 0, time.UTC) // 23:59 in Tokyo
+	d, err := OpenDailyFile(filepath.Join(dir, "app-{2006-01-02}.log"), &DailyFileOptions{
+		Location: tokyo,
+		MaxAge:   2,
+		Now:      func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Write([]byte("before midnight\n"))
+	now = now.Add(2 * time.Minute)
+	d.Write([]byte("after midnight\n"))
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"app-2024-05-31.log": "before midnight\n",
+		"app-2024-06-01.log": "after midnight\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", name, b, want)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"app-2024-05-31.log", "app-2024-06-01.log", "app-notadate.log", "other-2024-01-01.log"}
+	if len(names) != len(want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("files = %v, want %v", names, want)
+			break
+		}
+	}
+
+	if _, err := OpenDailyFile(filepath.Join(dir, "app.log"), nil); err == nil {
+		t.Error("expected an error for a path without a layout")
+	}
+}
@@ -0,0 +1,84 @@
+package sloghandler
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// DatagramWriter is an io.Writer that sends each Write as a single datagram to
+// a Unix datagram socket (or, on Windows, writes it to a named pipe such as
+// `\\.\pipe\app-logs`). It connects lazily and reconnects after write errors,
+// so a restarting log daemon does not break the application.
+//
+// The text handler writes each record with a single Write call, so every
+// datagram carries exactly one record.
+type DatagramWriter struct {
+	addr string
+	// RetryInterval is the minimum time between reconnection attempts.
+	// Writes while disconnected within this interval fail immediately.
+	RetryInterval time.Duration
+
+	mu       sync.Mutex
+	conn     io.WriteCloser
+	lastDial time.Time
+	closed   bool
+	dialFunc func(addr string) (io.WriteCloser, error)
+}
+
+// NewDatagramWriter returns a writer for the socket or named pipe at addr.
+func NewDatagramWriter(addr string) *DatagramWriter {
+	return &DatagramWriter{
+		addr:          addr,
+		RetryInterval: time.Second,
+		dialFunc:      dialDatagram,
+	}
+}
+
+// Write sends p as one datagram, reconnecting once if the connection was lost.
+func (w *DatagramWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			if err := w.dial(attempt > 0); err != nil {
+				return 0, err
+			}
+		}
+		n, err := w.conn.Write(p)
+		if err == nil || attempt > 0 {
+			return n, err
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *DatagramWriter) dial(force bool) error {
+	if !force && time.Since(w.lastDial) < w.RetryInterval {
+		return errNotConnected
+	}
+	w.lastDial = time.Now()
+	conn, err := w.dialFunc(w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Close closes the underlying connection.
+func (w *DatagramWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
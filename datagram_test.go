@@ -0,0 +1,63 @@
+//go:build !windows
+
+package sloghandler
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenUnixgram(t *testing.T, path string) *net.UnixConn {
+	t.Helper()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestDatagramWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	server := listenUnixgram(t, path)
+
+	w := NewDatagramWriter(path)
+	w.RetryInterval = 0
+	defer w.Close()
+	logger := slog.New(NewLogHandler(w, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}))
+
+	logger.Info("first")
+	if got := readDatagram(t, server); !strings.HasSuffix(got, "[INFO] first\n") {
+		t.Errorf("unexpected datagram %q", got)
+	}
+
+	// Restart the daemon: the writer reconnects transparently.
+	server.Close()
+	os.Remove(path)
+	server = listenUnixgram(t, path)
+	defer server.Close()
+	logger.Info("second")
+	if got := readDatagram(t, server); !strings.HasSuffix(got, "[INFO] second\n") {
+		t.Errorf("unexpected datagram after reconnect %q", got)
+	}
+
+	w.Close()
+	if _, err := w.Write([]byte("x")); err != ErrClosed {
+		t.Errorf("Write after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package sloghandler
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+var errNotConnected = errors.New("sloghandler: datagram socket not connected")
+
+func dialDatagram(addr string) (io.WriteCloser, error) {
+	return net.Dial("unixgram", addr)
+}
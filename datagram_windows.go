@@ -0,0 +1,15 @@
+//go:build windows
+
+package sloghandler
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var errNotConnected = errors.New("sloghandler: named pipe not connected")
+
+func dialDatagram(addr string) (io.WriteCloser, error) {
+	return os.OpenFile(addr, os.O_WRONLY, 0)
+}
@@ -0,0 +1,152 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewDebugHTTPHandler returns an http.Handler that serves the records
+// retained by store, e.g. mounted at /debug/logs.
+//
+// Query parameters:
+//
+//   - level: minimum level, e.g. "warn"
+//   - since: RFC3339 time or a duration such as "15m" (records newer than now minus the duration)
+//   - attr: "key:value" attribute match; may be repeated
+//   - limit: maximum number of most recent records
+//   - format: "html" (default), "ndjson" or "json"
+//   - stream: "1" to stream matching records live as Server-Sent Events
+func NewDebugHTTPHandler(store *MemoryHandler) http.Handler {
+	return &debugHTTPHandler{store: store}
+}
+
+type debugHTTPHandler struct {
+	store *MemoryHandler
+}
+
+func (d *debugHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q, err := parseDebugQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("stream") == "1" {
+		d.stream(w, r, q)
+		return
+	}
+	switch r.FormValue("format") {
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, e := range d.store.Records(q) {
+			enc.Encode(e)
+		}
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		d.store.WriteJSON(w, q)
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugHTMLTemplate.Execute(w, debugPage{
+			Entries: d.store.Records(q),
+			Levels:  []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError},
+			Query:   r.URL.Query(),
+		})
+	}
+}
+
+// stream sends matching records as Server-Sent Events until the client disconnects.
+func (d *debugHTTPHandler) stream(w http.ResponseWriter, r *http.Request, q Query) {
+	rc := http.NewResponseController(w)
+	ch, cancel := d.store.Subscribe(256)
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-ch:
+			if !q.Match(e) {
+				continue
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parseDebugQuery(r *http.Request) (Query, error) {
+	var q Query
+	if s := r.FormValue("level"); s != "" {
+		l, err := ParseLevel(s)
+		if err != nil {
+			return q, fmt.Errorf("invalid level: %w", err)
+		}
+		q.Level = l
+	}
+	if s := r.FormValue("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			q.Since = t
+		} else {
+			return q, fmt.Errorf("invalid since: %q", s)
+		}
+	}
+	if s := r.FormValue("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return q, fmt.Errorf("invalid limit: %w", err)
+		}
+		q.Limit = n
+	}
+	for _, s := range r.Form["attr"] {
+		k, v, ok := strings.Cut(s, ":")
+		if !ok {
+			return q, fmt.Errorf("invalid attr: %q", s)
+		}
+		if q.Attrs == nil {
+			q.Attrs = make(map[string]string)
+		}
+		q.Attrs[k] = v
+	}
+	return q, nil
+}
+
+type debugPage struct {
+	Entries []*Entry
+	Levels  []slog.Level
+	Query   map[string][]string
+}
+
+var debugHTMLTemplate = template.Must(template.New("logs").Funcs(template.FuncMap{
+	"levelHTMLColor": levelHTMLColor,
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>logs</title>
+<style>body{font-family:monospace} a{margin-right:1em} pre{white-space:pre-wrap}</style>
+</head><body>
+<nav><a href="?">ALL</a>{{ range .Levels }}<a href="?level={{ . }}" style="color: {{ levelHTMLColor . }}">{{ . }}+</a>{{ end }}
+<a href="?format=ndjson">ndjson</a><a href="?stream=1">stream</a></nav>
+<pre>
+{{- range .Entries }}
+<span style="color: {{ levelHTMLColor .Level }}">{{ .String }}</span>
+{{- end }}
+</pre>
+</body></html>
+`))
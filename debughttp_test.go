@@ -0,0 +1,88 @@
+package sloghandler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHTTPHandler(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	logger := slog.New(store)
+	logger.Info("hello <world>", "user", "alice")
+	logger.Error("boom", "user", "bob")
+
+	ts := httptest.NewServer(NewDebugHTTPHandler(store))
+	defer ts.Close()
+
+	get := func(query string) (string, *http.Response) {
+		resp, err := http.Get(ts.URL + "/?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), resp
+	}
+
+	body, resp := get("")
+	if !strings.Contains(body, "hello &lt;world&gt;") || !strings.Contains(body, "boom") {
+		t.Errorf("html output missing records: %s", body)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %s", ct)
+	}
+
+	body, _ = get("format=ndjson&attr=user:alice")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"user":"alice"`) {
+		t.Errorf("unexpected ndjson output %q", body)
+	}
+
+	body, _ = get("format=json&level=error")
+	var entries []map[string]any
+	if err := json.Unmarshal([]byte(body), &entries); err != nil || len(entries) != 1 || entries[0]["msg"] != "boom" {
+		t.Errorf("unexpected json output %s (%v)", body, err)
+	}
+
+	if _, resp := get("level=bogus"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid level status = %d", resp.StatusCode)
+	}
+}
+
+func TestDebugHTTPHandlerStream(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	ts := httptest.NewServer(NewDebugHTTPHandler(store))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/?stream=1&level=warn", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	logger := slog.New(store)
+	logger.Info("filtered out")
+	logger.Warn("streamed")
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, `"msg":"streamed"`) {
+			t.Errorf("unexpected event %q", line)
+		}
+		break
+	}
+}
@@ -0,0 +1,193 @@
+package sloghandler
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// maxDiffDepth bounds the nesting compared by DiffValues, which also stops
+// it on cyclic values.
+const maxDiffDepth = 32
+
+// Change is a difference found by DiffValues.
+type Change struct {
+	// Path locates the value, such as "Server.Timeout", "limits.cpu" or "hosts[1]".
+	Path string
+	// Old is nil if the path was added.
+	Old any
+	// New is nil if the path was removed.
+	New any
+}
+
+// String formats c as "path=old→new", "+path=new" or "-path=old".
+func (c Change) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+%s=%v", c.Path, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("-%s=%v", c.Path, c.Old)
+	}
+	return fmt.Sprintf("%s=%v→%v", c.Path, c.Old, c.New)
+}
+
+// Changes is the list of differences between two values. The text handler
+// renders it compactly as
+//
+//	[config:Server.Timeout=5s→10s +limits.cpu=2 -hosts[1]=b]
+//
+// and other handlers see a group keyed by path, with "old" and "new" members.
+type Changes []Change
+
+// String formats the changes separated by spaces.
+func (cs Changes) String() string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogValue returns the changes as a group keyed by path.
+func (cs Changes) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(cs))
+	for _, c := range cs {
+		var members []slog.Attr
+		if c.Old != nil {
+			members = append(members, slog.Any("old", c.Old))
+		}
+		if c.New != nil {
+			members = append(members, slog.Any("new", c.New))
+		}
+		attrs = append(attrs, slog.Attr{Key: c.Path, Value: slog.GroupValue(members...)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Diff returns an attribute with the differences between old and new, for
+// logging configuration reloads and state transitions:
+//
+//	logger.Info("config reloaded", sloghandler.Diff("config", prev, next))
+//
+// See DiffValues.
+func Diff(key string, old, new any) slog.Attr {
+	return slog.Any(key, DiffValues(old, new))
+}
+
+// DiffValues compares old and new, which are usually of the same struct or
+// map type, and returns the changed paths in order. Structs are compared by
+// exported field, maps by key and slices by index; pointers and interfaces
+// are followed. Other values, and structs without exported fields such as
+// time.Time, are compared as a whole, using an Equal method if they have one.
+func DiffValues(old, new any) Changes {
+	var cs Changes
+	diffValue(&cs, "", reflect.ValueOf(old), reflect.ValueOf(new), 0)
+	return cs
+}
+
+func diffValue(cs *Changes, path string, a, b reflect.Value, depth int) {
+	a, b = derefValue(a), derefValue(b)
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid():
+		*cs = append(*cs, Change{Path: path, New: b.Interface()})
+		return
+	case !b.IsValid():
+		*cs = append(*cs, Change{Path: path, Old: a.Interface()})
+		return
+	}
+	if a.Type() != b.Type() || depth >= maxDiffDepth || !diffComposite(cs, path, a, b, depth) {
+		if !equalValues(a, b) {
+			*cs = append(*cs, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// diffComposite compares the members of structs, maps and slices of the
+// same type. It returns false for other values.
+func diffComposite(cs *Changes, path string, a, b reflect.Value, depth int) bool {
+	switch a.Kind() {
+	case reflect.Struct:
+		t := a.Type()
+		if _, ok := t.MethodByName("Equal"); ok || !hasExportedField(t) {
+			return false
+		}
+		for i := range t.NumField() {
+			if f := t.Field(i); f.IsExported() {
+				diffValue(cs, joinPath(path, f.Name), a.Field(i), b.Field(i), depth+1)
+			}
+		}
+	case reflect.Map:
+		keys := slices.Concat(a.MapKeys(), b.MapKeys())
+		slices.SortFunc(keys, func(x, y reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(x.Interface()), fmt.Sprint(y.Interface()))
+		})
+		keys = slices.CompactFunc(keys, func(x, y reflect.Value) bool {
+			return fmt.Sprint(x.Interface()) == fmt.Sprint(y.Interface())
+		})
+		for _, k := range keys {
+			diffValue(cs, joinPath(path, fmt.Sprint(k.Interface())), a.MapIndex(k), b.MapIndex(k), depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			return false // bytes are compared as a whole
+		}
+		for i := range max(a.Len(), b.Len()) {
+			var x, y reflect.Value
+			if i < a.Len() {
+				x = a.Index(i)
+			}
+			if i < b.Len() {
+				y = b.Index(i)
+			}
+			diffValue(cs, fmt.Sprintf("%s[%d]", path, i), x, y, depth+1)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// derefValue follows pointers and interfaces, returning the zero Value for nil.
+func derefValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func equalValues(a, b reflect.Value) bool {
+	if a.Type() == b.Type() {
+		if m, ok := a.Type().MethodByName("Equal"); ok && m.Type.NumIn() == 2 && m.Type.In(1) == a.Type() &&
+			m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool {
+			return a.Method(m.Index).Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+	if !a.CanInterface() || !b.CanInterface() {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func hasExportedField(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,76 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type diffTestConfig struct {
+	Name    string
+	Timeout time.Duration
+	Started time.Time
+	Hosts   []string
+	Limits  map[string]int
+	TLS     *struct{ Cert string }
+	secret  string
+}
+
+func TestDiffValues(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	old := diffTestConfig{
+		Name:    "api",
+		Timeout: 5 * time.Second,
+		Started: t0,
+		Hosts:   []string{"a", "b"},
+		Limits:  map[string]int{"cpu": 1, "mem": 512},
+		secret:  "x",
+	}
+	new := old
+	new.Timeout = 10 * time.Second
+	new.Started = t0.In(time.FixedZone("JST", 9*60*60)) // same instant
+	new.Hosts = []string{"a", "c", "d"}
+	new.Limits = map[string]int{"cpu": 2, "disk": 10}
+	new.TLS = &struct{ Cert string }{"cert.pem"}
+	new.secret = "y"
+
+	got := DiffValues(old, &new).String()
+	want := "Timeout=5s→10s Hosts[1]=b→c +Hosts[2]=d Limits.cpu=1→2 +Limits.disk=10 -Limits.mem=512 +TLS={cert.pem}"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if cs := DiffValues(old, old); len(cs) != 0 {
+		t.Errorf("unexpected changes %v", cs)
+	}
+	if got := DiffValues(map[string]any{"level": "info"}, map[string]any{"level": "debug"}).String(); got != "level=info→debug" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestDiffRendering(t *testing.T) {
+	old := map[string]any{"level": "info", "port": 80}
+	new := map[string]any{"level": "debug", "port": 80, "tls": true}
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}))
+	logger.Info("config reloaded", Diff("config", old, new))
+	if got := buf.String(); !strings.HasSuffix(got, " [INFO] config reloaded [config:level=info→debug +tls=true]\n") {
+		t.Errorf("text = %q", got)
+	}
+
+	buf.Reset()
+	logger = slog.New(slog.NewJSONHandler(buf, nil))
+	logger.Info("config reloaded", Diff("config", old, new))
+	var rec struct {
+		Config map[string]map[string]any
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Config["level"]["old"] != "info" || rec.Config["level"]["new"] != "debug" || rec.Config["tls"]["new"] != true || len(rec.Config["tls"]) != 1 {
+		t.Errorf("json = %s", buf.String())
+	}
+}
@@ -0,0 +1,420 @@
+package sloghandler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDSN creates a handler from a URL-style data source name, so that
+// deployment manifests can specify sinks as strings. Supported schemes:
+//
+//   - console://stderr, console://stdout: the text handler on a standard stream.
+//     Parameters: color (auto, always or never; default auto), format (text or json),
+//     source, source_depth, source_root, max_len.
+//   - file:///var/log/app.log: the text handler appending to a file.
+//     Parameters: rotate (size such as 100MB; default no rotation), backups (default 3),
+//     coalesce (window such as 5ms for batching writes; see CoalescingWriter),
+//     color (default never), format, source, source_depth, source_root, max_len.
+//     A path with a date layout in braces, such as app-{2006-01-02}.log, opens a
+//     DailyFile instead, with max_age (days to keep) in place of rotate and backups.
+//   - http://, https://: HTTPHandler posting NDJSON. Parameters: gzip, batch_size, interval,
+//     spool (directory for a Spool that keeps batches while the endpoint is down).
+//   - loki+http://, loki+https://: LokiHandler. Parameters: label.<name>, tenant,
+//     batch_size, interval, spool.
+//   - elasticsearch+http://, elasticsearch+https://: ElasticsearchHandler.
+//     Parameters: index, batch_size, interval.
+//   - slack+https://, webhook+http://, webhook+https://: WebhookHandler.
+//     Parameters: interval, burst.
+//
+// Every scheme accepts level, e.g. level=debug. For console and file,
+// unknown parameters are an error; for remote sinks they are kept in the
+// request URL. User info in a remote URL is sent as basic authentication.
+//
+// Handlers that hold resources, such as files and background senders,
+// implement io.Closer; close the returned handler on shutdown.
+func ParseDSN(dsn string) (slog.Handler, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sloghandler: invalid dsn: %w", err)
+	}
+	p := &dsnParams{values: u.Query()}
+	var h slog.Handler
+	switch u.Scheme {
+	case "console":
+		h, err = consoleDSN(u, p)
+	case "file":
+		h, err = fileDSN(u, p)
+	case "http", "https":
+		h, err = httpDSN(u, p)
+	case "loki+http", "loki+https":
+		h, err = lokiDSN(u, p)
+	case "elasticsearch+http", "elasticsearch+https":
+		h, err = elasticsearchDSN(u, p)
+	case "slack+https", "webhook+http", "webhook+https":
+		h, err = webhookDSN(u, p)
+	default:
+		return nil, fmt.Errorf("sloghandler: unsupported dsn scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sloghandler: dsn %s: %w", redactDSN(u), err)
+	}
+	return h, nil
+}
+
+func consoleDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	var f *os.File
+	switch u.Host {
+	case "", "stderr":
+		f = os.Stderr
+	case "stdout":
+		f = os.Stdout
+	default:
+		return nil, fmt.Errorf("unknown stream %q", u.Host)
+	}
+	opts := p.textOptions("auto", f)
+	if err := p.finish(); err != nil {
+		return nil, err
+	}
+	return NewLogHandler(f, opts), nil
+}
+
+func fileDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	path := u.Host + u.Path
+	if path == "" {
+		return nil, fmt.Errorf("missing file path")
+	}
+	daily := strings.Contains(path, "{")
+	var rotate int64
+	var backups, maxAge int
+	if daily {
+		maxAge = p.int("max_age", 0)
+	} else {
+		rotate = p.size("rotate")
+		backups = p.int("backups", 3)
+	}
+	coalesce := p.duration("coalesce", 0)
+	opts := p.textOptions("never", nil)
+	if err := p.finish(); err != nil {
+		return nil, err
+	}
+	var f io.WriteCloser
+	var err error
+	if daily {
+		f, err = OpenDailyFile(path, &DailyFileOptions{MaxAge: maxAge})
+	} else {
+		f, err = OpenRotatingFile(path, rotate, backups)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if coalesce > 0 {
+		cw := NewCoalescingWriter(f, coalesce, 0)
+		return &closingHandler{Handler: NewLogHandler(cw, opts), Closer: closers{cw, f}}, nil
+	}
+	return &closingHandler{Handler: NewLogHandler(f, opts), Closer: f}, nil
+}
+
+func httpDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	o := &HTTPOptions{
+		Level:     p.level(slog.LevelInfo),
+		Gzip:      p.bool("gzip", false),
+		BatchSize: p.int("batch_size", 0),
+		Interval:  p.duration("interval", 0),
+	}
+	spoolDir := p.get("spool")
+	if p.err != nil {
+		return nil, p.err
+	}
+	o.Header = remoteHeader(u, p)
+	if spoolDir == "" {
+		return NewHTTPHandler(u.String(), o)
+	}
+	spool, err := OpenSpool(spoolDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	o.Spool = spool
+	h, err := NewHTTPHandler(u.String(), o)
+	if err != nil {
+		spool.Close()
+		return nil, err
+	}
+	return &closingHandler{Handler: h, Closer: closers{h, spool}}, nil
+}
+
+func lokiDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	o := &LokiOptions{
+		Level:     p.level(slog.LevelInfo),
+		BatchSize: p.int("batch_size", 0),
+		Interval:  p.duration("interval", 0),
+	}
+	tenant := p.get("tenant")
+	spoolDir := p.get("spool")
+	for k := range p.values {
+		if name, ok := strings.CutPrefix(k, "label."); ok {
+			if o.Labels == nil {
+				o.Labels = make(map[string]string)
+			}
+			o.Labels[name] = p.get(k)
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	u.Scheme = strings.TrimPrefix(u.Scheme, "loki+")
+	o.Header = remoteHeader(u, p)
+	if tenant != "" {
+		o.Header.Set("X-Scope-OrgID", tenant)
+	}
+	if spoolDir == "" {
+		return NewLokiHandler(u.String(), o)
+	}
+	spool, err := OpenSpool(spoolDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	o.Spool = spool
+	h, err := NewLokiHandler(u.String(), o)
+	if err != nil {
+		spool.Close()
+		return nil, err
+	}
+	return &closingHandler{Handler: h, Closer: closers{h, spool}}, nil
+}
+
+func elasticsearchDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	o := &ElasticsearchOptions{
+		Level:       p.level(slog.LevelInfo),
+		IndexPrefix: p.get("index"),
+		BatchSize:   p.int("batch_size", 0),
+		Interval:    p.duration("interval", 0),
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	u.Scheme = strings.TrimPrefix(u.Scheme, "elasticsearch+")
+	o.Header = remoteHeader(u, p)
+	return NewElasticsearchHandler(u.String(), o)
+}
+
+func webhookDSN(u *url.URL, p *dsnParams) (slog.Handler, error) {
+	o := &WebhookOptions{
+		Level:    p.level(slog.LevelError),
+		Interval: p.duration("interval", 0),
+		Burst:    p.int("burst", 0),
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	scheme, rest, _ := strings.Cut(u.Scheme, "+")
+	if scheme == "webhook" {
+		o.Format = WebhookJSON
+	}
+	u.Scheme = rest
+	o.Header = remoteHeader(u, p)
+	return NewWebhookHandler(u.String(), o)
+}
+
+// remoteHeader moves user info from u into a basic authentication header
+// and leaves the unconsumed parameters in u's query.
+func remoteHeader(u *url.URL, p *dsnParams) http.Header {
+	header := make(http.Header)
+	if u.User != nil {
+		pass, _ := u.User.Password()
+		cred := u.User.Username() + ":" + pass
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cred)))
+		u.User = nil
+	}
+	u.RawQuery = p.values.Encode()
+	return header
+}
+
+// redactDSN formats u for error messages without its password.
+func redactDSN(u *url.URL) string {
+	u2 := *u
+	u2.RawQuery = ""
+	return u2.Redacted()
+}
+
+// closingHandler attaches the resource backing a handler so it can be closed.
+type closingHandler struct {
+	slog.Handler
+	io.Closer
+}
+
+// Unwrap returns the handler, so that Flush can reach it.
+func (h *closingHandler) Unwrap() slog.Handler {
+	return h.Handler
+}
+
+// closers closes each of its elements in order.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var errs []error
+	for _, c := range cs {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dsnParams reads and consumes query parameters, keeping the first error.
+type dsnParams struct {
+	values url.Values
+	err    error
+}
+
+func (p *dsnParams) get(key string) string {
+	s := p.values.Get(key)
+	delete(p.values, key)
+	return s
+}
+
+func (p *dsnParams) fail(key, s string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("invalid %s %q: %w", key, s, err)
+	}
+}
+
+func (p *dsnParams) bool(key string, def bool) bool {
+	_, present := p.values[key]
+	s := p.get(key)
+	if !present {
+		return def
+	}
+	if s == "" {
+		return true
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		p.fail(key, s, err)
+	}
+	return b
+}
+
+func (p *dsnParams) int(key string, def int) int {
+	s := p.get(key)
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		p.fail(key, s, err)
+	}
+	return n
+}
+
+func (p *dsnParams) duration(key string, def time.Duration) time.Duration {
+	s := p.get(key)
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		p.fail(key, s, err)
+	}
+	return d
+}
+
+func (p *dsnParams) level(def slog.Level) slog.Level {
+	s := p.get("level")
+	if s == "" {
+		return def
+	}
+	l, err := ParseLevel(s)
+	if err != nil {
+		p.fail("level", s, err)
+	}
+	return l
+}
+
+func (p *dsnParams) size(key string) int64 {
+	s := p.get(key)
+	if s == "" {
+		return 0
+	}
+	n, err := parseSize(s)
+	if err != nil {
+		p.fail(key, s, err)
+	}
+	return n
+}
+
+// textOptions reads the text handler parameters. colorDef is the default
+// color mode; "auto" enables color when f is a terminal.
+func (p *dsnParams) textOptions(colorDef string, f *os.File) *HandlerOptions {
+	opts := &HandlerOptions{}
+	opts.Level = p.level(slog.LevelInfo)
+	mode := p.get("color")
+	if mode == "" {
+		mode = colorDef
+	}
+	switch mode {
+	case "auto":
+		opts.Color = f != nil && isTerminal(f) && os.Getenv("NO_COLOR") == ""
+	case "always", "true":
+		opts.Color = true
+	case "never", "false":
+	default:
+		p.fail("color", mode, fmt.Errorf("want auto, always or never"))
+	}
+	opts.AddSource = p.bool("source", false)
+	opts.SourceDepth = p.int("source_depth", 0)
+	opts.SourceRelativeTo = p.get("source_root")
+	opts.MaxLen = p.int("max_len", 0)
+	switch s := p.get("format"); s {
+	case "", "text":
+	case "json":
+		opts.Format = FormatJSON
+	default:
+		p.fail("format", s, fmt.Errorf("want text or json"))
+	}
+	return opts
+}
+
+// finish returns the first parse error, or an error naming unknown parameters.
+func (p *dsnParams) finish() error {
+	if p.err != nil {
+		return p.err
+	}
+	for k := range p.values {
+		return fmt.Errorf("unknown parameter %q", k)
+	}
+	return nil
+}
+
+// parseSize parses a byte size such as "512KB" or "100MB".
+// Units are powers of 1024; a number without a unit is bytes.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(upper, u.suffix); ok {
+			upper, mult = strings.TrimSpace(num), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,114 @@
+package sloghandler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseDSNFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := ParseDSN("file://" + path + "?level=debug&rotate=1MB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(h).Debug("hello", "k", "v")
+	if err := h.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "[DEBUG] hello [k:v]") {
+		t.Errorf("unexpected output %q", b)
+	}
+}
+
+func TestParseDSNLoki(t *testing.T) {
+	var mu sync.Mutex
+	var req *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		req = r
+	}))
+	defer ts.Close()
+
+	dsn := strings.Replace(ts.URL, "http://", "loki+http://user:pass@", 1) + "?tenant=t1&label.app=api&level=warn"
+	h, err := ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lh := h.(*LokiHandler)
+	if lh.opts.Labels["app"] != "api" {
+		t.Errorf("labels = %v", lh.opts.Labels)
+	}
+	slog.New(h).Info("dropped")
+	slog.New(h).Warn("sent")
+	lh.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if req == nil {
+		t.Fatal("no request")
+	}
+	if user, pass, _ := req.BasicAuth(); user != "user" || pass != "pass" {
+		t.Errorf("basic auth = %q %q", user, pass)
+	}
+	if req.Header.Get("X-Scope-OrgID") != "t1" || req.URL.Path != "/loki/api/v1/push" || req.URL.RawQuery != "" {
+		t.Errorf("unexpected request %s %v", req.URL, req.Header)
+	}
+}
+
+func TestParseDSNErrors(t *testing.T) {
+	for _, dsn := range []string{
+		"ftp://host",
+		"console://stdin",
+		"console://stderr?colour=auto",
+		"console://stderr?level=loud",
+		"console://stderr?color=sometimes",
+		"file:///tmp/x.log?rotate=big",
+		"loki+https://host?batch_size=many",
+	} {
+		if _, err := ParseDSN(dsn); err == nil {
+			t.Errorf("ParseDSN(%q) succeeded", dsn)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"100":   100,
+		"10B":   10,
+		"512KB": 512 << 10,
+		"100MB": 100 << 20,
+		"1g":    1 << 30,
+	} {
+		got, err := parseSize(s)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+}
+
+func TestParseDSNDailyFile(t *testing.T) {
+	dir := t.TempDir()
+	h, err := ParseDSN("file://" + dir + "/app-{2006-01-02}.log?max_age=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(h).Info("hello")
+	h.(io.Closer).Close()
+	matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(matches) != 1 || !strings.HasSuffix(matches[0], time.Now().Format("2006-01-02")+".log") {
+		t.Errorf("files = %v", matches)
+	}
+}
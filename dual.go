@@ -0,0 +1,55 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"os"
+)
+
+// FileOptions configures the file written by NewDualHandler.
+type FileOptions struct {
+	// HandlerOptions formats the file in the text format. Color is ignored.
+	// Default level is slog.LevelInfo.
+	HandlerOptions
+	// JSON writes records with slog.JSONHandler instead of the text format.
+	JSON bool
+	// MaxSize rotates the file once it grows beyond this many bytes,
+	// keeping Backups old files. Default is 0 (no rotation).
+	MaxSize int64
+	Backups int
+}
+
+// NewDualHandler returns a handler that writes text to os.Stderr and
+// plain text or JSON to the file at filePath, the combination most
+// command-line tools and daemons want. With nil consoleOpts, the console
+// logs at INFO and is colored when os.Stderr is a terminal.
+// Close the returned handler to close the file.
+func NewDualHandler(consoleOpts *HandlerOptions, filePath string, fileOpts *FileOptions) (*FanoutHandler, error) {
+	if consoleOpts == nil {
+		consoleOpts = &HandlerOptions{
+			HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+			Color:          isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == "",
+		}
+	}
+	fo := FileOptions{}
+	if fileOpts != nil {
+		fo = *fileOpts
+	}
+	if fo.Level == nil {
+		fo.Level = slog.LevelInfo
+	}
+	fo.Color = false
+	f, err := OpenRotatingFile(filePath, fo.MaxSize, fo.Backups)
+	if err != nil {
+		return nil, err
+	}
+	var fh slog.Handler
+	if fo.JSON {
+		fh = slog.NewJSONHandler(f, &fo.HandlerOptions.HandlerOptions)
+	} else {
+		fh = NewLogHandler(f, &fo.HandlerOptions)
+	}
+	return NewFanoutHandler(
+		NewLogHandler(os.Stderr, consoleOpts),
+		&closingHandler{Handler: fh, Closer: f},
+	), nil
+}
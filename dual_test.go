@@ -0,0 +1,61 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewDualHandler(t *testing.T) {
+	dir := t.TempDir()
+	console, err := os.Create(filepath.Join(dir, "console"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = console
+	defer func() { os.Stderr = stderr }()
+
+	path := filepath.Join(dir, "app.log")
+	h, err := NewDualHandler(nil, path, &FileOptions{
+		HandlerOptions: HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}},
+		JSON:           true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h).With("app", "demo")
+	logger.Debug("details")
+	logger.Info("started", "port", 8080)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	console.Close()
+
+	b, err := os.ReadFile(console.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); strings.Contains(got, "details") || !strings.Contains(got, "[INFO] [app:demo] started [port:8080]") {
+		t.Errorf("console = %q", got)
+	}
+
+	b, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("file = %q", b)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["msg"] != "started" || rec["app"] != "demo" || rec["port"] != float64(8080) {
+		t.Errorf("unexpected record %v", rec)
+	}
+}
@@ -0,0 +1,293 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ElasticsearchOptions configures an ElasticsearchHandler.
+type ElasticsearchOptions struct {
+	// Level is the minimum level indexed. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// IndexPrefix and IndexDateFormat build the index name from the record time in UTC,
+	// e.g. "logs-2024.06.01". Defaults are "logs-" and "2006.01.02".
+	// Set IndexDateFormat to "-" to write every record to IndexPrefix.
+	IndexPrefix     string
+	IndexDateFormat string
+	// FieldMap renames attributes to document fields, e.g. {"user_id": "user.id"}.
+	FieldMap map[string]string
+	// JSON selects the type-preserving encoding of attribute values, see
+	// HTTPOptions.JSON. With Schema JSONSchemaECS, documents follow the
+	// Elastic Common Schema: attributes in FieldMap become top-level fields
+	// and the others are nested under "labels".
+	JSON *JSONOptions
+	// Header is added to every request, e.g. for Authorization.
+	Header http.Header
+	// Compressor compresses bulk requests, setting Content-Encoding.
+	// Elasticsearch accepts gzip. Default is no compression.
+	Compressor Compressor
+	// Client is used for bulk requests. Default is a client with a 30 second timeout.
+	Client *http.Client
+	// BatchSize is the maximum number of documents per bulk request. Default is 500.
+	BatchSize int
+	// Interval is the maximum time a record waits before being indexed. Default is 5 seconds.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be indexed. Default is 10000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// MaxRetries is the number of retries for requests or documents rejected
+	// with 429 Too Many Requests. Default is 3; a negative value disables retries.
+	MaxRetries int
+	// Backoff is the wait before the first retry; it doubles on each retry. Default is 1 second.
+	Backoff time.Duration
+	// OnError is called when documents cannot be indexed. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+}
+
+// ElasticsearchHandler is a slog.Handler that indexes records into
+// Elasticsearch or OpenSearch through the _bulk API.
+type ElasticsearchHandler struct {
+	url    string
+	opts   ElasticsearchOptions
+	queue  *batcher[*Entry]
+	health *sinkHealth
+	scope  attrScope
+}
+
+// NewElasticsearchHandler creates a handler that sends bulk requests to the cluster at url,
+// e.g. "https://localhost:9200".
+func NewElasticsearchHandler(url string, opts *ElasticsearchOptions) (*ElasticsearchHandler, error) {
+	o := ElasticsearchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.IndexPrefix == "" {
+		o.IndexPrefix = "logs-"
+	}
+	if o.IndexDateFormat == "" {
+		o.IndexDateFormat = "2006.01.02"
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("elasticsearch")
+	}
+	h := &ElasticsearchHandler{url: strings.TrimSuffix(url, "/") + "/_bulk", opts: o}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(entries []*Entry) {
+		h.health.report(h.bulk(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	return h, nil
+}
+
+func (h *ElasticsearchHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *ElasticsearchHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.queue.add(ctx, newEntry(record, h.scope))
+}
+
+func (h *ElasticsearchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *ElasticsearchHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush indexes all queued records.
+func (h *ElasticsearchHandler) Flush() error {
+	h.queue.Flush()
+	return nil
+}
+
+// Close indexes pending records and stops the handler.
+func (h *ElasticsearchHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *ElasticsearchHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *ElasticsearchHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+// index returns the index name for e.
+func (h *ElasticsearchHandler) index(e *Entry) string {
+	if h.opts.IndexDateFormat == "-" {
+		return h.opts.IndexPrefix
+	}
+	return h.opts.IndexPrefix + e.Time.UTC().Format(h.opts.IndexDateFormat)
+}
+
+// document encodes e as an Elasticsearch document.
+func (h *ElasticsearchHandler) document(e *Entry) []byte {
+	if h.opts.JSON != nil && h.opts.JSON.Schema == JSONSchemaECS {
+		return h.opts.JSON.appendECS(nil, e, h.opts.FieldMap)
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	writeJSONField(buf, "@timestamp", e.Time.Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeJSONField(buf, "level", LevelName(e.Level))
+	buf.WriteByte(',')
+	writeJSONField(buf, "message", e.Message)
+	if e.Source != nil {
+		buf.WriteByte(',')
+		writeJSONField(buf, "source", fmt.Sprintf("%s:%d", e.Source.File, e.Source.Line))
+	}
+	for _, a := range e.Attrs {
+		key := a.Key
+		if k, ok := h.opts.FieldMap[key]; ok {
+			key = k
+		}
+		buf.WriteByte(',')
+		if h.opts.JSON != nil {
+			buf.Write(appendJSONString(nil, key))
+			buf.WriteByte(':')
+			buf.Write(h.opts.JSON.appendValue(nil, a.Value))
+			continue
+		}
+		writeJSONField(buf, key, a.Value.String())
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// bulk indexes entries, retrying requests and documents rejected with 429.
+func (h *ElasticsearchHandler) bulk(entries []*Entry) error {
+	backoff := h.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := h.send(entries)
+		if err != nil && len(retry) == 0 {
+			return err
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt >= h.opts.MaxRetries {
+			return fmt.Errorf("sloghandler: elasticsearch rejected %d documents after %d retries", len(retry), attempt)
+		}
+		if err != nil {
+			h.opts.OnError(err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		entries = retry
+	}
+}
+
+// send performs a single bulk request and returns the entries to retry.
+func (h *ElasticsearchHandler) send(entries []*Entry) ([]*Entry, error) {
+	body := new(bytes.Buffer)
+	for _, e := range entries {
+		body.WriteString(`{"index":{`)
+		writeJSONField(body, "_index", h.index(e))
+		body.WriteString("}}\n")
+		body.Write(h.document(e))
+		body.WriteByte('\n')
+	}
+	payload, err := compress(h.opts.Compressor, body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range h.opts.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if h.opts.Compressor != nil && h.opts.Compressor.Encoding() != "" {
+		req.Header.Set("Content-Encoding", h.opts.Compressor.Encoding())
+	}
+	resp, err := h.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return entries, fmt.Errorf("sloghandler: elasticsearch returned %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("sloghandler: elasticsearch returned %s: %s", resp.Status, b)
+	}
+	var br bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&br); err != nil {
+		return nil, fmt.Errorf("sloghandler: failed to decode bulk response: %w", err)
+	}
+	if !br.Errors {
+		return nil, nil
+	}
+	var retry []*Entry
+	failed := 0
+	for i, item := range br.Items {
+		for _, result := range item {
+			switch {
+			case result.Status == http.StatusTooManyRequests && i < len(entries):
+				retry = append(retry, entries[i])
+			case result.Status >= 300:
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		err = fmt.Errorf("sloghandler: elasticsearch failed to index %d documents", failed)
+	}
+	return retry, err
+}
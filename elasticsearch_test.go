@@ -0,0 +1,130 @@
+package sloghandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElasticsearchHandler(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var lines []string
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		mu.Lock()
+		requests = append(requests, lines)
+		n := len(requests)
+		mu.Unlock()
+		if n == 1 {
+			// Reject the second document once
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer ts.Close()
+
+	h, err := NewElasticsearchHandler(ts.URL+"/", &ElasticsearchOptions{
+		FieldMap: map[string]string{"user_id": "user.id"},
+		Interval: time.Hour,
+		Backoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	ts1 := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	r := slog.NewRecord(ts1, slog.LevelInfo, "first", 0)
+	r.AddAttrs(slog.String("user_id", "u1"))
+	logger.Handler().Handle(t.Context(), r)
+	logger.Handler().Handle(t.Context(), slog.NewRecord(ts1.Add(2*time.Hour), slog.LevelWarn, "second", 0))
+	h.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("got %d bulk requests, want 2", len(requests))
+	}
+	if requests[0][0] != `{"index":{"_index":"logs-2024.06.01"}}` {
+		t.Errorf("unexpected action line %s", requests[0][0])
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(requests[0][1]), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["user.id"] != "u1" || doc["message"] != "first" || doc["@timestamp"] != "2024-06-01T23:00:00Z" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if len(requests[1]) != 2 || !strings.Contains(requests[1][0], "logs-2024.06.02") {
+		t.Errorf("retry should contain only the rejected document, got %v", requests[1])
+	}
+}
+
+func TestElasticsearchActionLineJSON(t *testing.T) {
+	lines := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := bufio.NewScanner(r.Body)
+		sc.Scan()
+		lines <- sc.Text()
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer ts.Close()
+
+	const index = "logs-\u00e9v\u00e9nements\x01"
+	h, err := NewElasticsearchHandler(ts.URL, &ElasticsearchOptions{IndexPrefix: index, IndexDateFormat: "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(h).Info("hello")
+	h.Close()
+
+	var action struct {
+		Index struct {
+			Index string `json:"_index"`
+		} `json:"index"`
+	}
+	line := <-lines
+	if err := json.Unmarshal([]byte(line), &action); err != nil {
+		t.Fatalf("invalid action line %s: %v", line, err)
+	}
+	if action.Index.Index != index {
+		t.Errorf("_index = %q, want %q", action.Index.Index, index)
+	}
+}
+
+func TestElasticsearchDocumentECS(t *testing.T) {
+	h, err := NewElasticsearchHandler("http://localhost:9200", &ElasticsearchOptions{
+		FieldMap: map[string]string{"user_id": "user.id"},
+		JSON:     &JSONOptions{Schema: JSONSchemaECS},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	e := &Entry{
+		Time:    time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC),
+		Level:   slog.LevelError,
+		Message: "failed",
+		Source:  &slog.Source{File: "/src/app/main.go", Line: 42, Function: "main.run"},
+		Attrs:   []slog.Attr{slog.String("user_id", "u1"), slog.Int("http.status", 500)},
+	}
+	want := `{"@timestamp":"2024-06-01T23:00:00Z","log.level":"error","message":"failed","ecs.version":"8.11.0",` +
+		`"log.origin.file.name":"/src/app/main.go","log.origin.file.line":42,"log.origin.function":"main.run",` +
+		`"user.id":"u1","labels":{"http_status":500}}`
+	if got := string(h.document(e)); got != want {
+		t.Errorf("document =\n%s\nwant\n%s", got, want)
+	}
+}
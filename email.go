@@ -0,0 +1,232 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailOptions configures an EmailHandler.
+type EmailOptions struct {
+	// Addr is the SMTP server address, e.g. "smtp.example.com:587".
+	Addr string
+	// Auth is passed to smtp.SendMail. May be nil.
+	Auth smtp.Auth
+	// From and To are the envelope and header addresses of the digest.
+	From string
+	To   []string
+	// Subject is the digest subject. "%d" is replaced with the number of records.
+	// Default is "[sloghandler] %d log records".
+	Subject string
+	// Level is the minimum level included in digests. Default is slog.LevelError.
+	Level slog.Leveler
+	// ImmediateLevel is the level at which the digest is sent right away,
+	// including the triggering record. Default is LevelFatal.
+	ImmediateLevel slog.Leveler
+	// Interval is how often digests are sent. Default is 5 minutes.
+	Interval time.Duration
+	// MaxRecords sends a digest early once this many records are pending. Default is 500.
+	MaxRecords int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// OnError is called when a digest cannot be sent. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+	// SendMail sends the message. Default is smtp.SendMail.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// EmailHandler is a slog.Handler that batches records and emails them as a
+// digest with HTML and plain-text parts. Call Close before exiting to send
+// the pending digest.
+type EmailHandler struct {
+	opts   EmailOptions
+	queue  *batcher[*Entry]
+	health *sinkHealth
+	scope  attrScope
+}
+
+// NewEmailHandler creates a handler that emails digests of records.
+func NewEmailHandler(opts *EmailOptions) (*EmailHandler, error) {
+	if opts == nil || opts.Addr == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, errors.New("sloghandler: email Addr, From and To are required")
+	}
+	o := *opts
+	if o.Subject == "" {
+		o.Subject = "[sloghandler] %d log records"
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelError
+	}
+	if o.ImmediateLevel == nil {
+		o.ImmediateLevel = LevelFatal
+	}
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Minute
+	}
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = 500
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("email")
+	}
+	if o.SendMail == nil {
+		o.SendMail = smtp.SendMail
+	}
+	h := &EmailHandler{opts: o}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.MaxRecords, o.MaxRecords, o.Interval, func(entries []*Entry) {
+		h.health.report(h.send(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	return h, nil
+}
+
+func (h *EmailHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *EmailHandler) Handle(ctx context.Context, record slog.Record) error {
+	if err := h.queue.add(ctx, newEntry(record, h.scope)); err != nil {
+		return err
+	}
+	if record.Level >= h.opts.ImmediateLevel.Level() {
+		h.queue.Flush()
+	}
+	return nil
+}
+
+func (h *EmailHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *EmailHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush sends the pending digest immediately.
+func (h *EmailHandler) Flush() error {
+	h.queue.Flush()
+	return nil
+}
+
+// Close sends the pending digest and stops the handler.
+func (h *EmailHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *EmailHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *EmailHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+func (h *EmailHandler) send(entries []*Entry) error {
+	msg, err := h.message(entries, time.Now())
+	if err != nil {
+		return err
+	}
+	return h.opts.SendMail(h.opts.Addr, h.opts.Auth, h.opts.From, h.opts.To, msg)
+}
+
+var emailHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"levelHTMLColor": levelHTMLColor,
+}).Parse(`<html><body>
+<pre style="font-family: monospace">
+{{- range . }}
+<span style="color: {{ levelHTMLColor .Level }}">{{ .String }}</span>
+{{- end }}
+</pre>
+</body></html>
+`))
+
+func levelHTMLColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "#c00"
+	case level >= slog.LevelWarn:
+		return "#b80"
+	case level >= slog.LevelInfo:
+		return "inherit"
+	default:
+		return "#888"
+	}
+}
+
+// message builds a multipart/alternative MIME message for entries.
+func (h *EmailHandler) message(entries []*Entry, now time.Time) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	header := textproto.MIMEHeader{}
+	header.Set("From", h.opts.From)
+	header.Set("To", strings.Join(h.opts.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", strings.ReplaceAll(h.opts.Subject, "%d", strconv.Itoa(len(entries)))))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	for _, k := range []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type"} {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, header.Get(k))
+	}
+	buf.WriteString("\r\n")
+
+	// Quoted-printable keeps long lines within the SMTP line limit and the
+	// message intact through 7-bit relays.
+	part := func(contentType string) (*quotedprintable.Writer, error) {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return quotedprintable.NewWriter(w), nil
+	}
+	text, err := part("text/plain; charset=utf-8")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		fmt.Fprintf(text, "%s\r\n", e)
+	}
+	if err := text.Close(); err != nil {
+		return nil, err
+	}
+	html, err := part("text/html; charset=utf-8")
+	if err != nil {
+		return nil, err
+	}
+	if err := emailHTMLTemplate.Execute(html, entries); err != nil {
+		return nil, err
+	}
+	if err := html.Close(); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package sloghandler
+
+import (
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sentMail struct {
+	addr string
+	to   []string
+	msg  string
+}
+
+func TestEmailHandler(t *testing.T) {
+	var mu sync.Mutex
+	var sent []sentMail
+	h, err := NewEmailHandler(&EmailOptions{
+		Addr:     "smtp.example.com:25",
+		From:     "app@example.com",
+		To:       []string{"ops@example.com"},
+		Interval: time.Hour,
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, sentMail{addr, to, string(msg)})
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Warn("not included")
+	logger.Error("disk <full>", "path", "/var", "detail", strings.Repeat("é", 1000))
+	logger.Error("retry failed")
+
+	mu.Lock()
+	if len(sent) != 0 {
+		t.Errorf("digest sent before interval: %d", len(sent))
+	}
+	mu.Unlock()
+
+	logger.Log(t.Context(), LevelFatal, "giving up")
+	mu.Lock()
+	if len(sent) != 1 {
+		t.Fatalf("fatal record did not trigger digest, sent=%d", len(sent))
+	}
+	msg := sent[0].msg
+	mu.Unlock()
+
+	if !strings.Contains(msg, "Subject: [sloghandler] 3 log records") {
+		t.Errorf("unexpected subject:\n%s", msg)
+	}
+	for _, line := range strings.Split(msg, "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line longer than 998 octets: %d", len(line))
+		}
+	}
+	parts := emailParts(t, msg)
+	for contentType, wants := range map[string][]string{
+		"text/plain; charset=utf-8": {"disk <full> [path:/var]", "giving up"},
+		"text/html; charset=utf-8":  {"disk &lt;full&gt;"},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(parts[contentType], want) {
+				t.Errorf("%s part should contain %q:\n%s", contentType, want, parts[contentType])
+			}
+		}
+	}
+	if strings.Contains(msg, "not included") {
+		t.Error("digest should not contain records below Level")
+	}
+	h.Close()
+}
+
+func TestNewEmailHandlerRequiresAddresses(t *testing.T) {
+	if _, err := NewEmailHandler(&EmailOptions{Addr: "localhost:25"}); err == nil {
+		t.Error("expected error without From and To")
+	}
+}
+
+// emailParts decodes the parts of a multipart message by content type,
+// checking that each is quoted-printable.
+func emailParts(t *testing.T, msg string) map[string]string {
+	t.Helper()
+	m, err := mail.ReadMessage(strings.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	r := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if cte := p.Header.Get("Content-Transfer-Encoding"); cte != "quoted-printable" {
+			t.Errorf("Content-Transfer-Encoding = %q", cte)
+		}
+		b, err := io.ReadAll(quotedprintable.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[p.Header.Get("Content-Type")] = string(b)
+	}
+	return parts
+}
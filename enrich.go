@@ -0,0 +1,102 @@
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Enricher returns attributes to add to a record.
+type Enricher func(ctx context.Context, r slog.Record) []slog.Attr
+
+// EnrichAtLevel returns a Transformer that adds the attributes of enrichers
+// only to records at or above level, so the cost of expensive attributes is
+// paid only for the records that need them:
+//
+//	h := sloghandler.NewTransformHandler(handler,
+//		sloghandler.EnrichAtLevel(slog.LevelError, sloghandler.StackEnricher, sloghandler.MemStatsEnricher),
+//	)
+func EnrichAtLevel(level slog.Leveler, enrichers ...Enricher) Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		if r.Level < level.Level() {
+			return r, true
+		}
+		r = r.Clone()
+		for _, e := range enrichers {
+			r.AddAttrs(e(ctx, r)...)
+		}
+		return r, true
+	})
+}
+
+// Enrich returns a Transformer that adds the attributes of enrichers to
+// every record, such as IDs taken from the context:
+//
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Enrich(sloghandler.TraceParentEnricher))
+func Enrich(enrichers ...Enricher) Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		var attrs []slog.Attr
+		for _, e := range enrichers {
+			attrs = append(attrs, e(ctx, r)...)
+		}
+		if len(attrs) > 0 {
+			r = r.Clone()
+			r.AddAttrs(attrs...)
+		}
+		return r, true
+	})
+}
+
+// StackEnricher adds the stack of the logging goroutine, starting at the
+// logging call, as a "stack" attribute of type Stack.
+func StackEnricher(ctx context.Context, r slog.Record) []slog.Attr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	for i, pc := range pcs {
+		if pc == r.PC {
+			pcs = pcs[i:]
+			break
+		}
+	}
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "%s()\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return []slog.Attr{slog.Any("stack", Stack(strings.TrimSuffix(sb.String(), "\n")))}
+}
+
+// GoroutineDumpEnricher adds the stacks of all goroutines as a "goroutines"
+// attribute of type Stack. It stops the world while collecting them.
+func GoroutineDumpEnricher(ctx context.Context, r slog.Record) []slog.Attr {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	return []slog.Attr{slog.Any("goroutines", Stack(strings.TrimSuffix(string(buf), "\n")))}
+}
+
+// MemStatsEnricher adds a snapshot of runtime.MemStats as a "memstats" group.
+// It stops the world while reading the statistics.
+func MemStatsEnricher(ctx context.Context, r slog.Record) []slog.Attr {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return []slog.Attr{slog.Group("memstats",
+		slog.Uint64("heap_alloc", m.HeapAlloc),
+		slog.Uint64("heap_inuse", m.HeapInuse),
+		slog.Uint64("heap_objects", m.HeapObjects),
+		slog.Uint64("sys", m.Sys),
+		slog.Uint64("num_gc", uint64(m.NumGC)),
+	)}
+}
@@ -0,0 +1,46 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEnrichAtLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	calls := 0
+	counting := func(ctx context.Context, r slog.Record) []slog.Attr {
+		calls++
+		return []slog.Attr{slog.Int("calls", calls)}
+	}
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil),
+		EnrichAtLevel(slog.LevelError, counting, StackEnricher, MemStatsEnricher, GoroutineDumpEnricher),
+	))
+
+	logger.Info("cheap")
+	if calls != 0 || strings.Contains(buf.String(), "stack") {
+		t.Fatalf("enrichers ran below the threshold: %s", buf.String())
+	}
+	buf.Reset()
+	logger.Error("expensive")
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["calls"] != 1.0 {
+		t.Errorf("unexpected record %v", m)
+	}
+	stack, _ := m["stack"].(string)
+	if !strings.HasPrefix(stack, "github.com/fujiwara/sloghandler.TestEnrichAtLevel()") {
+		t.Errorf("stack should start at the logging call:\n%s", stack)
+	}
+	if ms, _ := m["memstats"].(map[string]any); ms["heap_alloc"] == nil {
+		t.Errorf("missing memstats: %v", m["memstats"])
+	}
+	if g, _ := m["goroutines"].(string); !strings.Contains(g, "goroutine ") {
+		t.Errorf("missing goroutine dump: %v", m["goroutines"])
+	}
+}
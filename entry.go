@@ -0,0 +1,175 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Entry is a flattened, self-contained representation of a slog.Record.
+// Attributes added via WithAttrs and WithGroup are resolved into Attrs,
+// with group names joined to keys by dots (e.g. "http.status").
+// Sinks use Entry so they can encode records without walking groups themselves.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Source  *slog.Source
+	Attrs   []slog.Attr
+}
+
+// Attr returns the value of the first attribute with the given key.
+func (e *Entry) Attr(key string) (slog.Value, bool) {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// String renders the entry in the bracket format of the text handler, without color.
+func (e *Entry) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(e.Time.Format(TimeFormat))
+	fmt.Fprintf(buf, " [%s]", LevelName(e.Level))
+	if e.Source != nil {
+		fmt.Fprintf(buf, " [%s:%d]", filepath.Base(e.Source.File), e.Source.Line)
+	}
+	fmt.Fprintf(buf, " %s", escapeControl(e.Message))
+	for _, a := range e.Attrs {
+		if a.Key == "" {
+			fmt.Fprintf(buf, " [%s]", escapeControl(a.Value.String()))
+		} else {
+			fmt.Fprintf(buf, " [%s:%s]", escapeControl(a.Key), escapeControl(a.Value.String()))
+		}
+	}
+	return buf.String()
+}
+
+// MarshalJSON encodes the entry as a single JSON object with "time", "level",
+// "msg" and optional "source" fields followed by the attributes.
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	writeJSONField(buf, "time", e.Time.Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeJSONField(buf, "level", LevelName(e.Level))
+	buf.WriteByte(',')
+	writeJSONField(buf, "msg", e.Message)
+	if e.Source != nil {
+		buf.WriteByte(',')
+		writeJSONField(buf, "source", e.Source)
+	}
+	for _, a := range e.Attrs {
+		buf.WriteByte(',')
+		writeJSONField(buf, a.Key, a.Value.String())
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// attrsJSON encodes attrs as a JSON object.
+func attrsJSON(attrs []slog.Attr) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, a := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONField(buf, a.Key, a.Value.String())
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, v any) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(err.Error())
+	}
+	buf.Write(b)
+}
+
+// newEntry builds an Entry from the record and the attributes accumulated in scope.
+// A source override attribute (see SourceAttr) replaces the record's source.
+func newEntry(r slog.Record, scope attrScope) *Entry {
+	src := recordSource(r)
+	if src == nil {
+		src = r.Source()
+	}
+	return &Entry{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Source:  src,
+		Attrs:   scope.collect(r),
+	}
+}
+
+// attrScope holds the attributes and group prefix accumulated by WithAttrs and
+// WithGroup, for handlers that flatten attributes at Handle time.
+type attrScope struct {
+	prefix string
+	attrs  []slog.Attr
+}
+
+func (s attrScope) withAttrs(attrs []slog.Attr) attrScope {
+	n := attrScope{prefix: s.prefix, attrs: slices.Clip(s.attrs)}
+	for _, a := range attrs {
+		n.attrs = appendFlatAttr(n.attrs, s.prefix, a)
+	}
+	return n
+}
+
+func (s attrScope) withGroup(name string) attrScope {
+	if name == "" {
+		return s
+	}
+	return attrScope{prefix: s.prefix + name + ".", attrs: slices.Clip(s.attrs)}
+}
+
+// collect returns the scope attributes followed by the record attributes, flattened.
+// Source override attributes are left out.
+func (s attrScope) collect(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, len(s.attrs), len(s.attrs)+r.NumAttrs())
+	copy(attrs, s.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		if _, ok := sourceOverride(a); ok {
+			return true
+		}
+		attrs = appendFlatAttr(attrs, s.prefix, a)
+		return true
+	})
+	return attrs
+}
+
+// appendFlatAttr resolves a and appends it to dst, expanding groups into
+// dotted keys. Empty attributes and empty groups are dropped as slog requires.
+func appendFlatAttr(dst []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			dst = appendFlatAttr(dst, p, ga)
+		}
+		return dst
+	}
+	if a.Key != "" {
+		a.Key = prefix + a.Key
+	}
+	return append(dst, a)
+}
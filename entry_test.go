@@ -0,0 +1,62 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAttrScopeCollect(t *testing.T) {
+	scope := attrScope{}.
+		withAttrs([]slog.Attr{slog.String("app", "test")}).
+		withGroup("http").
+		withAttrs([]slog.Attr{slog.String("method", "GET")})
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "request", 0)
+	record.AddAttrs(
+		slog.Int("status", 200),
+		slog.Group("req", slog.String("path", "/"), slog.Group("empty")),
+		slog.Attr{},
+	)
+
+	got := scope.collect(record)
+	want := []string{"app=test", "http.method=GET", "http.status=200", "http.req.path=/"}
+	if len(got) != len(want) {
+		t.Fatalf("collect() returned %d attrs, want %d: %v", len(got), len(want), got)
+	}
+	for i, a := range got {
+		if a.String() != want[i] {
+			t.Errorf("attr[%d] = %q, want %q", i, a.String(), want[i])
+		}
+	}
+}
+
+func TestAttrScopeIsolation(t *testing.T) {
+	base := attrScope{}.withAttrs([]slog.Attr{slog.String("a", "1")})
+	s1 := base.withAttrs([]slog.Attr{slog.String("b", "2")})
+	s2 := base.withAttrs([]slog.Attr{slog.String("c", "3")})
+	if s1.attrs[1].Key != "b" || s2.attrs[1].Key != "c" {
+		t.Errorf("derived scopes share attributes: %v %v", s1.attrs, s2.attrs)
+	}
+}
+
+func TestEntryMarshalJSON(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Level:   slog.LevelWarn,
+		Message: "hello",
+		Attrs:   []slog.Attr{slog.Int("n", 1)},
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"time":"2023-01-02T15:04:05Z","level":"WARN","msg":"hello","n":"1"}`
+	if string(b) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", b, want)
+	}
+	if v, ok := e.Attr("n"); !ok || v.Int64() != 1 {
+		t.Errorf("Attr(n) = %v, %v", v, ok)
+	}
+}
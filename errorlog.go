@@ -0,0 +1,78 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+)
+
+// lineRule gives the level of lines containing a substring.
+type lineRule struct {
+	substr string
+	level  slog.Level
+}
+
+// httpErrorRules classify the messages of net/http, x/net/http2 and
+// httputil.ReverseProxy. Most are caused by clients and are not errors of
+// the server.
+var httpErrorRules = []lineRule{
+	{"http: TLS handshake error", slog.LevelWarn},
+	{"error reading preface from client", slog.LevelWarn},
+	{"http: superfluous response.WriteHeader call", slog.LevelWarn},
+	{"http: URL query contains semicolon", slog.LevelWarn},
+	{"http: proxy error: context canceled", slog.LevelInfo},
+	{"ReverseProxy read error during body copy", slog.LevelWarn},
+}
+
+// sqlDriverRules classify the messages of database drivers. Broken
+// connections are retried by database/sql, so they are only warnings.
+var sqlDriverRules = []lineRule{
+	{"closing bad idle connection", slog.LevelWarn},
+	{"invalid connection", slog.LevelWarn},
+	{"unexpected EOF", slog.LevelWarn},
+	{"broken pipe", slog.LevelWarn},
+	{"connection reset by peer", slog.LevelWarn},
+}
+
+// classifyBy returns a LineWriter classifier applying level prefixes as
+// NewStdlibLogger does, then the first of rules matching the line.
+func classifyBy(rules []lineRule) func([]byte, slog.Level) (slog.Level, []byte) {
+	return func(line []byte, def slog.Level) (slog.Level, []byte) {
+		level, rest := cutLevelPrefix(line, def)
+		if len(rest) != len(line) {
+			return level, rest
+		}
+		for _, r := range rules {
+			if bytes.Contains(line, []byte(r.substr)) {
+				return r.level, line
+			}
+		}
+		return def, line
+	}
+}
+
+// NewHTTPErrorLog returns a *log.Logger for http.Server.ErrorLog and
+// httputil.ReverseProxy.ErrorLog, logging their messages to h instead of
+// the standard logger:
+//
+//	srv := &http.Server{Handler: mux, ErrorLog: sloghandler.NewHTTPErrorLog(handler)}
+//
+// Messages caused by clients are downgraded: TLS handshake errors, bad
+// HTTP/2 prefaces and interrupted proxy body copies are logged at WARN,
+// and proxy errors of canceled requests at INFO. Other messages, such as
+// panics in handlers and accept errors, are logged at ERROR.
+func NewHTTPErrorLog(h slog.Handler) *log.Logger {
+	return log.New(&LineWriter{handler: h, level: slog.LevelError, classify: classifyBy(httpErrorRules)}, "", 0)
+}
+
+// NewSQLDriverLogger returns a *log.Logger for database drivers that log
+// through a Print method, such as github.com/go-sql-driver/mysql:
+//
+//	mysql.SetLogger(sloghandler.NewSQLDriverLogger(handler))
+//
+// Broken and bad idle connections, which database/sql retries, are logged
+// at WARN, and other messages at ERROR. Level prefixes such as "[WARN]"
+// are honored as in NewStdlibLogger.
+func NewSQLDriverLogger(h slog.Handler) *log.Logger {
+	return log.New(&LineWriter{handler: h, level: slog.LevelError, classify: classifyBy(sqlDriverRules)}, "", 0)
+}
@@ -0,0 +1,47 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewHTTPErrorLog(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	logger := NewHTTPErrorLog(store)
+	logger.Printf("http: TLS handshake error from %s: %v", "192.0.2.1:5555", "EOF")
+	logger.Printf("http: proxy error: %v", "context canceled")
+	logger.Printf("http: proxy error: %v", "dial tcp 127.0.0.1:8080: connection refused")
+	logger.Printf("http: panic serving %s: %v", "192.0.2.1:5555", "boom")
+
+	want := []slog.Level{slog.LevelWarn, slog.LevelInfo, slog.LevelError, slog.LevelError}
+	records := store.Records(Query{})
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, e := range records {
+		if e.Level != want[i] {
+			t.Errorf("%q: level = %v, want %v", e.Message, e.Level, want[i])
+		}
+	}
+	if !strings.HasPrefix(records[0].Message, "http: TLS handshake error from 192.0.2.1:5555") {
+		t.Errorf("message = %q", records[0].Message)
+	}
+}
+
+func TestNewSQLDriverLogger(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	logger := NewSQLDriverLogger(store)
+	logger.Print("closing bad idle connection: unexpected read from socket")
+	logger.Print("busy buffer")
+	logger.Print("[INFO] reconnected")
+
+	var got []string
+	for _, e := range store.Records(Query{}) {
+		got = append(got, LevelName(e.Level)+" "+e.Message)
+	}
+	want := "WARN closing bad idle connection: unexpected read from socket|ERROR busy buffer|INFO reconnected"
+	if s := strings.Join(got, "|"); s != want {
+		t.Errorf("records = %q, want %q", s, want)
+	}
+}
@@ -0,0 +1,51 @@
+package sloghandler
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// needsEscape reports whether s contains control characters or invalid UTF-8.
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c == 0x7f || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeControl makes s safe to write on a single terminal line: newlines,
+// tabs and other control characters (including ESC, so embedded ANSI
+// sequences cannot restyle the terminal) are written as Go escapes such as
+// \n or \x1b, and invalid UTF-8 bytes as \xNN.
+func escapeControl(s string) string {
+	if !needsEscape(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case r >= 0x80 && r < 0xa0:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
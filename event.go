@@ -0,0 +1,114 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// EventKey is the attribute key of event names.
+const EventKey = "event"
+
+// EventBuilder builds a structured event. Create one with Event.
+type EventBuilder struct {
+	name  string
+	level slog.Level
+	attrs []slog.Attr
+}
+
+// Event starts an event named name, such as "payment.succeeded", for teams
+// logging events rather than free-form messages:
+//
+//	sloghandler.Event("payment.succeeded").Str("order", id).Dur("latency", d).Emit(ctx, logger)
+//	// [INFO] payment.succeeded [event:payment.succeeded] [order:o-123] [latency:82ms]
+//
+// The name is both the message and the "event" attribute, which the metrics
+// handlers can use as a label to count events:
+//
+//	prommetrics.NewHandlerWithOptions(h, counter, &prommetrics.Options{LabelAttributes: []string{"event"}})
+//
+// Events are logged at INFO unless Level is called.
+func Event(name string) *EventBuilder {
+	return &EventBuilder{name: name, level: slog.LevelInfo, attrs: []slog.Attr{slog.String(EventKey, name)}}
+}
+
+// Level sets the level of the event.
+func (e *EventBuilder) Level(level slog.Level) *EventBuilder {
+	e.level = level
+	return e
+}
+
+// Str adds a string attribute.
+func (e *EventBuilder) Str(key, value string) *EventBuilder {
+	return e.Attr(slog.String(key, value))
+}
+
+// Int adds an integer attribute.
+func (e *EventBuilder) Int(key string, value int) *EventBuilder {
+	return e.Attr(slog.Int(key, value))
+}
+
+// Int64 adds an integer attribute.
+func (e *EventBuilder) Int64(key string, value int64) *EventBuilder {
+	return e.Attr(slog.Int64(key, value))
+}
+
+// Uint64 adds an unsigned integer attribute.
+func (e *EventBuilder) Uint64(key string, value uint64) *EventBuilder {
+	return e.Attr(slog.Uint64(key, value))
+}
+
+// Float adds a floating point attribute.
+func (e *EventBuilder) Float(key string, value float64) *EventBuilder {
+	return e.Attr(slog.Float64(key, value))
+}
+
+// Bool adds a boolean attribute.
+func (e *EventBuilder) Bool(key string, value bool) *EventBuilder {
+	return e.Attr(slog.Bool(key, value))
+}
+
+// Dur adds a duration attribute.
+func (e *EventBuilder) Dur(key string, value time.Duration) *EventBuilder {
+	return e.Attr(slog.Duration(key, value))
+}
+
+// Time adds a time attribute.
+func (e *EventBuilder) Time(key string, value time.Time) *EventBuilder {
+	return e.Attr(slog.Time(key, value))
+}
+
+// Err adds err as an "error" attribute, unless it is nil.
+func (e *EventBuilder) Err(err error) *EventBuilder {
+	if err == nil {
+		return e
+	}
+	return e.Attr(slog.Any("error", err))
+}
+
+// Any adds an attribute of any value.
+func (e *EventBuilder) Any(key string, value any) *EventBuilder {
+	return e.Attr(slog.Any(key, value))
+}
+
+// Attr adds attributes.
+func (e *EventBuilder) Attr(attrs ...slog.Attr) *EventBuilder {
+	e.attrs = append(e.attrs, attrs...)
+	return e
+}
+
+// Emit logs the event with logger. The source location is the caller of Emit.
+func (e *EventBuilder) Emit(ctx context.Context, logger *slog.Logger) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !logger.Enabled(ctx, e.level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [Callers, Emit]
+	r := slog.NewRecord(time.Now(), e.level, e.name, pcs[0])
+	r.AddAttrs(e.attrs...)
+	_ = logger.Handler().Handle(ctx, r)
+}
@@ -0,0 +1,37 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true},
+	}))
+	ctx := context.Background()
+
+	Event("payment.succeeded").Str("order", "o-123").Int("items", 3).Dur("latency", 82*time.Millisecond).Err(nil).Emit(ctx, logger)
+	Event("payment.failed").Level(slog.LevelError).Float("amount", 9.5).Bool("retry", true).Err(errors.New("declined")).Emit(ctx, logger)
+	Event("cache.miss").Level(slog.LevelDebug).Emit(ctx, logger)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		"[INFO] [event_test.go:20] payment.succeeded [event:payment.succeeded] [order:o-123] [items:3] [latency:82ms]",
+		"[ERROR] [event_test.go:21] payment.failed [event:payment.failed] [amount:9.5] [retry:true] [error:declined]",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	for i := range want {
+		if !strings.HasSuffix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want suffix %q", i, lines[i], want[i])
+		}
+	}
+}
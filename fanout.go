@@ -0,0 +1,152 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// FanoutHandler is a slog.Handler that sends each record to every child
+// handler enabled for its level, so one logger can feed several sinks.
+// Wrap children with NewLevelHandler to give them different thresholds:
+//
+//	slog.New(sloghandler.NewFanoutHandler(
+//		sloghandler.NewLevelHandler(slog.LevelInfo, console),
+//		sloghandler.NewLevelHandler(slog.LevelDebug, file),
+//		sloghandler.NewLevelHandler(slog.LevelError, slack),
+//	))
+type FanoutHandler struct {
+	handlers []slog.Handler
+}
+
+// NewFanoutHandler creates a FanoutHandler that sends records to handlers.
+func NewFanoutHandler(handlers ...slog.Handler) *FanoutHandler {
+	return &FanoutHandler{handlers: handlers}
+}
+
+// Handlers returns the child handlers.
+func (f *FanoutHandler) Handlers() []slog.Handler {
+	return f.handlers
+}
+
+func (f *FanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle sends record to each enabled child and joins their errors.
+// A failing child does not prevent delivery to the others.
+func (f *FanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range f.handlers {
+		if !h.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &FanoutHandler{handlers: handlers}
+}
+
+func (f *FanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &FanoutHandler{handlers: handlers}
+}
+
+// Flush flushes the Syncers among the children and their wrapped handlers.
+func (f *FanoutHandler) Flush() error {
+	var errs []error
+	for _, h := range f.handlers {
+		if err := Flush(h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every child that implements io.Closer.
+func (f *FanoutHandler) Close() error {
+	var errs []error
+	for _, h := range f.handlers {
+		if c, ok := h.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LevelHandler raises the minimum level of a handler, e.g. to send only
+// errors to one child of a FanoutHandler. A level set with WithMinLevel
+// overrides it, as with the built-in handlers.
+type LevelHandler struct {
+	level   slog.Leveler
+	handler slog.Handler
+}
+
+// NewLevelHandler returns a handler that passes records at or above level to h.
+// Records must also be enabled by h itself.
+func NewLevelHandler(level slog.Leveler, h slog.Handler) *LevelHandler {
+	// Avoid nesting level handlers, keeping the stricter level.
+	if lh, ok := h.(*LevelHandler); ok {
+		h = lh.handler
+		level = maxLeveler{level, lh.level}
+	}
+	return &LevelHandler{level: level, handler: h}
+}
+
+// maxLeveler is the higher of two levels, which may change over time.
+type maxLeveler [2]slog.Leveler
+
+func (l maxLeveler) Level() slog.Level {
+	return max(l[0].Level(), l[1].Level())
+}
+
+// Handler returns the wrapped handler.
+func (h *LevelHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.level) && h.handler.Enabled(ctx, level)
+}
+
+func (h *LevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{level: h.level, handler: h.handler.WithGroup(name)}
+}
+
+// Flush flushes the wrapped handler chain. See Flush.
+func (h *LevelHandler) Flush() error {
+	return Flush(h.handler)
+}
+
+// Close closes the wrapped handler if it implements io.Closer.
+func (h *LevelHandler) Close() error {
+	return closeHandler(h.handler)
+}
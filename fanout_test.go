@@ -0,0 +1,93 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingHandler struct{ slog.Handler }
+
+func (failingHandler) Handle(context.Context, slog.Record) error { return errors.New("sink down") }
+
+func TestFanoutHandler(t *testing.T) {
+	var console, file, alerts bytes.Buffer
+	newText := func(buf *bytes.Buffer) slog.Handler {
+		return NewLogHandler(buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: LevelTrace}})
+	}
+	f := NewFanoutHandler(
+		NewLevelHandler(slog.LevelInfo, newText(&console)),
+		NewLevelHandler(slog.LevelDebug, newText(&file)),
+		NewLevelHandler(slog.LevelError, newText(&alerts)),
+	)
+	logger := slog.New(f).With("app", "api")
+	if logger.Enabled(context.Background(), LevelTrace) {
+		t.Error("trace should be disabled for every child")
+	}
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+
+	for name, tc := range map[string]struct {
+		buf  *bytes.Buffer
+		want []string
+	}{
+		"console": {&console, []string{"info", "error"}},
+		"file":    {&file, []string{"debug", "info", "error"}},
+		"alerts":  {&alerts, []string{"error"}},
+	} {
+		lines := strings.Split(strings.TrimSpace(tc.buf.String()), "\n")
+		if len(lines) != len(tc.want) {
+			t.Errorf("%s got %q, want messages %v", name, lines, tc.want)
+			continue
+		}
+		for i, msg := range tc.want {
+			if !strings.HasSuffix(lines[i], "[app:api] "+msg) {
+				t.Errorf("%s line %d = %q, want message %q", name, i, lines[i], msg)
+			}
+		}
+	}
+
+	ctx := WithMinLevel(context.Background(), slog.LevelDebug)
+	logger.DebugContext(ctx, "override")
+	if !strings.Contains(alerts.String(), "override") {
+		t.Error("WithMinLevel should lower the child threshold")
+	}
+}
+
+func TestFanoutHandlerErrors(t *testing.T) {
+	var buf bytes.Buffer
+	ok := NewLogHandler(&buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}})
+	f := NewFanoutHandler(failingHandler{ok}, ok)
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	if err := f.Handle(context.Background(), r); err == nil || !strings.Contains(err.Error(), "sink down") {
+		t.Errorf("expected the child error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "msg") {
+		t.Error("a failing child should not block the others")
+	}
+}
+
+func TestNestedLevelHandler(t *testing.T) {
+	store := NewMemoryHandler(nil)
+	var inner slog.LevelVar
+	inner.Set(slog.LevelError)
+	logger := slog.New(NewLevelHandler(slog.LevelInfo, NewLevelHandler(&inner, store)))
+	logger.Info("dropped")
+	logger.Error("kept")
+	inner.Set(slog.LevelDebug)
+	logger.Debug("dropped by the outer level")
+	logger.Info("kept after lowering the inner level")
+
+	var got []string
+	for _, e := range store.Records(Query{}) {
+		got = append(got, e.Message)
+	}
+	if s := strings.Join(got, "|"); s != "kept|kept after lowering the inner level" {
+		t.Errorf("records = %q", s)
+	}
+}
@@ -0,0 +1,147 @@
+// Package field provides typed attribute constructors named after those of
+// go.uber.org/zap, returning slog.Attr, so that code written against zap
+// can move to log/slog by replacing the package name:
+//
+//	logger.Info("request failed", zap.String("path", p), zap.Int("status", s), zap.Error(err))
+//	logger.LogAttrs(ctx, slog.LevelInfo, "request failed", field.String("path", p), field.Int("status", s), field.Error(err))
+//
+// Attributes can also be passed to slog.Logger.Info and the other methods
+// taking ...any. Constructors of zap that have no slog equivalent, such as
+// Namespace, Inline and Object, are not provided. For zerolog, the event
+// methods map to the same constructors: Str to String, Int to Int, Err to
+// Error and Interface to Any.
+package field
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Field is an attribute, as zap.Field is for zap.
+type Field = slog.Attr
+
+// ErrorKey is the key of attributes created by Error, "error" as in zap.
+const ErrorKey = "error"
+
+// Skip returns an empty attribute, which handlers ignore.
+func Skip() Field { return slog.Attr{} }
+
+// Any returns an attribute for a value of any type.
+func Any(key string, value any) Field { return slog.Any(key, value) }
+
+// Reflect returns an attribute for a value of any type, like Any.
+func Reflect(key string, value any) Field { return slog.Any(key, value) }
+
+// String returns a string attribute.
+func String(key, value string) Field { return slog.String(key, value) }
+
+// ByteString returns a string attribute for UTF-8 encoded bytes.
+func ByteString(key string, value []byte) Field { return slog.String(key, string(value)) }
+
+// Binary returns an attribute for opaque bytes.
+func Binary(key string, value []byte) Field { return slog.Any(key, value) }
+
+// Bool returns a bool attribute.
+func Bool(key string, value bool) Field { return slog.Bool(key, value) }
+
+// Int returns an int attribute.
+func Int(key string, value int) Field { return slog.Int(key, value) }
+
+// Int8 returns an int attribute.
+func Int8(key string, value int8) Field { return slog.Int64(key, int64(value)) }
+
+// Int16 returns an int attribute.
+func Int16(key string, value int16) Field { return slog.Int64(key, int64(value)) }
+
+// Int32 returns an int attribute.
+func Int32(key string, value int32) Field { return slog.Int64(key, int64(value)) }
+
+// Int64 returns an int attribute.
+func Int64(key string, value int64) Field { return slog.Int64(key, value) }
+
+// Uint returns an unsigned int attribute.
+func Uint(key string, value uint) Field { return slog.Uint64(key, uint64(value)) }
+
+// Uint8 returns an unsigned int attribute.
+func Uint8(key string, value uint8) Field { return slog.Uint64(key, uint64(value)) }
+
+// Uint16 returns an unsigned int attribute.
+func Uint16(key string, value uint16) Field { return slog.Uint64(key, uint64(value)) }
+
+// Uint32 returns an unsigned int attribute.
+func Uint32(key string, value uint32) Field { return slog.Uint64(key, uint64(value)) }
+
+// Uint64 returns an unsigned int attribute.
+func Uint64(key string, value uint64) Field { return slog.Uint64(key, value) }
+
+// Uintptr returns an unsigned int attribute.
+func Uintptr(key string, value uintptr) Field { return slog.Uint64(key, uint64(value)) }
+
+// Float32 returns a float attribute.
+func Float32(key string, value float32) Field { return slog.Float64(key, float64(value)) }
+
+// Float64 returns a float attribute.
+func Float64(key string, value float64) Field { return slog.Float64(key, value) }
+
+// Duration returns a duration attribute.
+func Duration(key string, value time.Duration) Field { return slog.Duration(key, value) }
+
+// Time returns a time attribute.
+func Time(key string, value time.Time) Field { return slog.Time(key, value) }
+
+// Stringer returns an attribute whose value is value.String(), called
+// only when a handler logs the record.
+func Stringer(key string, value fmt.Stringer) Field {
+	if value == nil {
+		return slog.String(key, "<nil>")
+	}
+	return slog.Any(key, stringer{value})
+}
+
+type stringer struct{ fmt.Stringer }
+
+func (s stringer) LogValue() slog.Value { return slog.StringValue(s.String()) }
+
+// Error returns an attribute for err under ErrorKey, or an empty
+// attribute when err is nil.
+func Error(err error) Field { return NamedError(ErrorKey, err) }
+
+// NamedError returns an attribute for err, or an empty attribute when err
+// is nil.
+func NamedError(key string, err error) Field {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any(key, err)
+}
+
+// Errors returns an attribute listing the messages of errs, leaving out
+// nil errors.
+func Errors(key string, errs []error) Field {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return slog.Any(key, msgs)
+}
+
+// Strings returns an attribute for a slice of strings.
+func Strings(key string, values []string) Field { return slog.Any(key, values) }
+
+// Ints returns an attribute for a slice of ints.
+func Ints(key string, values []int) Field { return slog.Any(key, values) }
+
+// Int64s returns an attribute for a slice of int64s.
+func Int64s(key string, values []int64) Field { return slog.Any(key, values) }
+
+// Float64s returns an attribute for a slice of float64s.
+func Float64s(key string, values []float64) Field { return slog.Any(key, values) }
+
+// Bools returns an attribute for a slice of bools.
+func Bools(key string, values []bool) Field { return slog.Any(key, values) }
+
+// Durations returns an attribute for a slice of durations.
+func Durations(key string, values []time.Duration) Field { return slog.Any(key, values) }
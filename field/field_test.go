@@ -0,0 +1,56 @@
+package field
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFields(t *testing.T) {
+	for _, tc := range []struct {
+		f    Field
+		kind slog.Kind
+		want string
+	}{
+		{String("s", "x"), slog.KindString, "x"},
+		{ByteString("b", []byte("y")), slog.KindString, "y"},
+		{Int8("i", -8), slog.KindInt64, "-8"},
+		{Uint16("u", 16), slog.KindUint64, "16"},
+		{Float32("f", 0.5), slog.KindFloat64, "0.5"},
+		{Duration("d", time.Second), slog.KindDuration, "1s"},
+		{Error(errors.New("boom")), slog.KindAny, "boom"},
+		{Stringer("ip", net.IPv4(192, 0, 2, 1)), slog.KindString, "192.0.2.1"},
+		{Stringer("nil", nil), slog.KindString, "<nil>"},
+		{Errors("errs", []error{errors.New("a"), nil, errors.New("b")}), slog.KindAny, "[a b]"},
+	} {
+		v := tc.f.Value.Resolve()
+		if v.Kind() != tc.kind || v.String() != tc.want {
+			t.Errorf("%s: got %s %q, want %s %q", tc.f.Key, v.Kind(), v.String(), tc.kind, tc.want)
+		}
+	}
+	if f := Error(nil); !f.Equal(Skip()) {
+		t.Errorf("Error(nil) should be empty, got %v", f)
+	}
+	if f := Error(errors.New("x")); f.Key != ErrorKey {
+		t.Errorf("unexpected key %q", f.Key)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("done", String("path", "/"), Int("status", 200), Error(nil))
+	if got, want := buf.String(), "level=INFO msg=done path=/ status=200\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
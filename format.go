@@ -0,0 +1,66 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Format selects the output format of the handler created by NewLogHandler.
+type Format int
+
+const (
+	// FormatText writes the bracket format. It is the default.
+	FormatText Format = iota
+	// FormatJSON writes one JSON object per line, with the fields of
+	// Entry.AppendJSON configured by HandlerOptions.JSON.
+	FormatJSON
+)
+
+// handleJSON writes record as a JSON line. The level, Now, AddSource,
+// CallerSkip, Translate and color options apply as in the text format;
+// the options of the text layout, such as widths and wrapping, do not.
+func (h *logHandler) handleJSON(ctx context.Context, record slog.Record) error {
+	override := recordSource(record)
+	if override == nil && h.opts.CallerSkip > 0 && record.PC != 0 {
+		record.PC = skipCallers(record.PC, h.opts.CallerSkip)
+	}
+	e := newEntry(record, h.scope)
+	if override == nil && !h.opts.Source && !h.opts.AddSource {
+		e.Source = nil
+	}
+	if h.opts.Now != nil {
+		e.Time = h.opts.Now()
+	}
+	if h.opts.Translate != nil {
+		e.Message = h.opts.Translate(e.Message, recordAttrs(record))
+	}
+	e.Attrs = slices.DeleteFunc(e.Attrs, func(a slog.Attr) bool {
+		_, ok := isTransientAttr(a)
+		return ok
+	})
+	rule := -1
+	if h.opts.Color && len(h.opts.ColorRules) > 0 {
+		record.Attrs(func(a slog.Attr) bool {
+			if i := matchColorRule(h.opts.ColorRules, a); i >= 0 && (rule < 0 || i < rule) {
+				rule = i
+			}
+			return true
+		})
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.Write(e.AppendJSON(buf.AvailableBuffer(), h.opts.JSON))
+	buf.WriteByte('\n')
+	out := buf.Bytes()
+	if c := h.lineColor(record.Level, rule); c != nil {
+		colored := getBuffer()
+		defer putBuffer(colored)
+		c.SetWriter(colored)
+		colored.Write(out)
+		c.UnsetWriter(colored)
+		out = colored.Bytes()
+	}
+	return h.write(ctx, out, false)
+}
@@ -0,0 +1,58 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestFormatJSON(t *testing.T) {
+	ts := time.Date(2024, 6, 1, 12, 34, 56, 0, time.UTC)
+	var buf bytes.Buffer
+	h := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Format:         FormatJSON,
+		Now:            func() time.Time { return ts },
+	})
+	log := slog.New(h)
+	log.Debug("dropped")
+	log.With("app", "web").WithGroup("req").Info("served", "status", 200, "path", "/api")
+	log.Warn("slow", slog.Group("db", "ms", 12.5))
+	want := `{"time":"2024-06-01T12:34:56Z","level":"INFO","msg":"served","app":"web","req.status":200,"req.path":"/api"}` + "\n" +
+		`{"time":"2024-06-01T12:34:56Z","level":"WARN","msg":"slow","db.ms":12.5}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	slog.New(NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Format:         FormatJSON,
+		Now:            func() time.Time { return ts },
+		JSON:           &JSONOptions{TimeFormat: JSONTimeEpochMillis},
+	})).Info("hello")
+	if got, want := buf.String(), `{"time":1717245296000,"level":"INFO","msg":"hello"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFormatJSONColor(t *testing.T) {
+	defer func(v bool) { color.NoColor = v }(color.NoColor)
+	color.NoColor = false
+
+	var buf bytes.Buffer
+	h := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Format:         FormatJSON,
+		Color:          true,
+		Now:            func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) },
+	})
+	slog.New(h).Error("failed")
+	want := "\x1b[31m" + `{"time":"2024-06-01T00:00:00Z","level":"ERROR","msg":"failed"}` + "\n\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
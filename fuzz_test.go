@@ -0,0 +1,107 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// checkLine fails unless out is a single valid UTF-8 line without control characters.
+func checkLine(t *testing.T, out string) {
+	t.Helper()
+	if !strings.HasSuffix(out, "\n") || strings.Count(out, "\n") != 1 {
+		t.Fatalf("output is not a single terminated line: %q", out)
+	}
+	if !utf8.ValidString(out) {
+		t.Fatalf("output is not valid UTF-8: %q", out)
+	}
+	for _, r := range strings.TrimSuffix(out, "\n") {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			t.Fatalf("output contains control character %U: %q", r, out)
+		}
+	}
+}
+
+func FuzzLogHandler(f *testing.F) {
+	f.Add("hello", "key", "value", 0)
+	f.Add("multi\nline\r\n", "k\ty", "\033[31mred\033[0m", 8)
+	f.Add("\xff\xfe invalid", "\x00", "日本語のログメッセージ", 5)
+	f.Add("\033]8;;http://x\033\\link", "", "\u0085\u009b", 1)
+	f.Fuzz(func(t *testing.T, msg, key, value string, width int) {
+		buf := &bytes.Buffer{}
+		opts := &HandlerOptions{
+			HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+			MaxLen:         width % 64,
+			MessageWidth:   width % 32,
+		}
+		logger := slog.New(NewLogHandler(buf, opts)).With(key, value)
+		logger.Info(msg, key, value, "bytes", []byte(value))
+		checkLine(t, buf.String())
+	})
+}
+
+func FuzzTruncateWidth(f *testing.F) {
+	f.Add("hello world", 5)
+	f.Add("日本語\033[31mの\033[0mログ", 3)
+	f.Add("\033[", 1)
+	f.Fuzz(func(t *testing.T, s string, width int) {
+		width %= 128
+		got := TruncateWidth(s, width, "…")
+		if width >= 0 && StringWidth(got) > max(width, StringWidth(s)) {
+			t.Fatalf("TruncateWidth(%q, %d) = %q is wider than %d", s, width, got, width)
+		}
+	})
+}
+
+func TestEscapeControl(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"日本語", "日本語"},
+		{"a\nb\tc\r", `a\nb\tc\r`},
+		{"\033[31m", `\x1b[31m`},
+		{"bad\xffbyte", `bad\xffbyte`},
+		{"\u009b", `\u009b`},
+	}
+	for _, tt := range tests {
+		if got := escapeControl(tt.in); got != tt.want {
+			t.Errorf("escapeControl(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerEscapesControlCharacters(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+	}))
+	logger.Info("line1\nline2", "user", "\033[31meve\033[0m\r")
+	out := buf.String()
+	checkLine(t, out)
+	if !strings.Contains(out, `line1\nline2 [user:eve\r]`) {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func FuzzBinaryDecoder(f *testing.F) {
+	e := &Entry{Message: "hello", Attrs: []slog.Attr{slog.Int("n", -1), slog.Any("m", map[string]any{"a": []any{1.5}})}}
+	f.Add(MessagePack.Append(nil, e), false)
+	f.Add(CBOR.Append(nil, e), true)
+	f.Add([]byte{0xdf, 0xff, 0xff, 0xff, 0xff}, false)
+	f.Add([]byte{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, true)
+	f.Fuzz(func(t *testing.T, b []byte, cbor bool) {
+		format := MessagePack
+		if cbor {
+			format = CBOR
+		}
+		dec := NewBinaryDecoder(bytes.NewReader(b), format)
+		for range 16 {
+			if _, err := dec.Decode(); err != nil {
+				return
+			}
+		}
+	})
+}
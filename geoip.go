@@ -0,0 +1,132 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+)
+
+// GeoIPOptions configures a GeoIP.
+type GeoIPOptions struct {
+	// Databases are the paths of MaxMind DB files, such as
+	// GeoLite2-Country.mmdb or GeoLite2-City.mmdb for the country and
+	// GeoLite2-ASN.mmdb for the autonomous system.
+	Databases []string
+	// Keys are the top-level attributes holding IP addresses, as strings,
+	// "host:port" strings or netip.Addr and net.IP values.
+	// Default is "client_ip" and "remote_addr".
+	Keys []string
+}
+
+// GeoIP adds the country and autonomous system of IP addresses in records,
+// looked up in MaxMind databases, for security and access logs:
+//
+//	geo, err := sloghandler.NewGeoIP(&sloghandler.GeoIPOptions{
+//		Databases: []string{"GeoLite2-Country.mmdb", "GeoLite2-ASN.mmdb"},
+//	})
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Enrich(geo.Enrich))
+//	// client_ip=203.0.113.7 client_ip.country=JP client_ip.asn=64500 client_ip.as_org="Example Net"
+//
+// The databases are read into memory by NewGeoIP. Private and loopback
+// addresses are not looked up.
+type GeoIP struct {
+	dbs  []*mmdb
+	keys map[string]bool
+}
+
+// NewGeoIP opens the databases of opts.
+func NewGeoIP(opts *GeoIPOptions) (*GeoIP, error) {
+	o := GeoIPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Keys) == 0 {
+		o.Keys = []string{"client_ip", "remote_addr"}
+	}
+	g := &GeoIP{keys: make(map[string]bool, len(o.Keys))}
+	for _, k := range o.Keys {
+		g.keys[k] = true
+	}
+	for _, path := range o.Databases {
+		db, err := openMMDB(path)
+		if err != nil {
+			return nil, err
+		}
+		g.dbs = append(g.dbs, db)
+	}
+	return g, nil
+}
+
+// Enrich adds "<key>.country", "<key>.asn" and "<key>.as_org" attributes
+// for each configured key of r holding a public IP address found in the
+// databases. It is an Enricher.
+func (g *GeoIP) Enrich(ctx context.Context, r slog.Record) []slog.Attr {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		if !g.keys[a.Key] {
+			return true
+		}
+		addr, ok := parseIPValue(a.Value.Resolve())
+		if !ok || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+			return true
+		}
+		attrs = append(attrs, g.lookup(a.Key, addr)...)
+		return true
+	})
+	return attrs
+}
+
+func (g *GeoIP) lookup(key string, addr netip.Addr) []slog.Attr {
+	var attrs []slog.Attr
+	for _, db := range g.dbs {
+		v, err := db.lookup(addr)
+		if err != nil {
+			continue
+		}
+		m, _ := v.(map[string]any)
+		if m == nil {
+			continue
+		}
+		country := mmdbPath(m, "country", "iso_code")
+		if country == nil {
+			country = mmdbPath(m, "registered_country", "iso_code")
+		}
+		if c, ok := country.(string); ok {
+			attrs = append(attrs, slog.String(key+".country", c))
+		}
+		if n, ok := m["autonomous_system_number"].(uint64); ok {
+			attrs = append(attrs, slog.Uint64(key+".asn", n))
+		}
+		if org, ok := m["autonomous_system_organization"].(string); ok {
+			attrs = append(attrs, slog.String(key+".as_org", org))
+		}
+	}
+	return attrs
+}
+
+// mmdbPath returns the value at the path of nested maps in v.
+func mmdbPath(v any, path ...string) any {
+	for _, p := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[p]
+	}
+	return v
+}
+
+// parseIPValue returns the IP address in v, an address or "host:port".
+func parseIPValue(v slog.Value) (netip.Addr, bool) {
+	if addr, ok := v.Any().(netip.Addr); ok {
+		return addr.Unmap(), addr.IsValid()
+	}
+	s := v.String()
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap().WithZone(""), true
+	}
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap.Addr().Unmap().WithZone(""), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,195 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// mmdbEncode appends v in the MaxMind DB data format. It supports the
+// value types used by the tests.
+func mmdbEncode(b []byte, v any) []byte {
+	ctrl := func(typ, size int) {
+		var ext []byte
+		if size >= 29 {
+			ext = []byte{byte(size - 29)} // the tests use sizes below 285
+			size = 29
+		}
+		if typ > 7 {
+			b = append(b, byte(size), byte(typ-7))
+		} else {
+			b = append(b, byte(typ<<5|size))
+		}
+		b = append(b, ext...)
+	}
+	switch v := v.(type) {
+	case string:
+		ctrl(2, len(v))
+		b = append(b, v...)
+	case uint32:
+		ctrl(6, 4)
+		b = binary.BigEndian.AppendUint32(b, v)
+	case uint16:
+		ctrl(5, 2)
+		b = binary.BigEndian.AppendUint16(b, v)
+	case map[string]any:
+		ctrl(7, len(v))
+		for k, e := range v {
+			b = mmdbEncode(b, k)
+			b = mmdbEncode(b, e)
+		}
+	}
+	return b
+}
+
+// writeTestMMDB writes an IPv6 MaxMind DB with 24 bit records mapping the
+// networks to their data.
+func writeTestMMDB(t *testing.T, networks map[string]map[string]any) string {
+	t.Helper()
+	type record struct {
+		node int // index of a node, or -1
+		data int // offset in the data section, or -1 if empty
+	}
+	nodes := [][2]record{{{-1, -1}, {-1, -1}}}
+	var data []byte
+	for cidr, v := range networks {
+		prefix := netip.MustParsePrefix(cidr)
+		bits := prefix.Bits()
+		if prefix.Addr().Is4() {
+			bits += 96
+		}
+		ip := prefix.Addr().As16()
+		if prefix.Addr().Is4() {
+			ip = [16]byte{}
+			a := prefix.Addr().As4()
+			copy(ip[12:], a[:])
+		}
+		node := 0
+		for i := range bits - 1 {
+			bit := ip[i/8] >> (7 - i%8) & 1
+			if nodes[node][bit].node < 0 {
+				nodes = append(nodes, [2]record{{-1, -1}, {-1, -1}})
+				nodes[node][bit].node = len(nodes) - 1
+			}
+			node = nodes[node][bit].node
+		}
+		bit := ip[(bits-1)/8] >> (7 - (bits-1)%8) & 1
+		nodes[node][bit].data = len(data)
+		data = mmdbEncode(data, v)
+	}
+	n := len(nodes)
+	var buf []byte
+	for _, rs := range nodes {
+		for _, r := range rs {
+			v := n // empty
+			switch {
+			case r.node >= 0:
+				v = r.node
+			case r.data >= 0:
+				v = n + 16 + r.data
+			}
+			buf = append(buf, byte(v>>16), byte(v>>8), byte(v))
+		}
+	}
+	buf = append(buf, make([]byte, 16)...)
+	buf = append(buf, data...)
+	buf = append(buf, mmdbMetadataMarker...)
+	buf = mmdbEncode(buf, map[string]any{
+		"node_count":  uint32(n),
+		"record_size": uint16(24),
+		"ip_version":  uint16(6),
+	})
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGeoIP(t *testing.T) {
+	country := writeTestMMDB(t, map[string]map[string]any{
+		"203.0.113.0/24": {"country": map[string]any{"iso_code": "JP"}},
+		"2001:db8::/32":  {"registered_country": map[string]any{"iso_code": "US"}},
+	})
+	asn := writeTestMMDB(t, map[string]map[string]any{
+		"203.0.113.0/25": {"autonomous_system_number": uint32(64500), "autonomous_system_organization": "Example Net"},
+	})
+	geo, err := NewGeoIP(&GeoIPOptions{Databases: []string{country, asn}, Keys: []string{"client_ip", "peer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		attrs []slog.Attr
+		want  map[string]string
+	}{
+		{"country and asn", []slog.Attr{slog.String("client_ip", "203.0.113.7")},
+			map[string]string{"client_ip.country": "JP", "client_ip.asn": "64500", "client_ip.as_org": "Example Net"}},
+		{"country only", []slog.Attr{slog.String("client_ip", "203.0.113.200:443")},
+			map[string]string{"client_ip.country": "JP"}},
+		{"ipv6 registered country", []slog.Attr{slog.Any("peer", netip.MustParseAddr("2001:db8::1"))},
+			map[string]string{"peer.country": "US"}},
+		{"net.IP", []slog.Attr{slog.Any("peer", net.ParseIP("203.0.113.1"))},
+			map[string]string{"peer.country": "JP", "peer.asn": "64500", "peer.as_org": "Example Net"}},
+		{"not found", []slog.Attr{slog.String("client_ip", "198.51.100.1")}, map[string]string{}},
+		{"private", []slog.Attr{slog.String("client_ip", "10.0.0.1")}, map[string]string{}},
+		{"other key", []slog.Attr{slog.String("server_ip", "203.0.113.7")}, map[string]string{}},
+		{"not an address", []slog.Attr{slog.String("client_ip", "unknown")}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "request", 0)
+			r.AddAttrs(tt.attrs...)
+			got := attrMap(geo.Enrich(context.Background(), r))
+			if len(got) != len(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestGeoIPInvalidDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.mmdb")
+	if err := os.WriteFile(path, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{path, filepath.Join(t.TempDir(), "missing.mmdb")} {
+		if _, err := NewGeoIP(&GeoIPOptions{Databases: []string{p}}); err == nil {
+			t.Errorf("%s: no error", p)
+		}
+	}
+}
+
+func TestMMDBDecodeMap(t *testing.T) {
+	b := mmdbEncode(nil, map[string]any{"a": "x", "b": uint32(7)})
+	d := mmdbDecoder{buf: b}
+	v, next, err := d.decode(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != uint(len(b)) {
+		t.Errorf("next = %d, want %d", next, len(b))
+	}
+	m := v.(map[string]any)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "b"}) || m["a"] != "x" || m["b"] != uint64(7) {
+		t.Errorf("got %v", m)
+	}
+}
@@ -0,0 +1,39 @@
+package sloghandler
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fujiwara/sloghandler/testutil"
+)
+
+// TestGoldenFormat locks down the text format. Run with -update after an
+// intended format change.
+func TestGoldenFormat(t *testing.T) {
+	records := []slog.Record{
+		testutil.NewRecord(LevelTrace, "trace message"),
+		testutil.NewRecord(slog.LevelDebug, "debug message", "key", "value"),
+		testutil.NewRecord(slog.LevelInfo, "info message", "count", 42, "elapsed", 1500*time.Millisecond),
+		testutil.NewRecord(slog.LevelWarn, "警告メッセージ", "path", "/tmp/ファイル.txt"),
+		testutil.NewRecord(slog.LevelError, "error message", "", "anonymous", slog.Group("req", "id", 7)),
+	}
+	tests := []struct {
+		name string
+		opts HandlerOptions
+	}{
+		{"text", HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: LevelTrace}}},
+		{"text_color", HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: LevelTrace}, Color: true}},
+		{"text_width", HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}, MaxLen: 10, MessageWidth: 16}},
+		{"text_wrap", HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}, WrapAttrs: 2}},
+		{"text_compact", HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: LevelTrace}, Compact: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.Golden(t, tt.name, func(w io.Writer) slog.Handler {
+				return NewLogHandler(w, &tt.opts).WithAttrs([]slog.Attr{slog.String("app", "demo")})
+			}, records...)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package grpcinterceptor provides gRPC server interceptors that log each
+// RPC through a log/slog logger.
+package grpcinterceptor
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Options contains configuration for the interceptors.
+type Options struct {
+	// Message is the log message. Default is "rpc".
+	Message string
+
+	// LogPayload adds the request and response messages of unary RPCs as the
+	// "request" and "response" attributes. Disabled by default.
+	LogPayload bool
+
+	// Redact is called with the full method name and each payload before it
+	// is logged, and returns the value to log instead, e.g. a copy with
+	// secrets removed. Returning nil omits the payload.
+	Redact func(fullMethod string, payload any) any
+
+	// Level returns the level for a status code.
+	// Default is DefaultLevel.
+	Level func(code codes.Code) slog.Level
+
+	// Begin, if set, is called when an RPC starts. The RPC is handled with
+	// the returned context, and the attributes returned by end are added to
+	// its log record, e.g. sloghandler.BeginCanonical to let handlers add
+	// attributes with sloghandler.AddCanonical.
+	Begin func(ctx context.Context) (_ context.Context, end func() []slog.Attr)
+}
+
+// DefaultOptions returns the default configuration options.
+func DefaultOptions() *Options {
+	return &Options{
+		Message: "rpc",
+		Level:   DefaultLevel,
+	}
+}
+
+// DefaultLevel returns INFO for OK, WARN for codes caused by the client
+// and ERROR for everything else.
+func DefaultLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// ServerOptions returns server options installing both the unary and stream
+// interceptors, so a server adopts RPC logging with one line:
+//
+//	s := grpc.NewServer(grpcinterceptor.ServerOptions(logger, nil)...)
+func ServerOptions(logger *slog.Logger, opts *Options) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(logger, opts)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(logger, opts)),
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that logs unary RPCs.
+// A nil opts uses DefaultOptions.
+func UnaryServerInterceptor(logger *slog.Logger, opts *Options) grpc.UnaryServerInterceptor {
+	o := mergeOptions(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		var end func() []slog.Attr
+		if o.Begin != nil {
+			ctx, end = o.Begin(ctx)
+		}
+		resp, err := handler(ctx, req)
+		attrs := o.attrs(ctx, info.FullMethod, err, start)
+		if o.LogPayload {
+			attrs = o.appendPayload(attrs, info.FullMethod, "request", req)
+			if err == nil {
+				attrs = o.appendPayload(attrs, info.FullMethod, "response", resp)
+			}
+		}
+		if end != nil {
+			attrs = append(attrs, end()...)
+		}
+		logger.LogAttrs(ctx, o.Level(status.Code(err)), o.Message, attrs...)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that logs streaming RPCs
+// when they finish. Payloads of streaming RPCs are never logged.
+// A nil opts uses DefaultOptions.
+func StreamServerInterceptor(logger *slog.Logger, opts *Options) grpc.StreamServerInterceptor {
+	o := mergeOptions(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		var end func() []slog.Attr
+		if o.Begin != nil {
+			var ctx context.Context
+			ctx, end = o.Begin(ss.Context())
+			ss = &serverStream{ServerStream: ss, ctx: ctx}
+		}
+		err := handler(srv, ss)
+		ctx := ss.Context()
+		attrs := o.attrs(ctx, info.FullMethod, err, start)
+		if end != nil {
+			attrs = append(attrs, end()...)
+		}
+		logger.LogAttrs(ctx, o.Level(status.Code(err)), o.Message, attrs...)
+		return err
+	}
+}
+
+// serverStream replaces the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func mergeOptions(opts *Options) *Options {
+	o := DefaultOptions()
+	if opts == nil {
+		return o
+	}
+	if opts.Message != "" {
+		o.Message = opts.Message
+	}
+	o.LogPayload = opts.LogPayload
+	o.Redact = opts.Redact
+	o.Begin = opts.Begin
+	if opts.Level != nil {
+		o.Level = opts.Level
+	}
+	return o
+}
+
+func (o *Options) attrs(ctx context.Context, fullMethod string, err error, start time.Time) []slog.Attr {
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("service", path.Dir(fullMethod)[1:]),
+		slog.String("method", path.Base(fullMethod)),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start))/float64(time.Millisecond)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	return attrs
+}
+
+func (o *Options) appendPayload(attrs []slog.Attr, fullMethod, key string, payload any) []slog.Attr {
+	if o.Redact != nil {
+		payload = o.Redact(fullMethod, payload)
+	}
+	if payload == nil {
+		return attrs
+	}
+	return append(attrs, slog.Any(key, payload))
+}
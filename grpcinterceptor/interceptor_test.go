@@ -0,0 +1,157 @@
+package grpcinterceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) logs(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var logs []map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, m)
+	}
+	return logs
+}
+
+func TestInterceptors(t *testing.T) {
+	out := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+	opts := &Options{
+		LogPayload: true,
+		Redact: func(fullMethod string, payload any) any {
+			if _, ok := payload.(*healthpb.HealthCheckResponse); ok {
+				return nil
+			}
+			return "redacted"
+		},
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerOptions(logger, opts)...)
+	hs := health.NewServer()
+	hs.SetServingStatus("known", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := t.Context()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Fatal(err)
+	}
+	client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+
+	logs := out.logs(t)
+	if len(logs) < 2 {
+		t.Fatalf("got %d logs, want at least 2", len(logs))
+	}
+	ok := logs[0]
+	if ok["level"] != "INFO" || ok["service"] != "grpc.health.v1.Health" || ok["method"] != "Check" ||
+		ok["code"] != "OK" || ok["request"] != "redacted" || ok["peer"] == nil {
+		t.Errorf("unexpected log for OK call %v", ok)
+	}
+	if _, found := ok["response"]; found {
+		t.Errorf("redacted response should be omitted: %v", ok)
+	}
+	notFound := logs[1]
+	if notFound["level"] != "WARN" || notFound["code"] != "NotFound" || notFound["error"] == nil {
+		t.Errorf("unexpected log for NotFound call %v", notFound)
+	}
+}
+
+func TestDefaultLevel(t *testing.T) {
+	if DefaultLevel(0) != slog.LevelInfo {
+		t.Error("OK should be INFO")
+	}
+	if DefaultLevel(13) != slog.LevelError { // Internal
+		t.Error("Internal should be ERROR")
+	}
+}
+
+type attrsKey struct{}
+
+// beginCollect is a Begin hook that collects attributes set by handlers.
+func beginCollect(ctx context.Context) (context.Context, func() []slog.Attr) {
+	attrs := &[]slog.Attr{}
+	return context.WithValue(ctx, attrsKey{}, attrs), func() []slog.Attr { return *attrs }
+}
+
+func addAttr(ctx context.Context, a slog.Attr) {
+	attrs := ctx.Value(attrsKey{}).(*[]slog.Attr)
+	*attrs = append(*attrs, a)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestBegin(t *testing.T) {
+	out := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+	opts := &Options{Begin: beginCollect}
+
+	unary := UnaryServerInterceptor(logger, opts)
+	unary(t.Context(), "req", &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req any) (any, error) {
+			addAttr(ctx, slog.String("user", "alice"))
+			return "resp", nil
+		})
+	stream := StreamServerInterceptor(logger, opts)
+	stream(nil, &fakeStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"},
+		func(srv any, ss grpc.ServerStream) error {
+			addAttr(ss.Context(), slog.Int("events", 3))
+			return nil
+		})
+
+	logs := out.logs(t)
+	if len(logs) != 2 {
+		t.Fatalf("got %d logs", len(logs))
+	}
+	if logs[0]["method"] != "Get" || logs[0]["user"] != "alice" {
+		t.Errorf("unary log = %v", logs[0])
+	}
+	if logs[1]["method"] != "Watch" || logs[1]["events"] != 3.0 {
+		t.Errorf("stream log = %v", logs[1])
+	}
+}
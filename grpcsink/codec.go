@@ -0,0 +1,121 @@
+package grpcsink
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	grpcencoding "google.golang.org/grpc/encoding"
+)
+
+// gRPC content-subtypes understood by the LogCollector service.
+const (
+	// JSONSubtype encodes messages as JSON, so the service can be used
+	// without generated protobuf code.
+	JSONSubtype = "sloghandler-json"
+	// ProtoSubtype encodes messages in the protobuf wire format of
+	// collector.proto, keeping attribute types and using less bandwidth.
+	ProtoSubtype = "sloghandler-proto"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return JSONSubtype }
+
+// protoCodec encodes messages with their MarshalBinary and UnmarshalBinary
+// methods. It is registered under its own name rather than "proto", which
+// would replace the gRPC codec for generated messages.
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("grpcsink: cannot encode %T as protobuf", v)
+	}
+	return m.MarshalBinary()
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("grpcsink: cannot decode protobuf into %T", v)
+	}
+	return m.UnmarshalBinary(data)
+}
+
+func (protoCodec) Name() string { return ProtoSubtype }
+
+func init() {
+	grpcencoding.RegisterCodec(jsonCodec{})
+	grpcencoding.RegisterCodec(protoCodec{})
+}
+
+// jsonAttr is the JSON form of Attr. Value is always a string, so peers
+// unaware of Kind still read it; Kind is omitted for strings.
+type jsonAttr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Kind  string `json:"kind,omitempty"`
+}
+
+// MarshalJSON encodes a as {"key": ..., "value": ..., "kind": ...}.
+func (a Attr) MarshalJSON() ([]byte, error) {
+	ja := jsonAttr{Key: a.Key}
+	switch k := a.Value.Kind(); k {
+	case slog.KindString:
+		ja.Value = a.Value.String()
+	case slog.KindTime:
+		ja.Value = a.Value.Time().Format(time.RFC3339Nano)
+		ja.Kind = k.String()
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool, slog.KindDuration:
+		ja.Value = a.Value.String()
+		ja.Kind = k.String()
+	default:
+		ja.Value = a.Value.String()
+	}
+	return json.Marshal(ja)
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON. A missing or
+// unknown kind yields a string value.
+func (a *Attr) UnmarshalJSON(data []byte) error {
+	var ja jsonAttr
+	if err := json.Unmarshal(data, &ja); err != nil {
+		return err
+	}
+	v, err := parseValue(ja.Kind, ja.Value)
+	if err != nil {
+		return fmt.Errorf("grpcsink: invalid %s attribute %q: %w", ja.Kind, ja.Key, err)
+	}
+	*a = Attr{Key: ja.Key, Value: v}
+	return nil
+}
+
+func parseValue(kind, s string) (slog.Value, error) {
+	switch kind {
+	case slog.KindInt64.String():
+		n, err := strconv.ParseInt(s, 10, 64)
+		return slog.Int64Value(n), err
+	case slog.KindUint64.String():
+		n, err := strconv.ParseUint(s, 10, 64)
+		return slog.Uint64Value(n), err
+	case slog.KindFloat64.String():
+		f, err := strconv.ParseFloat(s, 64)
+		return slog.Float64Value(f), err
+	case slog.KindBool.String():
+		b, err := strconv.ParseBool(s)
+		return slog.BoolValue(b), err
+	case slog.KindTime.String():
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return slog.TimeValue(t), err
+	case slog.KindDuration.String():
+		d, err := time.ParseDuration(s)
+		return slog.DurationValue(d), err
+	}
+	return slog.StringValue(s), nil
+}
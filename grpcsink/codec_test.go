@@ -0,0 +1,137 @@
+package grpcsink
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var testRecord = &Record{
+	Time:    time.Date(2024, 6, 1, 12, 34, 56, 789, time.UTC),
+	Level:   int64(slog.LevelDebug),
+	Message: "hello",
+	Source:  "/app/main.go:42",
+	Attrs: []Attr{
+		{Key: "s", Value: slog.StringValue("x")},
+		{Key: "empty", Value: slog.StringValue("")},
+		{Key: "i", Value: slog.Int64Value(math.MinInt64)},
+		{Key: "u", Value: slog.Uint64Value(math.MaxUint64)},
+		{Key: "f", Value: slog.Float64Value(0.25)},
+		{Key: "b", Value: slog.BoolValue(false)},
+		{Key: "t", Value: slog.TimeValue(time.Date(1960, 1, 1, 0, 0, 0, 5, time.UTC))},
+		{Key: "d", Value: slog.DurationValue(-1500 * time.Millisecond)},
+	},
+}
+
+func checkRecord(t *testing.T, got, want *Record) {
+	t.Helper()
+	if !got.Time.Equal(want.Time) || got.Level != want.Level || got.Message != want.Message ||
+		got.Source != want.Source || len(got.Attrs) != len(want.Attrs) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i, a := range got.Attrs {
+		if w := want.Attrs[i]; a.Key != w.Key || !a.Value.Equal(w.Value) {
+			t.Errorf("got attr %s=%v (%s), want %s=%v (%s)", a.Key, a.Value, a.Value.Kind(), w.Key, w.Value, w.Value.Kind())
+		}
+	}
+}
+
+func TestRecordProto(t *testing.T) {
+	b, err := testRecord.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Record
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &got, testRecord)
+
+	if err := new(Record).UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+}
+
+func TestRecordProtoWire(t *testing.T) {
+	r := &Record{Level: 4, Attrs: []Attr{{Key: "a", Value: slog.Int64Value(1)}}}
+	// level = 4; attrs { key: "a" int64_value: 1 } with an unknown field 9
+	b := r.AppendProto(nil)
+	if got, want := hex.EncodeToString(b), "10042a050a01611801"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	var got Record
+	if err := got.UnmarshalBinary(append(b, 0x48, 0x01)); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &got, r)
+	if err := got.UnmarshalBinary([]byte{0x12, 0x00}); err == nil {
+		t.Error("expected an error for a level of the wrong wire type")
+	}
+
+	// the time field is a google.protobuf.Timestamp
+	ts := testRecord.Time
+	b, err := proto.Marshal(timestamppb.New(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := appendTimestamp(nil, ts); string(got) != string(b) {
+		t.Errorf("timestamp: got %x, want %x", got, b)
+	}
+}
+
+func TestAttrJSON(t *testing.T) {
+	b, err := json.Marshal(testRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Record
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &got, testRecord)
+
+	// attributes from peers unaware of kinds are strings
+	var a Attr
+	if err := json.Unmarshal([]byte(`{"key":"status","value":"200"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Key != "status" || a.Value.Kind() != slog.KindString || a.Value.String() != "200" {
+		t.Errorf("unexpected attr %+v", a)
+	}
+	if err := json.Unmarshal([]byte(`{"key":"n","value":"x","kind":"Int64"}`), &a); err == nil {
+		t.Error("expected an error for an invalid Int64 value")
+	}
+}
+
+func TestHandlerProto(t *testing.T) {
+	received := make(chan []*Record, 1)
+	conn := startServer(t, CollectorFunc(func(ctx context.Context, records []*Record) error {
+		received <- records
+		return nil
+	}))
+	h := NewHandlerWithOptions(conn, &Options{Interval: time.Hour, ContentSubtype: ProtoSubtype})
+	slog.New(h).Info("typed", "n", 200, "ok", true, "elapsed", time.Second, "err", context.Canceled)
+	h.Flush()
+	records := <-received
+	h.Close()
+
+	checkRecord(t, records[0], &Record{
+		Time:    records[0].Time,
+		Level:   int64(slog.LevelInfo),
+		Message: "typed",
+		Source:  records[0].Source,
+		Attrs: []Attr{
+			{Key: "n", Value: slog.Int64Value(200)},
+			{Key: "ok", Value: slog.BoolValue(true)},
+			{Key: "elapsed", Value: slog.DurationValue(time.Second)},
+			{Key: "err", Value: slog.StringValue("context canceled")},
+		},
+	})
+}
@@ -0,0 +1,328 @@
+// Package grpcsink provides a log/slog handler that streams records to a
+// remote collector over gRPC, and a reference collector server.
+package grpcsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ErrQueueFull is returned by Handle when a record is dropped because the
+// queue is full.
+var ErrQueueFull = errors.New("grpcsink: queue full, record dropped")
+
+// Backpressure selects what Handle does with a record when the queue is
+// full, e.g. because the collector is slow or down. It mirrors
+// sloghandler.Backpressure, without spilling to disk.
+type Backpressure int
+
+const (
+	// BackpressureDropNewest drops the incoming record; Handle returns
+	// ErrQueueFull. This is the default.
+	BackpressureDropNewest Backpressure = iota
+	// BackpressureDropOldest discards the oldest queued record to make room
+	// for the incoming one.
+	BackpressureDropOldest
+	// BackpressureBlock makes Handle wait for queue space until its context
+	// is done, slowing callers down to the rate of the collector.
+	BackpressureBlock
+)
+
+// Options contains configuration for the Handler.
+type Options struct {
+	// Level is the minimum level streamed. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// BatchSize is the maximum number of records sent per stream. Default is 500.
+	BatchSize int
+	// Interval is the maximum time a record waits before being sent. Default is 1 second.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be sent. Default is 10000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// Timeout bounds each stream. Default is 30 seconds.
+	Timeout time.Duration
+	// OnError is called when records cannot be sent. Default writes the error to os.Stderr.
+	OnError func(error)
+	// ContentSubtype selects the encoding of streamed records: JSONSubtype
+	// (default) or ProtoSubtype. The reference server accepts both.
+	ContentSubtype string
+}
+
+// DefaultOptions returns the default configuration options.
+func DefaultOptions() *Options {
+	return &Options{
+		Level:     slog.LevelInfo,
+		BatchSize: 500,
+		Interval:  time.Second,
+		QueueSize: 10000,
+		Timeout:   30 * time.Second,
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "grpcsink: %v\n", err)
+		},
+		ContentSubtype: JSONSubtype,
+	}
+}
+
+// Handler is a slog.Handler that streams records to a LogCollector service.
+type Handler struct {
+	conn    grpc.ClientConnInterface
+	opts    *Options
+	queue   chan *Record
+	flush   chan chan struct{}
+	done    chan struct{}
+	mu      *sync.RWMutex
+	closed  *bool
+	dropped *atomic.Int64
+	attrs   []Attr
+	prefix  string
+}
+
+// NewHandler creates a Handler that sends records over conn.
+func NewHandler(conn grpc.ClientConnInterface) *Handler {
+	return NewHandlerWithOptions(conn, DefaultOptions())
+}
+
+// NewHandlerWithOptions creates a Handler with the provided options.
+func NewHandlerWithOptions(conn grpc.ClientConnInterface, opts *Options) *Handler {
+	o := *DefaultOptions()
+	if opts.Level != nil {
+		o.Level = opts.Level
+	}
+	if opts.BatchSize > 0 {
+		o.BatchSize = opts.BatchSize
+	}
+	if opts.Interval > 0 {
+		o.Interval = opts.Interval
+	}
+	if opts.QueueSize > 0 {
+		o.QueueSize = opts.QueueSize
+	}
+	if opts.Timeout > 0 {
+		o.Timeout = opts.Timeout
+	}
+	if opts.OnError != nil {
+		o.OnError = opts.OnError
+	}
+	if opts.ContentSubtype != "" {
+		o.ContentSubtype = opts.ContentSubtype
+	}
+	o.Backpressure = opts.Backpressure
+	h := &Handler{
+		conn:    conn,
+		opts:    &o,
+		queue:   make(chan *Record, o.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+		mu:      new(sync.RWMutex),
+		closed:  new(bool),
+		dropped: new(atomic.Int64),
+	}
+	go h.run()
+	return h
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle queues the record to be streamed. When the queue is full, the
+// record is handled according to Options.Backpressure.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	rec := &Record{
+		Time:    r.Time,
+		Level:   int64(r.Level),
+		Message: r.Message,
+		Attrs:   append([]Attr(nil), h.attrs...),
+	}
+	if s := r.Source(); s != nil {
+		rec.Source = fmt.Sprintf("%s:%d", s.File, s.Line)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = appendAttr(rec.Attrs, h.prefix, a)
+		return true
+	})
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if *h.closed {
+		return fmt.Errorf("grpcsink: handler closed")
+	}
+	select {
+	case h.queue <- rec:
+		return nil
+	default:
+	}
+	switch h.opts.Backpressure {
+	case BackpressureBlock:
+		select {
+		case h.queue <- rec:
+			return nil
+		case <-ctx.Done():
+			h.dropped.Add(1)
+			return ctx.Err()
+		}
+	case BackpressureDropOldest:
+		for {
+			select {
+			case <-h.queue:
+				h.dropped.Add(1)
+			default:
+			}
+			select {
+			case h.queue <- rec:
+				return nil
+			default:
+			}
+		}
+	}
+	h.dropped.Add(1)
+	return ErrQueueFull
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *Handler) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// Flush blocks until all queued records have been sent.
+func (h *Handler) Flush() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if *h.closed {
+		return nil
+	}
+	ack := make(chan struct{})
+	h.flush <- ack
+	<-ack
+	return nil
+}
+
+// Close sends pending records and stops the handler. It does not close conn.
+func (h *Handler) Close() error {
+	h.mu.Lock()
+	if !*h.closed {
+		*h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+	<-h.done
+	return nil
+}
+
+func (h *Handler) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.opts.Interval)
+	defer ticker.Stop()
+	var batch []*Record
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := h.send(batch); err != nil {
+			h.opts.OnError(err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case rec, ok := <-h.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, rec)
+			if len(batch) >= h.opts.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-h.flush:
+			for len(h.queue) > 0 {
+				batch = append(batch, <-h.queue)
+				if len(batch) >= h.opts.BatchSize {
+					send()
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+var streamDesc = &grpc.StreamDesc{StreamName: "Stream", ClientStreams: true}
+
+// send streams batch to the collector.
+func (h *Handler) send(batch []*Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.opts.Timeout)
+	defer cancel()
+	stream, err := h.conn.NewStream(ctx, streamDesc, streamMethod, grpc.CallContentSubtype(h.opts.ContentSubtype))
+	if err != nil {
+		return fmt.Errorf("grpcsink: failed to open stream: %w", err)
+	}
+	for _, rec := range batch {
+		if err := stream.SendMsg(rec); err != nil {
+			return fmt.Errorf("grpcsink: failed to send record: %w", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	var resp StreamResponse
+	if err := stream.RecvMsg(&resp); err != nil {
+		return fmt.Errorf("grpcsink: stream failed: %w", err)
+	}
+	return nil
+}
+
+// appendAttr resolves a and appends it to dst, flattening groups into dotted keys.
+func appendAttr(dst []Attr, prefix string, a slog.Attr) []Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			dst = appendAttr(dst, p, ga)
+		}
+		return dst
+	}
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindString, slog.KindInt64, slog.KindUint64, slog.KindFloat64,
+		slog.KindBool, slog.KindTime, slog.KindDuration:
+	default:
+		v = slog.StringValue(v.String())
+	}
+	return append(dst, Attr{Key: prefix + a.Key, Value: v})
+}
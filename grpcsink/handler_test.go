@@ -0,0 +1,125 @@
+package grpcsink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startServer(t *testing.T, c Collector) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterCollectorServer(s, c)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	conn := startServer(t, NewHandlerCollector(base))
+
+	h := NewHandlerWithOptions(conn, &Options{Interval: time.Hour})
+	logger := slog.New(h).With("service", "api").WithGroup("req")
+	logger.Debug("not sent")
+	logger.Info("request handled", "status", 200)
+	logger.Error("request failed", slog.Group("err", slog.String("msg", "timeout")))
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"level=INFO msg=\"request handled\"",
+		"service=api req.status=200",
+		"level=ERROR msg=\"request failed\"",
+		"req.err.msg=timeout",
+		"source=",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("collector output should contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "not sent") {
+		t.Error("records below Level should not be sent")
+	}
+}
+
+func TestStreamResponse(t *testing.T) {
+	received := make(chan int, 1)
+	conn := startServer(t, CollectorFunc(func(ctx context.Context, records []*Record) error {
+		received <- len(records)
+		return nil
+	}))
+	h := NewHandlerWithOptions(conn, &Options{Interval: time.Hour})
+	slog.New(h).Info("one")
+	h.Flush()
+	if n := <-received; n != 1 {
+		t.Errorf("collector received %d records, want 1", n)
+	}
+	h.Close()
+}
+
+func TestBackpressure(t *testing.T) {
+	for _, tc := range []struct {
+		policy  Backpressure
+		wantErr error
+		next    string // message received by the collector after "one"
+	}{
+		{BackpressureDropNewest, ErrQueueFull, "two"},
+		{BackpressureDropOldest, nil, "three"},
+		{BackpressureBlock, context.Canceled, "two"},
+	} {
+		received := make(chan string, 3)
+		release := make(chan struct{})
+		conn := startServer(t, CollectorFunc(func(ctx context.Context, records []*Record) error {
+			for _, r := range records {
+				received <- r.Message
+			}
+			<-release
+			return nil
+		}))
+		h := NewHandlerWithOptions(conn, &Options{BatchSize: 1, QueueSize: 1, Interval: time.Hour, Backpressure: tc.policy})
+		logger := slog.New(h)
+		logger.Info("one")
+		<-received // the collector holds "one"; the queue is empty
+		logger.Info("two")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "three", 0)
+		if err := h.Handle(ctx, r); !errors.Is(err, tc.wantErr) {
+			t.Errorf("policy %d: Handle = %v, want %v", tc.policy, err, tc.wantErr)
+		}
+		if n := h.Dropped(); n != 1 {
+			t.Errorf("policy %d: Dropped = %d, want 1", tc.policy, n)
+		}
+		close(release)
+		h.Close()
+		if got := <-received; got != tc.next {
+			t.Errorf("policy %d: collector received %q, want %q", tc.policy, got, tc.next)
+		}
+	}
+}
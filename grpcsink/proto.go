@@ -0,0 +1,258 @@
+package grpcsink
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The methods below encode messages in the protobuf wire format described
+// in collector.proto, so records can be exchanged with generated code in
+// any language or sent over transports other than gRPC.
+
+var errWireType = errors.New("grpcsink: unexpected protobuf wire type")
+
+// MarshalBinary encodes r as a protobuf Record message.
+func (r *Record) MarshalBinary() ([]byte, error) {
+	return r.AppendProto(nil), nil
+}
+
+// AppendProto appends the protobuf encoding of r to dst.
+func (r *Record) AppendProto(dst []byte) []byte {
+	if !r.Time.IsZero() {
+		dst = protowire.AppendTag(dst, 1, protowire.BytesType)
+		dst = protowire.AppendBytes(dst, appendTimestamp(nil, r.Time))
+	}
+	if r.Level != 0 {
+		dst = protowire.AppendTag(dst, 2, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(r.Level))
+	}
+	if r.Message != "" {
+		dst = protowire.AppendTag(dst, 3, protowire.BytesType)
+		dst = protowire.AppendString(dst, r.Message)
+	}
+	if r.Source != "" {
+		dst = protowire.AppendTag(dst, 4, protowire.BytesType)
+		dst = protowire.AppendString(dst, r.Source)
+	}
+	var buf []byte
+	for _, a := range r.Attrs {
+		buf = a.appendProto(buf[:0])
+		dst = protowire.AppendTag(dst, 5, protowire.BytesType)
+		dst = protowire.AppendBytes(dst, buf)
+	}
+	return dst
+}
+
+// UnmarshalBinary decodes a protobuf Record message into r, replacing its
+// contents. Unknown fields are skipped.
+func (r *Record) UnmarshalBinary(b []byte) error {
+	*r = Record{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			t, err := consumeTimestamp(v)
+			r.Time = t
+			return n, err
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			r.Level = int64(v)
+			return n, nil
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			r.Message = v
+			return n, nil
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			r.Source = v
+			return n, nil
+		case num == 5 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var a Attr
+			err := a.unmarshalProto(v)
+			r.Attrs = append(r.Attrs, a)
+			return n, err
+		case num >= 1 && num <= 5:
+			return 0, errWireType
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// appendProto appends the protobuf encoding of a to dst. Kinds without a
+// typed field in the schema are sent as strings.
+func (a Attr) appendProto(dst []byte) []byte {
+	if a.Key != "" {
+		dst = protowire.AppendTag(dst, 1, protowire.BytesType)
+		dst = protowire.AppendString(dst, a.Key)
+	}
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindInt64:
+		dst = protowire.AppendTag(dst, 3, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(v.Int64()))
+	case slog.KindUint64:
+		dst = protowire.AppendTag(dst, 4, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, v.Uint64())
+	case slog.KindFloat64:
+		dst = protowire.AppendTag(dst, 5, protowire.Fixed64Type)
+		dst = protowire.AppendFixed64(dst, math.Float64bits(v.Float64()))
+	case slog.KindBool:
+		dst = protowire.AppendTag(dst, 6, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, protowire.EncodeBool(v.Bool()))
+	case slog.KindTime:
+		dst = protowire.AppendTag(dst, 7, protowire.BytesType)
+		dst = protowire.AppendBytes(dst, appendTimestamp(nil, v.Time()))
+	case slog.KindDuration:
+		dst = protowire.AppendTag(dst, 8, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(v.Duration()))
+	default:
+		dst = protowire.AppendTag(dst, 2, protowire.BytesType)
+		dst = protowire.AppendString(dst, v.String())
+	}
+	return dst
+}
+
+func (a *Attr) unmarshalProto(b []byte) error {
+	*a = Attr{Value: slog.StringValue("")}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var n int
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			a.Key, n = protowire.ConsumeString(b)
+		case num == 2 && typ == protowire.BytesType:
+			var v string
+			v, n = protowire.ConsumeString(b)
+			a.Value = slog.StringValue(v)
+		case num == 3 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			a.Value = slog.Int64Value(int64(v))
+		case num == 4 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			a.Value = slog.Uint64Value(v)
+		case num == 5 && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			a.Value = slog.Float64Value(math.Float64frombits(v))
+		case num == 6 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			a.Value = slog.BoolValue(protowire.DecodeBool(v))
+		case num == 7 && typ == protowire.BytesType:
+			var v []byte
+			if v, n = protowire.ConsumeBytes(b); n < 0 {
+				return n, nil
+			}
+			t, err := consumeTimestamp(v)
+			a.Value = slog.TimeValue(t)
+			return n, err
+		case num == 8 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			a.Value = slog.DurationValue(time.Duration(v))
+		case num >= 1 && num <= 8:
+			return 0, errWireType
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		return n, nil
+	})
+}
+
+// MarshalBinary encodes r as a protobuf StreamResponse message.
+func (r *StreamResponse) MarshalBinary() ([]byte, error) {
+	var dst []byte
+	if r.Received != 0 {
+		dst = protowire.AppendTag(dst, 1, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(r.Received))
+	}
+	return dst, nil
+}
+
+// UnmarshalBinary decodes a protobuf StreamResponse message into r.
+func (r *StreamResponse) UnmarshalBinary(b []byte) error {
+	*r = StreamResponse{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			r.Received = int64(v)
+			return n, nil
+		case num == 1:
+			return 0, errWireType
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// appendTimestamp appends the fields of a google.protobuf.Timestamp.
+func appendTimestamp(dst []byte, t time.Time) []byte {
+	if s := t.Unix(); s != 0 {
+		dst = protowire.AppendTag(dst, 1, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(s))
+	}
+	if ns := t.Nanosecond(); ns != 0 {
+		dst = protowire.AppendTag(dst, 2, protowire.VarintType)
+		dst = protowire.AppendVarint(dst, uint64(ns))
+	}
+	return dst
+}
+
+func consumeTimestamp(b []byte) (time.Time, error) {
+	var sec, nsec int64
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case (num == 1 || num == 2) && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if num == 1 {
+				sec = int64(v)
+			} else {
+				nsec = int64(int32(v))
+			}
+			return n, nil
+		case num == 1 || num == 2:
+			return 0, errWireType
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if nsec < 0 || nsec >= 1e9 {
+		return time.Time{}, errors.New("grpcsink: invalid timestamp nanos")
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// consumeFields calls field for each field in b. field returns the length
+// of the value it consumed, or a negative protowire error code.
+func consumeFields(b []byte, field func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := field(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package grpcsink
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the fully qualified name of the LogCollector service.
+// See collector.proto for the service definition.
+const ServiceName = "sloghandler.v1.LogCollector"
+
+const streamMethod = "/" + ServiceName + "/Stream"
+
+// Record is a log record sent over the LogCollector service.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Level   int64     `json:"level"`
+	Message string    `json:"message"`
+	// Source is "file:line" when the record carries source information.
+	Source string `json:"source,omitempty"`
+	// Attrs are the record attributes with group names joined to keys by dots.
+	Attrs []Attr `json:"attrs,omitempty"`
+}
+
+// Attr is a single attribute of a Record.
+type Attr struct {
+	Key string
+	// Value is of kind String, Int64, Uint64, Float64, Bool, Time or
+	// Duration. The handler sends other kinds as strings.
+	Value slog.Value
+}
+
+// StreamResponse is returned when a client closes its stream.
+type StreamResponse struct {
+	// Received is the number of records received on the stream.
+	Received int64 `json:"received"`
+}
+
+// Collector receives records streamed by clients.
+type Collector interface {
+	Collect(ctx context.Context, records []*Record) error
+}
+
+// CollectorFunc adapts a function to the Collector interface.
+type CollectorFunc func(ctx context.Context, records []*Record) error
+
+// Collect calls f(ctx, records).
+func (f CollectorFunc) Collect(ctx context.Context, records []*Record) error {
+	return f(ctx, records)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Collector)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       streamHandler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "collector.proto",
+}
+
+// RegisterCollectorServer registers c as the LogCollector service on s.
+func RegisterCollectorServer(s grpc.ServiceRegistrar, c Collector) {
+	s.RegisterService(&serviceDesc, c)
+}
+
+// serverBatchSize bounds the number of records passed to Collect at once.
+const serverBatchSize = 100
+
+func streamHandler(srv any, stream grpc.ServerStream) error {
+	c := srv.(Collector)
+	var received int64
+	batch := make([]*Record, 0, serverBatchSize)
+	for {
+		r := new(Record)
+		err := stream.RecvMsg(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		received++
+		batch = append(batch, r)
+		if len(batch) == serverBatchSize {
+			if err := c.Collect(stream.Context(), batch); err != nil {
+				return err
+			}
+			batch = make([]*Record, 0, serverBatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		if err := c.Collect(stream.Context(), batch); err != nil {
+			return err
+		}
+	}
+	return stream.SendMsg(&StreamResponse{Received: received})
+}
+
+// NewHandlerCollector returns a reference Collector that replays received
+// records into h, preserving their original time, level and attributes.
+func NewHandlerCollector(h slog.Handler) Collector {
+	return CollectorFunc(func(ctx context.Context, records []*Record) error {
+		for _, rec := range records {
+			level := slog.Level(rec.Level)
+			if !h.Enabled(ctx, level) {
+				continue
+			}
+			r := slog.NewRecord(rec.Time, level, rec.Message, 0)
+			if rec.Source != "" {
+				r.AddAttrs(slog.String(slog.SourceKey, rec.Source))
+			}
+			for _, a := range rec.Attrs {
+				r.AddAttrs(slog.Attr{Key: a.Key, Value: a.Value})
+			}
+			if err := h.Handle(ctx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
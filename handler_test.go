@@ -115,20 +115,21 @@ func TestEnabled(t *testing.T) {
 	}
 }
 
-func TestFprintfFunc(t *testing.T) {
+func TestLineColor(t *testing.T) {
 	buf := &bytes.Buffer{}
 	tests := []struct {
 		level slog.Level
 		color bool
+		want  bool
 	}{
-		{slog.LevelDebug, false},
-		{slog.LevelInfo, false},
-		{slog.LevelWarn, false},
-		{slog.LevelError, false},
-		{slog.LevelDebug, true},
-		{slog.LevelInfo, true},
-		{slog.LevelWarn, true},
-		{slog.LevelError, true},
+		{slog.LevelDebug, false, false},
+		{slog.LevelInfo, false, false},
+		{slog.LevelWarn, false, false},
+		{slog.LevelError, false, false},
+		{slog.LevelDebug, true, true},
+		{slog.LevelInfo, true, false},
+		{slog.LevelWarn, true, true},
+		{slog.LevelError, true, true},
 	}
 
 	for _, tt := range tests {
@@ -137,16 +138,8 @@ func TestFprintfFunc(t *testing.T) {
 			Color:          tt.color,
 		}
 		handler := NewLogHandler(buf, opts).(*logHandler)
-		f := handler.FprintFunc(tt.level)
-		if f == nil {
-			t.Errorf("FprintfFunc(%v, color=%v) returned nil", tt.level, tt.color)
-		}
-
-		// Basic test that the function works without error
-		buf.Reset()
-		f(buf, "test")
-		if buf.Len() == 0 {
-			t.Errorf("FprintfFunc(%v, color=%v) did not write to buffer", tt.level, tt.color)
+		if got := handler.lineColor(tt.level, -1) != nil; got != tt.want {
+			t.Errorf("lineColor(%v, color=%v) != nil = %v, want %v", tt.level, tt.color, got, tt.want)
 		}
 	}
 }
@@ -426,7 +419,31 @@ func TestWithGroup(t *testing.T) {
 	}
 }
 
-func TestFprintFuncOutput(t *testing.T) {
+func TestWithGroupNamespace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}, GroupNamespace: true, Now: FrozenClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))}
+	logger := slog.New(NewLogHandler(buf, opts)).With("app", "demo")
+
+	logger.WithGroup("http").With("method", "GET").WithGroup("req").Info("served", "status", 200)
+	logger.WithGroup("db").Info("connected")
+	logger.WithGroup("db").WithGroup("pool").Info("opened", "size", 4)
+
+	want := "2024-01-02T03:04:05.000Z [INFO] [app:demo] http ▸ [method:GET] served req ▸ [status:200]\n" +
+		"2024-01-02T03:04:05.000Z [INFO] [app:demo] connected\n" +
+		"2024-01-02T03:04:05.000Z [INFO] [app:demo] opened db ▸ pool ▸ [size:4]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	opts.Color = true
+	slog.New(NewLogHandler(buf, opts)).WithGroup("http").Info("served", "status", 200)
+	if !bytes.Contains(buf.Bytes(), []byte("\x1b[2mhttp ▸\x1b[22m [status:200]")) {
+		t.Errorf("namespace is not dimmed: %q", buf.String())
+	}
+}
+
+func TestLineColorOutput(t *testing.T) {
 	tests := []struct {
 		name            string
 		level           slog.Level
@@ -507,17 +524,18 @@ func TestFprintFuncOutput(t *testing.T) {
 				Color:          tt.color,
 			}
 			handler := NewLogHandler(buf, opts).(*logHandler)
-			fprintfFunc := handler.FprintFunc(tt.level)
-
-			// Call the function to write to buffer
-			fprintfFunc(buf, "test message")
+			if c := handler.lineColor(tt.level, -1); c != nil {
+				c.Fprint(buf, "test message")
+			} else {
+				buf.WriteString("test message")
+			}
 
 			output := buf.String()
 
 			// Check for strings that should be in the output
 			for _, want := range tt.wantContains {
 				if !bytes.Contains(buf.Bytes(), []byte(want)) {
-					t.Errorf("FprintfFunc() output = %q, should contain %q", output, want)
+					t.Errorf("output = %q, should contain %q", output, want)
 				}
 			}
 
@@ -525,7 +543,7 @@ func TestFprintFuncOutput(t *testing.T) {
 			if tt.wantNotContains != nil {
 				for _, notWant := range tt.wantNotContains {
 					if bytes.Contains(buf.Bytes(), []byte(notWant)) {
-						t.Errorf("FprintfFunc() output = %q, should NOT contain %q", output, notWant)
+						t.Errorf("output = %q, should NOT contain %q", output, notWant)
 					}
 				}
 			}
@@ -0,0 +1,125 @@
+package sloghandler
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Health is a snapshot of a sink's delivery state, returned by the Health
+// method of the buffered sinks.
+type Health struct {
+	// LastSuccess is when a batch was last delivered, or zero if none has been.
+	LastSuccess time.Time
+	// LastFailure and LastError describe the most recent failed delivery.
+	LastFailure time.Time
+	LastError   error
+	// ConsecutiveFailures counts failed deliveries since the last success.
+	ConsecutiveFailures int
+	// QueueDepth is the number of records waiting to be delivered.
+	QueueDepth int
+	// Dropped is the number of records dropped because the queue was full.
+	Dropped int64
+}
+
+// Healthy reports whether the most recent delivery, if any, succeeded.
+func (h Health) Healthy() bool {
+	return h.ConsecutiveFailures == 0
+}
+
+// LogHealthChanges returns an OnHealthChange callback that logs to logger
+// when deliveries to the named sink start failing and when they recover.
+// The logger must not write to the same sink.
+func LogHealthChanges(logger *slog.Logger, sink string) func(Health) {
+	return func(h Health) {
+		if h.Healthy() {
+			logger.Info("log sink recovered", "sink", sink, "queue_depth", h.QueueDepth, "dropped", h.Dropped)
+		} else {
+			logger.Warn("log sink failing", "sink", sink, "error", h.LastError, "queue_depth", h.QueueDepth, "dropped", h.Dropped)
+		}
+	}
+}
+
+// queueStats is implemented by batcher.
+type queueStats interface {
+	depth() int
+	dropCount() int64
+}
+
+// sinkHealth records delivery outcomes of a sink. It is shared by the
+// handlers derived with WithAttrs and WithGroup.
+type sinkHealth struct {
+	queue    queueStats
+	onChange func(Health)
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastError   error
+	failures    int
+}
+
+func newSinkHealth(onChange func(Health)) *sinkHealth {
+	return &sinkHealth{onChange: onChange}
+}
+
+// report records the outcome of a delivery and passes its error to
+// onError, unless the batch was kept in a spool.
+func (s *sinkHealth) report(err error, onError func(error)) {
+	s.record(err)
+	if err := withoutSpooled(err); err != nil {
+		onError(err)
+	}
+}
+
+// withoutSpooled removes the errors of spooled batches from err.
+func withoutSpooled(err error) error {
+	var spooled *spooledError
+	if !errors.As(err, &spooled) {
+		return err
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var rest []error
+		for _, e := range joined.Unwrap() {
+			rest = append(rest, withoutSpooled(e))
+		}
+		return errors.Join(rest...)
+	}
+	return nil
+}
+
+// record notes the outcome of a delivery and calls onChange when the sink
+// starts failing or recovers.
+func (s *sinkHealth) record(err error) {
+	s.mu.Lock()
+	now := time.Now()
+	changed := (err != nil) == (s.failures == 0)
+	if err != nil {
+		s.lastFailure, s.lastError = now, err
+		s.failures++
+	} else {
+		s.lastSuccess = now
+		s.failures = 0
+	}
+	s.mu.Unlock()
+	if changed && s.onChange != nil {
+		s.onChange(s.snapshot())
+	}
+}
+
+func (s *sinkHealth) snapshot() Health {
+	s.mu.Lock()
+	h := Health{
+		LastSuccess:         s.lastSuccess,
+		LastFailure:         s.lastFailure,
+		LastError:           s.lastError,
+		ConsecutiveFailures: s.failures,
+	}
+	s.mu.Unlock()
+	if s.queue != nil {
+		h.QueueDepth = s.queue.depth()
+		h.Dropped = s.queue.dropCount()
+	}
+	return h
+}
@@ -0,0 +1,83 @@
+package sloghandler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	var changes []Health
+	var logs bytes.Buffer
+	logChange := LogHealthChanges(slog.New(NewLogHandler(&logs, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}})), "http")
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{
+		Retry:    &RetryPolicy{Backoff: time.Millisecond},
+		Interval: time.Hour,
+		OnError:  func(error) {},
+		OnHealthChange: func(health Health) {
+			changes = append(changes, health)
+			logChange(health)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if health := h.Health(); !health.Healthy() || !health.LastSuccess.IsZero() {
+		t.Errorf("initial health = %+v", health)
+	}
+
+	logger := slog.New(h).With("k", "v")
+	for range 2 {
+		logger.Info("failing")
+		h.Flush()
+	}
+	health := h.Health()
+	if health.Healthy() || health.ConsecutiveFailures != 2 || health.LastError == nil || health.LastFailure.IsZero() {
+		t.Errorf("health while down = %+v", health)
+	}
+
+	down.Store(false)
+	logger.Info("recovered")
+	h.Flush()
+	health = h.Health()
+	if !health.Healthy() || health.LastSuccess.IsZero() || health.QueueDepth != 0 {
+		t.Errorf("health after recovery = %+v", health)
+	}
+	if len(changes) != 2 || changes[0].Healthy() || !changes[1].Healthy() {
+		t.Errorf("changes = %+v, want failing then recovered", changes)
+	}
+	out := logs.String()
+	if !strings.Contains(out, "[WARN] log sink failing [sink:http]") || !strings.Contains(out, "[INFO] log sink recovered [sink:http]") {
+		t.Errorf("unexpected self-log:\n%s", out)
+	}
+}
+
+func TestWithoutSpooled(t *testing.T) {
+	rejected := errors.New("rejected")
+	spooled := &spooledError{errors.New("unavailable")}
+	if err := withoutSpooled(spooled); err != nil {
+		t.Errorf("withoutSpooled(spooled) = %v", err)
+	}
+	if err := withoutSpooled(errors.Join(rejected, spooled)); err == nil || err.Error() != "rejected" {
+		t.Errorf("withoutSpooled(joined) = %v", err)
+	}
+	if err := withoutSpooled(rejected); err != rejected {
+		t.Errorf("withoutSpooled(rejected) = %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+// Package httplog provides net/http middleware that writes access logs
+// through a log/slog logger.
+//
+// The middleware works with any router built on net/http, such as chi.
+// For Echo, wrap it with echo.WrapMiddleware.
+package httplog
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+// Options contains configuration for the access log middleware.
+type Options struct {
+	// Message is the log message. Default is "access".
+	Message string
+
+	// Attribute keys. Empty keys use the defaults shown.
+	MethodKey   string // "method"
+	PathKey     string // "path"
+	StatusKey   string // "status"
+	DurationKey string // "duration_ms"
+	BytesKey    string // "bytes"
+	ClientIPKey string // "client_ip"
+	QueryKey    string // "query"
+
+	// LogQuery logs the raw query string of requests that have one.
+	LogQuery bool
+
+	// RequestHeaders and ResponseHeaders name headers to log, in
+	// "request_header" and "response_header" groups keyed by the lower-case
+	// header name.
+	RequestHeaders  []string
+	ResponseHeaders []string
+
+	// Canonical starts a sloghandler.CanonicalLine for each request, so that
+	// handlers can add attributes to the access log record with
+	// sloghandler.AddCanonical instead of logging records of their own.
+	Canonical bool
+
+	// Redact removes credentials from the logged headers and query string
+	// with sloghandler.HTTPRedactionProfile: the Authorization, Cookie and
+	// Set-Cookie headers, API key headers, and API keys and tokens in
+	// query parameters.
+	Redact bool
+
+	// TrustProxy takes the client IP from the X-Forwarded-For or X-Real-IP
+	// header instead of the connection's remote address.
+	TrustProxy bool
+
+	// Level returns the level for a response status.
+	// Default is ERROR for 5xx, WARN for 4xx and INFO otherwise.
+	Level func(status int) slog.Level
+
+	// Skip excludes requests from logging, e.g. health checks.
+	Skip func(r *http.Request) bool
+}
+
+// DefaultOptions returns the default configuration options.
+func DefaultOptions() *Options {
+	return &Options{
+		Message:     "access",
+		MethodKey:   "method",
+		PathKey:     "path",
+		StatusKey:   "status",
+		DurationKey: "duration_ms",
+		BytesKey:    "bytes",
+		ClientIPKey: "client_ip",
+		QueryKey:    "query",
+		Level:       DefaultLevel,
+	}
+}
+
+// DefaultLevel returns ERROR for 5xx, WARN for 4xx and INFO otherwise.
+func DefaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Middleware returns access log middleware with default options.
+func Middleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return MiddlewareWithOptions(logger, DefaultOptions())
+}
+
+// MiddlewareWithOptions returns access log middleware with the provided options.
+// Status and bytes are logged as integers and the duration as fractional
+// milliseconds, so metrics handlers can use them directly.
+func MiddlewareWithOptions(logger *slog.Logger, opts *Options) func(http.Handler) http.Handler {
+	o := mergeOptions(opts)
+	if o.Redact {
+		logger = slog.New(sloghandler.NewTransformHandler(logger.Handler(),
+			sloghandler.Redact(sloghandler.HTTPRedactionProfile)))
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.Skip != nil && o.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			var line *sloghandler.CanonicalLine
+			if o.Canonical {
+				var ctx context.Context
+				ctx, line = sloghandler.WithCanonicalLine(r.Context())
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(rw, r)
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				slog.String(o.MethodKey, r.Method),
+				slog.String(o.PathKey, r.URL.Path),
+				slog.Int(o.StatusKey, status),
+				slog.Float64(o.DurationKey, float64(time.Since(start))/float64(time.Millisecond)),
+				slog.Int64(o.BytesKey, rw.bytes),
+				slog.String(o.ClientIPKey, clientIP(r, o.TrustProxy)),
+			}
+			if o.LogQuery && r.URL.RawQuery != "" {
+				attrs = append(attrs, slog.String(o.QueryKey, r.URL.RawQuery))
+			}
+			if a, ok := headerAttr("request_header", r.Header, o.RequestHeaders); ok {
+				attrs = append(attrs, a)
+			}
+			if a, ok := headerAttr("response_header", w.Header(), o.ResponseHeaders); ok {
+				attrs = append(attrs, a)
+			}
+			if line != nil {
+				attrs = append(attrs, line.Attrs()...)
+			}
+			logger.LogAttrs(r.Context(), o.Level(status), o.Message, attrs...)
+		})
+	}
+}
+
+func mergeOptions(opts *Options) *Options {
+	o := DefaultOptions()
+	if opts == nil {
+		return o
+	}
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&o.Message, opts.Message)
+	set(&o.MethodKey, opts.MethodKey)
+	set(&o.PathKey, opts.PathKey)
+	set(&o.StatusKey, opts.StatusKey)
+	set(&o.DurationKey, opts.DurationKey)
+	set(&o.BytesKey, opts.BytesKey)
+	set(&o.ClientIPKey, opts.ClientIPKey)
+	set(&o.QueryKey, opts.QueryKey)
+	o.TrustProxy = opts.TrustProxy
+	o.LogQuery = opts.LogQuery
+	o.RequestHeaders = opts.RequestHeaders
+	o.ResponseHeaders = opts.ResponseHeaders
+	o.Canonical = opts.Canonical
+	o.Redact = opts.Redact
+	if opts.Level != nil {
+		o.Level = opts.Level
+	}
+	o.Skip = opts.Skip
+	return o
+}
+
+// headerAttr returns a group of the named headers present in h.
+func headerAttr(key string, h http.Header, names []string) (slog.Attr, bool) {
+	var attrs []any
+	for _, name := range names {
+		if vs := h.Values(name); len(vs) > 0 {
+			attrs = append(attrs, slog.String(strings.ToLower(name), strings.Join(vs, ", ")))
+		}
+	}
+	if len(attrs) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Group(key, attrs...), true
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			ip, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(ip)
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseWriter records the status code and number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *responseWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
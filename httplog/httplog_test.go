@@ -0,0 +1,127 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := MiddlewareWithOptions(logger, &Options{
+		StatusKey:  "http.status",
+		TrustProxy: true,
+		Skip:       func(r *http.Request) bool { return r.URL.Path == "/healthz" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+
+	for _, path := range []string{"/hello", "/missing", "/healthz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var logs []map[string]any
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, m)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("got %d access logs, want 2", len(logs))
+	}
+	first := logs[0]
+	if first["level"] != "INFO" || first["method"] != "GET" || first["path"] != "/hello" ||
+		first["http.status"] != 200.0 || first["bytes"] != 5.0 || first["client_ip"] != "203.0.113.1" {
+		t.Errorf("unexpected access log %v", first)
+	}
+	if _, ok := first["duration_ms"].(float64); !ok {
+		t.Errorf("duration_ms should be numeric, got %T", first["duration_ms"])
+	}
+	if logs[1]["level"] != "WARN" || logs[1]["http.status"] != 404.0 {
+		t.Errorf("unexpected access log for 404 %v", logs[1])
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	req.Header.Set("X-Real-IP", "198.51.100.7")
+	if got := clientIP(req, false); got != "192.0.2.10" {
+		t.Errorf("clientIP without proxy = %s", got)
+	}
+	if got := clientIP(req, true); got != "198.51.100.7" {
+		t.Errorf("clientIP with proxy = %s", got)
+	}
+}
+
+func TestMiddlewareRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := MiddlewareWithOptions(logger, &Options{
+		LogQuery:        true,
+		RequestHeaders:  []string{"Authorization", "User-Agent"},
+		ResponseHeaders: []string{"Set-Cookie"},
+		Redact:          true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=go&api_key=k3y", nil)
+	req.Header.Set("Authorization", "Bearer t0ken")
+	req.Header.Set("User-Agent", "curl/8.5.0")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["query"] != "q=go&api_key=[REDACTED]" {
+		t.Errorf("query = %v", m["query"])
+	}
+	reqHeader, _ := m["request_header"].(map[string]any)
+	if reqHeader["authorization"] != "[REDACTED]" || reqHeader["user-agent"] != "curl/8.5.0" {
+		t.Errorf("request_header = %v", m["request_header"])
+	}
+	respHeader, _ := m["response_header"].(map[string]any)
+	if respHeader["set-cookie"] != "[REDACTED]" {
+		t.Errorf("response_header = %v", m["response_header"])
+	}
+	if bytes.Contains(buf.Bytes(), []byte("s3cr3t")) || bytes.Contains(buf.Bytes(), []byte("t0ken")) {
+		t.Errorf("credentials logged: %s", buf.String())
+	}
+}
+
+func TestMiddlewareCanonical(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := MiddlewareWithOptions(logger, &Options{Canonical: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sloghandler.AddCanonical(r.Context(), "user", "alice")
+		if line, ok := sloghandler.CanonicalLineFromContext(r.Context()); ok {
+			line.Inc("db_queries", 2)
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["msg"] != "access" || m["user"] != "alice" || m["db_queries"] != 2.0 {
+		t.Errorf("got %v", m)
+	}
+}
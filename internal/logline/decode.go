@@ -0,0 +1,225 @@
+// Package logline decodes log lines in the formats the command-line tools
+// understand: the sloghandler bracket format, slog JSON and logfmt.
+package logline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+// Decode decodes a line in the bracket format, slog JSON or logfmt into a record.
+// A source location is returned as a "source" attribute of the form "file:line".
+func Decode(line string) (slog.Record, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		return DecodeJSON(line)
+	}
+	if e, err := sloghandler.Parse(line); err == nil {
+		return EntryRecord(e), nil
+	}
+	return decodeLogfmt(line)
+}
+
+// EntryRecord converts e to a record. A source location is returned as a
+// "source" attribute of the form "file:line".
+func EntryRecord(e *sloghandler.Entry) slog.Record {
+	r := slog.NewRecord(e.Time, e.Level, e.Message, 0)
+	if e.Source != nil {
+		r.AddAttrs(slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", e.Source.File, e.Source.Line)))
+	}
+	r.AddAttrs(e.Attrs...)
+	return r
+}
+
+// newRecord builds a record from decoded fields, treating the slog built-in
+// keys (time, level, msg, source) specially.
+func newRecord(attrs []slog.Attr) (slog.Record, error) {
+	var (
+		t     time.Time
+		level slog.Level
+		msg   string
+		found bool
+		rest  []slog.Attr
+	)
+	for _, a := range attrs {
+		switch a.Key {
+		case slog.TimeKey:
+			if ts, err := time.Parse(time.RFC3339Nano, a.Value.String()); err == nil {
+				t = ts
+				continue
+			}
+		case slog.LevelKey:
+			if l, err := sloghandler.ParseLevel(a.Value.String()); err == nil {
+				level = l
+				found = true
+				continue
+			}
+		case slog.MessageKey:
+			msg = a.Value.String()
+			continue
+		case slog.SourceKey:
+			a = sourceAttr(a)
+		}
+		rest = append(rest, a)
+	}
+	if !found {
+		return slog.Record{}, errors.New("no level")
+	}
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(rest...)
+	return r, nil
+}
+
+// sourceAttr shortens a JSON source object to "file:line".
+func sourceAttr(a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	var file, line string
+	for _, ga := range a.Value.Group() {
+		switch ga.Key {
+		case "file":
+			file = filepath.Base(ga.Value.String())
+		case "line":
+			line = ga.Value.String()
+		}
+	}
+	return slog.String(a.Key, file+":"+line)
+}
+
+// DecodeJSON decodes a slog JSON line into a record.
+func DecodeJSON(line string) (slog.Record, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if v.Kind() != slog.KindGroup {
+		return slog.Record{}, errors.New("not an object")
+	}
+	return newRecord(v.Group())
+}
+
+// decodeJSONValue decodes the next JSON value, keeping the key order of
+// objects by turning them into groups.
+func decodeJSONValue(dec *json.Decoder) (slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			var attrs []slog.Attr
+			for dec.More() {
+				k, err := dec.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: fmt.Sprint(k), Value: v})
+			}
+			if _, err := dec.Token(); err != nil {
+				return slog.Value{}, err
+			}
+			return slog.GroupValue(attrs...), nil
+		case '[':
+			var items []string
+			for dec.More() {
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				items = append(items, v.String())
+			}
+			if _, err := dec.Token(); err != nil {
+				return slog.Value{}, err
+			}
+			return slog.StringValue("[" + strings.Join(items, " ") + "]"), nil
+		}
+		return slog.Value{}, fmt.Errorf("unexpected %v", tok)
+	case json.Number:
+		if n, err := tok.Int64(); err == nil {
+			return slog.Int64Value(n), nil
+		}
+		f, _ := tok.Float64()
+		return slog.Float64Value(f), nil
+	case string:
+		return slog.StringValue(tok), nil
+	case bool:
+		return slog.BoolValue(tok), nil
+	case nil:
+		return slog.AnyValue(nil), nil
+	}
+	return slog.Value{}, fmt.Errorf("unexpected token %v", tok)
+}
+
+// decodeLogfmt decodes key=value pairs as written by slog.TextHandler.
+func decodeLogfmt(line string) (slog.Record, error) {
+	var attrs []slog.Attr
+	s := line
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return slog.Record{}, errors.New("not logfmt")
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := quotedEnd(rest)
+			if end < 0 {
+				return slog.Record{}, errors.New("unterminated quote")
+			}
+			v, err := strconv.Unquote(rest[:end])
+			if err != nil {
+				return slog.Record{}, err
+			}
+			value, s = v, rest[end:]
+		} else {
+			value, s, _ = strings.Cut(rest, " ")
+		}
+		attrs = append(attrs, logfmtAttr(key, value))
+	}
+	return newRecord(attrs)
+}
+
+// quotedEnd returns the index just past the closing quote of the Go string
+// literal at the start of s, or -1.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// logfmtAttr types numeric and boolean values.
+func logfmtAttr(key, value string) slog.Attr {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return slog.Int64(key, n)
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && strings.ContainsAny(value, ".eE") {
+		return slog.Float64(key, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		return slog.Bool(key, b)
+	}
+	return slog.String(key, value)
+}
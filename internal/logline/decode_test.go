@@ -0,0 +1,47 @@
+package logline
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		line  string
+		level slog.Level
+		msg   string
+		attrs string
+	}{
+		{`{"time":"2023-05-09T12:34:56Z","level":"WARN","msg":"slow","latency":1.5,"req":{"id":7},"tags":["a","b"]}`,
+			slog.LevelWarn, "slow", "latency=1.5 req=[id=7] tags=[a b]"},
+		{`time=2023-05-09T12:34:56Z level=INFO msg="a \"quoted\" message" ok=true n=3`,
+			slog.LevelInfo, `a "quoted" message`, "ok=true n=3"},
+		{`2023-05-09T12:34:56.789Z [ERROR] [main.go:12] failed [code:1]`,
+			slog.LevelError, "failed", "source=main.go:12 code=1"},
+		{`2023-05-09T12:34:56.789Z [TRACE] deep`,
+			slog.LevelDebug - 4, "deep", ""},
+	}
+	for _, tt := range tests {
+		r, err := Decode(tt.line)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.line, err)
+			continue
+		}
+		var attrs string
+		r.Attrs(func(a slog.Attr) bool {
+			if attrs != "" {
+				attrs += " "
+			}
+			attrs += a.String()
+			return true
+		})
+		if r.Level != tt.level || r.Message != tt.msg || attrs != tt.attrs {
+			t.Errorf("Decode(%q) = %v %q %q, want %v %q %q", tt.line, r.Level, r.Message, attrs, tt.level, tt.msg, tt.attrs)
+		}
+	}
+	for _, line := range []string{"just some text", "panic: boom", `{"msg":"no level"}`} {
+		if _, err := Decode(line); err == nil {
+			t.Errorf("Decode(%q) should fail", line)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// JobSummaryOptions configures a JobSummaryHandler.
+type JobSummaryOptions struct {
+	// Name is added to the summary record as the "job" attribute when set.
+	Name string
+	// Message is the message of the summary record. Default is "job summary".
+	Message string
+	// FailLevel is the level at which a record marks the run as failed.
+	// Default is slog.LevelError.
+	FailLevel slog.Leveler
+}
+
+// JobSummaryHandler wraps a handler and counts the records of each level
+// during a job run, such as a cron job or batch. Close emits a single summary
+// record with the counts, the duration and a suggested exit code, so the
+// outcome of a run is visible at a glance.
+type JobSummaryHandler struct {
+	slog.Handler
+	state *jobState
+}
+
+type jobState struct {
+	mu         sync.Mutex
+	base       slog.Handler
+	opts       JobSummaryOptions
+	start      time.Time
+	counts     map[slog.Level]int
+	failed     bool
+	firstError string
+	closed     bool
+}
+
+// NewJobSummaryHandler creates a JobSummaryHandler that passes records to h.
+// The job is considered started when the handler is created.
+func NewJobSummaryHandler(h slog.Handler, opts *JobSummaryOptions) *JobSummaryHandler {
+	o := JobSummaryOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Message == "" {
+		o.Message = "job summary"
+	}
+	if o.FailLevel == nil {
+		o.FailLevel = slog.LevelError
+	}
+	return &JobSummaryHandler{
+		Handler: h,
+		state: &jobState{
+			base:   h,
+			opts:   o,
+			start:  time.Now(),
+			counts: make(map[slog.Level]int),
+		},
+	}
+}
+
+func (h *JobSummaryHandler) Handle(ctx context.Context, record slog.Record) error {
+	s := h.state
+	s.mu.Lock()
+	s.counts[record.Level]++
+	if !s.failed && record.Level >= s.opts.FailLevel.Level() {
+		s.failed = true
+		s.firstError = record.Message
+	}
+	s.mu.Unlock()
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *JobSummaryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &JobSummaryHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state}
+}
+
+func (h *JobSummaryHandler) WithGroup(name string) slog.Handler {
+	return &JobSummaryHandler{Handler: h.Handler.WithGroup(name), state: h.state}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *JobSummaryHandler) Unwrap() slog.Handler {
+	return h.Handler
+}
+
+// Counts returns the number of records handled so far for each level.
+func (h *JobSummaryHandler) Counts() map[slog.Level]int {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return maps.Clone(h.state.counts)
+}
+
+// ExitCode suggests a process exit code for the run: 1 if any record was
+// logged at or above FailLevel, otherwise 0.
+func (h *JobSummaryHandler) ExitCode() int {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return h.state.exitCode()
+}
+
+func (s *jobState) exitCode() int {
+	if s.failed {
+		return 1
+	}
+	return 0
+}
+
+// Close emits the summary record to the wrapped handler. The summary is
+// logged at INFO, or at ERROR when the run failed. Only the first call
+// emits a summary; handlers derived via WithAttrs or WithGroup share it.
+//
+//	h := sloghandler.NewJobSummaryHandler(base, &sloghandler.JobSummaryOptions{Name: "nightly-export"})
+//	logger := slog.New(h)
+//	run(logger)
+//	h.Close()
+//	os.Exit(h.ExitCode())
+func (h *JobSummaryHandler) Close() error {
+	s := h.state
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	exitCode := s.exitCode()
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, s.opts.Message, 0)
+	if exitCode != 0 {
+		record.Level = slog.LevelError
+	}
+	if s.opts.Name != "" {
+		record.AddAttrs(slog.String("job", s.opts.Name))
+	}
+	record.AddAttrs(
+		slog.Duration("duration", record.Time.Sub(s.start)),
+		slog.Any("counts", s.countsAttr()),
+		slog.Int("exit_code", exitCode),
+	)
+	if s.failed {
+		record.AddAttrs(slog.String("first_error", s.firstError))
+	}
+	s.mu.Unlock()
+	return s.base.Handle(context.Background(), record)
+}
+
+// countsAttr returns the counts as a group keyed by level name, in level order.
+func (s *jobState) countsAttr() slog.Value {
+	levels := slices.Sorted(maps.Keys(s.counts))
+	attrs := make([]slog.Attr, 0, len(levels))
+	for _, level := range levels {
+		attrs = append(attrs, slog.Int(LevelName(level), s.counts[level]))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,70 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestJobSummaryHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewJobSummaryHandler(slog.NewJSONHandler(buf, nil), &JobSummaryOptions{Name: "export"})
+	logger := slog.New(h).With("run", 1)
+	logger.Info("started")
+	logger.Warn("slow query")
+	logger.Info("processed")
+	if code := h.ExitCode(); code != 0 {
+		t.Errorf("exit code before errors = %d, want 0", code)
+	}
+	logger.Error("upload failed")
+	logger.Error("retry failed")
+
+	if got := h.Counts(); got[slog.LevelInfo] != 2 || got[slog.LevelWarn] != 1 || got[slog.LevelError] != 2 {
+		t.Errorf("unexpected counts %v", got)
+	}
+	if code := h.ExitCode(); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	buf.Reset()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary["msg"] != "job summary" || summary["level"] != "ERROR" || summary["job"] != "export" ||
+		summary["exit_code"] != 1.0 || summary["first_error"] != "upload failed" {
+		t.Errorf("unexpected summary %v", summary)
+	}
+	if _, ok := summary["run"]; ok {
+		t.Errorf("summary should not carry attrs of derived handlers: %v", summary)
+	}
+	counts, _ := summary["counts"].(map[string]any)
+	if counts["INFO"] != 2.0 || counts["WARN"] != 1.0 || counts["ERROR"] != 2.0 {
+		t.Errorf("unexpected counts %v", summary["counts"])
+	}
+
+	buf.Reset()
+	h.Close()
+	if buf.Len() != 0 {
+		t.Error("second Close should not emit another summary")
+	}
+}
+
+func TestJobSummaryHandlerSuccess(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewJobSummaryHandler(slog.NewJSONHandler(buf, nil), nil)
+	slog.New(h).Info("done")
+	buf.Reset()
+	h.Close()
+	var summary map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary["level"] != "INFO" || summary["exit_code"] != 0.0 {
+		t.Errorf("unexpected summary %v", summary)
+	}
+}
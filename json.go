@@ -0,0 +1,224 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONTimeFormat selects how times are encoded in JSON.
+type JSONTimeFormat int
+
+const (
+	// JSONTimeRFC3339 writes times as RFC 3339 strings with nanoseconds. This is the default.
+	JSONTimeRFC3339 JSONTimeFormat = iota
+	// JSONTimeEpochSeconds writes seconds since the Unix epoch, with a fractional part.
+	JSONTimeEpochSeconds
+	// JSONTimeEpochMillis writes integer milliseconds since the Unix epoch.
+	JSONTimeEpochMillis
+	// JSONTimeEpochNanos writes integer nanoseconds since the Unix epoch.
+	JSONTimeEpochNanos
+)
+
+// JSONSchema selects the field names of the JSON encoding.
+type JSONSchema int
+
+const (
+	// JSONSchemaDefault writes "time", "level", "msg" and "source" fields
+	// followed by the attributes at the top level.
+	JSONSchemaDefault JSONSchema = iota
+	// JSONSchemaECS follows the Elastic Common Schema: "@timestamp",
+	// "log.level" (lower case), "message", "ecs.version", the source as
+	// "log.origin.file.name", "log.origin.file.line" and "log.origin.function",
+	// and the attributes nested under "labels", with dots in their keys
+	// replaced by underscores as ECS requires.
+	JSONSchemaECS
+)
+
+// ecsVersion is the ECS version reported in "ecs.version".
+const ecsVersion = "8.11.0"
+
+// JSONOptions selects the type-preserving JSON encoding of entries, in
+// which numeric, bool, time and duration attributes are written as native
+// JSON values instead of strings.
+type JSONOptions struct {
+	// Schema selects the field names. Default is JSONSchemaDefault.
+	Schema JSONSchema
+	// TimeFormat applies to the record time and to time attributes.
+	// Default is JSONTimeRFC3339.
+	TimeFormat JSONTimeFormat
+	// DurationUnit is the unit durations are counted in: with time.Millisecond,
+	// 1500ms is written as 1500 and 1.5ms as 1.5. Default is time.Nanosecond,
+	// as with slog.JSONHandler.
+	DurationUnit time.Duration
+}
+
+// AppendJSON appends e to dst as a JSON object with the fields of
+// MarshalJSON, encoding attribute values with their native JSON types.
+// A nil opts uses the defaults.
+func (e *Entry) AppendJSON(dst []byte, opts *JSONOptions) []byte {
+	if opts == nil {
+		opts = &JSONOptions{}
+	}
+	if opts.Schema == JSONSchemaECS {
+		return opts.appendECS(dst, e, nil)
+	}
+	dst = append(dst, `{"time":`...)
+	dst = opts.appendTime(dst, e.Time)
+	dst = append(dst, `,"level":`...)
+	dst = appendJSONString(dst, LevelName(e.Level))
+	dst = append(dst, `,"msg":`...)
+	dst = appendJSONString(dst, e.Message)
+	if e.Source != nil {
+		dst = append(dst, `,"source":`...)
+		dst = appendJSONAny(dst, e.Source)
+	}
+	for _, a := range e.Attrs {
+		dst = append(dst, ',')
+		dst = appendJSONString(dst, a.Key)
+		dst = append(dst, ':')
+		dst = opts.appendValue(dst, a.Value)
+	}
+	return append(dst, '}')
+}
+
+// appendECS appends e in the ECS layout. Attributes whose keys are in
+// fields are written at the top level under the mapped name instead of
+// under "labels".
+func (o *JSONOptions) appendECS(dst []byte, e *Entry, fields map[string]string) []byte {
+	dst = append(dst, `{"@timestamp":`...)
+	dst = o.appendTime(dst, e.Time)
+	dst = append(dst, `,"log.level":`...)
+	dst = appendJSONString(dst, strings.ToLower(LevelName(e.Level)))
+	dst = append(dst, `,"message":`...)
+	dst = appendJSONString(dst, e.Message)
+	dst = append(dst, `,"ecs.version":"`+ecsVersion+`"`...)
+	if e.Source != nil {
+		dst = append(dst, `,"log.origin.file.name":`...)
+		dst = appendJSONString(dst, e.Source.File)
+		dst = append(dst, `,"log.origin.file.line":`...)
+		dst = strconv.AppendInt(dst, int64(e.Source.Line), 10)
+		if e.Source.Function != "" {
+			dst = append(dst, `,"log.origin.function":`...)
+			dst = appendJSONString(dst, e.Source.Function)
+		}
+	}
+	labels := 0
+	for _, a := range e.Attrs {
+		if name, ok := fields[a.Key]; ok {
+			dst = append(dst, ',')
+			dst = appendJSONString(dst, name)
+			dst = append(dst, ':')
+			dst = o.appendValue(dst, a.Value)
+			continue
+		}
+		labels++
+	}
+	if labels > 0 {
+		dst = append(dst, `,"labels":{`...)
+		first := true
+		for _, a := range e.Attrs {
+			if _, ok := fields[a.Key]; ok {
+				continue
+			}
+			if !first {
+				dst = append(dst, ',')
+			}
+			first = false
+			dst = appendJSONString(dst, strings.ReplaceAll(a.Key, ".", "_"))
+			dst = append(dst, ':')
+			dst = o.appendValue(dst, a.Value)
+		}
+		dst = append(dst, '}')
+	}
+	return append(dst, '}')
+}
+
+// appendValue appends v, which must be resolved and not a group.
+func (o *JSONOptions) appendValue(dst []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendJSONString(dst, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(dst, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(dst, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return appendJSONFloat(dst, v.Float64())
+	case slog.KindBool:
+		return strconv.AppendBool(dst, v.Bool())
+	case slog.KindTime:
+		return o.appendTime(dst, v.Time())
+	case slog.KindDuration:
+		return o.appendDuration(dst, v.Duration())
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return appendJSONString(dst, err.Error())
+		}
+		return appendJSONAny(dst, v.Any())
+	}
+	return appendJSONString(dst, v.String())
+}
+
+func (o *JSONOptions) appendTime(dst []byte, t time.Time) []byte {
+	switch o.TimeFormat {
+	case JSONTimeEpochSeconds:
+		return appendJSONFloat(dst, float64(t.UnixNano())/1e9)
+	case JSONTimeEpochMillis:
+		return strconv.AppendInt(dst, t.UnixMilli(), 10)
+	case JSONTimeEpochNanos:
+		return strconv.AppendInt(dst, t.UnixNano(), 10)
+	}
+	dst = append(dst, '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"')
+}
+
+func (o *JSONOptions) appendDuration(dst []byte, d time.Duration) []byte {
+	unit := o.DurationUnit
+	if unit <= time.Nanosecond {
+		return strconv.AppendInt(dst, int64(d), 10)
+	}
+	if d%unit == 0 {
+		return strconv.AppendInt(dst, int64(d/unit), 10)
+	}
+	return appendJSONFloat(dst, float64(d)/float64(unit))
+}
+
+// appendJSONFloat appends f as a number formatted as encoding/json does,
+// or as a string for NaN and infinities, which JSON cannot represent.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(dst, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.AppendFloat(dst, f, 'e', -1, 64)
+	}
+	return strconv.AppendFloat(dst, f, 'f', -1, 64)
+}
+
+func appendJSONString(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+// appendJSONAny appends the JSON encoding of v, or its string form if it
+// cannot be encoded.
+func appendJSONAny(dst []byte, v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(dst, slog.AnyValue(v).String())
+	}
+	return append(dst, b...)
+}
+
+// marshalEntry encodes e with opts, or with MarshalJSON if opts is nil.
+func marshalEntry(e *Entry, opts *JSONOptions) ([]byte, error) {
+	if opts == nil {
+		return json.Marshal(e)
+	}
+	return e.AppendJSON(nil, opts), nil
+}
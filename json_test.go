@@ -0,0 +1,88 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryAppendJSON(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 15, 4, 5, 500_000_000, time.UTC)
+	e := &Entry{
+		Time:    ts,
+		Level:   slog.LevelInfo,
+		Message: "typed",
+		Attrs: []slog.Attr{
+			slog.Int("n", -1),
+			slog.Uint64("u", 2),
+			slog.Float64("f", 1.5),
+			slog.Float64("nan", math.NaN()),
+			slog.Bool("ok", true),
+			slog.Time("at", ts),
+			slog.Duration("took", 1500*time.Microsecond),
+			slog.Any("err", errors.New("boom")),
+			slog.Any("tags", []string{"a", "b"}),
+			slog.String("s", "x"),
+		},
+	}
+	tests := []struct {
+		name string
+		opts *JSONOptions
+		want string
+	}{
+		{"default", nil,
+			`{"time":"2023-01-02T15:04:05.5Z","level":"INFO","msg":"typed","n":-1,"u":2,"f":1.5,"nan":"NaN","ok":true,` +
+				`"at":"2023-01-02T15:04:05.5Z","took":1500000,"err":"boom","tags":["a","b"],"s":"x"}`},
+		{"millis", &JSONOptions{TimeFormat: JSONTimeEpochMillis, DurationUnit: time.Millisecond},
+			`{"time":1672671845500,"level":"INFO","msg":"typed","n":-1,"u":2,"f":1.5,"nan":"NaN","ok":true,` +
+				`"at":1672671845500,"took":1.5,"err":"boom","tags":["a","b"],"s":"x"}`},
+		{"seconds", &JSONOptions{TimeFormat: JSONTimeEpochSeconds, DurationUnit: time.Microsecond},
+			`{"time":1672671845.5,"level":"INFO","msg":"typed","n":-1,"u":2,"f":1.5,"nan":"NaN","ok":true,` +
+				`"at":1672671845.5,"took":1500,"err":"boom","tags":["a","b"],"s":"x"}`},
+	}
+	for _, tt := range tests {
+		got := string(e.AppendJSON(nil, tt.opts))
+		if got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+		if !json.Valid([]byte(got)) {
+			t.Errorf("%s: invalid JSON %s", tt.name, got)
+		}
+	}
+}
+
+func TestEntryAppendJSONECS(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Level:   slog.LevelWarn,
+		Message: "slow",
+		Attrs:   []slog.Attr{slog.Duration("req.took", 2*time.Second)},
+	}
+	got := string(e.AppendJSON(nil, &JSONOptions{Schema: JSONSchemaECS, DurationUnit: time.Millisecond}))
+	want := `{"@timestamp":"2023-01-02T15:04:05Z","log.level":"warn","message":"slow","ecs.version":"8.11.0","labels":{"req_took":2000}}`
+	if got != want {
+		t.Errorf("AppendJSON(ECS) =\n%s\nwant\n%s", got, want)
+	}
+	e.Attrs = nil
+	if got := string(e.AppendJSON(nil, &JSONOptions{Schema: JSONSchemaECS})); strings.Contains(got, "labels") {
+		t.Errorf("empty labels written: %s", got)
+	}
+}
+
+func TestAppendJSONFloat(t *testing.T) {
+	for f, want := range map[float64]string{
+		0:           "0",
+		-2.25:       "-2.25",
+		1e21:        "1e+21",
+		1e-7:        "1e-07",
+		math.Inf(1): `"+Inf"`,
+	} {
+		if got := string(appendJSONFloat(nil, f)); got != want {
+			t.Errorf("appendJSONFloat(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
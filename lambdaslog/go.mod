@@ -0,0 +1,5 @@
+module github.com/fujiwara/sloghandler/lambdaslog
+
+go 1.25
+
+require github.com/aws/aws-lambda-go v1.49.0
@@ -0,0 +1,125 @@
+// Package lambdaslog wraps AWS Lambda handlers to log each invocation
+// through a log/slog logger.
+package lambdaslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Options contains configuration for Wrap.
+type Options struct {
+	// StartMessage is logged when an invocation starts. Default is "invocation start".
+	// Set it to "-" to skip the start record.
+	StartMessage string
+
+	// EndMessage is logged when an invocation ends. Default is "invocation end".
+	EndMessage string
+
+	// Level is the level of the start and end records. Failed invocations
+	// are logged at slog.LevelError. Default is slog.LevelInfo.
+	Level slog.Level
+}
+
+// DefaultOptions returns the default configuration options.
+func DefaultOptions() *Options {
+	return &Options{
+		StartMessage: "invocation start",
+		EndMessage:   "invocation end",
+		Level:        slog.LevelInfo,
+	}
+}
+
+// coldStart is true until the first invocation in this execution environment begins.
+var coldStart atomic.Bool
+
+func init() {
+	coldStart.Store(true)
+}
+
+type loggerKey struct{}
+
+// FromContext returns the invocation logger stored by Wrap, which carries
+// the request_id attribute. It returns slog.Default() outside a wrapped handler.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// Wrap returns a handler that logs the start and end of each invocation of
+// handler, with the request ID, cold-start flag, duration and memory usage.
+// The result can be passed to lambda.Start. A nil opts uses DefaultOptions.
+//
+//	lambda.Start(lambdaslog.Wrap(logger, handler, nil))
+//
+// Inside handler, FromContext returns a logger tagged with the request ID.
+// A panic in handler is logged and then re-raised.
+func Wrap[TIn, TOut any](logger *slog.Logger, handler func(context.Context, TIn) (TOut, error), opts *Options) func(context.Context, TIn) (TOut, error) {
+	o := DefaultOptions()
+	if opts != nil {
+		if opts.StartMessage != "" {
+			o.StartMessage = opts.StartMessage
+		}
+		if opts.EndMessage != "" {
+			o.EndMessage = opts.EndMessage
+		}
+		o.Level = opts.Level
+	}
+	return func(ctx context.Context, in TIn) (out TOut, err error) {
+		start := time.Now()
+		cold := coldStart.Swap(false)
+		l := logger
+		if lc, ok := lambdacontext.FromContext(ctx); ok {
+			l = l.With(slog.String("request_id", lc.AwsRequestID))
+		}
+		ctx = context.WithValue(ctx, loggerKey{}, l)
+		if o.StartMessage != "-" {
+			l.LogAttrs(ctx, o.Level, o.StartMessage,
+				slog.Bool("cold_start", cold),
+				slog.String("function", lambdacontext.FunctionName),
+				slog.String("version", lambdacontext.FunctionVersion),
+			)
+		}
+		defer func() {
+			level := o.Level
+			attrs := []slog.Attr{
+				slog.Bool("cold_start", cold),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			}
+			attrs = append(attrs, memoryAttrs()...)
+			r := recover()
+			if r != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("panic", fmt.Sprint(r)))
+			} else if err != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			l.LogAttrs(ctx, level, o.EndMessage, attrs...)
+			if r != nil {
+				panic(r)
+			}
+		}()
+		return handler(ctx, in)
+	}
+}
+
+// memoryAttrs reports the memory obtained from the OS by the Go runtime
+// and the function's configured memory limit, in megabytes.
+func memoryAttrs() []slog.Attr {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	attrs := []slog.Attr{slog.Uint64("memory_used_mb", m.Sys>>20)}
+	if lambdacontext.MemoryLimitInMB > 0 {
+		attrs = append(attrs, slog.Int("memory_limit_mb", lambdacontext.MemoryLimitInMB))
+	}
+	return attrs
+}
@@ -0,0 +1,102 @@
+package lambdaslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var logs []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, m)
+	}
+	buf.Reset()
+	return logs
+}
+
+func TestWrap(t *testing.T) {
+	coldStart.Store(true)
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	handler := Wrap(logger, func(ctx context.Context, name string) (string, error) {
+		FromContext(ctx).Info("hello", "name", name)
+		if name == "" {
+			return "", errors.New("name is required")
+		}
+		return "hello " + name, nil
+	}, nil)
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+	out, err := handler(ctx, "alice")
+	if err != nil || out != "hello alice" {
+		t.Fatalf("unexpected result %q %v", out, err)
+	}
+	logs := decodeLogs(t, buf)
+	if len(logs) != 3 {
+		t.Fatalf("got %d logs, want 3", len(logs))
+	}
+	for _, l := range logs {
+		if l["request_id"] != "req-1" {
+			t.Errorf("missing request_id: %v", l)
+		}
+	}
+	if logs[0]["msg"] != "invocation start" || logs[0]["cold_start"] != true {
+		t.Errorf("unexpected start record %v", logs[0])
+	}
+	end := logs[2]
+	if end["msg"] != "invocation end" || end["level"] != "INFO" || end["duration_ms"] == nil || end["memory_used_mb"] == nil {
+		t.Errorf("unexpected end record %v", end)
+	}
+
+	ctx = lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-2"})
+	if _, err := handler(ctx, ""); err == nil {
+		t.Fatal("expected error")
+	}
+	logs = decodeLogs(t, buf)
+	if logs[0]["cold_start"] != false {
+		t.Errorf("second invocation should be warm: %v", logs[0])
+	}
+	end = logs[len(logs)-1]
+	if end["level"] != "ERROR" || end["error"] != "name is required" {
+		t.Errorf("unexpected end record %v", end)
+	}
+}
+
+func TestWrapPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	handler := Wrap(logger, func(ctx context.Context, _ struct{}) (struct{}, error) {
+		panic("boom")
+	}, &Options{StartMessage: "-"})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was not re-raised")
+			}
+		}()
+		handler(context.Background(), struct{}{})
+	}()
+	logs := decodeLogs(t, buf)
+	if len(logs) != 1 || logs[0]["level"] != "ERROR" || logs[0]["panic"] != "boom" {
+		t.Errorf("unexpected logs %v", logs)
+	}
+}
+
+func TestFromContextDefault(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext should fall back to slog.Default")
+	}
+}
@@ -0,0 +1,86 @@
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// LevelTrace is the level below DEBUG for very verbose diagnostics.
+	LevelTrace = slog.LevelDebug - 4
+
+	// LevelFatal is the level treated as fatal by handlers that distinguish it,
+	// such as EmailHandler which sends records at this level immediately.
+	LevelFatal = slog.LevelError + 4
+)
+
+// LevelName returns the name of level as written by the handlers in this
+// package. It is like slog.Level.String but names LevelTrace "TRACE" and
+// LevelFatal "FATAL", with offsets relative to them (e.g. "TRACE+1").
+func LevelName(level slog.Level) string {
+	name := func(base string, offset slog.Level) string {
+		if offset == 0 {
+			return base
+		}
+		return fmt.Sprintf("%s%+d", base, offset)
+	}
+	switch {
+	case level < slog.LevelDebug:
+		return name("TRACE", level-LevelTrace)
+	case level >= LevelFatal:
+		return name("FATAL", level-LevelFatal)
+	default:
+		return level.String()
+	}
+}
+
+// ParseLevel parses a level name as produced by LevelName, case-insensitively.
+// In addition to the names accepted by slog.Level.UnmarshalText it accepts
+// "TRACE" and "FATAL" with optional offsets.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	upper := strings.ToUpper(s)
+	for _, base := range []struct {
+		name  string
+		level slog.Level
+	}{{"TRACE", LevelTrace}, {"FATAL", LevelFatal}} {
+		if rest, ok := strings.CutPrefix(upper, base.name); ok {
+			// Reuse slog's offset parsing by substituting INFO (level 0).
+			if err := l.UnmarshalText([]byte("INFO" + rest)); err != nil {
+				return 0, err
+			}
+			return l + base.level, nil
+		}
+	}
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+// Trace logs at LevelTrace.
+func Trace(logger *slog.Logger, msg string, args ...any) {
+	logAt(context.Background(), logger, LevelTrace, msg, args...)
+}
+
+// TraceContext logs at LevelTrace with the given context.
+func TraceContext(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	logAt(ctx, logger, LevelTrace, msg, args...)
+}
+
+// logAt logs like slog.Logger.Log, reporting the caller of its caller as the source.
+func logAt(ctx context.Context, logger *slog.Logger, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip [Callers, logAt, Trace]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = logger.Handler().Handle(ctx, r)
+}
@@ -0,0 +1,57 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelName(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace, "TRACE"},
+		{LevelTrace - 1, "TRACE-1"},
+		{LevelTrace + 2, "TRACE+2"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo + 1, "INFO+1"},
+		{slog.LevelError, "ERROR"},
+		{LevelFatal, "FATAL"},
+		{LevelFatal + 1, "FATAL+1"},
+	}
+	for _, tt := range tests {
+		if got := LevelName(tt.level); got != tt.want {
+			t.Errorf("LevelName(%d) = %q, want %q", tt.level, got, tt.want)
+		}
+		got, err := ParseLevel(strings.ToLower(tt.want))
+		if err != nil || got != tt.level {
+			t.Errorf("ParseLevel(%q) = %d, %v, want %d", tt.want, got, err, tt.level)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: LevelTrace, AddSource: true},
+	}))
+	Trace(logger, "entering", "fn", "parse")
+	out := buf.String()
+	if !strings.Contains(out, " [TRACE] [level_test.go:") || !strings.Contains(out, "entering [fn:parse]") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	buf.Reset()
+	logger = slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug},
+	}))
+	TraceContext(t.Context(), logger, "hidden")
+	if buf.Len() != 0 {
+		t.Errorf("trace logged at debug level: %s", buf.String())
+	}
+}
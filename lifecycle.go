@@ -0,0 +1,103 @@
+package sloghandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// LifecycleOptions configures the records emitted by Startup and Lifecycle.Shutdown.
+type LifecycleOptions struct {
+	// Version is the application version. Default is the main module version
+	// from the build info.
+	Version string
+	// Config is encoded as JSON and hashed into the "config_digest" attribute,
+	// so instances running with different configuration stand out. Optional.
+	Config any
+	// Attrs are added to the startup record.
+	Attrs []slog.Attr
+	// Closers are closed in order after the shutdown record is logged,
+	// e.g. sinks that buffer records.
+	Closers []io.Closer
+}
+
+// Lifecycle marks the startup and shutdown of a process in the log.
+type Lifecycle struct {
+	logger  *slog.Logger
+	start   time.Time
+	closers []io.Closer
+	once    sync.Once
+	err     error
+}
+
+// Startup logs a standardized "startup" record with the version, config
+// digest, Go version, host name and pid, and returns a Lifecycle whose
+// Shutdown logs the matching "shutdown" record.
+//
+//	lc := sloghandler.Startup(logger, &sloghandler.LifecycleOptions{
+//		Config:  cfg,
+//		Closers: []io.Closer{httpSink},
+//	})
+//	defer lc.Shutdown("exit")
+func Startup(logger *slog.Logger, opts *LifecycleOptions) *Lifecycle {
+	o := LifecycleOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Version == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			o.Version = bi.Main.Version
+		}
+	}
+	l := &Lifecycle{logger: logger, start: time.Now(), closers: o.Closers}
+	host, _ := os.Hostname()
+	attrs := []slog.Attr{
+		slog.String("version", o.Version),
+		slog.String("go_version", runtime.Version()),
+		slog.String("host", host),
+		slog.Int("pid", os.Getpid()),
+	}
+	if o.Config != nil {
+		attrs = append(attrs, slog.String("config_digest", configDigest(o.Config)))
+	}
+	attrs = append(attrs, o.Attrs...)
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "startup", attrs...)
+	return l
+}
+
+// Shutdown logs a "shutdown" record with reason and uptime, then closes the
+// closers so pending records are delivered. Only the first call has an effect;
+// later calls return the same error.
+func (l *Lifecycle) Shutdown(reason string) error {
+	l.once.Do(func() {
+		l.logger.LogAttrs(context.Background(), slog.LevelInfo, "shutdown",
+			slog.String("reason", reason),
+			slog.Duration("uptime", time.Since(l.start)),
+		)
+		var errs []error
+		for _, c := range l.closers {
+			errs = append(errs, c.Close())
+		}
+		l.err = errors.Join(errs...)
+	})
+	return l.err
+}
+
+// configDigest returns a short SHA-256 digest of the JSON encoding of config.
+func configDigest(config any) string {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "invalid:" + err.Error()
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,58 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestLifecycle(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	closed := 0
+	lc := Startup(logger, &LifecycleOptions{
+		Version: "v1.2.3",
+		Config:  map[string]any{"port": 8080},
+		Attrs:   []slog.Attr{slog.String("env", "prod")},
+		Closers: []io.Closer{closerFunc(func() error {
+			closed++
+			return errors.New("flush failed")
+		})},
+	})
+
+	var startup map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &startup); err != nil {
+		t.Fatal(err)
+	}
+	if startup["msg"] != "startup" || startup["version"] != "v1.2.3" || startup["env"] != "prod" ||
+		startup["config_digest"] != configDigest(map[string]any{"port": 8080}) || startup["pid"] == nil {
+		t.Errorf("unexpected startup record %v", startup)
+	}
+	if d := configDigest(map[string]any{"port": 8081}); d == startup["config_digest"] {
+		t.Error("different configs should have different digests")
+	}
+
+	buf.Reset()
+	if err := lc.Shutdown("SIGTERM"); err == nil || err.Error() != "flush failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	var shutdown map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &shutdown); err != nil {
+		t.Fatal(err)
+	}
+	if shutdown["msg"] != "shutdown" || shutdown["reason"] != "SIGTERM" || shutdown["uptime"] == nil {
+		t.Errorf("unexpected shutdown record %v", shutdown)
+	}
+
+	buf.Reset()
+	if err := lc.Shutdown("again"); err == nil || buf.Len() != 0 || closed != 1 {
+		t.Errorf("second Shutdown should be a no-op returning the same error")
+	}
+}
@@ -0,0 +1,68 @@
+package sloghandler
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// fileMap associates values with files without keeping the files
+// reachable: the entry of a file is removed once the file is garbage
+// collected, so handlers created for rotated or temporary files do not
+// accumulate. Values must not refer to their file.
+type fileMap[V any] struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[os.File]]V
+}
+
+// loadOrStore returns the value of f, storing the result of newValue if
+// there is none.
+func (m *fileMap[V]) loadOrStore(f *os.File, newValue func() V) V {
+	key := weak.Make(f)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.m[key]; ok {
+		return v
+	}
+	if m.m == nil {
+		m.m = make(map[weak.Pointer[os.File]]V)
+	}
+	v := newValue()
+	m.m[key] = v
+	runtime.AddCleanup(f, m.delete, key)
+	return v
+}
+
+func (m *fileMap[V]) delete(key weak.Pointer[os.File]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.m, key)
+}
+
+func (m *fileMap[V]) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.m)
+}
+
+// fileLocks holds one mutex per *os.File, so that independent handlers
+// writing to the same file, such as os.Stderr, do not interleave records.
+var fileLocks fileMap[*sync.Mutex]
+
+// lockFor returns the mutex guarding writes to w, or nil if w needs none.
+// Handlers derived with WithAttrs share the lock of their parent.
+func lockFor(w io.Writer) *sync.Mutex {
+	switch w := w.(type) {
+	case *os.File:
+		return fileLocks.loadOrStore(w, func() *sync.Mutex { return new(sync.Mutex) })
+	case *RotatingFile, *DailyFile, *CoalescingWriter, *DatagramWriter:
+		// These writers serialize their own writes.
+		return nil
+	}
+	if w == io.Discard {
+		return nil
+	}
+	return new(sync.Mutex)
+}
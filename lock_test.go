@@ -0,0 +1,109 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"weak"
+)
+
+func TestColorSingleWrite(t *testing.T) {
+	rw := &recordingWriter{}
+	logger := slog.New(NewLogHandler(rw, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Color:          true,
+	}))
+	logger.Warn("one")
+	logger.Error("two")
+	got := rw.Writes()
+	if len(got) != 2 {
+		t.Fatalf("got %d writes, want one per record: %q", len(got), got)
+	}
+	if !strings.HasPrefix(got[0], "\x1b[33m") || !strings.HasSuffix(got[0], "\x1b[0m") {
+		t.Errorf("write is not colored as a whole: %q", got[0])
+	}
+}
+
+func TestSharedFileLock(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	opts := &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}
+	h1 := NewLogHandler(f, opts).(*logHandler)
+	h2 := NewLogHandler(f, opts).(*logHandler)
+	if h1.mu == nil || h1.mu != h2.mu {
+		t.Error("handlers writing to the same file should share a lock")
+	}
+	if h := NewLogHandler(&recordingWriter{}, opts).(*logHandler); h.mu == nil || h.mu == h1.mu {
+		t.Error("other writers should get their own lock")
+	}
+	if h := NewLogHandler(NewCoalescingWriter(f, 0, 0), opts).(*logHandler); h.mu != nil {
+		t.Error("writers that serialize their own writes need no lock")
+	}
+	if h := NewBinaryHandler(f, MessagePack, nil); h.mu != h1.mu {
+		t.Error("binary handlers should share the lock of the file")
+	}
+
+	var wg sync.WaitGroup
+	for _, h := range []slog.Handler{h1, h2.WithAttrs([]slog.Attr{slog.Int("n", 2)})} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger := slog.New(h)
+			for range 200 {
+				logger.Info(strings.Repeat("x", 100))
+			}
+		}()
+	}
+	wg.Wait()
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		if !strings.HasSuffix(line, strings.Repeat("x", 100)) {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestFileLockReleased(t *testing.T) {
+	dir := t.TempDir()
+	has := func(key weak.Pointer[os.File]) bool {
+		fileLocks.mu.Lock()
+		defer fileLocks.mu.Unlock()
+		_, ok := fileLocks.m[key]
+		return ok
+	}
+	var keys []weak.Pointer[os.File]
+	for i := range 10 {
+		f, err := os.Create(filepath.Join(dir, strconv.Itoa(i)+".log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		slog.New(NewLogHandler(f, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}})).Info("hello")
+		key := weak.Make(f)
+		if !has(key) {
+			t.Fatal("no lock stored for the file")
+		}
+		keys = append(keys, key)
+		f.Close()
+	}
+	for range 100 {
+		runtime.GC()
+		if !slices.ContainsFunc(keys, has) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("locks of unreachable files are kept")
+}
@@ -0,0 +1,24 @@
+// Package logradapter exposes a log/slog handler chain as a logr.LogSink, so
+// Kubernetes controllers and other logr-based libraries emit into the same
+// colored, metrics-instrumented pipeline as the rest of the application.
+package logradapter
+
+import (
+	"log/slog"
+
+	"github.com/go-logr/logr"
+)
+
+// NewLogSink returns a logr.LogSink that writes to h.
+//
+// logr verbosity V(n) maps to slog.Level(-n), so V(0) is INFO and V(4) is DEBUG.
+// Errors are logged at ERROR with the error under the "err" key.
+// Logger names are joined with "/" and added as the "logger" attribute.
+func NewLogSink(h slog.Handler) logr.LogSink {
+	return logr.FromSlogHandler(h).GetSink()
+}
+
+// NewLogger returns a logr.Logger that writes to h.
+func NewLogger(h slog.Handler) logr.Logger {
+	return logr.FromSlogHandler(h)
+}
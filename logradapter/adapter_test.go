@@ -0,0 +1,38 @@
+package logradapter
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := NewLogger(h).WithName("controller").WithValues("namespace", "default")
+
+	logger.Info("reconciling", "pod", "web-1")
+	logger.V(4).Info("details")
+	logger.V(5).Info("too verbose")
+	logger.Error(errors.New("boom"), "reconcile failed")
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=INFO msg=reconciling namespace=default logger=controller pod=web-1`,
+		`level=DEBUG msg=details`,
+		`level=ERROR msg="reconcile failed"`,
+		`err=boom`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "too verbose") {
+		t.Error("V(5) should be below DEBUG")
+	}
+	if NewLogSink(h) == nil {
+		t.Error("NewLogSink returned nil")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/fujiwara/sloghandler/logradapter
+
+go 1.25
+
+require github.com/go-logr/logr v1.4.3
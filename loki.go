@@ -0,0 +1,204 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiOptions configures a LokiHandler.
+type LokiOptions struct {
+	// Level is the minimum level shipped. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// Labels are attached to every stream, e.g. {"app": "api"}.
+	// A "level" label with the lower-cased level name is always added.
+	Labels map[string]string
+	// JSON selects the type-preserving encoding of log lines, see HTTPOptions.JSON.
+	JSON *JSONOptions
+	// Header is added to every request, e.g. for Authorization or X-Scope-OrgID.
+	Header http.Header
+	// Compressor compresses push requests, setting Content-Encoding. Loki
+	// accepts gzip. Default is no compression.
+	Compressor Compressor
+	// Retry controls retries of failed requests. Default is DefaultRetryPolicy.
+	Retry *RetryPolicy
+	// Client is used to send requests. Default is a client with a 30 second timeout.
+	Client *http.Client
+	// BatchSize is the maximum number of records per push. Default is 500.
+	BatchSize int
+	// Interval is the maximum time a record waits before being pushed. Default is 5 seconds.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be pushed. Default is 10000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// OnError is called when a batch cannot be delivered. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+	// Spool keeps push requests on disk while Loki is unavailable and
+	// replays them in order once it accepts pushes again. Default is no
+	// spool. The handler does not close it.
+	Spool *Spool
+}
+
+// LokiHandler is a slog.Handler that pushes batches of records to Grafana Loki.
+// Each record is sent as a JSON line in a stream labeled by its level.
+type LokiHandler struct {
+	opts   LokiOptions
+	queue  *batcher[*Entry]
+	health *sinkHealth
+	scope  attrScope
+	sender *httpSender
+}
+
+// NewLokiHandler creates a handler that pushes records to the Loki server at url,
+// e.g. "http://localhost:3100". The push API path is appended when url has no path.
+func NewLokiHandler(url string, opts *LokiOptions) (*LokiHandler, error) {
+	o := LokiOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.Retry == nil {
+		p := DefaultRetryPolicy
+		o.Retry = &p
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("loki")
+	}
+	header := o.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Type", "application/json")
+	if o.Compressor != nil && o.Compressor.Encoding() != "" {
+		header.Set("Content-Encoding", o.Compressor.Encoding())
+	}
+	h := &LokiHandler{opts: o}
+	h.sender = &httpSender{name: "loki", url: lokiPushURL(url), header: header, client: o.Client, retry: o.Retry}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(entries []*Entry) {
+		h.health.report(h.push(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	h.queue.spill = o.Spool.spill(h.body)
+	return h, nil
+}
+
+func (h *LokiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *LokiHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.queue.add(ctx, newEntry(record, h.scope))
+}
+
+func (h *LokiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *LokiHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush pushes all queued records and replays the spool, if any.
+func (h *LokiHandler) Flush() error {
+	h.queue.Flush()
+	return h.opts.Spool.replay(context.Background(), h.sender)
+}
+
+// Close pushes pending records and stops the handler.
+func (h *LokiHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *LokiHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *LokiHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+// lokiPushURL appends the push API path to a server URL without a path.
+func lokiPushURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return s
+	}
+	u.Path = "/loki/api/v1/push"
+	return u.String()
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// body encodes entries as a push request with one stream per level,
+// compressed if configured.
+func (h *LokiHandler) body(entries []*Entry) ([]byte, error) {
+	var streams []*lokiStream
+	byLevel := make(map[slog.Level]*lokiStream)
+	for _, e := range entries {
+		s, ok := byLevel[e.Level]
+		if !ok {
+			labels := make(map[string]string, len(h.opts.Labels)+1)
+			for k, v := range h.opts.Labels {
+				labels[k] = v
+			}
+			labels["level"] = strings.ToLower(LevelName(e.Level))
+			s = &lokiStream{Stream: labels}
+			byLevel[e.Level] = s
+			streams = append(streams, s)
+		}
+		line, err := marshalEntry(e, h.opts.JSON)
+		if err != nil {
+			return nil, err
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), string(line)})
+	}
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	if err != nil {
+		return nil, err
+	}
+	return compress(h.opts.Compressor, body)
+}
+
+func (h *LokiHandler) push(entries []*Entry) error {
+	body, err := h.body(entries)
+	if err != nil {
+		return err
+	}
+	return h.opts.Spool.deliver(context.Background(), body, h.sender)
+}
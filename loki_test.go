@@ -0,0 +1,61 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLokiHandler(t *testing.T) {
+	type push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	var got push
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	h, err := NewLokiHandler(ts.URL, &LokiOptions{
+		Labels:   map[string]string{"app": "api"},
+		Interval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h).With("req", 1)
+	logger.Info("one")
+	logger.Error("two")
+	logger.Info("three")
+	h.Close()
+
+	if len(got.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2: %+v", len(got.Streams), got)
+	}
+	info := got.Streams[0]
+	if info.Stream["app"] != "api" || info.Stream["level"] != "info" || len(info.Values) != 2 {
+		t.Errorf("unexpected info stream %+v", info)
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(info.Values[1][1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "three" || line["req"] != "1" {
+		t.Errorf("unexpected line %v", line)
+	}
+	if got.Streams[1].Stream["level"] != "error" {
+		t.Errorf("unexpected error stream %+v", got.Streams[1])
+	}
+}
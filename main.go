@@ -6,20 +6,30 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 )
 
+const defaultTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
 var (
 	// TimeFormat defines the timestamp format used in log output.
 	// Default is RFC3339 with milliseconds.
-	TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+	TimeFormat = defaultTimeFormat
 
 	// DebugColor defines the color attribute for DEBUG level messages.
 	// Default is dark gray (color.FgHiBlack).
 	DebugColor = color.FgHiBlack
 
+	// TraceColor defines the color attribute for TRACE level messages.
+	// Default is faint (color.Faint).
+	TraceColor = color.Faint
+
 	// InfoColor defines the color attribute for INFO level messages.
 	// Default is 0 (no color). Set to a color.Attribute value to enable coloring.
 	InfoColor color.Attribute
@@ -34,12 +44,10 @@ var (
 )
 
 var (
-	debugColoredFprintFunc = color.New(DebugColor).FprintFunc()
-	warnColoredFprintFunc  = color.New(WarnColor).FprintFunc()
-	errorColoredFprintFunc = color.New(ErrorColor).FprintFunc()
-	defaultFprintFunc      = func(w io.Writer, args ...interface{}) {
-		fmt.Fprint(w, args...)
-	}
+	traceColored = color.New(TraceColor)
+	debugColored = color.New(DebugColor)
+	warnColored  = color.New(WarnColor)
+	errorColored = color.New(ErrorColor)
 )
 
 // HandlerOptions extends slog.HandlerOptions with additional formatting options.
@@ -55,110 +63,416 @@ type HandlerOptions struct {
 	// Default is 0 (filename only). Set to 1 for parent/file.go, 2 for grandparent/parent/file.go, etc.
 	// Negative values default to 0.
 	SourceDepth int
+	// SourceRelativeTo, if set, shows source files under this directory as
+	// paths relative to it, such as "./cmd/api/main.go", so editors can jump
+	// to them. A relative directory such as "." is resolved against the working
+	// directory when the handler is created. Files outside it fall back to SourceDepth.
+	SourceRelativeTo string
+	// CallerSkip skips this many additional stack frames when reporting the
+	// source location, so records logged through wrapper helpers point at the
+	// helper's caller. It applies while the record is handled on the logging
+	// goroutine. See also CallerPC.
+	CallerSkip int
+	// Compact omits the timestamp and level of INFO records, keeping them for
+	// other levels, in the minimal style of CLI tools. Compact INFO lines
+	// cannot be read back by Parse.
+	Compact bool
+	// FitTerminal truncates or wraps lines to the terminal width when writing
+	// to a terminal. Output redirected to files or pipes keeps full lines.
+	FitTerminal TerminalFit
+	// WrapAttrs, if positive, renders records with more than this many
+	// attributes on several lines: the message first, then each attribute
+	// on its own line indented by a tab. Wrapped output is meant for
+	// terminals; Parse and Scanner read single-line records only.
+	WrapAttrs int
+	// SourceCacheSize bounds the number of call sites whose rendered source
+	// location is cached. Default is 4096; a negative value disables caching.
+	// See SourceCacheStats.
+	SourceCacheSize int
+	// MaxLen truncates attribute values longer than this many terminal
+	// columns, ending them with "…". Wide characters count as two columns.
+	// Default is 0 (no limit).
+	MaxLen int
+	// MessageWidth pads messages to this many terminal columns so that
+	// attributes line up. Default is 0 (no padding).
+	MessageWidth int
+	// Translate, if set, is applied to each message before rendering, with the
+	// record's attributes, so operator-facing messages can be localized centrally.
+	// Return msg unchanged for messages without a translation.
+	Translate func(msg string, attrs []slog.Attr) string
+	// ColorRules color lines by attribute value when Color is true,
+	// taking precedence over the level color. See ColorRule.
+	ColorRules []ColorRule
+	// Now, if set, replaces the record time when formatting, so tests can
+	// assert on exact output. See FrozenClock.
+	Now func() time.Time
+	// PanicStack renders Stack attributes, such as those added by LogPanic,
+	// as an indented block below the log line instead of inline.
+	PanicStack bool
+	// GroupNamespace honors groups opened with WithGroup, rendering each as
+	// a namespace token such as "http ▸" before the first attribute in it,
+	// dimmed when Color is true. Without it, WithGroup has no effect on the
+	// output. Lines with namespaces cannot be read back by Parse.
+	GroupNamespace bool
+	// Format selects text (default) or JSON output. With FormatJSON, groups
+	// are joined to keys by dots and Color colors whole lines by level.
+	Format Format
+	// JSON configures the JSON output of FormatJSON. Default is the
+	// defaults of JSONOptions.
+	JSON *JSONOptions
+	// Async, if set, makes Handle queue formatted records for a background
+	// goroutine that writes them, so that logging does not wait for slow
+	// writers. The handler then implements Syncer and io.Closer; close it
+	// on shutdown to write the queued records.
+	Async *AsyncOptions
 }
 
 type logHandler struct {
 	opts         *HandlerOptions
 	preformatted []byte
-	mu           *sync.Mutex
+	preOffsets   []int       // start of each attribute in preformatted
+	mu           *sync.Mutex // guards w; nil if w is safe for concurrent use
 	w            io.Writer
-	sourceCache  sync.Map // Cache for formatted source file paths, keyed by path and depth
+	sources      *sourceCache        // rendered source fragments; nil if caching is disabled
+	sourceRoot   string              // absolute SourceRelativeTo
+	term         *terminal           // non-nil if w is a terminal
+	groups       []string            // groups opened with WithGroup, if GroupNamespace
+	shown        int                 // number of groups already rendered in preformatted
+	scope        attrScope           // attributes and groups, if FormatJSON
+	async        *batcher[asyncLine] // queue of the background writer, if Async
+	infoColored  *color.Color        // color of INFO lines; nil if InfoColor is unset
+	ruleColors   []*color.Color      // colors of ColorRules, by index
 }
 
 // NewLogHandler creates a new log handler that writes formatted log messages to w.
 // The handler supports colored output when opts.Color is true, with customizable
 // colors for each log level via global color variables.
+//
+// On a Windows console, colors are shown by enabling virtual terminal
+// processing; where that is not supported, output is written without color.
 func NewLogHandler(w io.Writer, opts *HandlerOptions) slog.Handler {
-	return &logHandler{
-		opts: opts,
-		mu:   new(sync.Mutex),
-		w:    w,
+	term := terminalFor(w)
+	if opts.Color && term != nil && term.plain {
+		o := *opts
+		o.Color = false
+		opts = &o
+	}
+	h := &logHandler{
+		opts:       opts,
+		mu:         lockFor(w),
+		w:          w,
+		sources:    newSourceCache(opts.SourceCacheSize),
+		sourceRoot: absDir(opts.SourceRelativeTo),
+		term:       term,
+	}
+	if opts.Color {
+		if InfoColor != 0 {
+			h.infoColored = color.New(InfoColor)
+		}
+		for _, rule := range opts.ColorRules {
+			h.ruleColors = append(h.ruleColors, color.New(rule.Color))
+		}
+	}
+	if opts.Async != nil {
+		h.startAsync(*opts.Async)
 	}
+	return h
 }
 
 func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.opts.Level.Level()
+	return level >= minLevel(ctx, h.opts.Level)
 }
 
-func (h *logHandler) FprintFunc(level slog.Level) func(io.Writer, ...interface{}) {
-	if h.opts.Color {
-		switch level {
-		case slog.LevelDebug:
-			return debugColoredFprintFunc
-		case slog.LevelInfo:
-			if InfoColor != 0 {
-				return color.New(InfoColor).FprintFunc()
-			}
-		case slog.LevelWarn:
-			return warnColoredFprintFunc
-		case slog.LevelError:
-			return errorColoredFprintFunc
-		}
+// lineColor returns the color of a record line at level, that of the color
+// rule at index rule if it is not negative, or nil for no color.
+func (h *logHandler) lineColor(level slog.Level, rule int) *color.Color {
+	if !h.opts.Color {
+		return nil
+	}
+	if rule >= 0 {
+		return h.ruleColors[rule]
+	}
+	switch level {
+	case LevelTrace:
+		return traceColored
+	case slog.LevelDebug:
+		return debugColored
+	case slog.LevelInfo:
+		return h.infoColored
+	case slog.LevelWarn:
+		return warnColored
+	case slog.LevelError:
+		return errorColored
+	}
+	return nil
+}
+
+// levelTokens caches the " [LEVEL]" tokens of the named levels.
+var levelTokens = map[slog.Level]string{
+	LevelTrace:      " [TRACE]",
+	slog.LevelDebug: " [DEBUG]",
+	slog.LevelInfo:  " [INFO]",
+	slog.LevelWarn:  " [WARN]",
+	slog.LevelError: " [ERROR]",
+	LevelFatal:      " [FATAL]",
+}
+
+// levelToken returns the level token of the text format, such as " [INFO]".
+func levelToken(level slog.Level) string {
+	if s, ok := levelTokens[level]; ok {
+		return s
 	}
-	return defaultFprintFunc
+	return " [" + LevelName(level) + "]"
 }
 
 func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
-	buf := new(bytes.Buffer)
+	if h.opts.Format == FormatJSON {
+		return h.handleJSON(ctx, record)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
 
 	// Build the log message without color formatting
-	fmt.Fprintf(buf, "%s", record.Time.Format(TimeFormat))
-	fmt.Fprintf(buf, " [%s]", record.Level.String())
+	t := record.Time
+	if h.opts.Now != nil {
+		t = h.opts.Now()
+	}
+	compact := h.opts.Compact && record.Level >= slog.LevelInfo && record.Level < slog.LevelWarn
+	if !compact {
+		buf.Write(t.AppendFormat(buf.AvailableBuffer(), TimeFormat))
+		buf.WriteString(levelToken(record.Level))
+	}
 
-	if len(h.preformatted) > 0 {
+	transient := h.term != nil && !h.term.plain && recordTransient(record)
+	wrap := !transient && h.opts.WrapAttrs > 0 && len(h.preOffsets)+record.NumAttrs() > h.opts.WrapAttrs
+	if len(h.preformatted) > 0 && !wrap {
 		buf.Write(h.preformatted)
 	}
 
-	if h.opts.Source || h.opts.AddSource {
+	override := recordSource(record)
+	if override != nil {
+		buf.Write(h.sourceFragment(override))
+	} else if h.opts.Source || h.opts.AddSource {
+		if h.opts.CallerSkip > 0 && record.PC != 0 {
+			record.PC = skipCallers(record.PC, h.opts.CallerSkip)
+		}
 		h.printSource(buf, record)
 	}
 
-	fmt.Fprintf(buf, " %s", record.Message)
+	msg := record.Message
+	if h.opts.Translate != nil {
+		attrs := make([]slog.Attr, 0, record.NumAttrs())
+		record.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		})
+		msg = h.opts.Translate(msg, attrs)
+	}
+	msg = escapeControl(StripANSI(msg))
+	if h.opts.MessageWidth > 0 {
+		msg = PadWidth(msg, h.opts.MessageWidth)
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+	if wrap {
+		for i, start := range h.preOffsets {
+			end := len(h.preformatted)
+			if i+1 < len(h.preOffsets) {
+				end = h.preOffsets[i+1]
+			}
+			buf.WriteString("\n\t")
+			buf.Write(h.preformatted[start+1 : end]) // without the leading space
+		}
+	}
 
+	var stacks []Stack
+	rule := -1
+	pending := h.namespace()
 	record.Attrs(func(a slog.Attr) bool {
-		if a.Key == "" {
-			fmt.Fprintf(buf, " [%v]", a.Value)
+		if h.opts.Color && len(h.opts.ColorRules) > 0 {
+			if i := matchColorRule(h.opts.ColorRules, a); i >= 0 && (rule < 0 || i < rule) {
+				rule = i
+			}
+		}
+		if override != nil {
+			if _, ok := sourceOverride(a); ok {
+				return true
+			}
+		}
+		if _, ok := isTransientAttr(a); ok {
+			return true
+		}
+		if h.opts.PanicStack && a.Value.Kind() == slog.KindAny {
+			if s, ok := a.Value.Any().(Stack); ok {
+				stacks = append(stacks, s)
+				return true
+			}
+		}
+		if wrap {
+			buf.WriteString("\n\t")
 		} else {
-			fmt.Fprintf(buf, " [%s:%v]", a.Key, a.Value)
+			buf.WriteByte(' ')
+		}
+		if pending != "" {
+			buf.WriteString(pending)
+			buf.WriteByte(' ')
+			pending = ""
 		}
+		h.writeAttr(buf, a)
 		return true
 	})
 
-	buf.WriteByte('\n')
+	if !transient {
+		buf.WriteByte('\n')
+	}
+	for _, s := range stacks {
+		writeStack(buf, s)
+	}
 
 	// Apply color only once at the end if needed
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	var err error
-	if h.opts.Color {
-		fprint := h.FprintFunc(record.Level)
-		fprint(h.w, buf.String())
-	} else {
-		// Write the buffer directly without color formatting
-		_, err = h.w.Write(buf.Bytes())
+	out := buf.Bytes()
+	if compact {
+		out = bytes.TrimPrefix(out, []byte(" "))
+	}
+	if h.term != nil && (transient || h.opts.FitTerminal != FitNone) {
+		// A transient line longer than the terminal could not be redrawn in place.
+		fit := h.opts.FitTerminal
+		if transient {
+			fit = FitTruncate
+		}
+		out = fitLines(out, h.term.width(), fit)
+	}
+	if c := h.lineColor(record.Level, rule); c != nil {
+		colored := getBuffer()
+		defer putBuffer(colored)
+		c.SetWriter(colored)
+		colored.Write(out)
+		c.UnsetWriter(colored)
+		out = colored.Bytes()
 	}
-	return err
+	return h.write(ctx, out, transient)
 }
 
 func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.opts.Format == FormatJSON {
+		h2 := *h
+		h2.scope = h.scope.withAttrs(attrs)
+		return &h2
+	}
 	preformatted := make([]byte, len(h.preformatted))
 	copy(preformatted, h.preformatted)
-	for _, a := range attrs {
-		if a.Key == "" {
-			preformatted = append(preformatted, fmt.Sprintf(" [%v]", a.Value)...)
-		} else {
-			// Preformat the attribute key-value pair
-			preformatted = append(preformatted, fmt.Sprintf(" [%s:%v]", a.Key, a.Value)...)
+	buf := bytes.NewBuffer(preformatted)
+	preOffsets := slices.Clip(h.preOffsets)
+	shown := h.shown
+	for i, a := range attrs {
+		// Preformat the attribute key-value pair
+		preOffsets = append(preOffsets, buf.Len())
+		if i == 0 && shown < len(h.groups) {
+			buf.WriteByte(' ')
+			buf.WriteString(h.namespace())
+			shown = len(h.groups)
 		}
+		h.appendAttr(buf, a)
 	}
+	preformatted = buf.Bytes()
 	return &logHandler{
 		opts:         h.opts,
 		preformatted: preformatted,
+		preOffsets:   preOffsets,
 		mu:           h.mu,
 		w:            h.w,
+		sources:      h.sources,
+		sourceRoot:   h.sourceRoot,
+		term:         h.term,
+		groups:       h.groups,
+		shown:        shown,
+		async:        h.async,
+		infoColored:  h.infoColored,
+		ruleColors:   h.ruleColors,
 	}
 }
 
 func (h *logHandler) WithGroup(group string) slog.Handler {
-	return h
+	if h.opts.Format == FormatJSON && group != "" {
+		h2 := *h
+		h2.scope = h.scope.withGroup(group)
+		return &h2
+	}
+	if !h.opts.GroupNamespace || group == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), group)
+	return &h2
+}
+
+// namespace returns the token of the groups opened since attributes were
+// last added with WithAttrs, such as "http ▸ req ▸", or "" if there are none.
+func (h *logHandler) namespace() string {
+	if h.shown >= len(h.groups) {
+		return ""
+	}
+	var sb strings.Builder
+	for i, g := range h.groups[h.shown:] {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(escapeControl(g))
+		sb.WriteString(" ▸")
+	}
+	if h.opts.Color {
+		// Restore normal intensity only, keeping the color of the line.
+		return "\x1b[2m" + sb.String() + "\x1b[22m"
+	}
+	return sb.String()
+}
+
+// appendAttr writes a as " [key:value]", or " [value]" for an empty key.
+func (h *logHandler) appendAttr(buf *bytes.Buffer, a slog.Attr) {
+	buf.WriteByte(' ')
+	h.writeAttr(buf, a)
+}
+
+// writeAttr writes a as "[key:value]", or "[value]" for an empty key.
+func (h *logHandler) writeAttr(buf *bytes.Buffer, a slog.Attr) {
+	// A LogValuer is shown as its resolved value, unless it is also a
+	// Stringer, whose text form is meant for humans.
+	val := a.Value
+	if val.Kind() == slog.KindLogValuer {
+		if _, ok := val.Any().(fmt.Stringer); !ok {
+			val = val.Resolve()
+		}
+	}
+	buf.WriteByte('[')
+	if a.Key != "" {
+		buf.WriteString(escapeControl(a.Key))
+		buf.WriteByte(':')
+	}
+	h.writeValue(buf, val)
+	buf.WriteByte(']')
+}
+
+// writeValue writes the text of v. Numbers and booleans are appended in
+// place, without the allocations of v.String.
+func (h *logHandler) writeValue(buf *bytes.Buffer, v slog.Value) {
+	var b []byte
+	switch v.Kind() {
+	case slog.KindInt64:
+		b = strconv.AppendInt(buf.AvailableBuffer(), v.Int64(), 10)
+	case slog.KindUint64:
+		b = strconv.AppendUint(buf.AvailableBuffer(), v.Uint64(), 10)
+	case slog.KindFloat64:
+		b = strconv.AppendFloat(buf.AvailableBuffer(), v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		b = strconv.AppendBool(buf.AvailableBuffer(), v.Bool())
+	}
+	if b != nil && (h.opts.MaxLen <= 0 || len(b) <= h.opts.MaxLen) {
+		buf.Write(b)
+		return
+	}
+	s := escapeControl(StripANSI(v.String()))
+	if h.opts.MaxLen > 0 {
+		s = TruncateWidth(s, h.opts.MaxLen, "…")
+	}
+	buf.WriteString(s)
 }
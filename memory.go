@@ -0,0 +1,193 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MemoryOptions configures a MemoryHandler.
+type MemoryOptions struct {
+	// Level is the minimum level retained. Default is slog.LevelDebug.
+	Level slog.Leveler
+	// Size is the number of most recent records retained. Default is 1000.
+	Size int
+}
+
+// Query selects records from a MemoryHandler. The zero Query matches every record.
+type Query struct {
+	// Level is the minimum level matched. nil matches all levels.
+	Level slog.Leveler
+	// Since and Until restrict the record time to [Since, Until). Zero values are unbounded.
+	Since time.Time
+	Until time.Time
+	// Attrs matches records whose attributes have all of the given string values.
+	Attrs map[string]string
+	// Limit returns at most the Limit most recent matches. 0 means no limit.
+	Limit int
+}
+
+// Match reports whether e matches the query.
+func (q *Query) Match(e *Entry) bool {
+	if q.Level != nil && e.Level < q.Level.Level() {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	for k, want := range q.Attrs {
+		v, ok := e.Attr(k)
+		if !ok || v.String() != want {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryStore is a fixed-size ring buffer of entries shared by a MemoryHandler
+// and the handlers derived from it.
+type memoryStore struct {
+	mu          sync.RWMutex
+	entries     []*Entry
+	next        int
+	full        bool
+	subscribers map[chan *Entry]struct{}
+}
+
+func (s *memoryStore) add(e *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = e
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- e:
+		default: // slow subscriber, drop
+		}
+	}
+}
+
+// snapshot returns the retained entries, oldest first.
+func (s *memoryStore) snapshot() []*Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.full {
+		return append([]*Entry(nil), s.entries[:s.next]...)
+	}
+	out := make([]*Entry, 0, len(s.entries))
+	out = append(out, s.entries[s.next:]...)
+	return append(out, s.entries[:s.next]...)
+}
+
+// Subscribe returns a channel that receives records as they are handled, and
+// a function to stop the subscription. Records are dropped for subscribers
+// that do not keep up with the buffer of size n.
+func (h *MemoryHandler) Subscribe(n int) (<-chan *Entry, func()) {
+	ch := make(chan *Entry, n)
+	s := h.store
+	s.mu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan *Entry]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, ch)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// MemoryHandler is a slog.Handler that retains the most recent records in
+// memory and provides query methods, e.g. for a /debug/logs endpoint.
+// It is safe for concurrent use.
+type MemoryHandler struct {
+	opts  MemoryOptions
+	store *memoryStore
+	scope attrScope
+}
+
+// NewMemoryHandler creates a handler that retains the most recent records.
+func NewMemoryHandler(opts *MemoryOptions) *MemoryHandler {
+	o := MemoryOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelDebug
+	}
+	if o.Size <= 0 {
+		o.Size = 1000
+	}
+	return &MemoryHandler{
+		opts:  o,
+		store: &memoryStore{entries: make([]*Entry, o.Size)},
+	}
+}
+
+func (h *MemoryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *MemoryHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.store.add(newEntry(record, h.scope))
+	return nil
+}
+
+func (h *MemoryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *MemoryHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Len returns the number of retained records.
+func (h *MemoryHandler) Len() int {
+	h.store.mu.RLock()
+	defer h.store.mu.RUnlock()
+	if h.store.full {
+		return len(h.store.entries)
+	}
+	return h.store.next
+}
+
+// Records returns the retained records matching q, oldest first.
+// The returned entries must not be modified.
+func (h *MemoryHandler) Records(q Query) []*Entry {
+	var out []*Entry
+	for _, e := range h.store.snapshot() {
+		if q.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// WriteJSON writes the records matching q to w as a JSON array.
+func (h *MemoryHandler) WriteJSON(w io.Writer, q Query) error {
+	entries := h.Records(q)
+	if entries == nil {
+		entries = []*Entry{}
+	}
+	return json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,66 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestMemoryHandler(t *testing.T) {
+	h := NewMemoryHandler(&MemoryOptions{Size: 3})
+	logger := slog.New(h)
+	logger.Debug("dropped by ring")
+	logger.Info("one", "user", "alice")
+	logger.With("user", "bob").Warn("two")
+	logger.Error("three", "user", "alice")
+
+	if h.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", h.Len())
+	}
+	msgs := func(entries []*Entry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Message)
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"one", "two", "three"}},
+		{"level", Query{Level: slog.LevelWarn}, []string{"two", "three"}},
+		{"attr", Query{Attrs: map[string]string{"user": "alice"}}, []string{"one", "three"}},
+		{"with attr", Query{Attrs: map[string]string{"user": "bob"}}, []string{"two"}},
+		{"limit", Query{Limit: 1}, []string{"three"}},
+		{"until", Query{Until: time.Now().Add(-time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := msgs(h.Records(tt.query))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Records() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Records() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := h.WriteJSON(&buf, Query{Level: slog.LevelError}); err != nil {
+		t.Fatal(err)
+	}
+	var exported []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 1 || exported[0]["msg"] != "three" {
+		t.Errorf("unexpected export %s", buf.String())
+	}
+}
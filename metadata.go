@@ -0,0 +1,272 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Metadata is a set of attributes describing where the process runs, such
+// as the host or container. They are resolved when the Metadata is created
+// and cached until Refresh is called. Attach them to every record with Enrich:
+//
+//	meta := sloghandler.HostMetadata()
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Enrich(meta.Enrich))
+//
+// Attribute keys follow the OpenTelemetry resource semantic conventions.
+type Metadata struct {
+	resolve func() []slog.Attr
+	attrs   atomic.Pointer[[]slog.Attr]
+}
+
+// NewMetadata returns a Metadata holding the attributes returned by
+// resolve, which is called now and on each Refresh.
+func NewMetadata(resolve func() []slog.Attr) *Metadata {
+	m := &Metadata{resolve: resolve}
+	m.Refresh()
+	return m
+}
+
+// Refresh resolves the attributes again, e.g. after a container is
+// migrated or a configuration reload. It is safe to call while logging.
+func (m *Metadata) Refresh() {
+	attrs := m.resolve()
+	m.attrs.Store(&attrs)
+}
+
+// Attrs returns the cached attributes. The slice must not be modified.
+func (m *Metadata) Attrs() []slog.Attr {
+	return *m.attrs.Load()
+}
+
+// Enrich returns the cached attributes. It is an Enricher.
+func (m *Metadata) Enrich(ctx context.Context, r slog.Record) []slog.Attr {
+	return m.Attrs()
+}
+
+// HostMetadata returns a Metadata with the host name and, when running in a
+// container, the container ID from the cgroup of the process and the
+// container runtime and image where they can be detected:
+//
+//	host.name, container.id, container.runtime, container.image.name,
+//	process.runtime.version
+func HostMetadata() *Metadata {
+	return NewMetadata(func() []slog.Attr { return hostAttrs("/") })
+}
+
+var (
+	containerIDPattern      = regexp.MustCompile(`[0-9a-f]{64}`)
+	mountContainerIDPattern = regexp.MustCompile(`containers/([0-9a-f]{64})/`)
+)
+
+// hostAttrs resolves the attributes of HostMetadata, reading files below root.
+func hostAttrs(root string) []slog.Attr {
+	var attrs []slog.Attr
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String("host.name", host))
+	}
+	id, runtimeName := cgroupContainer(filepath.Join(root, "proc/self/cgroup"))
+	if id == "" {
+		id = mountinfoContainer(filepath.Join(root, "proc/self/mountinfo"))
+	}
+	var image string
+	if b, err := os.ReadFile(filepath.Join(root, "run/.containerenv")); err == nil {
+		runtimeName = "podman"
+		image = parseDownwardAPIMap(b)["image"]
+	} else if _, err := os.Stat(filepath.Join(root, ".dockerenv")); err == nil {
+		runtimeName = "docker"
+	}
+	if id != "" {
+		attrs = append(attrs, slog.String("container.id", id))
+	}
+	if runtimeName != "" {
+		attrs = append(attrs, slog.String("container.runtime", runtimeName))
+	}
+	if image != "" {
+		attrs = append(attrs, slog.String("container.image.name", image))
+	}
+	return append(attrs, slog.String("process.runtime.version", runtime.Version()))
+}
+
+// cgroupContainer returns the container ID in a /proc/self/cgroup file,
+// and the runtime if its cgroup names tell.
+func cgroupContainer(path string) (id, runtimeName string) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", ""
+	}
+	for line := range strings.Lines(string(b)) {
+		id = containerIDPattern.FindString(line)
+		if id == "" {
+			continue
+		}
+		switch {
+		case strings.Contains(line, "cri-containerd-"), strings.Contains(line, "/containerd/"):
+			runtimeName = "containerd"
+		case strings.Contains(line, "crio-"):
+			runtimeName = "cri-o"
+		case strings.Contains(line, "docker"):
+			runtimeName = "docker"
+		case strings.Contains(line, "libpod"):
+			runtimeName = "podman"
+		}
+		return id, runtimeName
+	}
+	return "", ""
+}
+
+// mountinfoContainer returns the container ID found in the mounts of the
+// process, for cgroup v2 hosts where /proc/self/cgroup shows only "0::/".
+func mountinfoContainer(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if m := mountContainerIDPattern.FindSubmatch(b); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// ResourceAttributes formats the attributes in the form of the
+// OTEL_RESOURCE_ATTRIBUTES environment variable, "key1=value1,key2=value2",
+// so that an OpenTelemetry SDK or exporter started by the process reports
+// the same resource as the logs.
+func (m *Metadata) ResourceAttributes() string {
+	var sb strings.Builder
+	for _, a := range m.Attrs() {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(url.PathEscape(a.Key))
+		sb.WriteByte('=')
+		sb.WriteString(url.PathEscape(a.Value.String()))
+	}
+	return sb.String()
+}
+
+// KubernetesOptions configures KubernetesMetadata. The defaults match
+// a pod spec that exposes its metadata through the downward API as
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	- name: POD_NAMESPACE
+//	  valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	- name: POD_UID
+//	  valueFrom: {fieldRef: {fieldPath: metadata.uid}}
+//	- name: NODE_NAME
+//	  valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//	volumes:
+//	- name: podinfo
+//	  downwardAPI:
+//	    items:
+//	    - path: labels
+//	      fieldRef: {fieldPath: metadata.labels}
+//
+// with the podinfo volume mounted at /etc/podinfo.
+type KubernetesOptions struct {
+	// PodNameEnv, NamespaceEnv, PodUIDEnv and NodeNameEnv name the
+	// environment variables. Defaults are POD_NAME, POD_NAMESPACE, POD_UID
+	// and NODE_NAME.
+	PodNameEnv   string
+	NamespaceEnv string
+	PodUIDEnv    string
+	NodeNameEnv  string
+	// LabelsFile is the downward API file of the pod labels.
+	// Default is /etc/podinfo/labels.
+	LabelsFile string
+	// Labels selects the labels to attach. Default is all labels.
+	Labels []string
+}
+
+// serviceAccountNamespace is where every pod with a mounted service account
+// token finds its namespace.
+const serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// KubernetesMetadata returns a Metadata with the pod name, namespace, UID,
+// node and labels read from the downward API, as
+//
+//	k8s.pod.name, k8s.namespace.name, k8s.pod.uid, k8s.node.name,
+//	k8s.pod.label.<key>
+//
+// Missing values are omitted. Without the environment variables, the pod
+// name falls back to the host name and the namespace to the one of the
+// service account.
+func KubernetesMetadata(opts *KubernetesOptions) *Metadata {
+	o := KubernetesOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	set := func(dst *string, def string) {
+		if *dst == "" {
+			*dst = def
+		}
+	}
+	set(&o.PodNameEnv, "POD_NAME")
+	set(&o.NamespaceEnv, "POD_NAMESPACE")
+	set(&o.PodUIDEnv, "POD_UID")
+	set(&o.NodeNameEnv, "NODE_NAME")
+	set(&o.LabelsFile, "/etc/podinfo/labels")
+	return NewMetadata(func() []slog.Attr { return kubernetesAttrs(&o, serviceAccountNamespace) })
+}
+
+func kubernetesAttrs(o *KubernetesOptions, namespaceFile string) []slog.Attr {
+	var attrs []slog.Attr
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	pod := os.Getenv(o.PodNameEnv)
+	if pod == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		pod, _ = os.Hostname()
+	}
+	namespace := os.Getenv(o.NamespaceEnv)
+	if namespace == "" {
+		if b, err := os.ReadFile(namespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(b))
+		}
+	}
+	add("k8s.pod.name", pod)
+	add("k8s.namespace.name", namespace)
+	add("k8s.pod.uid", os.Getenv(o.PodUIDEnv))
+	add("k8s.node.name", os.Getenv(o.NodeNameEnv))
+	if b, err := os.ReadFile(o.LabelsFile); err == nil {
+		labels := parseDownwardAPIMap(b)
+		keys := o.Labels
+		if keys == nil {
+			keys = slices.Sorted(maps.Keys(labels))
+		}
+		for _, k := range keys {
+			add("k8s.pod.label."+k, labels[k])
+		}
+	}
+	return attrs
+}
+
+// parseDownwardAPIMap parses the key="value" lines of a downward API
+// labels or annotations file, or of /run/.containerenv.
+func parseDownwardAPIMap(b []byte) map[string]string {
+	m := make(map[string]string)
+	for line := range strings.Lines(string(b)) {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		if u, err := strconv.Unquote(v); err == nil {
+			v = u
+		}
+		m[k] = v
+	}
+	return m
+}
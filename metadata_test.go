@@ -0,0 +1,139 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testContainerID = "3f4e5d6c7b8a99887766554433221100ffeeddccbbaa00112233445566778899"
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func attrMap(attrs []slog.Attr) map[string]string {
+	m := make(map[string]string)
+	for _, a := range attrs {
+		m[a.Key] = a.Value.String()
+	}
+	return m
+}
+
+func TestHostAttrs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]string
+	}{
+		{
+			name: "cgroup v1 docker",
+			files: map[string]string{
+				"proc/self/cgroup": "12:memory:/docker/" + testContainerID + "\n",
+				".dockerenv":       "",
+			},
+			want: map[string]string{"container.id": testContainerID, "container.runtime": "docker"},
+		},
+		{
+			name: "kubernetes containerd",
+			files: map[string]string{
+				"proc/self/cgroup": "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + testContainerID + ".scope\n",
+			},
+			want: map[string]string{"container.id": testContainerID, "container.runtime": "containerd"},
+		},
+		{
+			name: "cgroup v2 podman",
+			files: map[string]string{
+				"proc/self/cgroup":    "0::/\n",
+				"proc/self/mountinfo": "1 2 0:3 /var/lib/containers/storage/overlay-containers/" + testContainerID + "/userdata/hostname /etc/hostname rw - ext4 /dev/vda1 rw\n",
+				"run/.containerenv":   "engine=\"podman-4.9.0\"\nname=\"api\"\nimage=\"quay.io/example/api:1.2\"\n",
+			},
+			want: map[string]string{"container.id": testContainerID, "container.runtime": "podman", "container.image.name": "quay.io/example/api:1.2"},
+		},
+		{
+			name:  "bare host",
+			files: map[string]string{"proc/self/cgroup": "0::/user.slice/session-1.scope\n"},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(root, name), content)
+			}
+			got := attrMap(hostAttrs(root))
+			if got["host.name"] == "" || got["process.runtime.version"] == "" {
+				t.Errorf("missing host attributes: %v", got)
+			}
+			for _, k := range []string{"container.id", "container.runtime", "container.image.name"} {
+				if got[k] != tt.want[k] {
+					t.Errorf("%s = %q, want %q", k, got[k], tt.want[k])
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataRefresh(t *testing.T) {
+	version := "1"
+	meta := NewMetadata(func() []slog.Attr { return []slog.Attr{slog.String("deploy", version)} })
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewTextHandler(buf, nil), Enrich(meta.Enrich)))
+	logger.Info("before")
+	version = "2"
+	logger.Info("cached")
+	meta.Refresh()
+	logger.Info("after")
+
+	out := buf.String()
+	for _, want := range []string{"msg=before deploy=1", "msg=cached deploy=1", "msg=after deploy=2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestKubernetesAttrs(t *testing.T) {
+	dir := t.TempDir()
+	labels := filepath.Join(dir, "labels")
+	writeTestFile(t, labels, "app=\"api\"\npod-template-hash=\"5d8f7c\"\ntier=\"backend\"\n")
+	namespace := filepath.Join(dir, "namespace")
+	writeTestFile(t, namespace, "from-sa\n")
+	t.Setenv("POD_NAME", "api-5d8f7c-x2x9z")
+	t.Setenv("POD_NAMESPACE", "")
+	t.Setenv("NODE_NAME", "ip-10-0-1-23")
+	t.Setenv("POD_UID", "")
+
+	o := &KubernetesOptions{PodNameEnv: "POD_NAME", NamespaceEnv: "POD_NAMESPACE", PodUIDEnv: "POD_UID", NodeNameEnv: "NODE_NAME", LabelsFile: labels}
+	meta := NewMetadata(func() []slog.Attr { return kubernetesAttrs(o, namespace) })
+	want := "k8s.pod.name=api-5d8f7c-x2x9z,k8s.namespace.name=from-sa,k8s.node.name=ip-10-0-1-23," +
+		"k8s.pod.label.app=api,k8s.pod.label.pod-template-hash=5d8f7c,k8s.pod.label.tier=backend"
+	if got := meta.ResourceAttributes(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	o.Labels = []string{"app", "missing"}
+	got := attrMap(kubernetesAttrs(o, namespace))
+	if got["k8s.pod.label.app"] != "api" || len(got) != 4 {
+		t.Errorf("selected labels: %v", got)
+	}
+}
+
+func TestMetadataResourceAttributes(t *testing.T) {
+	meta := NewMetadata(func() []slog.Attr {
+		return []slog.Attr{slog.String("service.name", "a,b"), slog.String("deployment", "blue green")}
+	})
+	if got, want := meta.ResourceAttributes(), "service.name=a%2Cb,deployment=blue%20green"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
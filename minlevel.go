@@ -0,0 +1,43 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+)
+
+type minLevelKey struct{}
+
+// WithMinLevel returns a context that overrides the minimum level of the
+// handlers in this package for records logged with it. Use it to trace a
+// single request at DEBUG in production:
+//
+//	if r.Header.Get("X-Debug") == token {
+//		ctx = sloghandler.WithMinLevel(ctx, slog.LevelDebug)
+//	}
+//	logger.DebugContext(ctx, "cache lookup", "key", key)
+//
+// The override only applies to the *Context logging methods. Alerting
+// handlers (WebhookHandler, EmailHandler) ignore it.
+func WithMinLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, minLevelKey{}, level)
+}
+
+// MinLevelFromContext returns the minimum level set by WithMinLevel.
+func MinLevelFromContext(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	l, ok := ctx.Value(minLevelKey{}).(slog.Leveler)
+	if !ok {
+		return 0, false
+	}
+	return l.Level(), true
+}
+
+// minLevel returns the minimum level for ctx, or def when ctx has no override.
+func minLevel(ctx context.Context, def slog.Leveler) slog.Level {
+	if l, ok := MinLevelFromContext(ctx); ok {
+		return l
+	}
+	return def.Level()
+}
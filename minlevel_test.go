@@ -0,0 +1,54 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithMinLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+	}))
+	ctx := context.Background()
+	traced := WithMinLevel(ctx, slog.LevelDebug)
+
+	logger.DebugContext(ctx, "untraced debug")
+	logger.DebugContext(traced, "traced debug")
+	logger.With("request_id", "r1").DebugContext(traced, "derived debug")
+
+	out := buf.String()
+	if strings.Contains(out, "untraced debug") {
+		t.Errorf("debug logged without override:\n%s", out)
+	}
+	for _, want := range []string{"traced debug", "derived debug"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	if _, ok := MinLevelFromContext(ctx); ok {
+		t.Error("unexpected override in background context")
+	}
+	if l, ok := MinLevelFromContext(traced); !ok || l != slog.LevelDebug {
+		t.Errorf("MinLevelFromContext = %v, %v", l, ok)
+	}
+}
+
+func TestWithMinLevelModuleLevelHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	base := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(NewModuleLevelHandler(base, &ModuleLevelOptions{
+		Levels: map[string]slog.Level{"db": slog.LevelError},
+	})).With("component", "db")
+
+	logger.InfoContext(context.Background(), "muted")
+	logger.DebugContext(WithMinLevel(context.Background(), slog.LevelDebug), "traced")
+	out := buf.String()
+	if strings.Contains(out, "muted") || !strings.Contains(out, "traced") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,259 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+)
+
+// mmdb is a reader of the MaxMind DB format used by GeoLite2 and GeoIP2
+// databases, https://maxmind.github.io/MaxMind-DB/. The whole file is read
+// into memory.
+type mmdb struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	data       []byte // the data section
+	ipv4Start  uint   // the node of ::/96 in an IPv6 tree
+}
+
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+var errMMDB = errors.New("sloghandler: invalid MaxMind DB")
+
+func openMMDB(path string) (*mmdb, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := newMMDB(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	return db, nil
+}
+
+func newMMDB(buf []byte) (*mmdb, error) {
+	i := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if i < 0 {
+		return nil, errMMDB
+	}
+	d := mmdbDecoder{buf: buf[i+len(mmdbMetadataMarker):]}
+	v, _, err := d.decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := v.(map[string]any)
+	if !ok {
+		return nil, errMMDB
+	}
+	uintOf := func(key string) uint {
+		n, _ := meta[key].(uint64)
+		return uint(n)
+	}
+	db := &mmdb{
+		buf:        buf,
+		nodeCount:  uintOf("node_count"),
+		recordSize: uintOf("record_size"),
+		ipVersion:  uintOf("ip_version"),
+	}
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, errMMDB
+	}
+	treeSize := db.recordSize * 2 / 8 * db.nodeCount
+	if treeSize+16 > uint(i) {
+		return nil, errMMDB
+	}
+	db.data = buf[treeSize+16 : i]
+	if db.ipVersion == 6 {
+		for range 96 {
+			if db.ipv4Start >= db.nodeCount {
+				break
+			}
+			db.ipv4Start = db.record(db.ipv4Start, 0)
+		}
+	}
+	return db, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (db *mmdb) record(node, bit uint) uint {
+	b := db.buf[node*db.recordSize/4:]
+	switch db.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// lookup returns the data of the network containing addr, or nil.
+func (db *mmdb) lookup(addr netip.Addr) (any, error) {
+	addr = addr.Unmap()
+	var node uint
+	var bits []byte
+	switch {
+	case addr.Is4() && db.ipVersion == 6:
+		node = db.ipv4Start
+		b := addr.As4()
+		bits = b[:]
+	case addr.Is4():
+		b := addr.As4()
+		bits = b[:]
+	case db.ipVersion == 6:
+		b := addr.As16()
+		bits = b[:]
+	default:
+		return nil, nil
+	}
+	for i := 0; i < len(bits)*8 && node < db.nodeCount; i++ {
+		node = db.record(node, uint(bits[i/8]>>(7-i%8)&1))
+	}
+	if node <= db.nodeCount {
+		return nil, nil
+	}
+	offset := node - db.nodeCount - 16
+	d := mmdbDecoder{buf: db.data}
+	v, _, err := d.decode(offset, 0)
+	return v, err
+}
+
+// mmdbDecoder decodes the data section of a MaxMind DB.
+type mmdbDecoder struct {
+	buf []byte
+}
+
+// maxMMDBDepth bounds the nesting of maps and arrays in corrupt files.
+const maxMMDBDepth = 32
+
+// decode returns the value at offset and the offset following it.
+func (d *mmdbDecoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxMMDBDepth || offset >= uint(len(d.buf)) {
+		return nil, 0, errMMDB
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+	if typ == 1 { // pointer
+		ss := uint(ctrl>>3) & 3
+		if offset+ss+1 > uint(len(d.buf)) {
+			return nil, 0, errMMDB
+		}
+		b := d.buf[offset : offset+ss+1]
+		vvv := uint(ctrl & 7)
+		var p uint
+		switch ss {
+		case 0:
+			p = vvv<<8 | uint(b[0])
+		case 1:
+			p = (vvv<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+		case 2:
+			p = (vvv<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+		case 3:
+			p = uint(binary.BigEndian.Uint32(b))
+		}
+		v, _, err := d.decode(p, depth+1)
+		return v, offset + ss + 1, err
+	}
+	if typ == 0 { // extended
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errMMDB
+		}
+		typ = 7 + uint(d.buf[offset])
+		offset++
+	}
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return nil, 0, errMMDB
+		}
+		var v uint
+		for _, c := range d.buf[offset : offset+n] {
+			v = v<<8 | uint(c)
+		}
+		size = []uint{29, 285, 65821}[n-1] + v
+		offset += n
+	}
+	switch typ {
+	case 7: // map
+		m := make(map[string]any, size)
+		for range size {
+			k, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, errMMDB
+			}
+			v, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[key] = v
+			offset = next
+		}
+		return m, offset, nil
+	case 11: // array
+		a := make([]any, 0, min(size, 1024))
+		for range size {
+			v, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, v)
+			offset = next
+		}
+		return a, offset, nil
+	case 14: // boolean
+		return size != 0, offset, nil
+	}
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errMMDB
+	}
+	b := d.buf[offset : offset+size]
+	offset += size
+	switch typ {
+	case 2: // string
+		return string(b), offset, nil
+	case 3: // double
+		if size != 8 {
+			return nil, 0, errMMDB
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case 15: // float
+		if size != 4 {
+			return nil, 0, errMMDB
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case 5, 6, 9: // uint16, uint32, uint64
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, offset, nil
+	case 8: // int32
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), offset, nil
+	case 4, 10: // bytes, uint128
+		return b, offset, nil
+	}
+	return nil, 0, errMMDB
+}
@@ -0,0 +1,186 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultModuleKeys are the attribute keys that name a module when
+// ModuleLevelOptions.Keys is nil.
+var DefaultModuleKeys = []string{"logger", "component"}
+
+// ModuleLevelOptions configures a ModuleLevelHandler.
+type ModuleLevelOptions struct {
+	// Default is the minimum level for records outside any configured module.
+	// Default is slog.LevelInfo.
+	Default slog.Leveler
+	// Levels maps module names to minimum levels. slog.Level implements
+	// encoding.TextUnmarshaler, so the map can be decoded directly from
+	// configuration such as {"db":"warn","http":"debug"}.
+	Levels map[string]slog.Level
+	// Keys are the attribute keys whose string value names the module.
+	// Default is DefaultModuleKeys.
+	Keys []string
+}
+
+// ModuleLevelHandler wraps a handler and applies a different minimum level
+// to each module, so noisy subsystems can be muted individually.
+//
+// A record's module is the value of the first attribute in Keys, from
+// WithAttrs or the record itself, or else the dotted path of its groups.
+// Levels match the longest configured prefix of that path: "db" applies to
+// records in group "db.tx" unless "db.tx" is configured as well.
+// The levels can be replaced at runtime with SetLevels.
+type ModuleLevelHandler struct {
+	base   slog.Handler
+	state  *moduleLevelState
+	module string // from a module key attribute
+	groups string // dotted group path
+}
+
+type moduleLevelState struct {
+	defaultLevel slog.Leveler
+	keys         []string
+	levels       atomic.Pointer[map[string]slog.Level]
+}
+
+// NewModuleLevelHandler creates a ModuleLevelHandler that passes enabled records to h.
+// h should accept the lowest level configured for any module.
+func NewModuleLevelHandler(h slog.Handler, opts *ModuleLevelOptions) *ModuleLevelHandler {
+	o := ModuleLevelOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Default == nil {
+		o.Default = slog.LevelInfo
+	}
+	if o.Keys == nil {
+		o.Keys = DefaultModuleKeys
+	}
+	s := &moduleLevelState{defaultLevel: o.Default, keys: o.Keys}
+	s.store(o.Levels)
+	return &ModuleLevelHandler{base: h, state: s}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *ModuleLevelHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+// SetLevels replaces the per-module levels. It is safe to call while logging,
+// and affects handlers derived via WithAttrs and WithGroup as well.
+func (h *ModuleLevelHandler) SetLevels(levels map[string]slog.Level) {
+	h.state.store(levels)
+}
+
+// Levels returns a copy of the current per-module levels.
+func (h *ModuleLevelHandler) Levels() map[string]slog.Level {
+	return maps.Clone(*h.state.levels.Load())
+}
+
+func (s *moduleLevelState) store(levels map[string]slog.Level) {
+	m := maps.Clone(levels)
+	if m == nil {
+		m = map[string]slog.Level{}
+	}
+	s.levels.Store(&m)
+}
+
+// levelFor returns the minimum level for module, matching the longest
+// configured dotted prefix.
+func (s *moduleLevelState) levelFor(module string) slog.Level {
+	levels := *s.levels.Load()
+	for name := module; name != ""; {
+		if l, ok := levels[name]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return s.defaultLevel.Level()
+}
+
+// minLevel returns the lowest level that any module may enable.
+func (s *moduleLevelState) minLevel() slog.Level {
+	lowest := s.defaultLevel.Level()
+	for _, l := range *s.levels.Load() {
+		if l < lowest {
+			lowest = l
+		}
+	}
+	return lowest
+}
+
+func (h *ModuleLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if !h.base.Enabled(ctx, level) {
+		return false
+	}
+	if l, ok := MinLevelFromContext(ctx); ok {
+		return level >= l
+	}
+	if h.module != "" {
+		return level >= h.state.levelFor(h.module)
+	}
+	// The record may still name its module in its own attributes.
+	return level >= h.state.minLevel()
+}
+
+func (h *ModuleLevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	module := h.module
+	if module == "" {
+		record.Attrs(func(a slog.Attr) bool {
+			module = h.state.moduleOf(a)
+			return module == ""
+		})
+	}
+	if module == "" {
+		module = h.groups
+	}
+	if l, ok := MinLevelFromContext(ctx); ok {
+		if record.Level < l {
+			return nil
+		}
+	} else if record.Level < h.state.levelFor(module) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (s *moduleLevelState) moduleOf(a slog.Attr) string {
+	if slices.Contains(s.keys, a.Key) {
+		return a.Value.Resolve().String()
+	}
+	return ""
+}
+
+func (h *ModuleLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	for _, a := range attrs {
+		if m := h.state.moduleOf(a); m != "" {
+			h2.module = m
+		}
+	}
+	return &h2
+}
+
+func (h *ModuleLevelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	if h.groups == "" {
+		h2.groups = name
+	} else {
+		h2.groups = h.groups + "." + name
+	}
+	return &h2
+}
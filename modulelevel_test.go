@@ -0,0 +1,74 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestModuleLevelHandler(t *testing.T) {
+	var levels map[string]slog.Level
+	if err := json.Unmarshal([]byte(`{"db":"warn","http":"debug","db.migrate":"info"}`), &levels); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	base := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	h := NewModuleLevelHandler(base, &ModuleLevelOptions{Levels: levels})
+	logger := slog.New(h)
+
+	db := logger.With("component", "db")
+	db.Info("db info")
+	db.Warn("db warn")
+	logger.WithGroup("db").WithGroup("migrate").Info("migrate info")
+	logger.WithGroup("db").WithGroup("pool").Info("pool info")
+	logger.Debug("http debug", "logger", "http")
+	logger.Debug("app debug")
+	logger.Info("app info")
+
+	out := buf.String()
+	for _, want := range []string{"db warn", "migrate info", "http debug", "app info"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"db info", "pool info", "app debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in output:\n%s", unwanted, out)
+		}
+	}
+
+	buf.Reset()
+	h.SetLevels(map[string]slog.Level{"db": slog.LevelDebug})
+	db.Debug("db debug after reload")
+	logger.Debug("http debug after reload", "logger", "http")
+	out = buf.String()
+	if !strings.Contains(out, "db debug after reload") {
+		t.Errorf("reloaded level not applied to derived handler:\n%s", out)
+	}
+	if strings.Contains(out, "http debug after reload") {
+		t.Errorf("removed module level still applied:\n%s", out)
+	}
+	if got := h.Levels(); len(got) != 1 || got["db"] != slog.LevelDebug {
+		t.Errorf("unexpected levels %v", got)
+	}
+}
+
+func TestModuleLevelHandlerEnabled(t *testing.T) {
+	base := slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug})
+	h := NewModuleLevelHandler(base, &ModuleLevelOptions{
+		Levels: map[string]slog.Level{"db": slog.LevelError},
+	})
+	ctx := t.Context()
+	if h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("debug should be disabled when no module allows it")
+	}
+	db := h.WithAttrs([]slog.Attr{slog.String("logger", "db")})
+	if db.Enabled(ctx, slog.LevelWarn) {
+		t.Error("warn should be disabled for db")
+	}
+	if !db.Enabled(ctx, slog.LevelError) {
+		t.Error("error should be enabled for db")
+	}
+}
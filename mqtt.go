@@ -0,0 +1,94 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// MQTTClient publishes a payload to an MQTT topic. Adapt your MQTT client
+// library to it, for example with github.com/eclipse/paho.mqtt.golang:
+//
+//	type pahoClient struct{ mqtt.Client }
+//
+//	func (c pahoClient) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+//		t := c.Client.Publish(topic, qos, retained, payload)
+//		select {
+//		case <-t.Done():
+//			return t.Error()
+//		case <-ctx.Done():
+//			return ctx.Err()
+//		}
+//	}
+type MQTTClient interface {
+	Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+}
+
+// MQTTOptions configures the handler returned by NewMQTTHandler.
+type MQTTOptions struct {
+	// Level is the minimum level published. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// Topic is a text/template rendered with *Entry, see PublisherOptions.Topic.
+	// MQTT wildcard characters ('+' and '#') in the result are replaced with '_'.
+	// Default is "logs/{{.Level}}".
+	Topic string
+	// QoS is the MQTT quality of service level (0, 1 or 2).
+	QoS byte
+	// Retained sets the MQTT retain flag on published messages.
+	Retained bool
+	// QueueSize bounds the number of records waiting to be published. Default is 1000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// Timeout bounds each publish. Default is 10 seconds.
+	Timeout time.Duration
+	// OnError is called when a record cannot be published. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+}
+
+var mqttTopicReplacer = strings.NewReplacer("+", "_", "#", "_")
+
+// NewMQTTHandler creates a handler that publishes JSON-encoded records to MQTT
+// topics through client. It is a PublisherHandler; call Close to publish
+// pending records before disconnecting the client.
+func NewMQTTHandler(client MQTTClient, opts *MQTTOptions) (*PublisherHandler, error) {
+	if client == nil {
+		return nil, errors.New("sloghandler: mqtt client is required")
+	}
+	o := MQTTOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Topic == "" {
+		o.Topic = "logs/{{.Level}}"
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1000
+	}
+	pub := PublisherFunc(func(ctx context.Context, msgs []Message) error {
+		var errs []error
+		for _, m := range msgs {
+			topic := mqttTopicReplacer.Replace(m.Topic)
+			if err := client.Publish(ctx, topic, o.QoS, o.Retained, m.Value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+	return NewPublisherHandler(pub, &PublisherOptions{
+		Level:          o.Level,
+		Topic:          o.Topic,
+		BatchSize:      1,
+		QueueSize:      o.QueueSize,
+		Backpressure:   o.Backpressure,
+		Timeout:        o.Timeout,
+		OnError:        o.OnError,
+		OnHealthChange: o.OnHealthChange,
+	})
+}
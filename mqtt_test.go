@@ -0,0 +1,53 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+type mqttPublished struct {
+	topic    string
+	qos      byte
+	retained bool
+}
+
+type fakeMQTTClient struct {
+	mu   sync.Mutex
+	msgs []mqttPublished
+}
+
+func (c *fakeMQTTClient) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, mqttPublished{topic, qos, retained})
+	return nil
+}
+
+func TestMQTTHandler(t *testing.T) {
+	client := &fakeMQTTClient{}
+	h, err := NewMQTTHandler(client, &MQTTOptions{
+		Topic: `devices/{{attr . "device"}}/{{.Level}}`,
+		QoS:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("boot", "device", "sensor-1")
+	logger.Warn("wildcard", "device", "a+b#")
+	h.Close()
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	want := []string{"devices/sensor-1/INFO", "devices/a_b_/WARN"}
+	if len(client.msgs) != len(want) {
+		t.Fatalf("published %d messages, want %d", len(client.msgs), len(want))
+	}
+	for i, m := range client.msgs {
+		if m.topic != want[i] || m.qos != 1 || m.retained {
+			t.Errorf("message[%d] = %+v, want topic %q with QoS 1", i, m, want[i])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MuteHandler wraps a handler so that records below a level can be
+// suppressed for a while, e.g. during planned maintenance:
+//
+//	mute := sloghandler.NewMuteHandler(handler)
+//	slog.SetDefault(slog.New(mute))
+//	...
+//	mute.MuteBelow(slog.LevelError, 10*time.Minute)
+//
+// When the window ends, a summary such as "muted 1234 records" is logged at
+// INFO if any record was suppressed.
+type MuteHandler struct {
+	base  slog.Handler
+	state *muteState
+}
+
+type muteState struct {
+	root   slog.Handler // receives the summary, without derived attributes
+	active atomic.Bool
+
+	mu    sync.Mutex
+	level slog.Level
+	start time.Time
+	timer *time.Timer
+	gen   int
+	muted int
+}
+
+// NewMuteHandler creates a MuteHandler that passes records to h.
+func NewMuteHandler(h slog.Handler) *MuteHandler {
+	return &MuteHandler{base: h, state: &muteState{root: h}}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *MuteHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+// MuteBelow drops records below level for d, including those of handlers
+// derived with WithAttrs and WithGroup. Calling it while muted replaces the
+// level and restarts the window; the summary covers both.
+func (h *MuteHandler) MuteBelow(level slog.Level, d time.Duration) {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if !s.active.Load() {
+		s.start = time.Now()
+		s.muted = 0
+	}
+	s.gen++
+	gen := s.gen
+	s.level = level
+	s.timer = time.AfterFunc(d, func() { s.end(gen) })
+	s.active.Store(true)
+}
+
+// Unmute ends the current window early and logs its summary.
+func (h *MuteHandler) Unmute() {
+	h.state.mu.Lock()
+	gen := h.state.gen
+	h.state.mu.Unlock()
+	h.state.end(gen)
+}
+
+// Muted reports whether records are being suppressed, and below which level.
+func (h *MuteHandler) Muted() (slog.Level, bool) {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.level, s.active.Load()
+}
+
+// end closes the window started as generation gen, unless a later
+// MuteBelow has replaced it.
+func (s *muteState) end(gen int) {
+	s.mu.Lock()
+	if gen != s.gen || !s.active.Load() {
+		s.mu.Unlock()
+		return
+	}
+	s.active.Store(false)
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	n, level, elapsed := s.muted, s.level, time.Since(s.start)
+	s.muted = 0
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	if n == 0 || !s.root.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, fmt.Sprintf("muted %d records", n), 0)
+	r.AddAttrs(slog.String("below", LevelName(level)), slog.Duration("duration", elapsed.Round(time.Millisecond)))
+	s.root.Handle(ctx, r)
+}
+
+// mute reports whether a record at level is suppressed, counting it if so.
+func (s *muteState) mute(level slog.Level) bool {
+	if !s.active.Load() {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active.Load() || level >= s.level {
+		return false
+	}
+	s.muted++
+	return true
+}
+
+func (h *MuteHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *MuteHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.state.mute(record.Level) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (h *MuteHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &MuteHandler{base: h.base.WithAttrs(attrs), state: h.state}
+}
+
+func (h *MuteHandler) WithGroup(name string) slog.Handler {
+	return &MuteHandler{base: h.base.WithGroup(name), state: h.state}
+}
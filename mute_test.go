@@ -0,0 +1,65 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMuteHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewMuteHandler(slog.NewTextHandler(buf, nil))
+	logger := slog.New(h).With("app", "demo")
+
+	h.MuteBelow(slog.LevelError, time.Hour)
+	if level, ok := h.Muted(); !ok || level != slog.LevelError {
+		t.Errorf("Muted() = %v, %v", level, ok)
+	}
+	logger.Info("maintenance noise")
+	logger.WithGroup("db").Warn("connection lost")
+	logger.Error("still reported")
+	h.Unmute()
+	logger.Info("back to normal")
+
+	out := buf.String()
+	if strings.Contains(out, "maintenance noise") || strings.Contains(out, "connection lost") {
+		t.Errorf("muted records were written:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if !strings.Contains(lines[0], `msg="still reported" app=demo`) {
+		t.Errorf("line 0 = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `msg="muted 2 records" below=ERROR duration=`) || strings.Contains(lines[1], "app=demo") {
+		t.Errorf("summary = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `msg="back to normal"`) {
+		t.Errorf("line 2 = %q", lines[2])
+	}
+}
+
+func TestMuteHandlerWindowEnds(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	records, stop := mem.Subscribe(10)
+	defer stop()
+	h := NewMuteHandler(mem)
+	logger := slog.New(h)
+
+	h.MuteBelow(slog.LevelWarn, 20*time.Millisecond)
+	logger.Info("noise")
+	select {
+	case e := <-records:
+		if e.Message != "muted 1 records" || e.Level != slog.LevelInfo {
+			t.Errorf("unexpected summary %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no summary after the window")
+	}
+	if _, ok := h.Muted(); ok {
+		t.Error("still muted after the window")
+	}
+}
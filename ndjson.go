@@ -0,0 +1,240 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// Backoff is the wait before the first retry; it doubles on each retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retryable reports whether a response status should be retried.
+	// Default retries 408, 429 and 5xx responses.
+	Retryable func(status int) bool
+}
+
+// DefaultRetryPolicy is used when HTTPOptions.Retry is nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Backoff:    time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+func (p *RetryPolicy) retryable(status int) bool {
+	if p.Retryable != nil {
+		return p.Retryable(status)
+	}
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+}
+
+// do calls attempt until it succeeds, returns a non-retryable error, or
+// retries are exhausted. attempt returns whether its error may be retried.
+func (p *RetryPolicy) do(attempt func() (bool, error)) error {
+	backoff := p.Backoff
+	for i := 0; ; i++ {
+		retry, err := attempt()
+		if err == nil || !retry {
+			return err
+		}
+		if i >= p.MaxRetries {
+			return &unavailableError{err}
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// HTTPOptions configures an HTTPHandler.
+type HTTPOptions struct {
+	// Level is the minimum level shipped. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// Header is added to every request, e.g. for Authorization.
+	Header http.Header
+	// Compressor compresses request bodies, setting Content-Encoding.
+	// Default is no compression.
+	Compressor Compressor
+	// Gzip compresses request bodies with Content-Encoding: gzip. It is a
+	// shorthand for Compressor: GzipCompression.
+	Gzip bool
+	// JSON selects the type-preserving encoding, in which numbers, bools,
+	// times and durations keep their JSON types. Default (nil) writes
+	// attribute values as strings, as Entry.MarshalJSON does.
+	JSON *JSONOptions
+	// Binary, if set, encodes request bodies as a sequence of entries in
+	// the format instead of NDJSON, with its Content-Type. JSON is then
+	// ignored.
+	Binary BinaryFormat
+	// Retry controls retries of failed requests. Default is DefaultRetryPolicy.
+	Retry *RetryPolicy
+	// Client is used to send requests. Default is a client with a 30 second timeout.
+	Client *http.Client
+	// BatchSize is the maximum number of records per request. Default is 500.
+	BatchSize int
+	// Interval is the maximum time a record waits before being sent. Default is 5 seconds.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be sent. Default is 10000.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// OnError is called when a batch cannot be delivered. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+	// Spool keeps request bodies on disk while the endpoint is unavailable
+	// and replays them in order once it accepts requests again. Default is
+	// no spool. The handler does not close it.
+	Spool *Spool
+}
+
+// HTTPHandler is a slog.Handler that POSTs batches of records as
+// newline-delimited JSON (application/x-ndjson) to an HTTP endpoint.
+type HTTPHandler struct {
+	opts   HTTPOptions
+	queue  *batcher[*Entry]
+	health *sinkHealth
+	scope  attrScope
+	sender *httpSender
+}
+
+// NewHTTPHandler creates a handler that ships records to url.
+func NewHTTPHandler(url string, opts *HTTPOptions) (*HTTPHandler, error) {
+	o := HTTPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.Retry == nil {
+		p := DefaultRetryPolicy
+		o.Retry = &p
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("http")
+	}
+	if o.Compressor == nil && o.Gzip {
+		o.Compressor = GzipCompression
+	}
+	header := o.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Type", "application/x-ndjson")
+	if o.Binary != 0 {
+		header.Set("Content-Type", o.Binary.ContentType())
+	}
+	if o.Compressor != nil && o.Compressor.Encoding() != "" {
+		header.Set("Content-Encoding", o.Compressor.Encoding())
+	}
+	h := &HTTPHandler{opts: o}
+	h.sender = &httpSender{name: "http sink", url: url, header: header, client: o.Client, retry: o.Retry}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(entries []*Entry) {
+		h.health.report(h.ship(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	h.queue.spill = o.Spool.spill(h.body)
+	return h, nil
+}
+
+func (h *HTTPHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *HTTPHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.queue.add(ctx, newEntry(record, h.scope))
+}
+
+func (h *HTTPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *HTTPHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush sends all queued records and replays the spool, if any.
+func (h *HTTPHandler) Flush() error {
+	h.queue.Flush()
+	return h.opts.Spool.replay(context.Background(), h.sender)
+}
+
+// Close sends pending records and stops the handler.
+func (h *HTTPHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *HTTPHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *HTTPHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+// encodeNDJSON encodes entries as newline-delimited JSON, type-preserving
+// if opts is not nil.
+func encodeNDJSON(entries []*Entry, opts *JSONOptions) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for _, e := range entries {
+		b, err := marshalEntry(e, opts)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *HTTPHandler) ship(entries []*Entry) error {
+	body, err := h.body(entries)
+	if err != nil {
+		return err
+	}
+	return h.opts.Spool.deliver(context.Background(), body, h.sender)
+}
+
+// body encodes entries as a request body, compressed if configured.
+func (h *HTTPHandler) body(entries []*Entry) ([]byte, error) {
+	var body []byte
+	var err error
+	if h.opts.Binary != 0 {
+		body = encodeBinary(entries, h.opts.Binary)
+	} else if body, err = encodeNDJSON(entries, h.opts.JSON); err != nil {
+		return nil, err
+	}
+	return compress(h.opts.Compressor, body)
+}
@@ -0,0 +1,90 @@
+package sloghandler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var records []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Token") != "secret" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sc := bufio.NewScanner(zr)
+		mu.Lock()
+		defer mu.Unlock()
+		for sc.Scan() {
+			var rec map[string]any
+			json.Unmarshal(sc.Bytes(), &rec)
+			records = append(records, rec)
+		}
+	}))
+	defer ts.Close()
+
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{
+		Header:   http.Header{"X-Token": {"secret"}},
+		Gzip:     true,
+		Retry:    &RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+		Interval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("one", "n", 1)
+	logger.Error("two")
+	h.Close()
+
+	if calls.Load() != 2 {
+		t.Errorf("got %d requests, want 2 (one retry)", calls.Load())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 2 || records[0]["msg"] != "one" || records[1]["level"] != "ERROR" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 2}
+	n := 0
+	err := p.do(func() (bool, error) {
+		n++
+		return true, errors.New("fail")
+	})
+	if err == nil || n != 3 {
+		t.Errorf("do() = %v after %d attempts, want error after 3", err, n)
+	}
+	n = 0
+	p.do(func() (bool, error) {
+		n++
+		return false, errors.New("permanent")
+	})
+	if n != 1 {
+		t.Errorf("non-retryable error attempted %d times", n)
+	}
+	if !p.retryable(503) || p.retryable(400) {
+		t.Error("unexpected default retryable statuses")
+	}
+}
@@ -0,0 +1,141 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// OnceKey is the key of the reserved attribute that limits how often a
+// record is logged. See Once.
+const OnceKey = "once"
+
+// onceMark is the value of a reserved once attribute.
+type onceMark struct {
+	key      string
+	interval time.Duration
+}
+
+// Once returns an attribute that limits a record to the first occurrence of
+// key in the process, so that warnings logged in loops or on every request
+// appear once. It is honored by OnceHandler, which removes it:
+//
+//	logger.Warn("config file not found, using defaults", sloghandler.Once("config-missing"))
+func Once(key string) slog.Attr {
+	return slog.Any(OnceKey, onceMark{key: key})
+}
+
+// OnceEvery is like Once but logs the record again once interval has passed
+// since it was last logged.
+func OnceEvery(key string, interval time.Duration) slog.Attr {
+	return slog.Any(OnceKey, onceMark{key: key, interval: interval})
+}
+
+// LogOnce logs at level the first time it is called with key in the
+// process, and does nothing afterwards. Unlike Once, it needs no handler support.
+func LogOnce(logger *slog.Logger, key string, level slog.Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) || !onceSeen.first(key, 0) {
+		return
+	}
+	logAt(ctx, logger, level, msg, args...)
+}
+
+// ResetOnce forgets the keys seen by LogOnce and OnceHandler, so the records
+// are logged again, e.g. between tests.
+func ResetOnce() {
+	onceSeen.reset()
+}
+
+// onceSeen is the process-wide record of keys logged once.
+var onceSeen = &onceRegistry{}
+
+type onceRegistry struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// first reports whether key may be logged now, and records it if so.
+func (o *onceRegistry) first(key string, interval time.Duration) bool {
+	now := time.Now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if t, ok := o.last[key]; ok && (interval <= 0 || now.Sub(t) < interval) {
+		return false
+	}
+	if o.last == nil {
+		o.last = make(map[string]time.Time)
+	}
+	o.last[key] = now
+	return true
+}
+
+func (o *onceRegistry) reset() {
+	o.mu.Lock()
+	o.last = nil
+	o.mu.Unlock()
+}
+
+// OnceHandler wraps a handler and drops records carrying an attribute from
+// Once or OnceEvery whose key has already been logged. The attribute is
+// removed from the records passed on.
+type OnceHandler struct {
+	base slog.Handler
+}
+
+// NewOnceHandler creates a OnceHandler that passes records to h.
+func NewOnceHandler(h slog.Handler) *OnceHandler {
+	return &OnceHandler{base: h}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *OnceHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *OnceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *OnceHandler) Handle(ctx context.Context, record slog.Record) error {
+	var mark *onceMark
+	record.Attrs(func(a slog.Attr) bool {
+		if m, ok := isOnceAttr(a); ok {
+			mark = &m
+			return false
+		}
+		return true
+	})
+	if mark == nil {
+		return h.base.Handle(ctx, record)
+	}
+	if !onceSeen.first(mark.key, mark.interval) {
+		return nil
+	}
+	r := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		if _, ok := isOnceAttr(a); !ok {
+			r.AddAttrs(a)
+		}
+		return true
+	})
+	return h.base.Handle(ctx, r)
+}
+
+func (h *OnceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &OnceHandler{base: h.base.WithAttrs(attrs)}
+}
+
+func (h *OnceHandler) WithGroup(name string) slog.Handler {
+	return &OnceHandler{base: h.base.WithGroup(name)}
+}
+
+// isOnceAttr reports whether a is a once attribute, and its value.
+func isOnceAttr(a slog.Attr) (onceMark, bool) {
+	if a.Key != OnceKey || a.Value.Kind() != slog.KindAny {
+		return onceMark{}, false
+	}
+	m, ok := a.Value.Any().(onceMark)
+	return m, ok
+}
@@ -0,0 +1,62 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogOnce(t *testing.T) {
+	ResetOnce()
+	defer ResetOnce()
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{AddSource: true}))
+	for range 3 {
+		LogOnce(logger, "deprecated-flag", slog.LevelWarn, "flag -x is deprecated", "flag", "x")
+	}
+	out := buf.String()
+	if n := strings.Count(out, "flag -x is deprecated"); n != 1 {
+		t.Errorf("logged %d times:\n%s", n, out)
+	}
+	if !strings.Contains(out, "once_test.go") {
+		t.Errorf("source is not the caller: %s", out)
+	}
+}
+
+func TestOnceHandler(t *testing.T) {
+	ResetOnce()
+	defer ResetOnce()
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewOnceHandler(slog.NewTextHandler(buf, nil))).With("app", "demo")
+	for i := range 3 {
+		logger.Warn("using defaults", Once("defaults"), "i", i)
+		logger.Info("tick", OnceEvery("tick", time.Hour))
+		logger.Info("request", "i", i)
+	}
+	out := buf.String()
+	for msg, want := range map[string]int{`msg="using defaults"`: 1, "msg=tick": 1, "msg=request": 3} {
+		if n := strings.Count(out, msg); n != want {
+			t.Errorf("%s logged %d times, want %d", msg, n, want)
+		}
+	}
+	if strings.Contains(out, OnceKey+"=") {
+		t.Errorf("once attribute was not removed:\n%s", out)
+	}
+	if !strings.Contains(out, `msg="using defaults" app=demo i=0`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestOnceEveryInterval(t *testing.T) {
+	ResetOnce()
+	defer ResetOnce()
+	if !onceSeen.first("k", time.Millisecond) || onceSeen.first("k", time.Hour) {
+		t.Fatal("second occurrence within the interval was allowed")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !onceSeen.first("k", time.Millisecond) {
+		t.Error("occurrence after the interval was suppressed")
+	}
+}
@@ -0,0 +1,32 @@
+package otelmetrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Dropper is implemented by the buffered sloghandler sinks; Dropped returns
+// the number of records discarded because the sink's queue was full.
+type Dropper interface {
+	Dropped() int64
+}
+
+// RegisterDropped exports the drop counts of sinks as an observable counter
+// named name, with a "sink" attribute set to the map key. Unregister the
+// returned registration to stop reporting.
+func RegisterDropped(meter metric.Meter, name string, sinks map[string]Dropper) (metric.Registration, error) {
+	counter, err := meter.Int64ObservableCounter(name,
+		metric.WithDescription("Number of log records dropped by backpressure"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for sink, d := range sinks {
+			o.ObserveInt64(counter, d.Dropped(), metric.WithAttributes(attribute.String("sink", sink)))
+		}
+		return nil
+	}, counter)
+}
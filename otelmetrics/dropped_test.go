@@ -0,0 +1,39 @@
+package otelmetrics_test
+
+import (
+	"testing"
+
+	"github.com/fujiwara/sloghandler/otelmetrics"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type fakeDropper int64
+
+func (d fakeDropper) Dropped() int64 { return int64(d) }
+
+func TestRegisterDropped(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := provider.Meter("example/logs")
+	reg, err := otelmetrics.RegisterDropped(meter, "log_records_dropped", map[string]otelmetrics.Dropper{
+		"loki": fakeDropper(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("unexpected data %#v", rm.ScopeMetrics[0].Metrics[0].Data)
+	}
+	dp := sum.DataPoints[0]
+	if sink, _ := dp.Attributes.Value("sink"); sink.AsString() != "loki" || dp.Value != 3 {
+		t.Errorf("got %v = %d", dp.Attributes.ToSlice(), dp.Value)
+	}
+}
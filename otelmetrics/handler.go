@@ -10,8 +10,20 @@ import (
 	"go.opentelemetry.io/otel/metric"
 )
 
+// levelTrace matches sloghandler.LevelTrace.
+const levelTrace = slog.LevelDebug - 4
+
 // predefinedLevels contains the standard log levels in ascending order of severity
-var predefinedLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+var predefinedLevels = []slog.Level{levelTrace, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// levelString returns the label value for level, naming levelTrace "TRACE"
+// as sloghandler does.
+func levelString(level slog.Level) string {
+	if level == levelTrace {
+		return "TRACE"
+	}
+	return level.String()
+}
 
 // Options contains configuration for the SlogHandler.
 type Options struct {
@@ -22,6 +34,15 @@ type Options struct {
 
 	// LabelAttributes specifies the attributes to use as labels in the OpenTelemetry counter.
 	LabelAttributes []string
+
+	// SLOCounter, if set, counts the records matching SLORules, regardless
+	// of MinLevel, with an "slo" attribute set to the name of the matching
+	// rule, so that burn-rate alerts can be driven from logs.
+	SLOCounter metric.Int64Counter
+
+	// SLORules select the records counted by SLOCounter. A record matching
+	// several rules is counted once for each.
+	SLORules []SLORule
 }
 
 // DefaultOptions returns the default configuration options.
@@ -69,11 +90,11 @@ func NewHandlerWithOptions(base slog.Handler, counter metric.Int64Counter, opts
 			if len(opts.LabelAttributes) == 0 {
 				// Add a zero value for each level to ensure it appears in metrics
 				// even if no logs have been recorded at that level yet.
-				counter.Add(ctx, 0, metric.WithAttributes(attribute.String("level", l.String())))
+				counter.Add(ctx, 0, metric.WithAttributes(attribute.String("level", levelString(l))))
 			} else {
 				// When using label attributes, initialize with empty values for other attributes
 				attrs := make([]attribute.KeyValue, len(opts.LabelAttributes)+1)
-				attrs[0] = attribute.String("level", l.String())
+				attrs[0] = attribute.String("level", levelString(l))
 				for i, attr := range opts.LabelAttributes {
 					attrs[i+1] = attribute.String(attr, "")
 				}
@@ -82,6 +103,12 @@ func NewHandlerWithOptions(base slog.Handler, counter metric.Int64Counter, opts
 		}
 	}
 
+	if opts.SLOCounter != nil {
+		for _, rule := range opts.SLORules {
+			opts.SLOCounter.Add(ctx, 0, metric.WithAttributes(attribute.String("slo", rule.Name)))
+		}
+	}
+
 	return &SlogHandler{
 		Handler: base,
 		counter: counter,
@@ -89,9 +116,16 @@ func NewHandlerWithOptions(base slog.Handler, counter metric.Int64Counter, opts
 	}
 }
 
+// Unwrap returns the wrapped handler, so that sloghandler.Flush can reach it.
+func (h *SlogHandler) Unwrap() slog.Handler {
+	return h.Handler
+}
+
 // Handle processes the log record, increments the appropriate counter with
 // the log level as an attribute, and passes the record to the underlying handler.
 func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.countSLO(ctx, r)
+
 	// Check if we should record this level based on MinLevel
 	if r.Level < h.options.MinLevel {
 		return h.Handler.Handle(ctx, r)
@@ -100,12 +134,12 @@ func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
 	if len(h.options.LabelAttributes) == 0 {
 		// Increment counter for this level only
 		h.counter.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("level", r.Level.String()),
+			attribute.String("level", levelString(r.Level)),
 		))
 	} else {
 		// Use the specified label attributes
 		attrs := make([]attribute.KeyValue, len(h.options.LabelAttributes)+1)
-		attrs[0] = attribute.String("level", r.Level.String())
+		attrs[0] = attribute.String("level", levelString(r.Level))
 		
 		// Initialize all attribute values with empty strings
 		for i, attrName := range h.options.LabelAttributes {
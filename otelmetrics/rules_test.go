@@ -0,0 +1,106 @@
+package otelmetrics_test
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fujiwara/sloghandler/otelmetrics"
+	"github.com/google/go-cmp/cmp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const testRules = `
+rules:
+  - name: http_requests
+    message: ^access$
+    labels: [method]
+  - name: http_request_duration
+    type: histogram
+    message: ^access$
+    value: duration
+    buckets: [0.1, 1]
+  - name: queue_depth
+    type: gauge
+    value: depth
+  - name: upstream_errors
+    level: ERROR
+    attrs: {component: upstream}
+`
+
+func TestRulesHandler(t *testing.T) {
+	rules, err := otelmetrics.LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	h, err := otelmetrics.NewRulesHandler(slog.NewTextHandler(io.Discard, nil), provider.Meter("test"), rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("access", "method", "GET", "duration", 50*time.Millisecond)
+	logger.Info("access", "method", "GET", "duration", 2*time.Second)
+	logger.Info("access", "method", "POST", "duration", "0.5")
+	logger.Info("queued", "depth", 3)
+	logger.Info("queued", "depth", 7)
+	upstream := logger.With("component", "upstream")
+	upstream.Error("connection refused")
+	upstream.Warn("retrying")
+	logger.Error("connection refused", "component", "db")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]any{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[float64]:
+			for _, dp := range data.DataPoints {
+				name := m.Name
+				if v, ok := dp.Attributes.Value("method"); ok {
+					name += "/" + v.AsString()
+				}
+				got[name] = dp.Value
+			}
+		case metricdata.Gauge[float64]:
+			got[m.Name] = data.DataPoints[0].Value
+		case metricdata.Histogram[float64]:
+			dp := data.DataPoints[0]
+			got[m.Name] = dp.BucketCounts
+			got[m.Name+"/count"] = dp.Count
+		}
+	}
+	want := map[string]any{
+		"http_requests/GET":           2.0,
+		"http_requests/POST":          1.0,
+		"http_request_duration":       []uint64{1, 1, 1},
+		"http_request_duration/count": uint64(3),
+		"queue_depth":                 7.0,
+		"upstream_errors":             1.0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRulesHandlerErrors(t *testing.T) {
+	meter := sdkmetric.NewMeterProvider().Meter("test")
+	for _, rules := range [][]otelmetrics.MetricRule{
+		{{}},
+		{{Name: "x", Type: "summary"}},
+		{{Name: "x", Type: otelmetrics.Histogram}},
+	} {
+		if _, err := otelmetrics.NewRulesHandler(slog.DiscardHandler, meter, rules); err == nil {
+			t.Errorf("NewRulesHandler(%v) succeeded", rules)
+		}
+	}
+	if _, err := otelmetrics.LoadRules(strings.NewReader("rules: [{name: x, level: LOUD}]")); err == nil {
+		t.Error("LoadRules with an invalid level succeeded")
+	}
+}
@@ -0,0 +1,19 @@
+package otelmetrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanSampled reports the sampling decision of the span in ctx, with ok
+// false if ctx carries no valid span context. It can be passed as
+// sloghandler.RequestBufferOptions.TraceSampled so that logs follow the
+// sampling of traces.
+func SpanSampled(ctx context.Context) (sampled, ok bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return false, false
+	}
+	return sc.IsSampled(), true
+}
@@ -0,0 +1,25 @@
+package otelmetrics
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanSampled(t *testing.T) {
+	if _, ok := SpanSampled(context.Background()); ok {
+		t.Error("decision reported without a span")
+	}
+	for _, flags := range []trace.TraceFlags{0, trace.FlagsSampled} {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{2},
+			TraceFlags: flags,
+		})
+		ctx := trace.ContextWithSpanContext(context.Background(), sc)
+		if sampled, ok := SpanSampled(ctx); !ok || sampled != flags.IsSampled() {
+			t.Errorf("flags %v: SpanSampled = %v, %v", flags, sampled, ok)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package otelmetrics
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// SLORule selects the records that count against a service level objective,
+// such as failed requests. A rule matches when all of its conditions that
+// are set hold.
+type SLORule struct {
+	// Name is the value of the "slo" attribute of matching records.
+	Name string
+	// Level, if set, matches records at or above it.
+	Level slog.Leveler
+	// Key, if set, matches records with this top-level attribute.
+	Key string
+	// Value, if set, matches when the string form of the Key attribute equals it.
+	Value string
+}
+
+func (r *SLORule) matches(record slog.Record) bool {
+	if r.Level != nil && record.Level < r.Level.Level() {
+		return false
+	}
+	if r.Key == "" {
+		return r.Level != nil
+	}
+	found := false
+	record.Attrs(func(a slog.Attr) bool {
+		found = a.Key == r.Key && (r.Value == "" || a.Value.Resolve().String() == r.Value)
+		return !found
+	})
+	return found
+}
+
+// countSLO increments the SLO counter once for each rule r matches.
+func (h *SlogHandler) countSLO(ctx context.Context, r slog.Record) {
+	if h.options.SLOCounter == nil {
+		return
+	}
+	for i := range h.options.SLORules {
+		if rule := &h.options.SLORules[i]; rule.matches(r) {
+			h.options.SLOCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("slo", rule.Name)))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package otelmetrics_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fujiwara/sloghandler/otelmetrics"
+	"github.com/google/go-cmp/cmp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSLOCounter(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := provider.Meter("test")
+	counter, err := meter.Int64Counter("log_messages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slo, err := meter.Int64Counter("slo_impacting_log_records")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := otelmetrics.NewHandlerWithOptions(slog.NewTextHandler(io.Discard, nil), counter, &otelmetrics.Options{
+		MinLevel:   slog.LevelWarn,
+		SLOCounter: slo,
+		SLORules: []otelmetrics.SLORule{
+			{Name: "availability", Level: slog.LevelError, Key: "slo_impact"},
+			{Name: "latency", Key: "slow", Value: "true"},
+			{Name: "errors", Level: slog.LevelError},
+			{Name: "unused", Key: "never"},
+		},
+	})
+	logger := slog.New(h)
+	logger.Error("upstream failed", "slo_impact", true)
+	logger.Error("bad input")
+	logger.Info("served", "slow", true)
+	logger.Info("served", "slow", false)
+	logger.Warn("degraded", "slo_impact", true)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "slo_impacting_log_records" {
+			continue
+		}
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			v, _ := dp.Attributes.Value("slo")
+			got[v.AsString()] = dp.Value
+		}
+	}
+	want := map[string]int64{"availability": 1, "latency": 1, "errors": 2, "unused": 0}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SLO counts mismatch (-want +got):\n%s", diff)
+	}
+}
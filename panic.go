@@ -0,0 +1,77 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+)
+
+// Stack is a goroutine stack trace attached to a record by LogPanic.
+// With HandlerOptions.PanicStack the text handler renders it as an indented
+// block below the log line.
+type Stack string
+
+// LogPanic logs a recovered panic value at ERROR with the "panic" and "stack"
+// attributes. Use it in middlewares and supervisors that recover themselves.
+func LogPanic(ctx context.Context, logger *slog.Logger, v any) {
+	logger.LogAttrs(ctx, slog.LevelError, "panic recovered",
+		slog.String("panic", fmt.Sprint(v)),
+		slog.Any("stack", panicStack()),
+	)
+}
+
+// RecoverAndLog recovers from a panic and logs it with LogPanic.
+// It must be deferred directly:
+//
+//	go func() {
+//		defer sloghandler.RecoverAndLog(logger)
+//		work()
+//	}()
+func RecoverAndLog(logger *slog.Logger) {
+	if v := recover(); v != nil {
+		LogPanic(context.Background(), logger, v)
+	}
+}
+
+// RecoverAndLogContext is like RecoverAndLog but logs with ctx.
+func RecoverAndLogContext(ctx context.Context, logger *slog.Logger) {
+	if v := recover(); v != nil {
+		LogPanic(ctx, logger, v)
+	}
+}
+
+// panicStack returns the current stack with the frames of the panic
+// machinery and this package removed, starting at the panicking function.
+func panicStack() Stack {
+	s := string(debug.Stack())
+	header, frames, ok := strings.Cut(s, "\n")
+	if !ok {
+		return Stack(s)
+	}
+	// Each frame is two lines: the function and its file:line.
+	if i := strings.Index(frames, "\npanic("); i >= 0 {
+		rest := frames[i+1:]
+		for range 2 {
+			_, rest, _ = strings.Cut(rest, "\n")
+		}
+		frames = rest
+	}
+	return Stack(header + "\n" + strings.TrimRight(frames, "\n"))
+}
+
+// writeStack writes stack as lines indented by a tab.
+func writeStack(buf *bytes.Buffer, stack Stack) {
+	for line := range strings.SplitSeq(string(stack), "\n") {
+		buf.WriteString("\t")
+		for i, field := range strings.Split(line, "\t") {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(escapeControl(field))
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,56 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func panicky() {
+	panic("boom")
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		PanicStack:     true,
+	}))
+	func() {
+		defer RecoverAndLog(logger)
+		panicky()
+	}()
+
+	header, stack, _ := strings.Cut(buf.String(), "\n")
+	if !strings.Contains(header, "[ERROR] panic recovered [panic:boom]") || strings.Contains(header, "stack") {
+		t.Errorf("unexpected header: %s", header)
+	}
+	if !strings.HasPrefix(stack, "\tgoroutine ") {
+		t.Errorf("stack should be an indented block:\n%s", stack)
+	}
+	first := strings.Split(stack, "\n")[1]
+	if !strings.Contains(first, "sloghandler.panicky") {
+		t.Errorf("stack should start at the panicking function, got %q", first)
+	}
+	if strings.Contains(stack, "runtime/debug.Stack") {
+		t.Errorf("stack should not include the recovery machinery:\n%s", stack)
+	}
+}
+
+func TestRecoverAndLogJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	func() {
+		defer RecoverAndLogContext(t.Context(), logger)
+		panicky()
+	}()
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := m["stack"].(string); m["panic"] != "boom" || !strings.Contains(s, "sloghandler.panicky") {
+		t.Errorf("unexpected record %v", m)
+	}
+}
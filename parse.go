@@ -0,0 +1,195 @@
+package sloghandler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a line written by the text handler back into an Entry.
+// ANSI colors are ignored and attribute values are returned as strings.
+//
+// The format is ambiguous in places, so Parse applies these rules: the
+// message starts at the first character that is not part of a leading
+// "[...]" token and ends before the trailing "[...]" tokens; the last leading
+// token of the form "[file.go:line]" is the source; and a token without a
+// colon is an attribute with an empty key.
+func Parse(line string) (*Entry, error) {
+	s := strings.TrimRight(StripANSI(line), "\r\n")
+	// The time ends at the level token, as TimeFormat may contain spaces.
+	i := strings.Index(s, " [")
+	if i < 0 {
+		return nil, errors.New("sloghandler: missing level")
+	}
+	ts, rest := s[:i], s[i+1:]
+	t, err := parseTime(ts)
+	if err != nil {
+		return nil, fmt.Errorf("sloghandler: invalid time: %w", err)
+	}
+	level, rest, ok := cutBracket(rest)
+	if !ok {
+		return nil, errors.New("sloghandler: missing level")
+	}
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("sloghandler: invalid level: %w", err)
+	}
+	e := &Entry{Time: t, Level: l}
+
+	// Leading tokens: attributes from WithAttrs, then the source.
+	var leading []string
+	for strings.HasPrefix(rest, " [") {
+		tok, r, ok := cutBracket(rest[1:])
+		if !ok {
+			break
+		}
+		leading = append(leading, tok)
+		rest = r
+	}
+	if n := len(leading); n > 0 {
+		if src, ok := parseSource(leading[n-1]); ok {
+			e.Source = src
+			leading = leading[:n-1]
+		}
+	}
+	for _, tok := range leading {
+		e.Attrs = append(e.Attrs, parseAttrToken(tok))
+	}
+
+	rest = strings.TrimPrefix(rest, " ")
+	msgEnd := len(rest)
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == ' ' && strings.HasPrefix(rest[i:], " [") {
+			if _, ok := bracketTokens(rest[i:]); ok {
+				msgEnd = i
+				break
+			}
+		}
+	}
+	e.Message = rest[:msgEnd]
+	tokens, _ := bracketTokens(rest[msgEnd:])
+	for _, tok := range tokens {
+		e.Attrs = append(e.Attrs, parseAttrToken(tok))
+	}
+	return e, nil
+}
+
+// parseTime parses s with TimeFormat, falling back to the default
+// TimeFormat and RFC 3339 for lines written by other configurations.
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(TimeFormat, s)
+	if err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{defaultTimeFormat, time.RFC3339Nano} {
+		if t, err2 := time.Parse(layout, s); err2 == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// cutBracket cuts a "[...]" token with balanced brackets from the start of s.
+func cutBracket(s string) (tok, rest string, ok bool) {
+	if !strings.HasPrefix(s, "[") {
+		return "", s, false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], true
+			}
+		}
+	}
+	return "", s, false
+}
+
+// bracketTokens parses s as a sequence of " [...]" tokens running to its end.
+func bracketTokens(s string) ([]string, bool) {
+	var tokens []string
+	for s != "" {
+		if !strings.HasPrefix(s, " [") {
+			return nil, false
+		}
+		tok, rest, ok := cutBracket(s[1:])
+		if !ok {
+			return nil, false
+		}
+		tokens = append(tokens, tok)
+		s = rest
+	}
+	return tokens, true
+}
+
+func parseSource(tok string) (*slog.Source, bool) {
+	i := strings.LastIndexByte(tok, ':')
+	if i < 0 || !strings.HasSuffix(tok[:i], ".go") {
+		return nil, false
+	}
+	line, err := strconv.Atoi(tok[i+1:])
+	if err != nil {
+		return nil, false
+	}
+	return &slog.Source{File: tok[:i], Line: line}, true
+}
+
+func parseAttrToken(tok string) slog.Attr {
+	key, value, ok := strings.Cut(tok, ":")
+	if !ok {
+		return slog.String("", tok)
+	}
+	return slog.String(key, value)
+}
+
+// Scanner reads lines written by the text handler and parses them.
+//
+//	sc := sloghandler.NewScanner(os.Stdin)
+//	for sc.Scan() {
+//		e, err := sc.Entry()
+//		if err != nil {
+//			fmt.Println(sc.Text()) // not a log line
+//			continue
+//		}
+//		...
+//	}
+//	if err := sc.Err(); err != nil { ... }
+type Scanner struct {
+	sc *bufio.Scanner
+}
+
+// NewScanner returns a Scanner reading from r. Lines may be up to 1 MiB long.
+func NewScanner(r io.Reader) *Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	return &Scanner{sc: sc}
+}
+
+// Scan advances to the next line, returning false at the end of input or on a read error.
+func (s *Scanner) Scan() bool {
+	return s.sc.Scan()
+}
+
+// Text returns the current line.
+func (s *Scanner) Text() string {
+	return s.sc.Text()
+}
+
+// Entry parses the current line.
+func (s *Scanner) Entry() (*Entry, error) {
+	return Parse(s.sc.Text())
+}
+
+// Err returns the first read error encountered by Scan.
+func (s *Scanner) Err() error {
+	return s.sc.Err()
+}
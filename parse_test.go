@@ -0,0 +1,123 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	now := time.Date(2023, 5, 9, 12, 34, 56, 789_000_000, time.UTC)
+	for _, color := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+			HandlerOptions: slog.HandlerOptions{Level: LevelTrace, AddSource: true},
+			Color:          color,
+			Now:            FrozenClock(now),
+		}))
+		logger.With("app", "demo").Warn("disk [almost] full", "usage", 0.93, "mount", "/var", slog.Group("req", "id", 7))
+
+		e, err := Parse(buf.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !e.Time.Equal(now) || e.Level != slog.LevelWarn || e.Message != "disk [almost] full" {
+			t.Errorf("unexpected entry %+v", e)
+		}
+		if e.Source == nil || e.Source.File != "parse_test.go" || e.Source.Line == 0 {
+			t.Errorf("unexpected source %+v", e.Source)
+		}
+		var got []string
+		for _, a := range e.Attrs {
+			got = append(got, a.String())
+		}
+		want := "app=demo usage=0.93 mount=/var req=[id=7]"
+		if strings.Join(got, " ") != want {
+			t.Errorf("attrs = %q, want %q", strings.Join(got, " "), want)
+		}
+	}
+}
+
+func TestParseTimeFormatWithSpace(t *testing.T) {
+	defer func(f string) { TimeFormat = f }(TimeFormat)
+	TimeFormat = time.DateTime
+
+	now := time.Date(2023, 5, 9, 12, 34, 56, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Now:            FrozenClock(now),
+	}))
+	logger.Info("hello world", "k", "v")
+	if !strings.HasPrefix(buf.String(), "2023-05-09 12:34:56 [INFO] hello world") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	e, err := Parse(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Time.Equal(now) || e.Level != slog.LevelInfo || e.Message != "hello world" || len(e.Attrs) != 1 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line    string
+		msg     string
+		attrs   int
+		wantErr bool
+	}{
+		{"2023-05-09T12:34:56.789Z [INFO] hello", "hello", 0, false},
+		{"2023-05-09T12:34:56.789Z [TRACE] [anonymous] msg [k:v]", "msg", 2, false},
+		{"2023-05-09T12:34:56.789Z [INFO] ends with [bracket", "ends with [bracket", 0, false},
+		{"2023-05-09T12:34:56.789Z [INFO] ", "", 0, false},
+		{"not a log line", "", 0, true},
+		{"2023-05-09T12:34:56.789Z [LOUD] msg", "", 0, true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) should fail", tt.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.line, err)
+			continue
+		}
+		if e.Message != tt.msg || len(e.Attrs) != tt.attrs {
+			t.Errorf("Parse(%q) = %q with %d attrs, want %q with %d", tt.line, e.Message, len(e.Attrs), tt.msg, tt.attrs)
+		}
+	}
+}
+
+func TestScanner(t *testing.T) {
+	input := "2023-05-09T12:34:56.789Z [INFO] first\npanic: something\n2023-05-09T12:34:57.000Z [ERROR] second [code:1]\n"
+	sc := NewScanner(strings.NewReader(input))
+	var msgs, raw []string
+	for sc.Scan() {
+		e, err := sc.Entry()
+		if err != nil {
+			raw = append(raw, sc.Text())
+			continue
+		}
+		msgs = append(msgs, e.Message)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(msgs, ",") != "first,second" || len(raw) != 1 {
+		t.Errorf("unexpected scan result %v %v", msgs, raw)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("2023-05-09T12:34:56.789Z [INFO] [a:b] [main.go:1] msg [k:[v]]")
+	f.Fuzz(func(t *testing.T, line string) {
+		Parse(line)
+	})
+}
@@ -0,0 +1,41 @@
+package prommetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Dropper is implemented by the buffered sloghandler sinks; Dropped returns
+// the number of records discarded because the sink's queue was full.
+type Dropper interface {
+	Dropped() int64
+}
+
+// droppedCollector exports the drop counts of sinks as a counter.
+type droppedCollector struct {
+	desc  *prometheus.Desc
+	sinks map[string]Dropper
+}
+
+// NewDroppedCollector returns a collector that exports the drop counts of
+// sinks as a counter named name with a "sink" label set to the map key:
+//
+//	prometheus.MustRegister(prommetrics.NewDroppedCollector(
+//	  "log_records_dropped_total",
+//	  map[string]prommetrics.Dropper{"loki": lokiHandler},
+//	))
+func NewDroppedCollector(name string, sinks map[string]Dropper) prometheus.Collector {
+	return &droppedCollector{
+		desc:  prometheus.NewDesc(name, "Number of log records dropped by backpressure", []string{"sink"}, nil),
+		sinks: sinks,
+	}
+}
+
+func (c *droppedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *droppedCollector) Collect(ch chan<- prometheus.Metric) {
+	for sink, d := range c.sinks {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(d.Dropped()), sink)
+	}
+}
@@ -0,0 +1,32 @@
+package prommetrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeDropper int64
+
+func (d fakeDropper) Dropped() int64 { return int64(d) }
+
+func TestDroppedCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewDroppedCollector("log_records_dropped_total", map[string]Dropper{
+		"loki": fakeDropper(3),
+		"http": fakeDropper(0),
+	}))
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]float64)
+	for _, mf := range metrics {
+		for _, m := range mf.Metric {
+			got[m.Label[0].GetValue()] = m.Counter.GetValue()
+		}
+	}
+	if len(got) != 2 || got["loki"] != 3 || got["http"] != 0 {
+		t.Errorf("dropped = %v", got)
+	}
+}
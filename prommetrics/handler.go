@@ -9,8 +9,20 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// levelTrace matches sloghandler.LevelTrace.
+const levelTrace = slog.LevelDebug - 4
+
 // predefinedLevels contains the standard log levels in ascending order of severity
-var predefinedLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+var predefinedLevels = []slog.Level{levelTrace, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// levelString returns the label value for level, naming levelTrace "TRACE"
+// as sloghandler does.
+func levelString(level slog.Level) string {
+	if level == levelTrace {
+		return "TRACE"
+	}
+	return level.String()
+}
 
 // Options contains configuration for the SlogHandler.
 type Options struct {
@@ -21,6 +33,20 @@ type Options struct {
 
 	// LabelAttributes specifies the attributes to use as labels in the Prometheus counter.
 	LabelAttributes []string
+
+	// SLOCounter, if set, counts the records matching SLORules, regardless
+	// of MinLevel, so that burn-rate alerts can be driven from logs. It must
+	// have an "slo" label, set to the name of the matching rule:
+	//
+	//	slo := prometheus.NewCounterVec(
+	//	  prometheus.CounterOpts{Name: "slo_impacting_log_records_total"},
+	//	  []string{"slo"},
+	//	)
+	SLOCounter *prometheus.CounterVec
+
+	// SLORules select the records counted by SLOCounter. A record matching
+	// several rules is counted once for each.
+	SLORules []SLORule
 }
 
 // DefaultOptions returns the default configuration options.
@@ -66,11 +92,11 @@ func NewHandlerWithOptions(base slog.Handler, counter *prometheus.CounterVec, op
 	for _, l := range predefinedLevels {
 		if l >= opts.MinLevel {
 			if len(opts.LabelAttributes) == 0 {
-				counter.WithLabelValues(l.String()).Add(0)
+				counter.WithLabelValues(levelString(l)).Add(0)
 			} else {
 				// When using label attributes, initialize with empty values for other labels
 				labels := make([]string, len(opts.LabelAttributes)+1)
-				labels[0] = l.String()
+				labels[0] = levelString(l)
 				for i := 1; i < len(labels); i++ {
 					labels[i] = ""
 				}
@@ -79,6 +105,12 @@ func NewHandlerWithOptions(base slog.Handler, counter *prometheus.CounterVec, op
 		}
 	}
 
+	if opts.SLOCounter != nil {
+		for _, rule := range opts.SLORules {
+			opts.SLOCounter.WithLabelValues(rule.Name).Add(0)
+		}
+	}
+
 	return &SlogHandler{
 		Handler: base,
 		counter: counter,
@@ -86,18 +118,24 @@ func NewHandlerWithOptions(base slog.Handler, counter *prometheus.CounterVec, op
 	}
 }
 
+// Unwrap returns the wrapped handler, so that sloghandler.Flush can reach it.
+func (h *SlogHandler) Unwrap() slog.Handler {
+	return h.Handler
+}
+
 // Handle processes the log record, increments the appropriate counter,
 // and passes the record to the underlying handler.
 func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.countSLO(r)
 	if r.Level < h.options.MinLevel {
 		return h.Handler.Handle(ctx, r)
 	}
 	if l := len(h.options.LabelAttributes); l == 0 {
-		h.counter.WithLabelValues(r.Level.String()).Inc()
+		h.counter.WithLabelValues(levelString(r.Level)).Inc()
 	} else {
 		// Use the specified label attributes
 		labels := make([]string, l+1)
-		labels[0] = r.Level.String()
+		labels[0] = levelString(r.Level)
 		// Initialize all attribute labels with empty strings
 		for i := 1; i < len(labels); i++ {
 			labels[i] = ""
@@ -2,6 +2,7 @@ package prommetrics
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"testing"
 	"time"
@@ -176,6 +177,39 @@ func TestMinLevel(t *testing.T) {
 	}
 }
 
+// TestTraceLevel tests that the trace level is labeled "TRACE"
+func TestTraceLevel(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_messages_trace_total",
+			Help: "Total number of log messages by level including trace",
+		},
+		[]string{"level"},
+	)
+	reg.MustRegister(counter)
+
+	var buf bytes.Buffer
+	baseHandler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: levelTrace})
+	handler := NewHandlerWithOptions(baseHandler, counter, &Options{MinLevel: levelTrace})
+	logger := slog.New(handler)
+
+	logger.Log(context.Background(), levelTrace, "Trace message")
+	logger.Debug("Debug message")
+
+	got := gatherCounts(t, reg, "log_messages_trace_total")
+	want := map[string]float64{
+		"TRACE": 1,
+		"DEBUG": 1,
+		"INFO":  0,
+		"WARN":  0,
+		"ERROR": 0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Metric counts mismatch (-want +got):\n%s", diff)
+	}
+}
+
 // TestLabelAttributes tests the handler with custom label attributes
 func TestLabelAttributes(t *testing.T) {
 	// Create a test registry
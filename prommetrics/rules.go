@@ -0,0 +1,311 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+// MetricType is the kind of metric a MetricRule derives from records.
+type MetricType string
+
+const (
+	// Counter counts matching records, or adds up their Value.
+	Counter MetricType = "counter"
+	// Gauge is set to the Value of the last matching record.
+	Gauge MetricType = "gauge"
+	// Histogram observes the Value of matching records.
+	Histogram MetricType = "histogram"
+)
+
+// MetricRule derives a metric from the records it matches. A rule matches
+// when all of its conditions that are set hold.
+type MetricRule struct {
+	// Name is the metric name.
+	Name string
+	// Type is the kind of the metric. Default is Counter.
+	Type MetricType
+	// Help is the metric description.
+	Help string
+	// Level, if set, matches records at or above it.
+	Level slog.Leveler
+	// Message, if set, must match the message.
+	Message *regexp.Regexp
+	// Attrs, if set, match records whose top-level attributes have these
+	// string forms. An empty value matches any value of a present attribute.
+	Attrs map[string]string
+	// Value is the attribute holding the amount added to a counter (default
+	// 1), the value a gauge is set to or the value a histogram observes.
+	// Numbers, numeric strings and durations (in seconds) are accepted;
+	// matching records without a value are not measured. It is required for
+	// gauges and histograms.
+	Value string
+	// Labels are the attributes exported as labels, empty when missing.
+	Labels []string
+	// Buckets are the histogram buckets. Default is prometheus.DefBuckets.
+	Buckets []float64
+}
+
+func (rule *MetricRule) matches(r slog.Record, attrs []slog.Attr) bool {
+	if rule.Level != nil && r.Level < rule.Level.Level() {
+		return false
+	}
+	if rule.Message != nil && !rule.Message.MatchString(r.Message) {
+		return false
+	}
+	for k, want := range rule.Attrs {
+		v, ok := lookupAttr(r, attrs, k)
+		if !ok || (want != "" && v.String() != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupAttr returns the top-level attribute key of r, or else of attrs.
+func lookupAttr(r slog.Record, attrs []slog.Attr, key string) (slog.Value, bool) {
+	var v slog.Value
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			v, found = a.Value.Resolve(), true
+		}
+		return !found
+	})
+	if found {
+		return v, true
+	}
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == key {
+			return attrs[i].Value.Resolve(), true
+		}
+	}
+	return v, false
+}
+
+// metricValue converts v to a metric value.
+func metricValue(v slog.Value) (float64, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return float64(v.Int64()), true
+	case slog.KindUint64:
+		return float64(v.Uint64()), true
+	case slog.KindFloat64:
+		return v.Float64(), true
+	case slog.KindDuration:
+		return v.Duration().Seconds(), true
+	case slog.KindString:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ruleConfig is the YAML form of a MetricRule.
+type ruleConfig struct {
+	Name    string            `yaml:"name"`
+	Type    MetricType        `yaml:"type"`
+	Help    string            `yaml:"help"`
+	Level   string            `yaml:"level"`
+	Message string            `yaml:"message"`
+	Attrs   map[string]string `yaml:"attrs"`
+	Value   string            `yaml:"value"`
+	Labels  []string          `yaml:"labels"`
+	Buckets []float64         `yaml:"buckets"`
+}
+
+// LoadRules reads metric rules from YAML, so that metrics can be derived
+// from logs without code changes:
+//
+//	rules:
+//	  - name: http_requests_total
+//	    message: ^access$
+//	    labels: [method, status]
+//	  - name: http_request_duration_seconds
+//	    type: histogram
+//	    message: ^access$
+//	    value: duration
+//	    buckets: [0.01, 0.1, 1]
+//	  - name: upstream_errors_total
+//	    level: ERROR
+//	    attrs: {component: upstream}
+//
+// Levels are parsed by slog.Level.UnmarshalText and messages are regular
+// expressions.
+func LoadRules(r io.Reader) ([]MetricRule, error) {
+	var config struct {
+		Rules []ruleConfig `yaml:"rules"`
+	}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("prommetrics: invalid rules: %w", err)
+	}
+	rules := make([]MetricRule, 0, len(config.Rules))
+	for _, c := range config.Rules {
+		rule := MetricRule{
+			Name:    c.Name,
+			Type:    c.Type,
+			Help:    c.Help,
+			Attrs:   c.Attrs,
+			Value:   c.Value,
+			Labels:  c.Labels,
+			Buckets: c.Buckets,
+		}
+		if c.Level != "" {
+			var l slog.Level
+			if err := l.UnmarshalText([]byte(c.Level)); err != nil {
+				return nil, fmt.Errorf("prommetrics: rule %q: invalid level: %w", c.Name, err)
+			}
+			rule.Level = l
+		}
+		if c.Message != "" {
+			re, err := regexp.Compile(c.Message)
+			if err != nil {
+				return nil, fmt.Errorf("prommetrics: rule %q: invalid message: %w", c.Name, err)
+			}
+			rule.Message = re
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// RulesHandler is a slog.Handler that derives Prometheus metrics from the
+// records matching its rules.
+type RulesHandler struct {
+	base   slog.Handler
+	rules  []*promRule
+	attrs  []slog.Attr // top-level attributes added with WithAttrs
+	groups bool        // a group was opened with WithGroup
+}
+
+type promRule struct {
+	MetricRule
+	counter   *prometheus.CounterVec
+	gauge     *prometheus.GaugeVec
+	histogram *prometheus.HistogramVec
+}
+
+// NewRulesHandler creates a RulesHandler passing records to base and
+// registering the metrics of rules with reg:
+//
+//	rules, err := prommetrics.LoadRules(f)
+//	...
+//	handler, err := prommetrics.NewRulesHandler(baseHandler, prometheus.DefaultRegisterer, rules)
+//
+// Only records that base is enabled for are measured.
+func NewRulesHandler(base slog.Handler, reg prometheus.Registerer, rules []MetricRule) (*RulesHandler, error) {
+	h := &RulesHandler{base: base}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, errors.New("prommetrics: rule without name")
+		}
+		p := &promRule{MetricRule: rule}
+		switch rule.Type {
+		case Counter, "":
+			p.counter = prometheus.NewCounterVec(prometheus.CounterOpts{Name: rule.Name, Help: rule.Help}, rule.Labels)
+			if len(rule.Labels) == 0 {
+				p.counter.WithLabelValues().Add(0)
+			}
+		case Gauge:
+			p.gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: rule.Name, Help: rule.Help}, rule.Labels)
+		case Histogram:
+			p.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: rule.Name, Help: rule.Help, Buckets: rule.Buckets}, rule.Labels)
+		default:
+			return nil, fmt.Errorf("prommetrics: rule %q: unknown type %q", rule.Name, rule.Type)
+		}
+		if rule.Value == "" && (p.gauge != nil || p.histogram != nil) {
+			return nil, fmt.Errorf("prommetrics: rule %q: %s requires a value", rule.Name, rule.Type)
+		}
+		if err := reg.Register(p.collector()); err != nil {
+			return nil, fmt.Errorf("prommetrics: rule %q: %w", rule.Name, err)
+		}
+		h.rules = append(h.rules, p)
+	}
+	return h, nil
+}
+
+func (p *promRule) collector() prometheus.Collector {
+	switch {
+	case p.gauge != nil:
+		return p.gauge
+	case p.histogram != nil:
+		return p.histogram
+	}
+	return p.counter
+}
+
+// Unwrap returns the wrapped handler, so that sloghandler.Flush can reach it.
+func (h *RulesHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *RulesHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+// Handle measures the record with the matching rules and passes it to the
+// underlying handler.
+func (h *RulesHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, p := range h.rules {
+		if p.matches(r, h.attrs) {
+			h.measure(p, r)
+		}
+	}
+	return h.base.Handle(ctx, r)
+}
+
+func (h *RulesHandler) measure(p *promRule, r slog.Record) {
+	value := 1.0
+	if p.Value != "" {
+		v, ok := lookupAttr(r, h.attrs, p.Value)
+		if !ok {
+			return
+		}
+		if value, ok = metricValue(v); !ok {
+			return
+		}
+	}
+	labels := make([]string, len(p.Labels))
+	for i, key := range p.Labels {
+		if v, ok := lookupAttr(r, h.attrs, key); ok {
+			labels[i] = v.String()
+		}
+	}
+	switch {
+	case p.gauge != nil:
+		p.gauge.WithLabelValues(labels...).Set(value)
+	case p.histogram != nil:
+		p.histogram.WithLabelValues(labels...).Observe(value)
+	case value >= 0:
+		p.counter.WithLabelValues(labels...).Add(value)
+	}
+}
+
+func (h *RulesHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	if !h.groups {
+		h2.attrs = append(slices.Clip(h.attrs), attrs...)
+	}
+	return &h2
+}
+
+func (h *RulesHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.groups = true
+	return &h2
+}
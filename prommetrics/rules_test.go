@@ -0,0 +1,119 @@
+package prommetrics
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const testRules = `
+rules:
+  - name: http_requests_total
+    message: ^access$
+    labels: [method]
+  - name: http_request_duration_seconds
+    type: histogram
+    message: ^access$
+    value: duration
+    buckets: [0.1, 1]
+  - name: queue_depth
+    type: gauge
+    value: depth
+  - name: upstream_errors_total
+    level: ERROR
+    attrs: {component: upstream}
+`
+
+func TestRulesHandler(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := prometheus.NewRegistry()
+	h, err := NewRulesHandler(slog.NewTextHandler(io.Discard, nil), reg, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	ctx := context.Background()
+	logger.InfoContext(ctx, "access", "method", "GET", "duration", 50*time.Millisecond)
+	logger.InfoContext(ctx, "access", "method", "GET", "duration", 2*time.Second)
+	logger.InfoContext(ctx, "access", "method", "POST", "duration", "0.5")
+	logger.InfoContext(ctx, "queued", "depth", 3)
+	logger.InfoContext(ctx, "queued", "depth", 7)
+	upstream := logger.With("component", "upstream")
+	upstream.ErrorContext(ctx, "connection refused")
+	upstream.WarnContext(ctx, "retrying")
+	logger.ErrorContext(ctx, "connection refused", "component", "db")
+
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, mf := range metrics {
+		for _, m := range mf.Metric {
+			name := mf.GetName()
+			for _, l := range m.Label {
+				name += "/" + l.GetValue()
+			}
+			switch {
+			case m.Counter != nil:
+				got[name] = m.Counter.GetValue()
+			case m.Gauge != nil:
+				got[name] = m.Gauge.GetValue()
+			case m.Histogram != nil:
+				got[name+"/count"] = float64(m.Histogram.GetSampleCount())
+				for _, b := range m.Histogram.Bucket {
+					got[name+"/le"+strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)] = float64(b.GetCumulativeCount())
+				}
+			}
+		}
+	}
+	want := map[string]float64{
+		"http_requests_total/GET":             2,
+		"http_requests_total/POST":            1,
+		"http_request_duration_seconds/count": 3,
+		"http_request_duration_seconds/le0.1": 1,
+		"http_request_duration_seconds/le1":   2,
+		"queue_depth":                         7,
+		"upstream_errors_total":               1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRulesHandlerErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rules []MetricRule
+	}{
+		{"no name", []MetricRule{{}}},
+		{"unknown type", []MetricRule{{Name: "x", Type: "summary"}}},
+		{"gauge without value", []MetricRule{{Name: "x", Type: Gauge}}},
+		{"duplicate", []MetricRule{{Name: "x"}, {Name: "x"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewRulesHandler(slog.DiscardHandler, prometheus.NewRegistry(), tc.rules); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	for _, config := range []string{
+		"rules: [{name: x, level: LOUD}]",
+		"rules: [{name: x, message: '('}]",
+		"rules: [{name: x, color: red}]",
+	} {
+		if _, err := LoadRules(strings.NewReader(config)); err == nil {
+			t.Errorf("LoadRules(%q) succeeded", config)
+		}
+	}
+}
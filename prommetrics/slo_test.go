@@ -0,0 +1,54 @@
+package prommetrics
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSLOCounter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "log_messages_total"}, []string{"level"})
+	slo := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "slo_impacting_log_records_total"}, []string{"slo"})
+	reg.MustRegister(counter, slo)
+
+	h := NewHandlerWithOptions(slog.NewTextHandler(io.Discard, nil), counter, &Options{
+		MinLevel:   slog.LevelWarn,
+		SLOCounter: slo,
+		SLORules: []SLORule{
+			{Name: "availability", Level: slog.LevelError, Key: "slo_impact"},
+			{Name: "latency", Key: "slow", Value: "true"},
+			{Name: "errors", Level: slog.LevelError},
+			{Name: "unused", Key: "never"},
+		},
+	})
+	logger := slog.New(h)
+	ctx := context.Background()
+	logger.ErrorContext(ctx, "upstream failed", "slo_impact", true)
+	logger.ErrorContext(ctx, "bad input")
+	logger.InfoContext(ctx, "served", "slow", true)
+	logger.InfoContext(ctx, "served", "slow", false)
+	logger.WarnContext(ctx, "degraded", "slo_impact", true)
+
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, mf := range metrics {
+		if mf.GetName() != "slo_impacting_log_records_total" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			got[m.Label[0].GetValue()] = m.Counter.GetValue()
+		}
+	}
+	want := map[string]float64{"availability": 1, "latency": 1, "errors": 2, "unused": 0}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SLO counts mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,85 @@
+package sloghandler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// PseudonymProfile lists what Pseudonymize replaces. Names are matched
+// case-insensitively.
+type PseudonymProfile struct {
+	// Keys are attributes whose values are replaced, at any group depth
+	// and of any kind.
+	Keys []string
+	// Patterns are replaced wherever they match in messages and string
+	// attribute values.
+	Patterns []*regexp.Regexp
+}
+
+// PIIPseudonymProfile replaces common personal identifiers: user and
+// account IDs, names, e-mail addresses, phone numbers and client IP
+// addresses in attributes named after them, and e-mail and IPv4 addresses
+// anywhere in messages and string values.
+var PIIPseudonymProfile = PseudonymProfile{
+	Keys: []string{
+		"user", "user_id", "userid", "username", "account", "account_id",
+		"name", "email", "mail", "phone",
+		"ip", "client_ip", "remote_addr", "remote_ip", "x-forwarded-for", "x-real-ip",
+	},
+	Patterns: []*regexp.Regexp{
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+	},
+}
+
+// Pseudonym returns the pseudonym of value under key: "anon:" followed by
+// the first 16 hex digits of HMAC-SHA256(key, value). Use it to find the
+// records of a known value in an excerpt scrubbed with the same key.
+func Pseudonym(key []byte, value string) string {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(value))
+	return "anon:" + hex.EncodeToString(m.Sum(nil)[:8])
+}
+
+// Pseudonymize returns a Transformer that replaces the values listed in p
+// with their Pseudonym under key, so that log excerpts can be shared, e.g.
+// in bug reports, without personal data:
+//
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Pseudonymize(key, sloghandler.PIIPseudonymProfile))
+//
+// A value always gets the same pseudonym under one key, so records of the
+// same user or client can still be joined, while values cannot be
+// recovered or guessed without the key. Keep the key secret; a nil key
+// uses a random key, consistent only within the process.
+func Pseudonymize(key []byte, p PseudonymProfile) Transformer {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	keys := lowerSet(p.Keys)
+	replace := func(s string) string {
+		for _, re := range p.Patterns {
+			s = re.ReplaceAllStringFunc(s, func(m string) string { return Pseudonym(key, m) })
+		}
+		return s
+	}
+	attrs := MapAttrs(func(groups []string, a slog.Attr) slog.Attr {
+		switch {
+		case keys[strings.ToLower(a.Key)]:
+			return slog.String(a.Key, Pseudonym(key, a.Value.String()))
+		case len(p.Patterns) > 0 && a.Value.Kind() == slog.KindString:
+			return slog.String(a.Key, replace(a.Value.String()))
+		}
+		return a
+	})
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		r.Message = replace(r.Message)
+		return attrs.Transform(ctx, r)
+	})
+}
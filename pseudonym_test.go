@@ -0,0 +1,38 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymize(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	h := NewTransformHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), Pseudonymize(key, PIIPseudonymProfile))
+	logger := slog.New(h)
+
+	logger.Info("mail sent to alice@example.com", "User_ID", 42, slog.Group("req", "client_ip", "192.0.2.1", "path", "/"))
+	logger.With("user_id", "42").Info("retry", "detail", "from 192.0.2.1")
+
+	user, ip := Pseudonym(key, "42"), Pseudonym(key, "192.0.2.1")
+	want := `level=INFO msg="mail sent to ` + Pseudonym(key, "alice@example.com") + `" User_ID=` + user + ` req.client_ip=` + ip + ` req.path=/` + "\n" +
+		`level=INFO msg=retry user_id=` + user + ` detail="from ` + ip + `"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if !strings.HasPrefix(user, "anon:") || len(user) != len("anon:")+16 {
+		t.Errorf("unexpected pseudonym %q", user)
+	}
+	if Pseudonym([]byte("other"), "42") == user {
+		t.Error("pseudonyms should depend on the key")
+	}
+}
@@ -0,0 +1,214 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Message is a single encoded record passed to a Publisher.
+type Message struct {
+	// Key is the value of PublisherOptions.KeyAttr, or nil if unset or absent.
+	// Kafka uses it to select the partition.
+	Key []byte
+	// Value is the JSON-encoded record.
+	Value []byte
+	// Topic is rendered from PublisherOptions.Topic, or empty if unset.
+	Topic string
+}
+
+// Publisher publishes messages to a streaming system such as Kafka or NATS.
+// Publish is called from a single background goroutine.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface. For example,
+// with github.com/segmentio/kafka-go:
+//
+//	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "logs"}
+//	pub := sloghandler.PublisherFunc(func(ctx context.Context, msgs []sloghandler.Message) error {
+//		kms := make([]kafka.Message, len(msgs))
+//		for i, m := range msgs {
+//			kms[i] = kafka.Message{Key: m.Key, Value: m.Value}
+//		}
+//		return w.WriteMessages(ctx, kms...)
+//	})
+//
+// or with github.com/nats-io/nats.go:
+//
+//	pub := sloghandler.PublisherFunc(func(ctx context.Context, msgs []sloghandler.Message) error {
+//		for _, m := range msgs {
+//			if err := nc.Publish("logs", m.Value); err != nil {
+//				return err
+//			}
+//		}
+//		return nc.FlushWithContext(ctx)
+//	})
+type PublisherFunc func(ctx context.Context, msgs []Message) error
+
+// Publish calls f(ctx, msgs).
+func (f PublisherFunc) Publish(ctx context.Context, msgs []Message) error {
+	return f(ctx, msgs)
+}
+
+// PublisherOptions configures a PublisherHandler.
+type PublisherOptions struct {
+	// Level is the minimum level published. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// KeyAttr names the attribute used as the message key.
+	KeyAttr string
+	// Topic is a text/template rendered with *Entry to set Message.Topic,
+	// e.g. `logs.{{.Level}}` or `devices/{{attr . "device"}}/logs`.
+	// The attr function returns an attribute value, or "" if absent.
+	Topic string
+	// BatchSize is the maximum number of messages per Publish call. Default is 100.
+	BatchSize int
+	// Interval is the maximum time a record waits before being published. Default is 1 second.
+	Interval time.Duration
+	// QueueSize bounds the number of records waiting to be published. Default is 10000.
+	// Records are dropped and Handle returns ErrQueueFull when the queue is full.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// Timeout bounds each Publish call. Default is 10 seconds.
+	Timeout time.Duration
+	// OnError is called when a batch cannot be published. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+}
+
+// PublisherHandler is a slog.Handler that publishes JSON-encoded records
+// through a Publisher in bounded asynchronous batches.
+type PublisherHandler struct {
+	pub    Publisher
+	opts   PublisherOptions
+	topic  *template.Template
+	queue  *batcher[Message]
+	health *sinkHealth
+	scope  attrScope
+}
+
+// NewPublisherHandler creates a handler that publishes records through pub.
+func NewPublisherHandler(pub Publisher, opts *PublisherOptions) (*PublisherHandler, error) {
+	if pub == nil {
+		return nil, errors.New("sloghandler: publisher is required")
+	}
+	o := PublisherOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("publisher")
+	}
+	h := &PublisherHandler{pub: pub, opts: o}
+	if o.Topic != "" {
+		tmpl, err := template.New("topic").Funcs(entryTemplateFuncs).Parse(o.Topic)
+		if err != nil {
+			return nil, fmt.Errorf("sloghandler: invalid topic template: %w", err)
+		}
+		h.topic = tmpl
+	}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(msgs []Message) {
+		ctx, cancel := context.WithTimeout(context.Background(), h.opts.Timeout)
+		defer cancel()
+		h.health.report(h.pub.Publish(ctx, msgs), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	return h, nil
+}
+
+func (h *PublisherHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *PublisherHandler) Handle(ctx context.Context, record slog.Record) error {
+	e := newEntry(record, h.scope)
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	msg := Message{Value: value}
+	if h.opts.KeyAttr != "" {
+		if v, ok := e.Attr(h.opts.KeyAttr); ok {
+			msg.Key = []byte(v.String())
+		}
+	}
+	if h.topic != nil {
+		var sb strings.Builder
+		if err := h.topic.Execute(&sb, e); err != nil {
+			return fmt.Errorf("sloghandler: failed to render topic: %w", err)
+		}
+		msg.Topic = sb.String()
+	}
+	return h.queue.add(ctx, msg)
+}
+
+// entryTemplateFuncs are the functions available to templates rendered with *Entry.
+var entryTemplateFuncs = template.FuncMap{
+	"attr": func(e *Entry, key string) string {
+		if v, ok := e.Attr(key); ok {
+			return v.String()
+		}
+		return ""
+	},
+}
+
+func (h *PublisherHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *PublisherHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush publishes all queued records.
+func (h *PublisherHandler) Flush() error {
+	h.queue.Flush()
+	return nil
+}
+
+// Close publishes pending records and stops the handler. It does not close the Publisher.
+func (h *PublisherHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *PublisherHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *PublisherHandler) Health() Health {
+	return h.health.snapshot()
+}
@@ -0,0 +1,49 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPublisherHandler(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Message
+	pub := PublisherFunc(func(ctx context.Context, msgs []Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, append([]Message(nil), msgs...))
+		return nil
+	})
+	h, err := NewPublisherHandler(pub, &PublisherOptions{KeyAttr: "user", BatchSize: 2, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h)
+	logger.Info("login", "user", "alice")
+	logger.Info("logout", "user", "bob")
+	logger.Warn("anonymous")
+	h.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	if string(batches[0][0].Key) != "alice" || string(batches[0][1].Key) != "bob" {
+		t.Errorf("unexpected keys: %q %q", batches[0][0].Key, batches[0][1].Key)
+	}
+	if batches[1][0].Key != nil {
+		t.Errorf("key should be nil when the attribute is absent, got %q", batches[1][0].Key)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal(batches[0][0].Value, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["msg"] != "login" || rec["user"] != "alice" || rec["level"] != "INFO" {
+		t.Errorf("unexpected record: %v", rec)
+	}
+}
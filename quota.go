@@ -0,0 +1,227 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QuotaOptions configures a QuotaHandler.
+type QuotaOptions struct {
+	// Key is the attribute naming the tenant, in a record or added with
+	// WithAttrs outside groups. Default is "tenant".
+	Key string
+	// Tenant, if set, returns the tenant of a context, for records whose
+	// attributes do not name one.
+	Tenant func(ctx context.Context) (string, bool)
+	// Records is the number of records a tenant may log per Interval.
+	// Default is 0 (no limit).
+	Records int
+	// Bytes is the volume a tenant may log per Interval, counted as the
+	// length of the message and of the keys and values of the record's
+	// attributes. Default is 0 (no limit).
+	Bytes int
+	// Interval is the quota window. Default is one minute.
+	Interval time.Duration
+	// Overrides sets the record and byte quotas of individual tenants.
+	Overrides map[string]Quota
+	// ExemptLevel, if set, lets records at or above it through regardless
+	// of quotas, e.g. slog.LevelError. They still count against the quota.
+	ExemptLevel slog.Leveler
+}
+
+// Quota is a record and byte quota per window. Zero means no limit.
+type Quota struct {
+	Records int
+	Bytes   int
+}
+
+// maxQuotaTenants is the number of tenants above which the windows of
+// idle tenants are forgotten.
+const maxQuotaTenants = 10000
+
+// QuotaHandler wraps a handler and enforces a log quota per tenant, so that
+// a single noisy tenant of a multi-tenant service cannot flood the shared
+// logging infrastructure:
+//
+//	h := sloghandler.NewQuotaHandler(handler, &sloghandler.QuotaOptions{
+//		Records: 1000, Bytes: 1 << 20, Interval: time.Minute,
+//	})
+//	logger := slog.New(h).With("tenant", tenantID)
+//
+// Records over quota are dropped until the tenant's window ends. The first
+// drop in a window is reported by a WARN record "log quota exceeded" with
+// the tenant and the quota. Records without a tenant are not limited.
+type QuotaHandler struct {
+	base   slog.Handler
+	state  *quotaState
+	tenant string // from WithAttrs, if any
+	groups bool   // a group was opened with WithGroup
+}
+
+type quotaState struct {
+	opts    QuotaOptions
+	root    slog.Handler // receives the "log quota exceeded" records
+	now     func() time.Time
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+	dropped atomic.Int64
+	byTen   map[string]int64 // drops per tenant, guarded by mu
+}
+
+type quotaWindow struct {
+	start    time.Time
+	records  int
+	bytes    int
+	notified bool // a drop was reported in this window
+}
+
+// NewQuotaHandler creates a QuotaHandler passing records within quota to h.
+func NewQuotaHandler(h slog.Handler, opts *QuotaOptions) *QuotaHandler {
+	o := QuotaOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Key == "" {
+		o.Key = "tenant"
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Minute
+	}
+	return &QuotaHandler{base: h, state: &quotaState{
+		opts:    o,
+		root:    h,
+		now:     time.Now,
+		windows: make(map[string]*quotaWindow),
+		byTen:   make(map[string]int64),
+	}}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *QuotaHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+// Dropped returns the number of records dropped over quota.
+func (h *QuotaHandler) Dropped() int64 {
+	return h.state.dropped.Load()
+}
+
+// DroppedByTenant returns the number of records dropped over quota per tenant.
+func (h *QuotaHandler) DroppedByTenant() map[string]int64 {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.byTen)
+}
+
+func (h *QuotaHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *QuotaHandler) Handle(ctx context.Context, record slog.Record) error {
+	s := h.state
+	tenant, ok := h.tenant, h.tenant != ""
+	if !ok && !h.groups {
+		tenant, ok = recordString(record, s.opts.Key)
+	}
+	if !ok && s.opts.Tenant != nil {
+		tenant, ok = s.opts.Tenant(ctx)
+	}
+	if !ok {
+		return h.base.Handle(ctx, record)
+	}
+	size := recordSize(record)
+	exempt := s.opts.ExemptLevel != nil && record.Level >= s.opts.ExemptLevel.Level()
+	allowed, first, quota := s.take(tenant, size, exempt)
+	if allowed {
+		return h.base.Handle(ctx, record)
+	}
+	if first && s.root.Enabled(ctx, slog.LevelWarn) {
+		r := slog.NewRecord(s.now(), slog.LevelWarn, "log quota exceeded", 0)
+		r.AddAttrs(slog.String(s.opts.Key, tenant), slog.Duration("interval", s.opts.Interval))
+		if quota.Records > 0 {
+			r.AddAttrs(slog.Int("records", quota.Records))
+		}
+		if quota.Bytes > 0 {
+			r.AddAttrs(slog.Int("bytes", quota.Bytes))
+		}
+		return s.root.Handle(ctx, r)
+	}
+	return nil
+}
+
+// take counts a record of size bytes against the quota of tenant. It
+// reports whether the record is within quota or exempt and, if not, whether
+// it is the first record dropped in the window.
+func (s *quotaState) take(tenant string, size int, exempt bool) (allowed, first bool, quota Quota) {
+	quota = Quota{Records: s.opts.Records, Bytes: s.opts.Bytes}
+	if q, ok := s.opts.Overrides[tenant]; ok {
+		quota = q
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.windows[tenant]
+	if w == nil || now.Sub(w.start) >= s.opts.Interval {
+		if w == nil && len(s.windows) >= maxQuotaTenants {
+			for t, w := range s.windows {
+				if now.Sub(w.start) >= s.opts.Interval {
+					delete(s.windows, t)
+				}
+			}
+		}
+		w = &quotaWindow{start: now}
+		s.windows[tenant] = w
+	}
+	over := (quota.Records > 0 && w.records >= quota.Records) ||
+		(quota.Bytes > 0 && w.bytes+size > quota.Bytes)
+	if !over || exempt {
+		w.records++
+		w.bytes += size
+		return true, false, quota
+	}
+	s.dropped.Add(1)
+	s.byTen[tenant]++
+	first, w.notified = !w.notified, true
+	return false, first, quota
+}
+
+func (h *QuotaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	if !h.groups {
+		for _, a := range attrs {
+			if a.Key == h.state.opts.Key {
+				h2.tenant = a.Value.Resolve().String()
+			}
+		}
+	}
+	return &h2
+}
+
+func (h *QuotaHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.groups = true
+	return &h2
+}
+
+// recordString returns the string form of the top-level attribute key of r.
+func recordString(r slog.Record, key string) (string, bool) {
+	var v string
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			v, found = a.Value.Resolve().String(), true
+		}
+		return !found
+	})
+	return v, found
+}
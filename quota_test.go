@@ -0,0 +1,102 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestQuotaHandler(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	h := NewQuotaHandler(mem, &QuotaOptions{
+		Records:     2,
+		Interval:    time.Minute,
+		Overrides:   map[string]Quota{"big": {Records: 5}},
+		ExemptLevel: slog.LevelError,
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.state.now = func() time.Time { return now }
+
+	a := slog.New(h).With("tenant", "a")
+	for range 4 {
+		a.Info("hello")
+	}
+	a.Error("still logged")
+	for range 5 {
+		slog.New(h).Info("hello", "tenant", "big")
+	}
+	slog.New(h).Info("no tenant")
+
+	var msgs []string
+	for _, r := range mem.Records(Query{}) {
+		msgs = append(msgs, r.Message)
+	}
+	want := []string{"hello", "hello", "log quota exceeded", "still logged",
+		"hello", "hello", "hello", "hello", "hello", "no tenant"}
+	if len(msgs) != len(want) {
+		t.Fatalf("got %q\nwant %q", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("record %d = %q, want %q", i, msgs[i], want[i])
+		}
+	}
+	warn := mem.Records(Query{})[2]
+	if got := attrMap(warn.Attrs); got["tenant"] != "a" || got["records"] != "2" || got["interval"] != "1m0s" {
+		t.Errorf("quota warning attrs = %v", got)
+	}
+	if h.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", h.Dropped())
+	}
+	if d := h.DroppedByTenant(); len(d) != 1 || d["a"] != 2 {
+		t.Errorf("DroppedByTenant() = %v", d)
+	}
+
+	now = now.Add(time.Minute)
+	a.Info("next window")
+	if rs := mem.Records(Query{}); rs[len(rs)-1].Message != "next window" {
+		t.Errorf("record in the next window dropped")
+	}
+}
+
+func TestQuotaHandlerBytes(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	h := NewQuotaHandler(mem, &QuotaOptions{
+		Bytes:  20,
+		Tenant: func(ctx context.Context) (string, bool) { return "ctx", true },
+	})
+	logger := slog.New(h)
+	logger.Info("0123456789")         // 10 bytes
+	logger.Info("0123456789", "k", 1) // 12 bytes, over
+	logger.Info("short")              // 5 bytes, within the 20 byte quota again
+	if n := len(mem.Records(Query{})); n != 3 {
+		t.Errorf("got %d records, want 3 including the quota warning", n)
+	}
+	if h.DroppedByTenant()["ctx"] != 1 {
+		t.Errorf("DroppedByTenant() = %v", h.DroppedByTenant())
+	}
+}
+
+func TestQuotaHandlerGroup(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	h := NewQuotaHandler(mem, &QuotaOptions{Records: 1, Interval: time.Minute})
+	g := slog.New(h).WithGroup("req")
+	for range 3 {
+		g.Info("hello", "tenant", "a") // req.tenant, not the tenant
+	}
+	if n := len(mem.Records(Query{})); n != 3 {
+		t.Errorf("got %d records, want 3", n)
+	}
+	if h.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", h.Dropped())
+	}
+
+	a := slog.New(h).With("tenant", "a").WithGroup("req")
+	for range 3 {
+		a.Info("hello", "tenant", "b")
+	}
+	if d := h.DroppedByTenant(); d["a"] != 2 || d["b"] != 0 {
+		t.Errorf("DroppedByTenant() = %v", d)
+	}
+}
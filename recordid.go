@@ -0,0 +1,95 @@
+package sloghandler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewUUIDv7 returns a new UUID version 7 (RFC 9562) in its canonical form.
+// UUIDv7s start with the creation time in milliseconds, followed by a
+// counter within the millisecond, so those from this process sort by
+// creation order as strings.
+func NewUUIDv7() string {
+	return formatUUID(newUUIDv7(time.Now()))
+}
+
+func formatUUID(b [16]byte) string {
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// uuidClock keeps the UUIDv7s of the process increasing.
+var uuidClock struct {
+	sync.Mutex
+	ms  int64
+	seq uint16
+}
+
+// newUUIDv7 returns a UUIDv7 for t whose 12-bit rand_a field is a counter,
+// as in method 1 of RFC 9562 section 6.2. A t not after the previous one
+// reuses its millisecond, advancing it when the counter overflows.
+func newUUIDv7(t time.Time) [16]byte {
+	ms := t.UnixMilli()
+	uuidClock.Lock()
+	if ms <= uuidClock.ms {
+		ms = uuidClock.ms
+		uuidClock.seq++
+		if uuidClock.seq > 0xfff {
+			ms++
+			uuidClock.seq = 0
+		}
+	} else {
+		uuidClock.seq = 0
+	}
+	uuidClock.ms = ms
+	seq := uuidClock.seq
+	uuidClock.Unlock()
+
+	var b [16]byte
+	rand.Read(b[8:])
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(ms))
+	copy(b[0:6], buf[2:])
+	b[6] = 0x70 | byte(seq>>8) // version 7
+	b[7] = byte(seq)
+	b[8] = 0x80 | b[8]&0x3f // variant 10
+	return b
+}
+
+// RecordIDKey is the default attribute key of StampRecordID.
+const RecordIDKey = "record_id"
+
+// StampRecordID returns a Transformer that adds a unique, time-sortable ID
+// to each record under key, or RecordIDKey if key is empty, so individual
+// lines can be referenced in tickets and deduplicated downstream. IDs are
+// UUIDv7s derived from the record time; see NewUUIDv7.
+//
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.StampRecordID(""))
+func StampRecordID(key string) Transformer {
+	if key == "" {
+		key = RecordIDKey
+	}
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		t := r.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		r = r.Clone()
+		r.AddAttrs(slog.String(key, formatUUID(newUUIDv7(t))))
+		return r, true
+	})
+}
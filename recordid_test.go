@@ -0,0 +1,48 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var uuidv7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewUUIDv7(t *testing.T) {
+	a := NewUUIDv7()
+	time.Sleep(2 * time.Millisecond)
+	b := NewUUIDv7()
+	if !uuidv7Pattern.MatchString(a) || !uuidv7Pattern.MatchString(b) {
+		t.Fatalf("malformed UUIDs %s %s", a, b)
+	}
+	if a >= b {
+		t.Errorf("UUIDs do not sort by time: %s >= %s", a, b)
+	}
+}
+
+func TestStampRecordID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil), StampRecordID("")))
+	for range 100 {
+		logger.Info("tick")
+	}
+	var prev string
+	for line := range strings.Lines(buf.String()) {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		id, _ := rec[RecordIDKey].(string)
+		if !uuidv7Pattern.MatchString(id) {
+			t.Fatalf("malformed ID in %s", line)
+		}
+		if id <= prev {
+			t.Fatalf("IDs are not increasing: %s after %s", id, prev)
+		}
+		prev = id
+	}
+}
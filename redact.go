@@ -0,0 +1,100 @@
+package sloghandler
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces the values removed by Redact.
+const RedactedValue = "[REDACTED]"
+
+// RedactionProfile lists what Redact removes. Names are matched
+// case-insensitively.
+type RedactionProfile struct {
+	// Keys are attributes whose values are replaced, at any group depth.
+	Keys []string
+	// URLKeys are attributes holding URLs or query strings, in which the
+	// values of QueryParams are replaced.
+	URLKeys []string
+	// QueryParams are query parameters whose values are replaced.
+	QueryParams []string
+}
+
+// HTTPRedactionProfile removes credentials from HTTP access logs: the
+// Authorization, Cookie and Set-Cookie headers and common API key headers,
+// logged as attributes named after the header, and API keys, tokens and
+// signatures in the query strings of "query", "url", "uri" and "referer"
+// attributes.
+var HTTPRedactionProfile = RedactionProfile{
+	Keys: []string{
+		"authorization", "proxy-authorization", "cookie", "set-cookie",
+		"x-api-key", "x-auth-token", "x-amz-security-token",
+	},
+	URLKeys: []string{"query", "url", "uri", "request_uri", "referer"},
+	QueryParams: []string{
+		"api_key", "apikey", "key", "token", "access_token", "id_token", "refresh_token",
+		"password", "secret", "client_secret", "signature", "sig",
+		"x-amz-signature", "x-amz-credential", "x-amz-security-token",
+	},
+}
+
+// Redact returns a Transformer that removes the values listed in p:
+//
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Redact(sloghandler.HTTPRedactionProfile))
+func Redact(p RedactionProfile) Transformer {
+	keys := lowerSet(p.Keys)
+	urlKeys := lowerSet(p.URLKeys)
+	params := lowerSet(p.QueryParams)
+	return MapAttrs(func(groups []string, a slog.Attr) slog.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case keys[key]:
+			return slog.String(a.Key, RedactedValue)
+		case urlKeys[key] && len(params) > 0 && a.Value.Kind() == slog.KindString:
+			return slog.String(a.Key, redactQuery(a.Value.String(), params))
+		}
+		return a
+	})
+}
+
+func lowerSet(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[strings.ToLower(n)] = true
+	}
+	return m
+}
+
+// redactQuery replaces the values of params in the query string of s, a URL
+// or a query string, keeping everything else as it is.
+func redactQuery(s string, params map[string]bool) string {
+	prefix, query, fragment := "", s, ""
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		prefix, query = s[:i+1], s[i+1:]
+	} else if strings.Contains(s, "://") || strings.HasPrefix(s, "/") {
+		return s // a URL without a query
+	}
+	if i := strings.IndexByte(query, '#'); i >= 0 {
+		query, fragment = query[:i], query[i:]
+	}
+	parts := strings.Split(query, "&")
+	changed := false
+	for i, part := range parts {
+		name, _, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if params[strings.ToLower(name)] {
+			parts[i] = part[:strings.IndexByte(part, '=')+1] + RedactedValue
+			changed = true
+		}
+	}
+	if !changed {
+		return s
+	}
+	return prefix + strings.Join(parts, "&") + fragment
+}
@@ -0,0 +1,33 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewTransformHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), Redact(HTTPRedactionProfile))
+	logger := slog.New(h)
+
+	logger.Info("request",
+		"url", "https://example.com/cb?code=1&ACCESS_TOKEN=abc&state=x#frag",
+		"referer", "https://example.com/",
+		slog.Group("header", "Authorization", "Basic dXNlcjpwYXNz", "Accept", "*/*"),
+	)
+	logger.With("cookie", "session=1").Info("raw", "query", "a=1&sig=zzz&b")
+
+	want := `level=INFO msg=request url="https://example.com/cb?code=1&ACCESS_TOKEN=[REDACTED]&state=x#frag" referer=https://example.com/ header.Authorization=[REDACTED] header.Accept=*/*` + "\n" +
+		`level=INFO msg=raw cookie=[REDACTED] query="a=1&sig=[REDACTED]&b"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ReloadableHandler is a slog.Handler whose underlying handler can be
+// replaced while it is in use. Loggers derived with WithAttrs and WithGroup
+// follow the replacement, and no record is dropped: Swap waits for records
+// being handled by the old handler before returning it.
+type ReloadableHandler struct {
+	state *reloadState
+	goas  []groupOrAttrs
+	cache atomic.Pointer[reloadDerived]
+}
+
+type reloadState struct {
+	mu     sync.RWMutex
+	target *reloadTarget
+}
+
+// reloadTarget wraps the current handler so derived handlers can tell by
+// pointer identity whether their cached handler is still current.
+type reloadTarget struct {
+	h slog.Handler
+}
+
+type reloadDerived struct {
+	target *reloadTarget
+	h      slog.Handler
+}
+
+// NewReloadableHandler creates a ReloadableHandler that initially sends records to h.
+func NewReloadableHandler(h slog.Handler) *ReloadableHandler {
+	return &ReloadableHandler{state: &reloadState{target: &reloadTarget{h: h}}}
+}
+
+// Swap replaces the underlying handler with h and returns the previous one.
+// The caller is responsible for closing the previous handler if needed.
+func (r *ReloadableHandler) Swap(h slog.Handler) slog.Handler {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	old := r.state.target.h
+	r.state.target = &reloadTarget{h: h}
+	return old
+}
+
+// Handler returns the current underlying handler.
+func (r *ReloadableHandler) Handler() slog.Handler {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	return r.state.target.h
+}
+
+// current returns the handler for the current target with r's attributes
+// and groups applied. The caller must hold r.state.mu.
+func (r *ReloadableHandler) current() slog.Handler {
+	target := r.state.target
+	if len(r.goas) == 0 {
+		return target.h
+	}
+	if d := r.cache.Load(); d != nil && d.target == target {
+		return d.h
+	}
+	h := target.h
+	for _, goa := range r.goas {
+		if goa.group != "" {
+			h = h.WithGroup(goa.group)
+		} else {
+			h = h.WithAttrs(goa.attrs)
+		}
+	}
+	r.cache.Store(&reloadDerived{target: target, h: h})
+	return h
+}
+
+func (r *ReloadableHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	return r.current().Enabled(ctx, level)
+}
+
+func (r *ReloadableHandler) Handle(ctx context.Context, record slog.Record) error {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	h := r.current()
+	if !h.Enabled(ctx, record.Level) {
+		// The level may have changed since the caller checked Enabled.
+		return nil
+	}
+	return h.Handle(ctx, record)
+}
+
+func (r *ReloadableHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return r
+	}
+	return r.with(groupOrAttrs{attrs: attrs})
+}
+
+func (r *ReloadableHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	return r.with(groupOrAttrs{group: name})
+}
+
+func (r *ReloadableHandler) with(goa groupOrAttrs) *ReloadableHandler {
+	return &ReloadableHandler{
+		state: r.state,
+		goas:  append(slices.Clip(r.goas), goa),
+	}
+}
@@ -0,0 +1,73 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReloadableHandler(t *testing.T) {
+	var first, second bytes.Buffer
+	newText := func(buf *bytes.Buffer, level slog.Level) slog.Handler {
+		return NewLogHandler(buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: level}})
+	}
+	r := NewReloadableHandler(newText(&first, slog.LevelInfo))
+	logger := slog.New(r).With("req", 1)
+
+	logger.Debug("hidden")
+	logger.Info("one")
+	old := r.Swap(newText(&second, slog.LevelDebug))
+	if old == nil {
+		t.Fatal("Swap returned nil")
+	}
+	logger.Debug("two")
+
+	if got := first.String(); !strings.Contains(got, "[INFO] [req:1] one") || strings.Contains(got, "hidden") {
+		t.Errorf("first handler got %q", got)
+	}
+	if got := second.String(); !strings.Contains(got, "[DEBUG] [req:1] two") {
+		t.Errorf("second handler got %q", got)
+	}
+}
+
+func TestReloadableHandlerConcurrentSwap(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[int]int)
+	newCounter := func(id int) slog.Handler {
+		return NewTransformHandler(NewLogHandler(&bytes.Buffer{}, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}}),
+			TransformerFunc(func(_ context.Context, r slog.Record) (slog.Record, bool) {
+				mu.Lock()
+				counts[id]++
+				mu.Unlock()
+				return r, true
+			}))
+	}
+	r := NewReloadableHandler(newCounter(0))
+	logger := slog.New(r).WithGroup("g").With("k", "v")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 250 {
+				logger.Info("msg")
+			}
+		}()
+	}
+	for i := 1; i <= 10; i++ {
+		r.Swap(newCounter(i))
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total != 1000 {
+		t.Errorf("handled %d records, want 1000", total)
+	}
+}
@@ -0,0 +1,175 @@
+// Package replay reads stored log records and handles them again through
+// any slog.Handler, keeping their original times. It is useful to test
+// sinks against real logs and to backfill metrics from old files:
+//
+//	f, err := os.Open("app.log")
+//	if err != nil { ... }
+//	n, err := replay.Replay(ctx, f, rulesHandler, nil)
+//
+// Records keep their time, level, message and attributes. A source
+// location cannot be restored as a program counter, so it is replayed as a
+// "source" attribute of the form "file:line".
+package replay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"strings"
+
+	"github.com/fujiwara/sloghandler"
+	"github.com/fujiwara/sloghandler/internal/logline"
+)
+
+// Format is the encoding of stored records.
+type Format int
+
+const (
+	// Auto reads one record per line, detecting for each line the
+	// sloghandler bracket format, slog JSON or logfmt.
+	Auto Format = iota
+	// NDJSON reads slog JSON lines.
+	NDJSON
+	// Text reads lines in the sloghandler bracket format, as Parse does.
+	Text
+	// MessagePack reads the output of sloghandler.NewBinaryHandler with
+	// sloghandler.MessagePack.
+	MessagePack
+	// CBOR reads the output of sloghandler.NewBinaryHandler with
+	// sloghandler.CBOR.
+	CBOR
+)
+
+// Options configures Replay.
+type Options struct {
+	// Format is the encoding of the input. Default is Auto.
+	Format Format
+	// SkipInvalid skips lines that cannot be decoded instead of returning
+	// a *DecodeError. Binary input cannot be read past an invalid record.
+	SkipInvalid bool
+}
+
+// DecodeError reports a line that cannot be decoded.
+type DecodeError struct {
+	// Line is the 1-based line number.
+	Line int
+	// Text is the content of the line.
+	Text string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("replay: line %d: %v", e.Line, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// Records returns the records read from r in format f. Lines that cannot
+// be decoded yield a *DecodeError and reading continues; other errors end
+// the sequence. Empty lines are ignored.
+func Records(r io.Reader, f Format) iter.Seq2[slog.Record, error] {
+	switch f {
+	case MessagePack:
+		return binaryRecords(r, sloghandler.MessagePack)
+	case CBOR:
+		return binaryRecords(r, sloghandler.CBOR)
+	case Auto, NDJSON, Text:
+		return lineRecords(r, f)
+	}
+	return func(yield func(slog.Record, error) bool) {
+		yield(slog.Record{}, fmt.Errorf("replay: unknown format %d", f))
+	}
+}
+
+func lineRecords(r io.Reader, f Format) iter.Seq2[slog.Record, error] {
+	decode := logline.Decode
+	switch f {
+	case NDJSON:
+		decode = logline.DecodeJSON
+	case Text:
+		decode = func(line string) (slog.Record, error) {
+			e, err := sloghandler.Parse(line)
+			if err != nil {
+				return slog.Record{}, err
+			}
+			return logline.EntryRecord(e), nil
+		}
+	}
+	return func(yield func(slog.Record, error) bool) {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for n := 1; sc.Scan(); n++ {
+			line := sc.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			rec, err := decode(line)
+			if err != nil {
+				if !yield(slog.Record{}, &DecodeError{Line: n, Text: line, Err: err}) {
+					return
+				}
+				continue
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			yield(slog.Record{}, err)
+		}
+	}
+}
+
+func binaryRecords(r io.Reader, f sloghandler.BinaryFormat) iter.Seq2[slog.Record, error] {
+	return func(yield func(slog.Record, error) bool) {
+		dec := sloghandler.NewBinaryDecoder(r, f)
+		for {
+			e, err := dec.Decode()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(slog.Record{}, err)
+				return
+			}
+			if !yield(logline.EntryRecord(e), nil) {
+				return
+			}
+		}
+	}
+}
+
+// Replay reads the records of r and passes those enabled by h to
+// h.Handle with their original times. It returns the number of records
+// handled, stopping at the first error of h or when ctx is done.
+func Replay(ctx context.Context, r io.Reader, h slog.Handler, opts *Options) (int, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	var n int
+	for rec, err := range Records(r, o.Format) {
+		if err != nil {
+			var de *DecodeError
+			if o.SkipInvalid && errors.As(err, &de) {
+				continue
+			}
+			return n, err
+		}
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, rec); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fujiwara/sloghandler"
+)
+
+var testTime = time.Date(2024, 6, 1, 12, 34, 56, 789_000_000, time.UTC)
+
+// write logs two records through h, with fixed times.
+func write(t *testing.T, h slog.Handler) {
+	t.Helper()
+	ctx := context.Background()
+	r := slog.NewRecord(testTime, slog.LevelInfo, "served", 0)
+	r.AddAttrs(slog.Int("status", 200), slog.String("path", "/api"))
+	if err := h.Handle(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	r = slog.NewRecord(testTime.Add(time.Second), slog.LevelError, "failed", 0)
+	r.AddAttrs(slog.String("err", "timeout"))
+	if err := h.Handle(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		format  Format
+		handler func(*bytes.Buffer) slog.Handler
+	}{
+		{"json", NDJSON, func(b *bytes.Buffer) slog.Handler { return slog.NewJSONHandler(b, nil) }},
+		{"text", Text, func(b *bytes.Buffer) slog.Handler { return sloghandler.NewLogHandler(b, &sloghandler.HandlerOptions{}) }},
+		{"auto", Auto, func(b *bytes.Buffer) slog.Handler { return slog.NewJSONHandler(b, nil) }},
+		{"msgpack", MessagePack, func(b *bytes.Buffer) slog.Handler {
+			return sloghandler.NewBinaryHandler(b, sloghandler.MessagePack, nil)
+		}},
+		{"cbor", CBOR, func(b *bytes.Buffer) slog.Handler { return sloghandler.NewBinaryHandler(b, sloghandler.CBOR, nil) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			write(t, tc.handler(&buf))
+			mem := sloghandler.NewMemoryHandler(nil)
+			n, err := Replay(context.Background(), &buf, mem, &Options{Format: tc.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries := mem.Records(sloghandler.Query{})
+			if n != 2 || len(entries) != 2 {
+				t.Fatalf("replayed %d records, got %d entries", n, len(entries))
+			}
+			for i, want := range []string{"[INFO] served [status:200] [path:/api]", "[ERROR] failed [err:timeout]"} {
+				e := entries[i]
+				if !e.Time.Equal(testTime.Add(time.Duration(i) * time.Second)) {
+					t.Errorf("record %d: time %v was not preserved", i, e.Time)
+				}
+				if got := e.String(); !strings.HasSuffix(got, want) {
+					t.Errorf("got %q, want suffix %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayInvalid(t *testing.T) {
+	in := `{"time":"2024-06-01T12:34:56Z","level":"INFO","msg":"one"}
+not a record
+
+{"time":"2024-06-01T12:34:57Z","level":"WARN","msg":"two"}
+`
+	mem := sloghandler.NewMemoryHandler(nil)
+	_, err := Replay(context.Background(), strings.NewReader(in), mem, &Options{Format: NDJSON})
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 2 || de.Text != "not a record" {
+		t.Errorf("expected a DecodeError for line 2, got %v", err)
+	}
+
+	n, err := Replay(context.Background(), strings.NewReader(in), mem, &Options{Format: NDJSON, SkipInvalid: true})
+	if err != nil || n != 2 {
+		t.Errorf("replayed %d records: %v", n, err)
+	}
+}
+
+func TestReplayLevel(t *testing.T) {
+	var buf bytes.Buffer
+	write(t, slog.NewJSONHandler(&buf, nil))
+	mem := sloghandler.NewMemoryHandler(&sloghandler.MemoryOptions{Level: slog.LevelWarn})
+	n, err := Replay(context.Background(), &buf, mem, nil)
+	if err != nil || n != 1 {
+		t.Errorf("replayed %d records: %v", n, err)
+	}
+}
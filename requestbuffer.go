@@ -0,0 +1,258 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestBufferOptions configures a RequestBufferHandler.
+type RequestBufferOptions struct {
+	// Level is the minimum level of the records held for a request.
+	// Default is slog.LevelDebug, so failed requests come with their
+	// debug records even if the wrapped handler logs at INFO.
+	Level slog.Leveler
+	// FlushLevel is the level at which a record causes the request's records
+	// to be written, including those held so far. Default is slog.LevelError.
+	FlushLevel slog.Leveler
+	// Latency, if positive, writes the records of requests that take longer.
+	Latency time.Duration
+	// MaxRecords bounds the records held per request, discarding the
+	// oldest beyond it. Default is 1000.
+	MaxRecords int
+	// SummaryMessage, if set, is logged at INFO with the number of records
+	// and the duration when the records of a request are discarded.
+	// Default is to discard them silently.
+	SummaryMessage string
+	// TraceSampled, if set, reports the sampling decision of the trace in
+	// a request's context, so that logs follow traces: the records of
+	// sampled traces are written as they arrive, and those of unsampled
+	// traces are held as usual. ok is false if the context has no trace.
+	// otelmetrics.SpanSampled implements it for OpenTelemetry spans.
+	TraceSampled func(ctx context.Context) (sampled, ok bool)
+}
+
+// RequestBufferHandler wraps a handler and holds the records logged with a
+// request's context until the request ends. They are written only if the
+// request fails, takes longer than the latency threshold, or logs a record
+// at FlushLevel; otherwise they are discarded, which cuts the log volume of
+// healthy traffic:
+//
+//	h := sloghandler.NewRequestBufferHandler(handler, &sloghandler.RequestBufferOptions{Latency: time.Second})
+//	logger := slog.New(h)
+//	...
+//	ctx, buf := h.Begin(ctx)
+//	err := process(ctx)
+//	buf.End(err)
+//
+// Only records logged with a context from Begin, i.e. with the *Context
+// logging methods, are held; others are passed on directly.
+type RequestBufferHandler struct {
+	base slog.Handler
+	opts *RequestBufferOptions
+}
+
+// NewRequestBufferHandler creates a RequestBufferHandler that passes records to h.
+func NewRequestBufferHandler(h slog.Handler, opts *RequestBufferOptions) *RequestBufferHandler {
+	o := RequestBufferOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelDebug
+	}
+	if o.FlushLevel == nil {
+		o.FlushLevel = slog.LevelError
+	}
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = 1000
+	}
+	return &RequestBufferHandler{base: h, opts: &o}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *RequestBufferHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+type requestBufferKey struct{}
+
+// Begin starts holding the records logged with the returned context.
+// Call End on the returned RequestBuffer when the request is done.
+func (h *RequestBufferHandler) Begin(ctx context.Context) (context.Context, *RequestBuffer) {
+	b := &RequestBuffer{opts: h.opts, root: h.base, start: time.Now()}
+	if h.opts.TraceSampled != nil {
+		if sampled, ok := h.opts.TraceSampled(ctx); ok && sampled {
+			b.kept = true
+		}
+	}
+	return context.WithValue(ctx, requestBufferKey{}, b), b
+}
+
+// Middleware returns net/http middleware that holds the records of each
+// request, writing them if the response status is 5xx, the handler panics
+// or the request is slower than Latency. Panics are re-raised after the
+// records are written.
+func (h *RequestBufferHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, buf := h.Begin(r.Context())
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p != nil || sw.status >= 500 {
+				buf.Keep()
+			}
+			buf.End(nil)
+			if p != nil {
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(sw, r.WithContext(ctx))
+	})
+}
+
+func (h *RequestBufferHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if b := requestBufferFrom(ctx); b != nil && b.holding() {
+		return level >= minLevel(ctx, h.opts.Level) || h.base.Enabled(ctx, level)
+	}
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *RequestBufferHandler) Handle(ctx context.Context, record slog.Record) error {
+	if b := requestBufferFrom(ctx); b != nil && b.hold(ctx, h.base, record) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (h *RequestBufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RequestBufferHandler{base: h.base.WithAttrs(attrs), opts: h.opts}
+}
+
+func (h *RequestBufferHandler) WithGroup(name string) slog.Handler {
+	return &RequestBufferHandler{base: h.base.WithGroup(name), opts: h.opts}
+}
+
+func requestBufferFrom(ctx context.Context) *RequestBuffer {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(requestBufferKey{}).(*RequestBuffer)
+	return b
+}
+
+// RequestBuffer holds the records of one request. See RequestBufferHandler.
+type RequestBuffer struct {
+	opts  *RequestBufferOptions
+	root  slog.Handler
+	start time.Time
+
+	mu       sync.Mutex
+	held     []heldRecord
+	overflow int
+	kept     bool // records are written as they arrive
+	ended    bool
+}
+
+type heldRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+func (b *RequestBuffer) holding() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.kept && !b.ended
+}
+
+// hold keeps record for handler, or writes the held records if record is
+// at FlushLevel. It returns false if record should be passed on directly.
+func (b *RequestBuffer) hold(ctx context.Context, handler slog.Handler, record slog.Record) bool {
+	b.mu.Lock()
+	if b.kept || b.ended {
+		b.mu.Unlock()
+		return false
+	}
+	if record.Level >= b.opts.FlushLevel.Level() {
+		b.mu.Unlock()
+		b.Keep()
+		return false
+	}
+	if len(b.held) >= b.opts.MaxRecords {
+		b.held = b.held[1:]
+		b.overflow++
+	}
+	b.held = append(b.held, heldRecord{ctx: ctx, handler: handler, record: record.Clone()})
+	b.mu.Unlock()
+	return true
+}
+
+// Keep writes the records held so far and passes later records of the
+// request on as they arrive.
+func (b *RequestBuffer) Keep() {
+	b.mu.Lock()
+	if b.kept || b.ended {
+		b.mu.Unlock()
+		return
+	}
+	b.kept = true
+	held, overflow := b.held, b.overflow
+	b.held = nil
+	b.mu.Unlock()
+
+	if overflow > 0 {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "request log buffer overflowed", 0)
+		r.AddAttrs(slog.Int("discarded", overflow))
+		b.root.Handle(context.Background(), r)
+	}
+	for _, h := range held {
+		h.handler.Handle(h.ctx, h.record)
+	}
+}
+
+// End finishes the request. The held records are written if err is not
+// nil or the request took longer than Latency, and discarded otherwise.
+// Records logged with the request's context afterwards are passed on directly.
+func (b *RequestBuffer) End(err error) {
+	elapsed := time.Since(b.start)
+	if err != nil || (b.opts.Latency > 0 && elapsed > b.opts.Latency) {
+		b.Keep()
+	}
+	b.mu.Lock()
+	n := 0
+	if !b.kept {
+		n = len(b.held) + b.overflow
+	}
+	b.held = nil
+	b.ended = true
+	b.mu.Unlock()
+
+	ctx := context.Background()
+	if n == 0 || b.opts.SummaryMessage == "" || !b.root.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, b.opts.SummaryMessage, 0)
+	r.AddAttrs(slog.Int("records", n), slog.Duration("duration", elapsed))
+	b.root.Handle(ctx, r)
+}
+
+// statusWriter records the response status for RequestBufferHandler.Middleware.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
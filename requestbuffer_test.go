@@ -0,0 +1,157 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestBufferHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), &RequestBufferOptions{SummaryMessage: "request logs discarded"})
+	logger := slog.New(h).With("app", "demo")
+
+	ctx, ok := h.Begin(context.Background())
+	logger.DebugContext(ctx, "healthy debug")
+	logger.InfoContext(ctx, "healthy info")
+	ok.End(nil)
+
+	ctx, failed := h.Begin(context.Background())
+	logger.DebugContext(ctx, "failing debug")
+	logger.InfoContext(ctx, "failing info")
+	failed.End(errors.New("boom"))
+	logger.InfoContext(ctx, "after end")
+
+	logger.Info("no request")
+
+	out := buf.String()
+	if strings.Contains(out, "healthy") {
+		t.Errorf("records of a healthy request were written:\n%s", out)
+	}
+	for _, want := range []string{
+		`msg="request logs discarded" records=2 duration=`,
+		`level=DEBUG msg="failing debug" app=demo`,
+		`msg="failing info" app=demo`,
+		`msg="after end"`,
+		`msg="no request"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRequestBufferFlushLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), &RequestBufferOptions{MaxRecords: 2})
+	logger := slog.New(h)
+
+	ctx, rb := h.Begin(context.Background())
+	for _, msg := range []string{"one", "two", "three"} {
+		logger.InfoContext(ctx, msg)
+	}
+	logger.ErrorContext(ctx, "failed")
+	logger.InfoContext(ctx, "four")
+	rb.End(nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var msgs []string
+	for _, l := range lines {
+		_, msg, _ := strings.Cut(l, "msg=")
+		msgs = append(msgs, msg)
+	}
+	want := []string{`"request log buffer overflowed" discarded=1`, "two", "three", "failed", "four"}
+	if strings.Join(msgs, ",") != strings.Join(want, ",") {
+		t.Errorf("got %q, want %q", msgs, want)
+	}
+}
+
+func TestRequestBufferLatency(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), &RequestBufferOptions{Latency: time.Millisecond})
+	logger := slog.New(h)
+	ctx, rb := h.Begin(context.Background())
+	logger.InfoContext(ctx, "slow query")
+	time.Sleep(5 * time.Millisecond)
+	rb.End(nil)
+	if !strings.Contains(buf.String(), "slow query") {
+		t.Errorf("records of a slow request were discarded: %q", buf.String())
+	}
+}
+
+func TestRequestBufferMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), nil)
+	logger := slog.New(h)
+	srv := h.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(r.Context(), "handling", "path", r.URL.Path)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	for _, path := range []string{"/ok", "/fail"} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	out := buf.String()
+	if strings.Contains(out, "path=/ok") || !strings.Contains(out, "path=/fail") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestRequestBufferMiddlewarePanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), nil)
+	logger := slog.New(h)
+	srv := h.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(r.Context(), "handling", "path", r.URL.Path)
+		panic("boom")
+	}))
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("recovered %v, want the handler's panic", p)
+			}
+		}()
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+	if !strings.Contains(buf.String(), "path=/panic") {
+		t.Errorf("records of the panicking request were discarded:\n%s", buf.String())
+	}
+}
+
+type sampledKey struct{}
+
+func TestRequestBufferTraceSampled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewRequestBufferHandler(slog.NewTextHandler(buf, nil), &RequestBufferOptions{
+		TraceSampled: func(ctx context.Context) (bool, bool) {
+			sampled, ok := ctx.Value(sampledKey{}).(bool)
+			return sampled, ok
+		},
+	})
+	logger := slog.New(h)
+	for _, tc := range []struct {
+		msg string
+		ctx context.Context
+	}{
+		{"sampled", context.WithValue(context.Background(), sampledKey{}, true)},
+		{"unsampled", context.WithValue(context.Background(), sampledKey{}, false)},
+		{"untraced", context.Background()},
+	} {
+		ctx, rb := h.Begin(tc.ctx)
+		logger.InfoContext(ctx, tc.msg)
+		if tc.msg == "sampled" && !strings.Contains(buf.String(), "msg=sampled") {
+			t.Error("record of a sampled trace was held")
+		}
+		rb.End(nil)
+	}
+	if out := buf.String(); strings.Count(out, "\n") != 1 {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
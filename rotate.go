@@ -0,0 +1,177 @@
+package sloghandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// RotatingFileOptions configures a RotatingFile.
+type RotatingFileOptions struct {
+	// MaxSize is the size in bytes beyond which the file is rotated.
+	// Default is 0 (no rotation).
+	MaxSize int64
+	// Backups is the number of rotated files kept. Default is 0.
+	Backups int
+	// Compressor, if set, compresses rotated files, which are named with
+	// its extension, e.g. app.log.1.gz. Compression happens during the
+	// write that rotates the file.
+	Compressor Compressor
+}
+
+// RotatingFile is an io.WriteCloser that appends to a file and rotates it
+// once it grows beyond a size limit. Rotated files are renamed with numeric
+// suffixes: app.log.1 is the most recent, app.log.<Backups> the oldest.
+type RotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+	comp    Compressor
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed.
+// The file is rotated before a write would make it larger than maxSize bytes,
+// keeping up to backups old files. A maxSize of 0 disables rotation.
+func OpenRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	return OpenRotatingFileWithOptions(path, &RotatingFileOptions{MaxSize: maxSize, Backups: backups})
+}
+
+// OpenRotatingFileWithOptions opens path for appending as OpenRotatingFile
+// does, with the options of opts.
+func OpenRotatingFileWithOptions(path string, opts *RotatingFileOptions) (*RotatingFile, error) {
+	o := RotatingFileOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Compressor != nil && o.Compressor.Encoding() == "" {
+		o.Compressor = nil
+	}
+	r := &RotatingFile{path: path, maxSize: o.MaxSize, backups: o.Backups, comp: o.Compressor}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = st.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating first if needed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups, renames or compresses the current file to
+// path.1 and reopens path. If the current file cannot be moved aside, it is
+// reopened for appending and rotation is retried on the next write.
+func (r *RotatingFile) rotate() error {
+	err := r.f.Close()
+	r.f = nil
+	if err == nil {
+		err = r.shift()
+	}
+	return errors.Join(err, r.open())
+}
+
+// shift moves the closed current file and its backups aside.
+func (r *RotatingFile) shift() error {
+	if r.backups == 0 {
+		return os.Remove(r.path)
+	}
+	for i := r.backups - 1; i > 0; i-- {
+		os.Rename(r.backupName(i), r.backupName(i+1))
+	}
+	return r.archive(r.backupName(1))
+}
+
+// backupName returns the name of the i-th backup.
+func (r *RotatingFile) backupName(i int) string {
+	name := fmt.Sprintf("%s.%d", r.path, i)
+	if r.comp != nil {
+		name += r.comp.Extension()
+	}
+	return name
+}
+
+// archive moves the current file to name, compressing it if configured.
+func (r *RotatingFile) archive(name string) error {
+	if r.comp == nil {
+		return os.Rename(r.path, name)
+	}
+	src, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	zw, err := r.comp.NewWriter(dst)
+	if err == nil {
+		_, err = io.Copy(zw, src)
+		err = errors.Join(err, zw.Close())
+	}
+	src.Close() // before removing it, for Windows
+	if err = errors.Join(err, dst.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return err
+	}
+	return os.Remove(r.path)
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
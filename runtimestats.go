@@ -0,0 +1,48 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+var runtimeStatsSamples = []string{
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/heap/unused:bytes",
+	"/sched/goroutines:goroutines",
+}
+
+// RuntimeStatsEnricher adds a "runtime" group with the heap in use, the
+// number of goroutines and the duration and age of the last GC pause, to
+// help diagnose whether failures correlate with resource pressure. Unlike
+// MemStatsEnricher it does not stop the world. Use it with EnrichAtLevel:
+//
+//	sloghandler.EnrichAtLevel(slog.LevelError, sloghandler.RuntimeStatsEnricher)
+func RuntimeStatsEnricher(ctx context.Context, r slog.Record) []slog.Attr {
+	samples := make([]metrics.Sample, len(runtimeStatsSamples))
+	for i, name := range runtimeStatsSamples {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	value := func(i int) uint64 {
+		if samples[i].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return samples[i].Value.Uint64()
+	}
+	attrs := []slog.Attr{
+		slog.Uint64("heap_inuse_bytes", value(0)+value(1)),
+		slog.Uint64("goroutines", value(2)),
+	}
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	if gc.NumGC > 0 && len(gc.Pause) > 0 {
+		attrs = append(attrs,
+			slog.Duration("last_gc_pause", gc.Pause[0]),
+			slog.Duration("last_gc_ago", time.Since(gc.LastGC).Round(time.Millisecond)),
+		)
+	}
+	return []slog.Attr{{Key: "runtime", Value: slog.GroupValue(attrs...)}}
+}
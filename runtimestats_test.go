@@ -0,0 +1,33 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+func TestRuntimeStatsEnricher(t *testing.T) {
+	runtime.GC()
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil),
+		EnrichAtLevel(slog.LevelError, RuntimeStatsEnricher),
+	))
+	logger.Error("failed")
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	rt, _ := m["runtime"].(map[string]any)
+	if v, _ := rt["heap_inuse_bytes"].(float64); v <= 0 {
+		t.Errorf("unexpected heap_inuse_bytes in %v", rt)
+	}
+	if v, _ := rt["goroutines"].(float64); v < 1 {
+		t.Errorf("unexpected goroutines in %v", rt)
+	}
+	if _, ok := rt["last_gc_pause"]; !ok {
+		t.Errorf("missing last_gc_pause in %v", rt)
+	}
+}
@@ -0,0 +1,217 @@
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AttrSchema declares the attributes records are expected to carry. Keys
+// are joined to their groups by dots, as in "http.status", and include the
+// attributes added with WithAttrs.
+type AttrSchema struct {
+	// Rules apply to the records they match.
+	Rules []SchemaRule
+	// Types are the kinds of attributes checked in every record having
+	// them, e.g. {"user_id": slog.KindString}.
+	Types map[string]slog.Kind
+}
+
+// SchemaRule declares the attributes of one kind of record. A rule matches
+// records with its Message and its Event, as set by Event; a rule with
+// neither matches every record.
+type SchemaRule struct {
+	Message string
+	Event   string
+	// Required are keys the matched records must have.
+	Required []string
+	// Types are the kinds of attributes of the matched records, checked
+	// when present. Add the key to Required as well to demand it.
+	Types map[string]slog.Kind
+}
+
+// SchemaOptions configures a handler created by NewSchemaHandler.
+type SchemaOptions struct {
+	// OnViolation is called for each record breaking the schema. Default
+	// logs a WARN record "schema violation" to the wrapped handler after
+	// the record.
+	OnViolation func(*SchemaViolation)
+}
+
+// SchemaViolation describes a record breaking an AttrSchema.
+type SchemaViolation struct {
+	Message string
+	Event   string
+	Source  *slog.Source
+	// Missing lists the required keys the record lacks.
+	Missing []string
+	// Mismatched lists attributes of the wrong kind, as
+	// "key: want string, got int64".
+	Mismatched []string
+}
+
+func (v *SchemaViolation) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sloghandler: record %q", v.Message)
+	if v.Source != nil && v.Source.File != "" {
+		fmt.Fprintf(&b, " at %s:%d", v.Source.File, v.Source.Line)
+	}
+	b.WriteString(" breaks schema:")
+	if len(v.Missing) > 0 {
+		b.WriteString(" missing " + strings.Join(v.Missing, ", "))
+		if len(v.Mismatched) > 0 {
+			b.WriteByte(';')
+		}
+	}
+	if len(v.Mismatched) > 0 {
+		b.WriteString(" " + strings.Join(v.Mismatched, "; "))
+	}
+	return b.String()
+}
+
+type schemaHandler struct {
+	base   slog.Handler
+	root   slog.Handler // receives violations, without derived attributes
+	scope  attrScope
+	schema AttrSchema
+	opts   SchemaOptions
+}
+
+// NewSchemaHandler wraps h to check records against schema before passing
+// them on, catching drift in structured logging, such as a renamed key or
+// an ID logged as a number, while developing:
+//
+//	schema := sloghandler.AttrSchema{
+//		Rules: []sloghandler.SchemaRule{{
+//			Event:    "payment.succeeded",
+//			Required: []string{"order", "amount"},
+//			Types:    map[string]slog.Kind{"amount": slog.KindInt64},
+//		}},
+//		Types: map[string]slog.Kind{"user_id": slog.KindString},
+//	}
+//	h = sloghandler.NewSchemaHandler(h, schema, nil)
+//
+// Records are passed on whether or not they conform. In tests, fail on
+// violations with:
+//
+//	&sloghandler.SchemaOptions{OnViolation: func(v *sloghandler.SchemaViolation) { t.Error(v) }}
+//
+// Checking costs a pass over the attributes of each record; leave the
+// handler out of production builds where that matters.
+func NewSchemaHandler(h slog.Handler, schema AttrSchema, opts *SchemaOptions) slog.Handler {
+	s := &schemaHandler{base: h, root: h, schema: schema}
+	if opts != nil {
+		s.opts = *opts
+	}
+	return s
+}
+
+// Unwrap returns the wrapped handler.
+func (h *schemaHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *schemaHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *schemaHandler) Handle(ctx context.Context, r slog.Record) error {
+	v := h.check(r)
+	err := h.base.Handle(ctx, r)
+	if v == nil {
+		return err
+	}
+	if h.opts.OnViolation != nil {
+		h.opts.OnViolation(v)
+		return err
+	}
+	if h.root.Enabled(ctx, slog.LevelWarn) {
+		vr := slog.NewRecord(time.Now(), slog.LevelWarn, "schema violation", 0)
+		vr.AddAttrs(slog.String("record", v.Message))
+		if v.Event != "" {
+			vr.AddAttrs(slog.String(EventKey, v.Event))
+		}
+		if v.Source != nil && v.Source.File != "" {
+			vr.AddAttrs(slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", v.Source.File, v.Source.Line)))
+		}
+		if len(v.Missing) > 0 {
+			vr.AddAttrs(slog.String("missing", strings.Join(v.Missing, ",")))
+		}
+		if len(v.Mismatched) > 0 {
+			vr.AddAttrs(slog.String("mismatched", strings.Join(v.Mismatched, "; ")))
+		}
+		h.root.Handle(ctx, vr)
+	}
+	return err
+}
+
+// check returns the violation of r, or nil if r conforms.
+func (h *schemaHandler) check(r slog.Record) *SchemaViolation {
+	e := newEntry(r, h.scope)
+	kinds := make(map[string]slog.Kind, len(e.Attrs))
+	for _, a := range e.Attrs {
+		if _, ok := kinds[a.Key]; !ok {
+			kinds[a.Key] = a.Value.Kind()
+		}
+	}
+	event := ""
+	if v, ok := e.Attr(EventKey); ok {
+		event = v.String()
+	}
+	v := &SchemaViolation{Message: e.Message, Event: event, Source: e.Source}
+	checkTypes := func(types map[string]slog.Kind) {
+		for key, want := range types {
+			if got, ok := kinds[key]; ok && got != want {
+				v.Mismatched = append(v.Mismatched, fmt.Sprintf("%s: want %s, got %s", key, kindName(want), kindName(got)))
+			}
+		}
+	}
+	checkTypes(h.schema.Types)
+	for _, rule := range h.schema.Rules {
+		if rule.Message != "" && rule.Message != e.Message || rule.Event != "" && rule.Event != event {
+			continue
+		}
+		for _, key := range rule.Required {
+			if _, ok := kinds[key]; !ok {
+				v.Missing = append(v.Missing, key)
+			}
+		}
+		checkTypes(rule.Types)
+	}
+	if len(v.Missing) == 0 && len(v.Mismatched) == 0 {
+		return nil
+	}
+	slices.Sort(v.Missing)
+	v.Missing = slices.Compact(v.Missing)
+	slices.Sort(v.Mismatched)
+	v.Mismatched = slices.Compact(v.Mismatched)
+	return v
+}
+
+// kindName names k in lower case, as in "int64".
+func kindName(k slog.Kind) string {
+	return strings.ToLower(k.String())
+}
+
+func (h *schemaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *schemaHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
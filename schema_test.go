@@ -0,0 +1,68 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+var testSchema = AttrSchema{
+	Rules: []SchemaRule{
+		{Event: "payment.succeeded", Required: []string{"order", "amount"}, Types: map[string]slog.Kind{"amount": slog.KindInt64}},
+		{Message: "request served", Required: []string{"http.status"}},
+	},
+	Types: map[string]slog.Kind{"user_id": slog.KindString},
+}
+
+func TestSchemaHandler(t *testing.T) {
+	var violations []*SchemaViolation
+	h := NewSchemaHandler(NewMemoryHandler(nil), testSchema, &SchemaOptions{
+		OnViolation: func(v *SchemaViolation) { violations = append(violations, v) },
+	})
+	logger := slog.New(h)
+	logger.Info("payment.succeeded", EventKey, "payment.succeeded", "order", "o-1", "amount", 100)
+	logger.With("user_id", "u-1").WithGroup("http").Info("request served", "status", 200)
+	logger.Info("unrelated", "k", "v")
+	if len(violations) != 0 {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+
+	logger.Info("payment.succeeded", EventKey, "payment.succeeded", "amount", "100", "user_id", 7)
+	logger.WithGroup("http").Info("request served", "code", 200)
+	if len(violations) != 2 {
+		t.Fatalf("got %d violations, want 2", len(violations))
+	}
+	v := violations[0]
+	if v.Event != "payment.succeeded" || strings.Join(v.Missing, ",") != "order" ||
+		strings.Join(v.Mismatched, ";") != "amount: want int64, got string;user_id: want string, got int64" {
+		t.Errorf("unexpected violation %+v", v)
+	}
+	if v.Source == nil || !strings.HasSuffix(v.Source.File, "schema_test.go") {
+		t.Errorf("source = %v", v.Source)
+	}
+	if got := violations[1].Missing; len(got) != 1 || got[0] != "http.status" {
+		t.Errorf("missing = %v", got)
+	}
+	if err := error(violations[1]); !strings.Contains(err.Error(), `record "request served" at `) || !strings.HasSuffix(err.Error(), "breaks schema: missing http.status") {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestSchemaHandlerDefault(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewLogHandler(&buf, &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}})
+	logger := slog.New(NewSchemaHandler(base, testSchema, nil)).With("app", "web")
+	logger.Info("request served")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "[INFO] [app:web] request served") {
+		t.Errorf("record = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "[WARN] schema violation [record:request served]") ||
+		!strings.HasSuffix(lines[1], "[missing:http.status]") || strings.Contains(lines[1], "app:web") {
+		t.Errorf("violation = %q", lines[1])
+	}
+}
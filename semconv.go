@@ -0,0 +1,94 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DefaultSemconvMapping maps common attribute keys, and the names of older
+// OpenTelemetry semantic conventions, to the current semantic convention names.
+var DefaultSemconvMapping = map[string]string{
+	"method":                               "http.request.method",
+	"http.method":                          "http.request.method",
+	"status":                               "http.response.status_code",
+	"status_code":                          "http.response.status_code",
+	"http.status":                          "http.response.status_code",
+	"http.status_code":                     "http.response.status_code",
+	"url":                                  "url.full",
+	"http.url":                             "url.full",
+	"path":                                 "url.path",
+	"http.path":                            "url.path",
+	"http.target":                          "url.path",
+	"http.scheme":                          "url.scheme",
+	"query":                                "url.query",
+	"user_agent":                           "user_agent.original",
+	"http.user_agent":                      "user_agent.original",
+	"remote_addr":                          "client.address",
+	"client_ip":                            "client.address",
+	"http.client_ip":                       "client.address",
+	"http.request_content_length":          "http.request.body.size",
+	"http.response_content_length":         "http.response.body.size",
+	"http.flavor":                          "network.protocol.version",
+	"net.peer.name":                        "server.address",
+	"net.peer.port":                        "server.port",
+	"net.host.name":                        "server.address",
+	"net.host.port":                        "server.port",
+	"net.sock.peer.addr":                   "network.peer.address",
+	"net.sock.peer.port":                   "network.peer.port",
+	"db.statement":                         "db.query.text",
+	"db.operation":                         "db.operation.name",
+	"rpc.grpc.status":                      "rpc.grpc.status_code",
+	"messaging.destination":                "messaging.destination.name",
+	"messaging.message_payload_size_bytes": "messaging.message.body.size",
+}
+
+// NormalizeSemconv returns a Transformer that renames attributes to
+// OpenTelemetry semantic convention names, so that the text, JSON and OTLP
+// outputs of a service agree on keys such as "http.request.method".
+//
+// mapping is keyed by the dotted path of an attribute, including its
+// groups: "http.method" matches both the key "http.method" and the key
+// "method" in group "http". A renamed attribute leaves its groups and is
+// added at the top level under the mapped name. A nil mapping uses
+// DefaultSemconvMapping.
+func NormalizeSemconv(mapping map[string]string) Transformer {
+	if mapping == nil {
+		mapping = DefaultSemconvMapping
+	}
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		var renamed []slog.Attr
+		attrs := semconvAttrs("", recordAttrs(r), mapping, &renamed)
+		if len(renamed) == 0 {
+			return r, true
+		}
+		r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r2.AddAttrs(attrs...)
+		r2.AddAttrs(renamed...)
+		return r2, true
+	})
+}
+
+// semconvAttrs returns attrs without the attributes renamed by mapping,
+// which it appends to renamed with their new keys.
+func semconvAttrs(prefix string, attrs []slog.Attr, mapping map[string]string, renamed *[]slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			p := prefix
+			if a.Key != "" {
+				p += a.Key + "."
+			}
+			if members := semconvAttrs(p, a.Value.Group(), mapping, renamed); len(members) > 0 {
+				out = append(out, slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)})
+			}
+			continue
+		}
+		if name, ok := mapping[prefix+a.Key]; ok && a.Key != "" {
+			*renamed = append(*renamed, slog.Attr{Key: name, Value: a.Value})
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
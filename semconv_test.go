@@ -0,0 +1,40 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNormalizeSemconv(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil), NormalizeSemconv(nil)))
+	logger.WithGroup("http").With("method", "GET").
+		Info("request", "status", 200, "route", "/users/{id}", slog.Group("client", "id", 3))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["http.request.method"] != "GET" || m["http.response.status_code"] != float64(200) {
+		t.Errorf("attributes not renamed: %v", m)
+	}
+	http, _ := m["http"].(map[string]any)
+	if http["route"] != "/users/{id}" || http["method"] != nil || http["status"] != nil {
+		t.Errorf("unexpected http group %v", http)
+	}
+	if client, _ := http["client"].(map[string]any); client["id"] != float64(3) {
+		t.Errorf("unmapped group changed: %v", http["client"])
+	}
+}
+
+func TestNormalizeSemconvCustomMapping(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewTextHandler(buf, nil), NormalizeSemconv(map[string]string{"svc": "service.name"})))
+	logger.Info("hello", "svc", "api", "method", "GET")
+	want := "level=INFO msg=hello method=GET service.name=api\n"
+	if !bytes.HasSuffix(buf.Bytes(), []byte(want)) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,128 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+)
+
+// SeverityRule changes the level of the records it matches. A rule matches
+// when all of its conditions that are set hold:
+//
+//	rules := []sloghandler.SeverityRule{
+//		{Message: regexp.MustCompile(`^connection reset`), Level: slog.LevelError},
+//		{Key: "retryable", Match: sloghandler.Equals("true"), Level: slog.LevelWarn},
+//	}
+type SeverityRule struct {
+	// Message, if set, must match the message.
+	Message *regexp.Regexp
+	// Key is a top-level attribute tested by Match, including attributes
+	// added with WithAttrs outside groups.
+	Key string
+	// Match reports whether the attribute value matches. A nil Match
+	// matches any value of a present attribute. See Equals and AtLeast.
+	Match func(v slog.Value) bool
+	// Level is the new level of matching records. It may be higher or lower.
+	Level slog.Level
+	// OnlyBelow and OnlyAbove, if set, limit the rule to records whose
+	// original level is below or above them, e.g. to raise but never lower.
+	OnlyBelow, OnlyAbove slog.Leveler
+}
+
+func (r *SeverityRule) matches(record slog.Record, attrs []slog.Attr) bool {
+	if r.OnlyBelow != nil && record.Level >= r.OnlyBelow.Level() {
+		return false
+	}
+	if r.OnlyAbove != nil && record.Level <= r.OnlyAbove.Level() {
+		return false
+	}
+	if r.Message != nil && !r.Message.MatchString(record.Message) {
+		return false
+	}
+	if r.Key == "" {
+		return true
+	}
+	match := func(a slog.Attr) bool {
+		return a.Key == r.Key && (r.Match == nil || r.Match(a.Value.Resolve()))
+	}
+	if slices.ContainsFunc(attrs, match) {
+		return true
+	}
+	found := false
+	record.Attrs(func(a slog.Attr) bool {
+		found = match(a)
+		return !found
+	})
+	return found
+}
+
+// SeverityHandler wraps a handler and changes the level of records matching
+// its rules before the wrapped handler filters or counts them, so operators
+// can tune the severity of noisy libraries in one place. The first matching
+// rule wins.
+//
+// A rule raising records to a level the wrapped handler logs makes the
+// handler enabled for every level below, so that the records can be
+// examined; the records that keep a disabled level are then dropped.
+type SeverityHandler struct {
+	base   slog.Handler
+	rules  []SeverityRule
+	attrs  []slog.Attr // top-level attributes added with WithAttrs
+	groups bool        // a group was opened with WithGroup
+}
+
+// NewSeverityHandler creates a SeverityHandler applying rules to the
+// records passed to h.
+func NewSeverityHandler(h slog.Handler, rules ...SeverityRule) *SeverityHandler {
+	return &SeverityHandler{base: h, rules: rules}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *SeverityHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *SeverityHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.base.Enabled(ctx, level) {
+		return true
+	}
+	for i := range h.rules {
+		if r := &h.rules[i]; r.Level > level && h.base.Enabled(ctx, r.Level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *SeverityHandler) Handle(ctx context.Context, record slog.Record) error {
+	for i := range h.rules {
+		if r := &h.rules[i]; r.matches(record, h.attrs) {
+			record.Level = r.Level
+			break
+		}
+	}
+	if !h.base.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (h *SeverityHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	if !h.groups {
+		h2.attrs = append(slices.Clip(h.attrs), attrs...)
+	}
+	return &h2
+}
+
+func (h *SeverityHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.groups = true
+	return &h2
+}
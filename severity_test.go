@@ -0,0 +1,51 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSeverityHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	base := NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+	})
+	h := NewSeverityHandler(base,
+		SeverityRule{Message: regexp.MustCompile(`^connection reset`), Level: slog.LevelError},
+		SeverityRule{Key: "retryable", Match: Equals("true"), Level: slog.LevelWarn, OnlyAbove: slog.LevelWarn},
+		SeverityRule{Key: "lib", Match: Equals("chatty"), Level: slog.LevelDebug},
+	)
+	logger := slog.New(h)
+	ctx := context.Background()
+
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("not enabled for DEBUG records that may be raised")
+	}
+	logger.Debug("connection reset by peer")
+	logger.Debug("ignored")
+	logger.Error("upload failed", "retryable", true)
+	logger.Info("retrying", "retryable", true)
+	logger.With("lib", "chatty").Info("noise")
+	logger.With("lib", "chatty").WithGroup("g").Info("still noise")
+	logger.WithGroup("g").With("lib", "chatty").Info("grouped attribute")
+
+	want := []string{
+		"[ERROR] connection reset by peer",
+		"[WARN] upload failed [retryable:true]",
+		"[INFO] retrying [retryable:true]",
+		"[INFO] [lib:chatty] grouped attribute",
+	}
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines:\n%s", len(got), buf.String())
+	}
+	for i := range want {
+		if !strings.HasSuffix(got[i], want[i]) {
+			t.Errorf("line %d = %q, want suffix %q", i, got[i], want[i])
+		}
+	}
+}
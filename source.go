@@ -2,22 +2,100 @@ package sloghandler
 
 import (
 	"bytes"
-	"fmt"
 	"log/slog"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
-type sourceCacheKey struct {
-	path  string
-	depth int
+// defaultSourceCacheSize is the number of call sites cached when
+// HandlerOptions.SourceCacheSize is 0.
+const defaultSourceCacheSize = 4096
+
+// SourceCacheStats returns the source cache statistics of a handler created
+// by NewLogHandler. It returns false for other handlers or when source
+// caching is disabled.
+func SourceCacheStats(h slog.Handler) (CacheStats, bool) {
+	lh, ok := h.(*logHandler)
+	if !ok || lh.sources == nil {
+		return CacheStats{}, false
+	}
+	return lh.sources.stats(), true
 }
 
-func (h *logHandler) getFilePath(path string) []byte {
-	cacheKey := sourceCacheKey{path: path, depth: h.opts.SourceDepth}
-	if cached, ok := h.sourceCache.Load(cacheKey); ok {
-		return cached.([]byte)
+// sourceCache is a bounded LRU cache of rendered " [file:line]" fragments
+// keyed by program counter. It is shared by handlers derived with WithAttrs.
+type sourceCache = lruCache[uintptr, []byte]
+
+func newSourceCache(size int) *sourceCache {
+	if size < 0 {
+		return nil
 	}
+	if size == 0 {
+		size = defaultSourceCacheSize
+	}
+	return newLRUCache[uintptr, []byte](size)
+}
 
+// SourceAttr returns a reserved attribute that overrides the source location
+// reported for the record it is added to, so code that replays records from
+// queues or parses external logs can preserve the original call sites:
+//
+//	logger.Info(msg, sloghandler.SourceAttr(&slog.Source{File: file, Line: line}))
+//
+// The attribute is recognized among a record's own attributes, not those
+// added with WithAttrs, and is shown in place of the caller's location
+// even when AddSource is false.
+func SourceAttr(s *slog.Source) slog.Attr {
+	return slog.Any(slog.SourceKey, s)
+}
+
+// sourceOverride reports whether a is a source override attribute.
+func sourceOverride(a slog.Attr) (*slog.Source, bool) {
+	if a.Key != slog.SourceKey || a.Value.Kind() != slog.KindAny {
+		return nil, false
+	}
+	switch s := a.Value.Any().(type) {
+	case *slog.Source:
+		return s, s != nil
+	case slog.Source:
+		return &s, true
+	}
+	return nil, false
+}
+
+// recordSource returns the source override of r, if any.
+func recordSource(r slog.Record) *slog.Source {
+	var src *slog.Source
+	r.Attrs(func(a slog.Attr) bool {
+		if s, ok := sourceOverride(a); ok {
+			src = s
+			return false
+		}
+		return true
+	})
+	return src
+}
+
+// absDir returns dir as an absolute path, or "" if dir is empty or cannot be resolved.
+func absDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
+
+// getFilePath formats path according to SourceRelativeTo and SourceDepth.
+func (h *logHandler) getFilePath(path string) []byte {
+	if h.sourceRoot != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(h.sourceRoot, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return []byte("./" + filepath.ToSlash(rel))
+		}
+	}
 	depth := h.opts.SourceDepth
 	if depth < 0 {
 		depth = 0 // Default to 0 if negative
@@ -25,9 +103,7 @@ func (h *logHandler) getFilePath(path string) []byte {
 
 	if depth == 0 {
 		// Show only filename
-		result := []byte(filepath.Base(path))
-		h.sourceCache.Store(cacheKey, result)
-		return result
+		return []byte(filepath.Base(path))
 	}
 
 	// Build path with specified depth
@@ -39,14 +115,36 @@ func (h *logHandler) getFilePath(path string) []byte {
 		currentPath = filepath.Dir(currentPath)
 	}
 
-	result := []byte(filepath.Join(parts...))
-	h.sourceCache.Store(cacheKey, result)
-	return result
+	return []byte(filepath.Join(parts...))
+}
+
+// sourceFragment renders s as " [file:line]".
+func (h *logHandler) sourceFragment(s *slog.Source) []byte {
+	frag := make([]byte, 0, 64)
+	frag = append(frag, " ["...)
+	frag = append(frag, h.getFilePath(s.File)...)
+	frag = append(frag, ':')
+	frag = strconv.AppendInt(frag, int64(s.Line), 10)
+	return append(frag, ']')
 }
 
 func (h *logHandler) printSource(buf *bytes.Buffer, record slog.Record) {
-	if s := record.Source(); s != nil {
-		file := h.getFilePath(s.File)
-		fmt.Fprintf(buf, " [%s:%d]", file, s.Line)
+	if record.PC == 0 {
+		return
+	}
+	if h.sources != nil {
+		if frag, ok := h.sources.get(record.PC); ok {
+			buf.Write(frag)
+			return
+		}
+	}
+	s := record.Source()
+	if s == nil {
+		return
+	}
+	frag := h.sourceFragment(s)
+	if h.sources != nil {
+		h.sources.add(record.PC, frag)
 	}
+	buf.Write(frag)
 }
@@ -0,0 +1,111 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceCache(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions:  slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true},
+		SourceCacheSize: 2,
+	})
+	child := h.WithAttrs([]slog.Attr{slog.String("k", "v")})
+
+	caller := func() uintptr {
+		pc, _, _, _ := runtime.Caller(1)
+		return pc
+	}
+	pcs := []uintptr{
+		caller(),
+		caller(),
+		caller(),
+	}
+	for _, pc := range []uintptr{pcs[0], pcs[1], pcs[0], pcs[2], pcs[1]} {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", pc)
+		if err := child.Handle(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.Contains(buf.String(), " [source_test.go:") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+	stats, ok := SourceCacheStats(h)
+	if !ok {
+		t.Fatal("no stats")
+	}
+	want := CacheStats{Hits: 1, Misses: 4, Evictions: 2, Len: 2, Cap: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+
+	disabled := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions:  slog.HandlerOptions{Level: slog.LevelInfo},
+		SourceCacheSize: -1,
+	})
+	if _, ok := SourceCacheStats(disabled); ok {
+		t.Error("expected no stats when caching is disabled")
+	}
+}
+
+func TestSourceRelativeTo(t *testing.T) {
+	root := t.TempDir()
+	h := NewLogHandler(nil, &HandlerOptions{
+		SourceDepth:      1,
+		SourceRelativeTo: root,
+	}).(*logHandler)
+	for path, want := range map[string]string{
+		filepath.Join(root, "cmd", "api", "main.go"): "./cmd/api/main.go",
+		filepath.Join(root, "main.go"):               "./main.go",
+		filepath.Join(root+"x", "pkg", "main.go"):    "pkg/main.go",
+		filepath.Join(root, "..", "other", "x.go"):   "other/x.go",
+		"github.com/example/app/main.go":             "app/main.go",
+	} {
+		if got := string(h.getFilePath(path)); got != want {
+			t.Errorf("getFilePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h = NewLogHandler(nil, &HandlerOptions{SourceRelativeTo: "."}).(*logHandler)
+	if got := string(h.getFilePath(filepath.Join(wd, "source.go"))); got != "./source.go" {
+		t.Errorf("relative to the working directory: got %q", got)
+	}
+}
+
+func TestSourceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		SourceDepth:    1,
+	}))
+	logger.Info("replayed", SourceAttr(&slog.Source{File: "/src/worker/job.go", Line: 42}), "id", 7)
+	if got, want := buf.String(), " [worker/job.go:42] replayed [id:7]\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("got %q, want suffix %q", got, want)
+	}
+
+	buf.Reset()
+	logger.Info("value", slog.Any(slog.SourceKey, slog.Source{File: "a.go", Line: 1}))
+	logger.Info("plain", "source", "not a location")
+	if got := buf.String(); !strings.Contains(got, " [a.go:1] value\n") || !strings.Contains(got, "plain [source:not a location]") {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	m := NewMemoryHandler(&MemoryOptions{})
+	slog.New(m).Info("replayed", SourceAttr(&slog.Source{File: "/src/job.go", Line: 3}), "id", 7)
+	e := m.Records(Query{})[0]
+	if e.Source == nil || e.Source.Line != 3 || len(e.Attrs) != 1 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
@@ -0,0 +1,156 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// SourceFilterOptions configures a SourceFilterHandler.
+//
+// A pattern matches a record if it matches the import path of the package
+// that logged it or the trailing elements of its source file path:
+//
+//   - "github.com/foo/bar/..." matches the package and its subpackages.
+//   - Other patterns are path.Match globs. "github.com/foo/*" matches the
+//     packages directly under github.com/foo, and "legacy/*.go" matches the
+//     files in any directory named legacy.
+type SourceFilterOptions struct {
+	// Allow, if not empty, passes only records whose source matches one of
+	// these patterns.
+	Allow []string
+	// Deny drops records whose source matches one of these patterns, even
+	// if they are allowed.
+	Deny []string
+}
+
+// SourceFilterHandler wraps a handler and drops records by the location
+// that logged them, so a dependency's internal logging can be silenced
+// without changing levels. The location is taken from the record's PC or
+// from an attribute added with SourceAttr. Records without a location pass.
+type SourceFilterHandler struct {
+	base   slog.Handler
+	filter *sourceFilter
+}
+
+type sourceFilter struct {
+	allow []string
+	deny  []string
+	cache sync.Map // PC -> bool
+}
+
+// NewSourceFilterHandler creates a SourceFilterHandler that passes the
+// records matching opts to h.
+func NewSourceFilterHandler(h slog.Handler, opts *SourceFilterOptions) *SourceFilterHandler {
+	o := SourceFilterOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	return &SourceFilterHandler{base: h, filter: &sourceFilter{allow: o.Allow, deny: o.Deny}}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *SourceFilterHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *SourceFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *SourceFilterHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.filter.pass(record) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (h *SourceFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SourceFilterHandler{base: h.base.WithAttrs(attrs), filter: h.filter}
+}
+
+func (h *SourceFilterHandler) WithGroup(name string) slog.Handler {
+	return &SourceFilterHandler{base: h.base.WithGroup(name), filter: h.filter}
+}
+
+// pass reports whether r should be handled. Decisions for call sites are
+// cached by PC.
+func (f *sourceFilter) pass(r slog.Record) bool {
+	if s := recordSource(r); s != nil {
+		return f.match(funcPackage(s.Function), s.File)
+	}
+	if r.PC == 0 {
+		return true
+	}
+	if ok, found := f.cache.Load(r.PC); found {
+		return ok.(bool)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	ok := f.match(funcPackage(frame.Function), frame.File)
+	f.cache.Store(r.PC, ok)
+	return ok
+}
+
+func (f *sourceFilter) match(pkg, file string) bool {
+	if pkg == "" && file == "" {
+		return true
+	}
+	file = filepath.ToSlash(file)
+	for _, p := range f.deny {
+		if matchSource(p, pkg, file) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, p := range f.allow {
+		if matchSource(p, pkg, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSource reports whether pattern matches the package import path pkg
+// or the trailing elements of file. See SourceFilterOptions.
+func matchSource(pattern, pkg, file string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg != "" && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/"))
+	}
+	if pkg != "" {
+		if ok, _ := path.Match(pattern, pkg); ok {
+			return true
+		}
+	}
+	if file == "" {
+		return false
+	}
+	// Match as many trailing elements as the pattern has.
+	n := strings.Count(pattern, "/") + 1
+	i := len(file)
+	for ; n > 0 && i > 0; n-- {
+		i = strings.LastIndexByte(file[:i], '/')
+		if i < 0 {
+			i = 0
+			break
+		}
+	}
+	ok, _ := path.Match(pattern, strings.TrimPrefix(file[i:], "/"))
+	return ok
+}
+
+// funcPackage returns the import path of the package of a function name
+// as reported by runtime.Frame, such as "github.com/foo/bar" for
+// "github.com/foo/bar.(*T).Method".
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot >= 0 {
+		return fn[:slash+dot]
+	}
+	return fn
+}
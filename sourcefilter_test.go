@@ -0,0 +1,50 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceFilterHandler(t *testing.T) {
+	vendored := SourceAttr(&slog.Source{Function: "github.com/noisy/lib/internal/pool.(*Pool).Get", File: "/go/pkg/mod/github.com/noisy/lib/internal/pool/pool.go", Line: 10})
+	legacy := SourceAttr(&slog.Source{File: "/src/app/legacy/handler.go", Line: 20})
+
+	tests := []struct {
+		name string
+		opts SourceFilterOptions
+		want []string
+	}{
+		{"no patterns", SourceFilterOptions{}, []string{"own", "vendored", "legacy", "unknown"}},
+		{"deny subpackages", SourceFilterOptions{Deny: []string{"github.com/noisy/lib/..."}}, []string{"own", "legacy", "unknown"}},
+		{"deny file glob", SourceFilterOptions{Deny: []string{"legacy/*.go", "sourcefilter_test.go"}}, []string{"vendored", "unknown"}},
+		{"allow package", SourceFilterOptions{Allow: []string{"github.com/fujiwara/*"}}, []string{"own", "unknown"}},
+		{"allow and deny", SourceFilterOptions{Allow: []string{"github.com/noisy/lib/...", "*.go"}, Deny: []string{"pool.go"}}, []string{"own", "legacy", "unknown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewSourceFilterHandler(slog.NewTextHandler(buf, nil), &tt.opts)
+			logger := slog.New(h).With("app", "demo")
+			logger.Info("own")
+			logger.Info("vendored", vendored)
+			logger.Info("legacy", legacy)
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "unknown", 0)
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if _, msg, ok := strings.Cut(line, "msg="); ok {
+					got = append(got, strings.Fields(msg)[0])
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
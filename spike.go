@@ -0,0 +1,240 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// SpikeOptions configures a SpikeHandler.
+type SpikeOptions struct {
+	// Key is the attribute naming the service, in a record or added with
+	// WithAttrs outside groups. Default is "service".
+	Key string
+	// Level is the level of the records watched. Default is slog.LevelError.
+	Level slog.Leveler
+	// Window is the length of the sliding window. Default is one minute.
+	Window time.Duration
+	// Threshold is the number of records of a service and message family
+	// within Window that is a spike. Default is 10.
+	Threshold int
+	// Family returns the message family of a message. Default is
+	// MessageFamily.
+	Family func(msg string) string
+	// OnSpike, if set, is called when a spike starts. Otherwise a WARN
+	// record "error spike" is logged with the service, family, count and
+	// window.
+	OnSpike func(ctx context.Context, s Spike)
+}
+
+// Spike describes an error spike detected by a SpikeHandler.
+type Spike struct {
+	Service string
+	Family  string
+	Count   int // records within Window, estimated
+	Window  time.Duration
+	Time    time.Time // of the record that started the spike
+	Message string    // of the record that started the spike
+}
+
+// maxSpikeKeys is the number of (service, family) pairs above which the
+// windows of idle pairs are forgotten.
+const maxSpikeKeys = 10000
+
+// SpikeHandler wraps a handler and watches the rate of errors per service
+// and message family, reporting when it exceeds a threshold, for
+// lightweight self-alerting in small deployments:
+//
+//	h := sloghandler.NewSpikeHandler(handler, &sloghandler.SpikeOptions{
+//		Threshold: 50, Window: time.Minute,
+//	})
+//	// [WARN] error spike [service:api] [family:dial tcp *.*.*.*:*: connection refused] [count:50] [window:1m0s]
+//
+// A spike is reported once when it starts, and again only after the rate
+// has fallen below the threshold. The rate is estimated from the counts of
+// the current and previous windows, as of the time of the records.
+type SpikeHandler struct {
+	base    slog.Handler
+	state   *spikeState
+	service string // from WithAttrs, if any
+	groups  bool   // a group was opened with WithGroup
+}
+
+type spikeState struct {
+	opts    SpikeOptions
+	root    slog.Handler // receives the "error spike" records
+	mu      sync.Mutex
+	windows map[spikeKey]*spikeWindow
+}
+
+type spikeKey struct {
+	service string
+	family  string
+}
+
+type spikeWindow struct {
+	start     time.Time // of the current window
+	cur, prev int
+	firing    bool
+}
+
+// NewSpikeHandler creates a SpikeHandler passing records to h.
+func NewSpikeHandler(h slog.Handler, opts *SpikeOptions) *SpikeHandler {
+	o := SpikeOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Key == "" {
+		o.Key = "service"
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelError
+	}
+	if o.Window <= 0 {
+		o.Window = time.Minute
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = 10
+	}
+	if o.Family == nil {
+		o.Family = MessageFamily
+	}
+	return &SpikeHandler{base: h, state: &spikeState{
+		opts:    o,
+		root:    h,
+		windows: make(map[spikeKey]*spikeWindow),
+	}}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *SpikeHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *SpikeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *SpikeHandler) Handle(ctx context.Context, record slog.Record) error {
+	err := h.base.Handle(ctx, record)
+	s := h.state
+	if record.Level < s.opts.Level.Level() {
+		return err
+	}
+	service := h.service
+	if !h.groups {
+		if v, ok := recordString(record, s.opts.Key); ok {
+			service = v
+		}
+	}
+	t := record.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	k := spikeKey{service: service, family: s.opts.Family(record.Message)}
+	count, start := s.observe(k, t)
+	if !start {
+		return err
+	}
+	spike := Spike{
+		Service: k.service,
+		Family:  k.family,
+		Count:   count,
+		Window:  s.opts.Window,
+		Time:    t,
+		Message: record.Message,
+	}
+	if s.opts.OnSpike != nil {
+		s.opts.OnSpike(ctx, spike)
+		return err
+	}
+	if s.root.Enabled(ctx, slog.LevelWarn) {
+		r := slog.NewRecord(t, slog.LevelWarn, "error spike", 0)
+		if spike.Service != "" {
+			r.AddAttrs(slog.String(s.opts.Key, spike.Service))
+		}
+		r.AddAttrs(
+			slog.String("family", spike.Family),
+			slog.Int("count", spike.Count),
+			slog.Duration("window", spike.Window),
+		)
+		s.root.Handle(ctx, r)
+	}
+	return err
+}
+
+// observe counts a record of k at t. It returns the estimated number of
+// records within the window and reports whether a spike starts.
+func (s *spikeState) observe(k spikeKey, t time.Time) (int, bool) {
+	window := s.opts.Window
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.windows[k]
+	if w == nil {
+		if len(s.windows) >= maxSpikeKeys {
+			for k, w := range s.windows {
+				if t.Sub(w.start) >= 2*window {
+					delete(s.windows, k)
+				}
+			}
+		}
+		w = &spikeWindow{start: t}
+		s.windows[k] = w
+	}
+	switch elapsed := t.Sub(w.start); {
+	case elapsed >= 2*window:
+		w.start, w.cur, w.prev = t, 0, 0
+	case elapsed >= window:
+		w.start, w.cur, w.prev = w.start.Add(window), 0, w.cur
+	}
+	w.cur++
+	weight := 1 - float64(t.Sub(w.start))/float64(window)
+	count := w.cur + int(float64(w.prev)*max(weight, 0))
+	if count < s.opts.Threshold {
+		w.firing = false
+		return count, false
+	}
+	start := !w.firing
+	w.firing = true
+	return count, start
+}
+
+func (h *SpikeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.base = h.base.WithAttrs(attrs)
+	if !h.groups {
+		for _, a := range attrs {
+			if a.Key == h.state.opts.Key {
+				h2.service = a.Value.Resolve().String()
+			}
+		}
+	}
+	return &h2
+}
+
+func (h *SpikeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.base = h.base.WithGroup(name)
+	h2.groups = true
+	return &h2
+}
+
+var (
+	familyQuoted = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	familyNumber = regexp.MustCompile(`[0-9a-fA-F]*[0-9][0-9a-fA-F]*`)
+)
+
+// MessageFamily returns msg with its variable parts, quoted strings and
+// numbers or hexadecimal IDs, replaced by "*", so that messages differing
+// only in those parts fall into the same family:
+//
+//	MessageFamily(`user 42 not found`) // "user * not found"
+func MessageFamily(msg string) string {
+	msg = familyQuoted.ReplaceAllString(msg, "*")
+	return familyNumber.ReplaceAllString(msg, "*")
+}
@@ -0,0 +1,81 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpikeHandler(t *testing.T) {
+	mem := NewMemoryHandler(nil)
+	h := NewSpikeHandler(mem, &SpikeOptions{Threshold: 3, Window: time.Minute})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := func(l slog.Handler, level slog.Level, msg string) {
+		r := slog.NewRecord(now, level, msg, 0)
+		l.Handle(context.Background(), r)
+	}
+	api := h.WithAttrs([]slog.Attr{slog.String("service", "api")})
+	for i := range 4 {
+		log(api, slog.LevelError, "user "+string(rune('0'+i))+" not found")
+		log(api, slog.LevelWarn, "slow query") // not watched
+		now = now.Add(time.Second)
+	}
+	log(h, slog.LevelError, "user 1 not found") // another service
+
+	// the estimate decays in the next window, ending the spike
+	now = now.Add(96 * time.Second)
+	log(api, slog.LevelError, "user 5 not found")
+	log(api, slog.LevelError, "user 6 not found") // a new spike
+	log(api, slog.LevelError, "user 7 not found")
+
+	var spikes []*Entry
+	for _, e := range mem.Records(Query{}) {
+		if e.Message == "error spike" {
+			spikes = append(spikes, e)
+		}
+	}
+	if len(spikes) != 2 {
+		t.Fatalf("got %d spikes, want 2", len(spikes))
+	}
+	want := "[WARN] error spike [service:api] [family:user * not found] [count:3] [window:1m0s]"
+	if got := spikes[0].String(); !strings.HasSuffix(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSpikeHandlerOnSpike(t *testing.T) {
+	var got []Spike
+	h := NewSpikeHandler(NewMemoryHandler(nil), &SpikeOptions{
+		Key:       "app",
+		Level:     slog.LevelWarn,
+		Threshold: 2,
+		OnSpike:   func(ctx context.Context, s Spike) { got = append(got, s) },
+	})
+	logger := slog.New(h)
+	logger.Warn("retrying", "app", "worker")
+	logger.Warn("retrying", "app", "web")
+	logger.Error("retrying", "app", "worker")
+	logger.Error("retrying", "app", "worker")
+	if len(got) != 1 {
+		t.Fatalf("got %d spikes, want 1", len(got))
+	}
+	if s := got[0]; s.Service != "worker" || s.Family != "retrying" || s.Count != 2 || s.Message != "retrying" {
+		t.Errorf("unexpected spike %+v", s)
+	}
+}
+
+func TestMessageFamily(t *testing.T) {
+	for msg, want := range map[string]string{
+		"user 42 not found":                          "user * not found",
+		`open "/tmp/a.log": permission denied`:       "open *: permission denied",
+		"dial tcp 10.0.0.1:5432: connection refused": "dial tcp *.*.*.*:*: connection refused",
+		"request 3fa85f64-5717-4562 failed":          "request *-*-* failed",
+		"no variable parts":                          "no variable parts",
+	} {
+		if got := MessageFamily(msg); got != want {
+			t.Errorf("MessageFamily(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
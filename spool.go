@@ -0,0 +1,436 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SpoolOptions configures a Spool.
+type SpoolOptions struct {
+	// SegmentSize starts a new segment file once the current one reaches
+	// this many bytes. Default is 8 MiB.
+	SegmentSize int64
+	// MaxSize bounds the total size of the spool. When it is exceeded the
+	// oldest segments are removed and their payloads lost. Default is 1 GiB.
+	MaxSize int64
+}
+
+// Spool is a disk-backed FIFO queue of payloads, stored in append-only
+// segment files in a directory. Network sinks use it to keep batches the
+// remote could not accept and replay them in order once it is reachable,
+// so log shipping survives collector outages without unbounded memory.
+//
+// Payloads are read with Peek and removed with Commit after they have been
+// delivered, so a crash between the two delivers a payload again rather
+// than losing it. The read position is kept in a checkpoint file.
+type Spool struct {
+	dir  string
+	opts SpoolOptions
+
+	mu       sync.Mutex
+	segments []int64 // ids of segment files, oldest first
+	sizes    map[int64]int64
+	w        *os.File // last segment, opened for appending
+	readID   int64
+	readOff  int64
+	peeked   int64 // length of the record returned by Peek, or 0
+	dropped  int64
+
+	// sendMu serializes deliver and replay so payloads go out in order.
+	sendMu sync.Mutex
+}
+
+// spoolHeaderSize is the size of a record header: payload length and CRC-32.
+const spoolHeaderSize = 8
+
+// OpenSpool opens or creates a spool in dir.
+func OpenSpool(dir string, opts *SpoolOptions) (*Spool, error) {
+	s := &Spool{dir: dir, sizes: make(map[int64]int64)}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.SegmentSize <= 0 {
+		s.opts.SegmentSize = 8 << 20
+	}
+	if s.opts.MaxSize <= 0 {
+		s.opts.MaxSize = 1 << 30
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".seg")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, id)
+		s.sizes[id] = info.Size()
+	}
+	slices.Sort(s.segments)
+	if len(s.segments) == 0 {
+		s.segments = []int64{1}
+		s.sizes[1] = 0
+	}
+	if err := s.readCheckpoint(); err != nil {
+		return nil, err
+	}
+	if err := s.openWriter(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Spool) segmentPath(id int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%016d.seg", id))
+}
+
+func (s *Spool) checkpointPath() string {
+	return filepath.Join(s.dir, "checkpoint")
+}
+
+func (s *Spool) readCheckpoint() error {
+	s.readID, s.readOff = s.segments[0], 0
+	b, err := os.ReadFile(s.checkpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var id, off int64
+	if _, err := fmt.Sscanf(string(b), "%d %d", &id, &off); err != nil {
+		return fmt.Errorf("sloghandler: invalid spool checkpoint: %w", err)
+	}
+	if id >= s.readID {
+		s.readID, s.readOff = id, off
+	}
+	return nil
+}
+
+func (s *Spool) writeCheckpoint() error {
+	tmp := s.checkpointPath() + ".tmp"
+	data := fmt.Sprintf("%d %d\n", s.readID, s.readOff)
+	if err := os.WriteFile(tmp, []byte(data), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.checkpointPath())
+}
+
+// openWriter opens the last segment for appending, cutting off a record
+// left incomplete by a crash.
+func (s *Spool) openWriter() error {
+	id := s.segments[len(s.segments)-1]
+	f, err := os.OpenFile(s.segmentPath(id), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	var off int64
+	for {
+		n, err := readSpoolRecord(f, off, fi.Size(), nil)
+		if err != nil {
+			break
+		}
+		off += n
+	}
+	if off != s.sizes[id] {
+		if err := f.Truncate(off); err != nil {
+			f.Close()
+			return err
+		}
+		s.sizes[id] = off
+	}
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	s.w = f
+	return nil
+}
+
+// readSpoolRecord reads the record at off in f, a segment of size bytes,
+// appending its payload to *dst if dst is not nil, and returns the record's
+// size on disk.
+func readSpoolRecord(f *os.File, off, size int64, dst *[]byte) (int64, error) {
+	var hdr [spoolHeaderSize]byte
+	if _, err := f.ReadAt(hdr[:], off); err != nil {
+		return 0, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if int64(n) > size-off-spoolHeaderSize {
+		// A torn or damaged length; do not allocate what it claims.
+		return 0, fmt.Errorf("sloghandler: corrupt spool record at offset %d", off)
+	}
+	data := make([]byte, n)
+	if _, err := f.ReadAt(data, off+spoolHeaderSize); err != nil {
+		return 0, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:]) {
+		return 0, fmt.Errorf("sloghandler: corrupt spool record at offset %d", off)
+	}
+	if dst != nil {
+		*dst = data
+	}
+	return spoolHeaderSize + int64(n), nil
+}
+
+// Append adds payload to the end of the spool.
+func (s *Spool) Append(payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return os.ErrClosed
+	}
+	id := s.segments[len(s.segments)-1]
+	if s.sizes[id] >= s.opts.SegmentSize {
+		if err := s.w.Close(); err != nil {
+			return err
+		}
+		id++
+		f, err := os.OpenFile(s.segmentPath(id), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		s.w = f
+		s.segments = append(s.segments, id)
+		s.sizes[id] = 0
+	}
+	rec := make([]byte, spoolHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(rec[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(payload))
+	copy(rec[spoolHeaderSize:], payload)
+	n, err := s.w.Write(rec)
+	s.sizes[id] += int64(n)
+	if err != nil {
+		return err
+	}
+	return s.enforceMaxSize()
+}
+
+// enforceMaxSize removes the oldest segments while the spool is too large,
+// checkpointing the read position if any were removed.
+func (s *Spool) enforceMaxSize() error {
+	removed := false
+	for len(s.segments) > 1 && s.size() > s.opts.MaxSize {
+		id := s.segments[0]
+		if id == s.readID {
+			s.dropped++ // at least one payload is lost
+			s.readID, s.readOff, s.peeked = s.segments[1], 0, 0
+		}
+		if err := s.removeSegment(id); err != nil {
+			return err
+		}
+		removed = true
+	}
+	if !removed {
+		return nil
+	}
+	return s.writeCheckpoint()
+}
+
+func (s *Spool) removeSegment(id int64) error {
+	s.segments = slices.DeleteFunc(s.segments, func(x int64) bool { return x == id })
+	delete(s.sizes, id)
+	if err := os.Remove(s.segmentPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Spool) size() int64 {
+	var total int64
+	for _, n := range s.sizes {
+		total += n
+	}
+	return total
+}
+
+// Peek returns the oldest payload that has not been committed, or io.EOF
+// if the spool is empty. Repeated calls return the same payload until Commit.
+func (s *Spool) Peek() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return nil, os.ErrClosed
+	}
+	for {
+		if s.readOff < s.sizes[s.readID] {
+			f, err := os.Open(s.segmentPath(s.readID))
+			if err != nil {
+				return nil, err
+			}
+			var data []byte
+			fi, err := f.Stat()
+			var n int64
+			if err == nil {
+				n, err = readSpoolRecord(f, s.readOff, fi.Size(), &data)
+			}
+			f.Close()
+			if err != nil {
+				// Skip the damaged remainder of the segment.
+				s.dropped++
+				s.readOff = s.sizes[s.readID]
+				continue
+			}
+			s.peeked = n
+			return data, nil
+		}
+		if s.readID == s.segments[len(s.segments)-1] {
+			return nil, io.EOF
+		}
+		// The segment is fully delivered.
+		old := s.readID
+		i := slices.Index(s.segments, old)
+		s.readID, s.readOff = s.segments[i+1], 0
+		if err := s.writeCheckpoint(); err != nil {
+			return nil, err
+		}
+		if err := s.removeSegment(old); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Commit removes the payload returned by the last Peek.
+func (s *Spool) Commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.peeked == 0 {
+		return errors.New("sloghandler: spool commit without peek")
+	}
+	s.readOff += s.peeked
+	s.peeked = 0
+	return s.writeCheckpoint()
+}
+
+// Empty reports whether every payload has been committed.
+func (s *Spool) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readID == s.segments[len(s.segments)-1] && s.readOff >= s.sizes[s.readID]
+}
+
+// Dropped returns the number of times payloads were lost, either to
+// MaxSize or to a damaged segment file.
+func (s *Spool) Dropped() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Close closes the spool. Pending payloads stay on disk for the next OpenSpool.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return nil
+	}
+	err := s.w.Close()
+	s.w = nil
+	return err
+}
+
+// spill returns a function that encodes an entry as a batch of its own and
+// appends it to the spool, for BackpressureSpillToDisk. It returns nil for
+// a nil spool.
+func (s *Spool) spill(encode func([]*Entry) ([]byte, error)) func(*Entry) error {
+	if s == nil {
+		return nil
+	}
+	return func(e *Entry) error {
+		payload, err := encode([]*Entry{e})
+		if err != nil {
+			return err
+		}
+		return s.Append(payload)
+	}
+}
+
+// spooledError is the delivery error of a batch that was kept in a spool.
+type spooledError struct{ err error }
+
+func (e *spooledError) Error() string { return "sloghandler: batch spooled: " + e.err.Error() }
+func (e *spooledError) Unwrap() error { return e.err }
+
+// deliver sends payload through sender after replaying the spooled
+// payloads. While the remote is unavailable, payload is spooled instead of
+// sent and the returned error includes a *spooledError. A nil spool just sends payload.
+func (s *Spool) deliver(ctx context.Context, payload []byte, sender BatchSender) error {
+	if s == nil {
+		return sender.SendBatch(ctx, payload)
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	down, err := s.replayLocked(ctx, sender)
+	if down == nil {
+		down = sender.SendBatch(ctx, payload)
+		if !errors.Is(down, ErrUnavailable) {
+			return errors.Join(err, down)
+		}
+	}
+	if aerr := s.Append(payload); aerr != nil {
+		return errors.Join(err, down, aerr)
+	}
+	return errors.Join(err, &spooledError{down})
+}
+
+// replay sends spooled payloads in order until the spool is empty or the
+// remote is unavailable. Payloads the remote rejects are dropped and their
+// errors returned.
+func (s *Spool) replay(ctx context.Context, sender BatchSender) error {
+	if s == nil {
+		return nil
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	_, err := s.replayLocked(ctx, sender)
+	return err
+}
+
+// replayLocked returns the error that stopped replay when the remote is
+// unavailable, or nil once the spool is empty, and the errors of rejected
+// payloads. Each payload is committed once sender acknowledges or rejects it.
+func (s *Spool) replayLocked(ctx context.Context, sender BatchSender) (down, err error) {
+	var errs []error
+	for {
+		payload, err := s.Peek()
+		if err == io.EOF {
+			return nil, errors.Join(errs...)
+		} else if err != nil {
+			return err, errors.Join(errs...)
+		}
+		if err := sender.SendBatch(ctx, payload); errors.Is(err, ErrUnavailable) {
+			return err, errors.Join(errs...)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+		if err := s.Commit(); err != nil {
+			return err, errors.Join(errs...)
+		}
+	}
+}
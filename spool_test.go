@@ -0,0 +1,235 @@
+package sloghandler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, &SpoolOptions{SegmentSize: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		if err := s.Append([]byte(fmt.Sprintf("payload-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 2 {
+		b, err := s.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("payload-%d", i); string(b) != want {
+			t.Errorf("Peek() = %q, want %q", b, want)
+		}
+		s.Commit()
+	}
+	s.Close()
+
+	// Committed payloads stay delivered after reopening.
+	s, err = OpenSpool(dir, &SpoolOptions{SegmentSize: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var got []string
+	for {
+		b, err := s.Peek()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+		s.Commit()
+	}
+	if want := "payload-2 payload-3 payload-4"; strings.Join(got, " ") != want {
+		t.Errorf("replayed %q, want %q", got, want)
+	}
+	if !s.Empty() {
+		t.Error("spool not empty")
+	}
+	segs, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	if len(segs) != 1 {
+		t.Errorf("delivered segments not removed: %v", segs)
+	}
+}
+
+func TestSpoolTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append([]byte("complete"))
+	s.Append([]byte("partial"))
+	s.Close()
+	seg, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	info, _ := os.Stat(seg[0])
+	os.Truncate(seg[0], info.Size()-3)
+
+	s, err = OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Append([]byte("after"))
+	for _, want := range []string{"complete", "after"} {
+		b, err := s.Peek()
+		if err != nil || string(b) != want {
+			t.Errorf("Peek() = %q, %v, want %q", b, err, want)
+		}
+		s.Commit()
+	}
+}
+
+func TestSpoolCorruptLength(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append([]byte("complete"))
+	s.Append([]byte("damaged"))
+	s.Close()
+	seg, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	f, err := os.OpenFile(seg[0], os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Claim a 4 GiB payload in the header of the second record.
+	f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, spoolHeaderSize+int64(len("complete")))
+	f.Close()
+
+	s, err = OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if b, err := s.Peek(); err != nil || string(b) != "complete" {
+		t.Errorf("Peek() = %q, %v, want %q", b, err, "complete")
+	}
+	s.Commit()
+	if b, err := s.Peek(); err != io.EOF {
+		t.Errorf("Peek() = %q, %v, want io.EOF", b, err)
+	}
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	s, err := OpenSpool(t.TempDir(), &SpoolOptions{SegmentSize: 20, MaxSize: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := range 10 {
+		s.Append([]byte(fmt.Sprintf("payload-%d", i)))
+	}
+	if s.Dropped() == 0 {
+		t.Error("no payloads dropped")
+	}
+	b, _ := s.Peek()
+	if string(b) == "payload-0" {
+		t.Error("oldest payload kept")
+	}
+}
+
+func TestSpoolAppendCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, &SpoolOptions{SegmentSize: 20, MaxSize: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkpoint := filepath.Join(dir, "checkpoint")
+	os.Remove(checkpoint)
+	for i := range 3 {
+		s.Append([]byte(fmt.Sprintf("payload-%d", i)))
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint written without eviction: %v", err)
+	}
+	for i := 3; i < 10; i++ {
+		s.Append([]byte(fmt.Sprintf("payload-%d", i)))
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Errorf("checkpoint not written after eviction: %v", err)
+	}
+}
+
+func TestHTTPHandlerSpool(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var mu sync.Mutex
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	spool, err := OpenSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+	var errs atomic.Int32
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{
+		Retry:    &RetryPolicy{Backoff: time.Millisecond},
+		Interval: time.Hour,
+		Spool:    spool,
+		OnError:  func(error) { errs.Add(1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	logger := slog.New(h)
+	logger.Info("one")
+	h.Flush()
+	logger.Info("two")
+	h.Flush()
+	if spool.Empty() {
+		t.Fatal("nothing spooled while the endpoint is down")
+	}
+
+	down.Store(false)
+	logger.Info("three")
+	h.Flush()
+	if !spool.Empty() {
+		t.Error("spool not replayed")
+	}
+	if errs.Load() != 0 {
+		t.Errorf("OnError called %d times", errs.Load())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	var msgs []string
+	for _, b := range bodies {
+		for _, word := range []string{"one", "two", "three"} {
+			if strings.Contains(b, `"msg":"`+word+`"`) {
+				msgs = append(msgs, word)
+			}
+		}
+	}
+	if got := strings.Join(msgs, " "); got != "one two three" {
+		t.Errorf("delivered %q, want in order", got)
+	}
+}
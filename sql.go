@@ -0,0 +1,242 @@
+package sloghandler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SQLDialect selects the placeholder syntax used by SQLHandler.
+type SQLDialect int
+
+const (
+	// SQLDialectPostgres uses $1, $2, ... placeholders.
+	SQLDialectPostgres SQLDialect = iota
+	// SQLDialectMySQL uses ? placeholders.
+	SQLDialectMySQL
+)
+
+// SQLColumns names the columns written by SQLHandler.
+// An empty name skips that column.
+type SQLColumns struct {
+	Time    string
+	Level   string
+	Message string
+	Source  string
+	// Attrs receives all attributes as a JSON object. Use a JSONB (PostgreSQL)
+	// or JSON (MySQL) column type.
+	Attrs string
+}
+
+// DefaultSQLColumns are the column names used when SQLOptions.Columns is zero.
+var DefaultSQLColumns = SQLColumns{
+	Time:    "time",
+	Level:   "level",
+	Message: "message",
+	Source:  "source",
+	Attrs:   "attrs",
+}
+
+// SQLOptions configures an SQLHandler.
+type SQLOptions struct {
+	// Table is the table to insert into. Default is "logs".
+	Table string
+	// Columns names the columns to insert into. Default is DefaultSQLColumns.
+	Columns SQLColumns
+	// Dialect selects the placeholder syntax. Default is SQLDialectPostgres.
+	Dialect SQLDialect
+	// Level is the minimum level stored. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// BatchSize is the maximum number of rows per INSERT statement. Default is 100.
+	BatchSize int
+	// Interval is the maximum time a record waits before being inserted. Default is 1 second.
+	Interval time.Duration
+	// QueueSize is the number of records buffered for insertion. Default is 10000.
+	QueueSize int
+	// Block makes Handle wait for queue space when the database falls behind,
+	// applying backpressure to callers. It is equivalent to setting
+	// Backpressure to BackpressureBlock.
+	Block bool
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// Timeout bounds each INSERT statement. Default is 10 seconds.
+	Timeout time.Duration
+	// OnError is called when a batch cannot be inserted. Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+}
+
+// SQLHandler is a slog.Handler that inserts records into a database table
+// in batches. Call Close before closing the database to insert pending records.
+type SQLHandler struct {
+	db      *sql.DB
+	opts    SQLOptions
+	columns []string
+	queue   *batcher[*Entry]
+	health  *sinkHealth
+	scope   attrScope
+}
+
+// NewSQLHandler creates a handler that writes records to db.
+// The table must already exist, for example on PostgreSQL:
+//
+//	CREATE TABLE logs (
+//	  time    timestamptz NOT NULL,
+//	  level   text NOT NULL,
+//	  message text NOT NULL,
+//	  source  text,
+//	  attrs   jsonb
+//	);
+func NewSQLHandler(db *sql.DB, opts *SQLOptions) (*SQLHandler, error) {
+	if db == nil {
+		return nil, errors.New("sloghandler: sql db is required")
+	}
+	o := SQLOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Table == "" {
+		o.Table = "logs"
+	}
+	if o.Columns == (SQLColumns{}) {
+		o.Columns = DefaultSQLColumns
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("sql")
+	}
+	var columns []string
+	for _, c := range []string{o.Columns.Time, o.Columns.Level, o.Columns.Message, o.Columns.Source, o.Columns.Attrs} {
+		if c != "" {
+			columns = append(columns, c)
+		}
+	}
+	if o.Block {
+		o.Backpressure = BackpressureBlock
+	}
+	h := &SQLHandler{db: db, opts: o, columns: columns}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, o.BatchSize, o.Interval, func(entries []*Entry) {
+		h.health.report(h.insert(entries), h.opts.OnError)
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	return h, nil
+}
+
+func (h *SQLHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *SQLHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.queue.add(ctx, newEntry(record, h.scope))
+}
+
+func (h *SQLHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *SQLHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush inserts all queued records.
+func (h *SQLHandler) Flush() error {
+	h.queue.Flush()
+	return nil
+}
+
+// Close inserts pending records and stops the handler. It does not close the database.
+func (h *SQLHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *SQLHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *SQLHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+func (h *SQLHandler) insert(entries []*Entry) error {
+	query, args := h.query(entries)
+	ctx, cancel := context.WithTimeout(context.Background(), h.opts.Timeout)
+	defer cancel()
+	if _, err := h.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("sloghandler: failed to insert %d records: %w", len(entries), err)
+	}
+	return nil
+}
+
+// query builds a multi-row INSERT statement for entries.
+func (h *SQLHandler) query(entries []*Entry) (string, []any) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", h.opts.Table, strings.Join(h.columns, ", "))
+	args := make([]any, 0, len(entries)*len(h.columns))
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := range h.columns {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			if h.opts.Dialect == SQLDialectMySQL {
+				sb.WriteByte('?')
+			} else {
+				fmt.Fprintf(&sb, "$%d", len(args)+j+1)
+			}
+		}
+		sb.WriteByte(')')
+		if h.opts.Columns.Time != "" {
+			args = append(args, e.Time)
+		}
+		if h.opts.Columns.Level != "" {
+			args = append(args, LevelName(e.Level))
+		}
+		if h.opts.Columns.Message != "" {
+			args = append(args, e.Message)
+		}
+		if h.opts.Columns.Source != "" {
+			var source sql.NullString
+			if e.Source != nil {
+				source = sql.NullString{String: fmt.Sprintf("%s:%d", e.Source.File, e.Source.Line), Valid: true}
+			}
+			args = append(args, source)
+		}
+		if h.opts.Columns.Attrs != "" {
+			args = append(args, string(attrsJSON(e.Attrs)))
+		}
+	}
+	return sb.String(), args
+}
@@ -0,0 +1,103 @@
+package sloghandler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingDriver is a database/sql driver that records executed statements.
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []recordedExec
+}
+
+type recordedExec struct {
+	query string
+	args  []driver.NamedValue
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, recordedExec{query, args})
+	return driver.RowsAffected(1), nil
+}
+
+var testSQLDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("sloghandler-recording", testSQLDriver)
+}
+
+func TestSQLHandler(t *testing.T) {
+	db, err := sql.Open("sloghandler-recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h, err := NewSQLHandler(db, &SQLOptions{
+		Table:     "app_logs",
+		BatchSize: 2,
+		Interval:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h).WithGroup("req")
+	logger.Debug("ignored")
+	logger.Info("first", "id", 1)
+	logger.Warn("second")
+	logger.Error("third")
+	h.Close()
+
+	testSQLDriver.mu.Lock()
+	defer testSQLDriver.mu.Unlock()
+	if len(testSQLDriver.execs) != 2 {
+		t.Fatalf("got %d statements, want 2", len(testSQLDriver.execs))
+	}
+	first := testSQLDriver.execs[0]
+	wantQuery := "INSERT INTO app_logs (time, level, message, source, attrs) VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)"
+	if first.query != wantQuery {
+		t.Errorf("query = %q, want %q", first.query, wantQuery)
+	}
+	if got := first.args[1].Value; got != "INFO" {
+		t.Errorf("level arg = %v, want INFO", got)
+	}
+	if got := first.args[4].Value; got != `{"req.id":"1"}` {
+		t.Errorf("attrs arg = %v", got)
+	}
+	if src, _ := first.args[3].Value.(string); !strings.Contains(src, "sql_test.go:") {
+		t.Errorf("source arg = %v", first.args[3].Value)
+	}
+	if len(testSQLDriver.execs[1].args) != 5 {
+		t.Errorf("second statement should insert one row, got args %v", testSQLDriver.execs[1].args)
+	}
+}
+
+func TestSQLHandlerMySQLColumns(t *testing.T) {
+	h := &SQLHandler{
+		opts:    SQLOptions{Table: "logs", Dialect: SQLDialectMySQL, Columns: SQLColumns{Message: "msg", Attrs: "data"}},
+		columns: []string{"msg", "data"},
+	}
+	query, args := h.query([]*Entry{{Message: "a"}, {Message: "b"}})
+	if want := "INSERT INTO logs (msg, data) VALUES (?, ?), (?, ?)"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if len(args) != 4 || args[2] != "b" || args[3] != "{}" {
+		t.Errorf("unexpected args %v", args)
+	}
+}
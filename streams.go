@@ -0,0 +1,78 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// NewStdStreamsHandler returns a handler that writes WARN and above to
+// os.Stderr and lower levels to os.Stdout, both in the text format of opts.
+// Container runtimes and orchestrators often treat the two streams
+// differently, e.g. collecting stderr as errors. With nil opts, both
+// streams log at INFO and each is colored when it is a terminal.
+func NewStdStreamsHandler(opts *HandlerOptions) slog.Handler {
+	if opts == nil {
+		return newStreamsHandler(os.Stdout, os.Stderr, slog.LevelWarn,
+			defaultStreamOptions(os.Stdout), defaultStreamOptions(os.Stderr))
+	}
+	return newStreamsHandler(os.Stdout, os.Stderr, slog.LevelWarn, opts, opts)
+}
+
+func defaultStreamOptions(f *os.File) *HandlerOptions {
+	return &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Color:          isTerminal(f) && os.Getenv("NO_COLOR") == "",
+	}
+}
+
+func newStreamsHandler(low, high io.Writer, threshold slog.Level, lowOpts, highOpts *HandlerOptions) *streamsHandler {
+	return &streamsHandler{
+		threshold: threshold,
+		low:       NewLogHandler(low, lowOpts),
+		high:      NewLogHandler(high, highOpts),
+	}
+}
+
+// streamsHandler sends records at or above threshold to high and the
+// others to low.
+type streamsHandler struct {
+	threshold slog.Level
+	low       slog.Handler
+	high      slog.Handler
+}
+
+func (h *streamsHandler) pick(level slog.Level) slog.Handler {
+	if level >= h.threshold {
+		return h.high
+	}
+	return h.low
+}
+
+// Handlers returns the handlers of the two streams.
+func (h *streamsHandler) Handlers() []slog.Handler {
+	return []slog.Handler{h.low, h.high}
+}
+
+func (h *streamsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.pick(level).Enabled(ctx, level)
+}
+
+func (h *streamsHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.pick(record.Level).Handle(ctx, record)
+}
+
+func (h *streamsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &streamsHandler{threshold: h.threshold, low: h.low.WithAttrs(attrs), high: h.high.WithAttrs(attrs)}
+}
+
+func (h *streamsHandler) WithGroup(name string) slog.Handler {
+	return &streamsHandler{threshold: h.threshold, low: h.low.WithGroup(name), high: h.high.WithGroup(name)}
+}
+
+// Flush flushes both streams. See Flush.
+func (h *streamsHandler) Flush() error {
+	return errors.Join(Flush(h.low), Flush(h.high))
+}
@@ -0,0 +1,29 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStreamsHandler(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	opts := &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}}
+	logger := slog.New(newStreamsHandler(&stdout, &stderr, slog.LevelWarn, opts, opts)).With("app", "demo")
+	logger.Debug("details")
+	logger.Info("started")
+	logger.Warn("slow")
+	logger.Error("failed")
+
+	out := stdout.String()
+	if !strings.Contains(out, "[DEBUG] [app:demo] details") || !strings.Contains(out, "[INFO] [app:demo] started") ||
+		strings.Contains(out, "slow") || strings.Contains(out, "failed") {
+		t.Errorf("stdout = %q", out)
+	}
+	errOut := stderr.String()
+	if !strings.Contains(errOut, "[WARN] [app:demo] slow") || !strings.Contains(errOut, "[ERROR] [app:demo] failed") ||
+		strings.Contains(errOut, "started") {
+		t.Errorf("stderr = %q", errOut)
+	}
+}
@@ -0,0 +1,105 @@
+package sloghandler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// StripANSIWriter is an io.Writer that removes ANSI escape sequences before
+// writing to the underlying writer, so colored output can be stored as plain
+// text. A sequence split across writes is held back until it is complete.
+type StripANSIWriter struct {
+	w       io.Writer
+	pending []byte
+}
+
+// NewStripANSIWriter returns a writer that strips ANSI escape sequences from writes to w.
+func NewStripANSIWriter(w io.Writer) *StripANSIWriter {
+	return &StripANSIWriter{w: w}
+}
+
+// Write writes p to the underlying writer without escape sequences.
+// It reports len(p) on success even though fewer bytes are written.
+func (s *StripANSIWriter) Write(p []byte) (int, error) {
+	data := p
+	if len(s.pending) > 0 {
+		data = append(s.pending, p...)
+		s.pending = nil
+	}
+	// Hold back a trailing escape sequence that may continue in the next write.
+	if i := bytes.LastIndexByte(data, '\033'); i >= 0 && incompleteANSI(data[i:]) {
+		s.pending = bytes.Clone(data[i:])
+		data = data[:i]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	if _, err := io.WriteString(s.w, StripANSI(string(data))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// incompleteANSI reports whether b, starting with ESC, is the beginning
+// of an escape sequence that is not yet terminated.
+func incompleteANSI(b []byte) bool {
+	if len(b) == 1 {
+		return true
+	}
+	if len(b) > 256 || (b[1] != '[' && b[1] != ']') {
+		return false
+	}
+	return ansiLen(string(b), 0) == 0
+}
+
+// TeeWriter is an io.Writer that duplicates writes to several writers,
+// such as a colored console and a log file.
+type TeeWriter struct {
+	writers []io.Writer
+}
+
+// NewTeeWriter returns a writer that writes to w unchanged and to each of
+// tees. Tees that are not terminals, and Windows consoles without virtual
+// terminal support, receive the output with ANSI escape sequences stripped,
+// so a colored console handler can append clean text to a file at the same
+// time:
+//
+//	f, _ := os.OpenFile("app.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+//	h := sloghandler.NewLogHandler(sloghandler.NewTeeWriter(os.Stderr, f), &sloghandler.HandlerOptions{Color: true})
+func NewTeeWriter(w io.Writer, tees ...io.Writer) *TeeWriter {
+	if t := terminalFor(w); t != nil && t.plain {
+		w = NewStripANSIWriter(w)
+	}
+	writers := []io.Writer{w}
+	for _, t := range tees {
+		if term := terminalFor(t); term == nil || term.plain {
+			t = NewStripANSIWriter(t)
+		}
+		writers = append(writers, t)
+	}
+	return &TeeWriter{writers: writers}
+}
+
+// Write writes p to every writer, even if some fail, and joins their errors.
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	var errs []error
+	for _, w := range t.writers {
+		if _, err := w.Write(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	return len(p), nil
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
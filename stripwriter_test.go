@@ -0,0 +1,42 @@
+package sloghandler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestStripANSIWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStripANSIWriter(&buf)
+	for _, s := range []string{"\x1b[31mred\x1b", "[0m plain ", "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", " \x1bc"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	if got, want := buf.String(), "red plain link \x1bc"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTeeWriter(t *testing.T) {
+	var console, file bytes.Buffer
+	logger := slog.New(NewLogHandler(NewTeeWriter(&console, &file), &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Color:          true,
+		Compact:        true,
+	}))
+	logger.Error("failed", "code", 1)
+	if got := console.String(); !bytes.Contains(console.Bytes(), []byte("\x1b[31m")) {
+		t.Errorf("console should be colored: %q", got)
+	}
+	if got := file.String(); bytes.Contains(file.Bytes(), []byte("\x1b")) || !bytes.HasSuffix(file.Bytes(), []byte("[ERROR] failed [code:1]\n")) {
+		t.Errorf("file should be plain: %q", got)
+	}
+
+	tw := NewTeeWriter(&console, &recordingWriter{err: errors.New("disk full")})
+	if _, err := tw.Write([]byte("x")); err == nil {
+		t.Error("expected the error of the failing writer")
+	}
+}
@@ -0,0 +1,52 @@
+package sloghandler
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// Syncer is implemented by handlers that hold records before writing them,
+// such as the network sinks. Flush returns once the records handled before
+// the call have been written or delivered, or reports why they could not be.
+// A Syncer that wraps other handlers is responsible for flushing them.
+type Syncer interface {
+	Flush() error
+}
+
+// Flush flushes every Syncer in the handler chain of h, so code that only
+// holds a slog.Handler or a *slog.Logger can flush without knowing concrete
+// types. It walks through wrappers that expose what they wrap with one of
+//
+//	Unwrap() slog.Handler
+//	Handler() slog.Handler
+//	Handlers() []slog.Handler
+//
+// and stops at the first Syncer on each path. The errors are joined.
+func Flush(h slog.Handler) error {
+	switch w := h.(type) {
+	case nil:
+		return nil
+	case Syncer:
+		return w.Flush()
+	case interface{ Handlers() []slog.Handler }:
+		var errs []error
+		for _, child := range w.Handlers() {
+			errs = append(errs, Flush(child))
+		}
+		return errors.Join(errs...)
+	case interface{ Unwrap() slog.Handler }:
+		return Flush(w.Unwrap())
+	case interface{ Handler() slog.Handler }:
+		return Flush(w.Handler())
+	}
+	return nil
+}
+
+// FlushLogger flushes the handlers behind l, or behind slog.Default() if l is nil.
+// See Flush.
+func FlushLogger(l *slog.Logger) error {
+	if l == nil {
+		l = slog.Default()
+	}
+	return Flush(l.Handler())
+}
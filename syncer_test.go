@@ -0,0 +1,49 @@
+package sloghandler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// flushCounter is a Syncer that counts Flush calls.
+type flushCounter struct {
+	slog.Handler
+	flushes int
+	err     error
+}
+
+func (f *flushCounter) Flush() error {
+	f.flushes++
+	return f.err
+}
+
+func TestFlush(t *testing.T) {
+	discard := slog.NewTextHandler(io.Discard, nil)
+	a := &flushCounter{Handler: discard}
+	b := &flushCounter{Handler: discard, err: errors.New("b failed")}
+	c := &flushCounter{Handler: discard}
+	chain := NewTransformHandler(NewFanoutHandler(
+		NewLevelHandler(slog.LevelWarn, a),
+		&closingHandler{Handler: b, Closer: io.NopCloser(nil)},
+		NewReloadableHandler(NewModuleLevelHandler(c, nil)),
+		discard,
+	))
+	logger := slog.New(chain).With("k", "v")
+
+	err := FlushLogger(logger)
+	if err == nil || err.Error() != "b failed" {
+		t.Errorf("FlushLogger() = %v, want b's error", err)
+	}
+	if a.flushes != 1 || b.flushes != 1 || c.flushes != 1 {
+		t.Errorf("flushes = %d %d %d, want each once", a.flushes, b.flushes, c.flushes)
+	}
+
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	slog.SetDefault(slog.New(a))
+	if err := FlushLogger(nil); err != nil || a.flushes != 2 {
+		t.Errorf("FlushLogger(nil) = %v, flushes = %d", err, a.flushes)
+	}
+}
@@ -0,0 +1,139 @@
+package sloghandler
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// TerminalFit controls how the text handler fits lines to the terminal width.
+// It applies only when writing to a terminal; redirected output is never cut.
+type TerminalFit int
+
+const (
+	// FitNone writes lines as they are. It is the default.
+	FitNone TerminalFit = iota
+	// FitTruncate cuts lines at the terminal width, ending them with "…".
+	FitTruncate
+	// FitWrap breaks lines at the terminal width, indenting continuation lines.
+	FitWrap
+)
+
+// terminal tracks the state of a terminal written by the text handler:
+// its width and whether a transient line is displayed.
+// It is shared by all handlers writing to the same file and guarded by the
+// file's lock.
+type terminal struct {
+	pending bool
+	// columns returns the current width, or 0 if unknown.
+	columns func() int
+	// plain is set when the terminal does not show ANSI escape sequences.
+	plain bool
+}
+
+var fileTerminals fileMap[*terminal]
+
+// terminalFor returns the terminal state of w, or nil if w is not a terminal.
+func terminalFor(w io.Writer) *terminal {
+	f, ok := w.(*os.File)
+	if !ok || !isTerminal(f) {
+		return nil
+	}
+	return fileTerminals.loadOrStore(f, func() *terminal {
+		fd := f.Fd()
+		return &terminal{
+			columns: func() int {
+				if n := terminalColumns(fd); n > 0 {
+					return n
+				}
+				n, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+				return n
+			},
+			plain: !enableVirtualTerminal(f),
+		}
+	})
+}
+
+// clearLine returns the cursor to the start of the line and erases it.
+var clearLine = []byte("\r\x1b[2K")
+
+// frame prepares out for writing. A transient record, which has no
+// trailing newline, is drawn over the current line; the record after it
+// erases it first.
+func (t *terminal) frame(out []byte, transient bool) []byte {
+	if !transient && !t.pending {
+		return out
+	}
+	t.pending = transient
+	return append(bytes.Clone(clearLine), out...)
+}
+
+// width returns the terminal width, or 0 if unknown.
+func (t *terminal) width() int {
+	if t.columns == nil {
+		return 0
+	}
+	return t.columns()
+}
+
+// fitLines fits each line of out to width columns.
+func fitLines(out []byte, width int, fit TerminalFit) []byte {
+	if width <= 0 {
+		return out
+	}
+	s := string(out)
+	newline := strings.HasSuffix(s, "\n")
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if fit == FitWrap {
+			sb.WriteString(wrapWidth(line, width, "  "))
+		} else {
+			sb.WriteString(TruncateWidth(line, width, "…"))
+		}
+	}
+	if newline {
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
+
+// wrapWidth breaks s into lines of at most width columns, starting
+// continuation lines with indent. Wide characters are never split.
+func wrapWidth(s string, width int, indent string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	indentWidth := runewidth.StringWidth(indent)
+	if indentWidth >= width {
+		indent, indentWidth = "", 0
+	}
+	var sb strings.Builder
+	w := 0
+	for i := 0; i < len(s); {
+		if n := ansiLen(s, i); n > 0 {
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := runewidth.RuneWidth(r)
+		if w+rw > width && w > indentWidth {
+			sb.WriteByte('\n')
+			sb.WriteString(indent)
+			w = indentWidth
+		}
+		sb.WriteString(s[i : i+size])
+		w += rw
+		i += size
+	}
+	return sb.String()
+}
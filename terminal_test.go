@@ -0,0 +1,58 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFitTerminal(t *testing.T) {
+	long := strings.Repeat("x", 30)
+	for _, tt := range []struct {
+		fit  TerminalFit
+		want string
+	}{
+		{FitNone, "msg [k:" + long + "]\n"},
+		{FitTruncate, "msg [k:xxxxxxxxxxxx…\n"},
+		{FitWrap, "msg [k:xxxxxxxxxxxxx\n  xxxxxxxxxxxxxxxxx]\n"},
+	} {
+		var buf bytes.Buffer
+		h := NewLogHandler(&buf, &HandlerOptions{
+			HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+			Compact:        true,
+			FitTerminal:    tt.fit,
+		}).(*logHandler)
+		h.term = &terminal{columns: func() int { return 20 }}
+		slog.New(h).Info("msg", "k", long)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("fit %d: got %q, want %q", tt.fit, got, tt.want)
+		}
+
+		// Redirected output is never cut.
+		buf.Reset()
+		slog.New(NewLogHandler(&buf, h.opts)).Info("msg", "k", long)
+		if got := buf.String(); got != "msg [k:"+long+"]\n" {
+			t.Errorf("fit %d without a terminal: got %q", tt.fit, got)
+		}
+	}
+}
+
+func TestTransientTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLogHandler(&buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Compact:        true,
+	}).(*logHandler)
+	h.term = &terminal{columns: func() int { return 10 }}
+	slog.New(h).Info("progress", "n", 12345, Transient())
+	if got, want := buf.String(), "\r\x1b[2Kprogress …"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWrapWidth(t *testing.T) {
+	if got, want := wrapWidth("日本語のテキスト", 6, " "), "日本語\n のテ\n キス\n ト"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
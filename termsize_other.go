@@ -0,0 +1,8 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || windows)
+
+package sloghandler
+
+// terminalColumns returns 0: the terminal width is unknown on this platform.
+func terminalColumns(fd uintptr) int {
+	return 0
+}
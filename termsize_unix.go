@@ -0,0 +1,14 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package sloghandler
+
+import "golang.org/x/sys/unix"
+
+// terminalColumns returns the width of the terminal at fd, or 0 if unknown.
+func terminalColumns(fd uintptr) int {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package sloghandler
+
+import "golang.org/x/sys/windows"
+
+// terminalColumns returns the width of the console at fd, or 0 if unknown.
+func terminalColumns(fd uintptr) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(fd), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Right-info.Window.Left) + 1
+}
@@ -0,0 +1,117 @@
+// Package testutil helps lock down the exact output of slog handlers with
+// golden files.
+//
+// Records are rendered with a fixed time and compared against
+// testdata/<name>.golden. Run the tests with -update to rewrite the golden
+// files after an intended format change:
+//
+//	go test ./... -update
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Time is the time of records created by NewRecord.
+var Time = time.Date(2023, 5, 9, 12, 34, 56, 789_000_000, time.UTC)
+
+func init() {
+	// Tolerate test binaries that define their own -update flag.
+	if flag.Lookup("update") == nil {
+		flag.Bool("update", false, "update golden files")
+	}
+}
+
+func updating() bool {
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+// NewRecord returns a record at Time with the given level, message and
+// attributes, as passed to slog.Logger.Log.
+func NewRecord(level slog.Level, msg string, args ...any) slog.Record {
+	r := slog.NewRecord(Time, level, msg, 0)
+	r.Add(args...)
+	return r
+}
+
+// Render passes records to h and returns what it wrote to w.
+// newHandler is called once with the output buffer.
+func Render(newHandler func(w io.Writer) slog.Handler, records ...slog.Record) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	h := newHandler(buf)
+	for _, r := range records {
+		if !h.Enabled(context.Background(), r.Level) {
+			continue
+		}
+		if err := h.Handle(context.Background(), r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Golden renders records through the handler built by newHandler and compares
+// the output with testdata/<name>.golden.
+func Golden(t testing.TB, name string, newHandler func(w io.Writer) slog.Handler, records ...slog.Record) {
+	t.Helper()
+	got, err := Render(newHandler, records...)
+	if err != nil {
+		t.Fatalf("render %s: %v", name, err)
+	}
+	AssertGolden(t, name, got)
+}
+
+// AssertGolden compares got with testdata/<name>.golden, or writes it there
+// when the tests run with -update.
+func AssertGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run with -update to accept it):\n%s", path, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff describes the differing lines of want and got.
+func lineDiff(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	var sb strings.Builder
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			continue
+		}
+		fmt.Fprintf(&sb, "line %d:\n  want: %q\n  got:  %q\n", i+1, w, g)
+	}
+	return sb.String()
+}
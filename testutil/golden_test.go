@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newJSONHandler(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "json", newJSONHandler,
+		NewRecord(slog.LevelInfo, "started", "port", 8080),
+		NewRecord(slog.LevelDebug, "skipped"),
+		NewRecord(slog.LevelError, "failed", slog.Group("req", "id", 7)),
+	)
+}
+
+func TestLineDiff(t *testing.T) {
+	d := lineDiff("a\nb\nc\n", "a\nB\nc\n")
+	if !strings.Contains(d, "line 2:") || strings.Contains(d, "line 1:") {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+}
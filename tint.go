@@ -0,0 +1,285 @@
+package sloghandler
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ANSI sequences of the tint layout.
+const (
+	tintReset      = "\033[0m"
+	tintFaint      = "\033[2m"
+	tintResetFaint = "\033[22m"
+	tintRed        = "\033[91m"
+	tintGreen      = "\033[92m"
+	tintYellow     = "\033[93m"
+	tintRedFaint   = "\033[91;2m"
+)
+
+// TintOptions configures a handler created by NewTintHandler. The fields
+// match the options of github.com/lmittmann/tint.
+type TintOptions struct {
+	// AddSource writes the source location as "dir/file.go:line".
+	AddSource bool
+	// Level is the minimum level logged. Default is slog.LevelInfo.
+	Level slog.Leveler
+	// ReplaceAttr rewrites attributes as slog.HandlerOptions.ReplaceAttr
+	// does, including the built-in time, level, source and message.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+	// TimeFormat is the layout of the time. Default is time.StampMilli.
+	TimeFormat string
+	// NoColor disables colors.
+	NoColor bool
+}
+
+type tintHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   TintOptions
+	pre    []byte   // attributes from WithAttrs, rendered
+	groups []string // groups opened with WithGroup
+	prefix string   // groups joined with dots, ending with a dot
+}
+
+// NewTintHandler returns a handler writing the console layout of the
+// popular github.com/lmittmann/tint package, so that programs moving to
+// this package keep their familiar output:
+//
+//	Jun  1 12:34:56.789 INF request served status=200 path=/api
+//
+// The time and attribute keys are dimmed, INF is green, WRN yellow and ERR
+// red, and attributes holding errors are red. Levels between the named
+// ones are written with an offset, e.g. DBG-4 for TRACE. The handler can
+// be wrapped by any other handler of this package, such as the metrics
+// and sampling handlers.
+func NewTintHandler(w io.Writer, opts *TintOptions) slog.Handler {
+	h := &tintHandler{w: w, mu: lockFor(w)}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	if h.opts.TimeFormat == "" {
+		h.opts.TimeFormat = time.StampMilli
+	}
+	if t := terminalFor(w); t != nil && t.plain {
+		h.opts.NoColor = true
+	}
+	return h
+}
+
+func (h *tintHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(ctx, h.opts.Level)
+}
+
+func (h *tintHandler) Handle(ctx context.Context, r slog.Record) error {
+	buf := make([]byte, 0, 256)
+	rep := h.opts.ReplaceAttr
+
+	if !r.Time.IsZero() {
+		a := slog.Time(slog.TimeKey, r.Time.Round(0))
+		if rep != nil {
+			a = rep(nil, a)
+			a.Value = a.Value.Resolve()
+		}
+		if !a.Equal(slog.Attr{}) {
+			buf = h.faint(buf, h.value(a.Value))
+			buf = append(buf, ' ')
+		}
+	}
+
+	if rep == nil {
+		buf = h.appendLevel(buf, r.Level)
+		buf = append(buf, ' ')
+	} else if a := rep(nil, slog.Any(slog.LevelKey, r.Level)); !a.Equal(slog.Attr{}) {
+		a.Value = a.Value.Resolve()
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			buf = h.appendLevel(buf, l)
+		} else {
+			buf = append(buf, a.Value.String()...)
+		}
+		buf = append(buf, ' ')
+	}
+
+	if h.opts.AddSource && r.PC != 0 {
+		src := r.Source()
+		a := slog.Any(slog.SourceKey, src)
+		if rep != nil {
+			a = rep(nil, a)
+			a.Value = a.Value.Resolve()
+		}
+		if s, ok := a.Value.Any().(*slog.Source); ok && s != nil {
+			dir, file := filepath.Split(s.File)
+			buf = h.faint(buf, filepath.Join(filepath.Base(dir), file)+":"+strconv.Itoa(s.Line))
+			buf = append(buf, ' ')
+		} else if !a.Equal(slog.Attr{}) {
+			buf = h.faint(buf, a.Value.String())
+			buf = append(buf, ' ')
+		}
+	}
+
+	msg := slog.String(slog.MessageKey, r.Message)
+	if rep != nil {
+		msg = rep(nil, msg)
+		msg.Value = msg.Value.Resolve()
+	}
+	if !msg.Equal(slog.Attr{}) {
+		buf = append(buf, msg.Value.String()...)
+		buf = append(buf, ' ')
+	}
+
+	buf = append(buf, h.pre...)
+	r.Attrs(func(a slog.Attr) bool {
+		buf = h.appendAttr(buf, a, h.prefix, h.groups)
+		return true
+	})
+	if n := len(buf); n > 0 && buf[n-1] == ' ' {
+		buf = buf[:n-1]
+	}
+	buf = append(buf, '\n')
+
+	if h.mu != nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+	}
+	_, err := h.w.Write(buf)
+	return err
+}
+
+func (h *tintHandler) faint(buf []byte, s string) []byte {
+	if h.opts.NoColor {
+		return append(buf, s...)
+	}
+	buf = append(buf, tintFaint...)
+	buf = append(buf, s...)
+	return append(buf, tintReset...)
+}
+
+func (h *tintHandler) appendLevel(buf []byte, level slog.Level) []byte {
+	color, name, base := "", "DBG", slog.LevelDebug
+	switch {
+	case level >= slog.LevelError:
+		color, name, base = tintRed, "ERR", slog.LevelError
+	case level >= slog.LevelWarn:
+		color, name, base = tintYellow, "WRN", slog.LevelWarn
+	case level >= slog.LevelInfo:
+		color, name, base = tintGreen, "INF", slog.LevelInfo
+	}
+	if !h.opts.NoColor && color != "" {
+		buf = append(buf, color...)
+	}
+	buf = append(buf, name...)
+	if d := level - base; d > 0 {
+		buf = append(buf, '+')
+		buf = strconv.AppendInt(buf, int64(d), 10)
+	} else if d < 0 {
+		buf = strconv.AppendInt(buf, int64(d), 10)
+	}
+	if !h.opts.NoColor && color != "" {
+		buf = append(buf, tintReset...)
+	}
+	return buf
+}
+
+// appendAttr renders a as "key=value " with its groups in prefix.
+func (h *tintHandler) appendAttr(buf []byte, a slog.Attr, prefix string, groups []string) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			buf = h.appendAttr(buf, ga, prefix, groups)
+		}
+		return buf
+	}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+	_, isErr := a.Value.Any().(error)
+	isErr = isErr && a.Value.Kind() == slog.KindAny
+	switch {
+	case h.opts.NoColor:
+	case isErr:
+		buf = append(buf, tintRedFaint...)
+	default:
+		buf = append(buf, tintFaint...)
+	}
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+	if !h.opts.NoColor {
+		buf = append(buf, tintResetFaint...)
+	}
+	buf = append(buf, tintQuote(h.value(a.Value))...)
+	if !h.opts.NoColor && isErr {
+		buf = append(buf, tintReset...)
+	}
+	return append(buf, ' ')
+}
+
+func (h *tintHandler) value(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(h.opts.TimeFormat)
+	case slog.KindAny:
+		if tm, ok := v.Any().(encoding.TextMarshaler); ok {
+			if b, err := tm.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+		return fmt.Sprint(v.Any())
+	}
+	return v.String()
+}
+
+// tintQuote quotes s when it is empty or contains spaces, quotes, equal
+// signs or unprintable characters.
+func tintQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+func (h *tintHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.pre = slices.Clip(h.pre)
+	for _, a := range attrs {
+		h2.pre = h.appendAttr(h2.pre, a, h.prefix, h.groups)
+	}
+	return &h2
+}
+
+func (h *tintHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
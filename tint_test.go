@@ -0,0 +1,61 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestTintHandler(t *testing.T) {
+	ts := time.Date(2024, 6, 1, 12, 34, 56, 789_000_000, time.UTC)
+	log := func(h slog.Handler, level slog.Level, msg string, args ...any) {
+		r := slog.NewRecord(ts, level, msg, 0)
+		r.Add(args...)
+		h.Handle(context.Background(), r)
+	}
+
+	var buf bytes.Buffer
+	h := NewTintHandler(&buf, &TintOptions{NoColor: true, Level: LevelTrace})
+	log(h, slog.LevelInfo, "request served", "status", 200, "path", "/api")
+	log(h.WithAttrs([]slog.Attr{slog.String("app", "web")}).WithGroup("req"), slog.LevelWarn+2, "slow", "query", "a b", "empty", "")
+	log(h, LevelTrace, "trace", slog.Group("g", "k", "v"))
+	log(h, slog.LevelError, "failed", "err", errors.New("boom"))
+	want := "Jun  1 12:34:56.789 INF request served status=200 path=/api\n" +
+		"Jun  1 12:34:56.789 WRN+2 slow app=web req.query=\"a b\" req.empty=\"\"\n" +
+		"Jun  1 12:34:56.789 DBG-4 trace g.k=v\n" +
+		"Jun  1 12:34:56.789 ERR failed err=boom\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	log(NewTintHandler(&buf, nil), slog.LevelError, "failed", "n", 1, "err", errors.New("boom"))
+	want = "\033[2mJun  1 12:34:56.789\033[0m \033[91mERR\033[0m failed \033[2mn=\033[22m1 \033[91;2merr=\033[22mboom\033[0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestTintHandlerReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewTintHandler(&buf, &TintOptions{
+		NoColor: true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey || a.Key == "secret" {
+				return slog.Attr{}
+			}
+			if len(groups) == 1 && groups[0] == "req" && a.Key == "id" {
+				a.Value = slog.StringValue("redacted")
+			}
+			return a
+		},
+	})
+	slog.New(h).WithGroup("req").Debug("dropped")
+	slog.New(h).WithGroup("req").Info("hello", "id", 7, "secret", "x")
+	if got, want := buf.String(), "INF hello req.id=redacted\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// TraceParent is the trace context of a W3C traceparent header.
+type TraceParent struct {
+	// TraceID is the 32 hex digit trace ID.
+	TraceID string
+	// SpanID is the 16 hex digit ID of the caller's span.
+	SpanID string
+	// Sampled reports whether the caller sampled the trace.
+	Sampled bool
+}
+
+var errTraceParent = errors.New("sloghandler: invalid traceparent")
+
+// ParseTraceParent parses the value of a W3C traceparent header, such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". It needs no
+// OpenTelemetry SDK.
+func ParseTraceParent(header string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return TraceParent{}, errTraceParent
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	switch {
+	case !isLowerHex(version, 2) || version == "ff",
+		version == "00" && len(parts) != 4,
+		!isLowerHex(traceID, 32) || strings.Trim(traceID, "0") == "",
+		!isLowerHex(spanID, 16) || strings.Trim(spanID, "0") == "",
+		!isLowerHex(flags, 2):
+		return TraceParent{}, errTraceParent
+	}
+	sampled := strings.IndexByte("13579bdf", flags[1]) >= 0 // the low bit of the flags
+	return TraceParent{TraceID: traceID, SpanID: spanID, Sampled: sampled}, nil
+}
+
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := range len(s) {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type traceParentKey struct{}
+
+// WithTraceParent returns a context carrying tp.
+func WithTraceParent(ctx context.Context, tp TraceParent) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, tp)
+}
+
+// TraceParentFromContext returns the trace context stored by WithTraceParent
+// or TraceParentMiddleware.
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	if ctx == nil {
+		return TraceParent{}, false
+	}
+	tp, ok := ctx.Value(traceParentKey{}).(TraceParent)
+	return tp, ok
+}
+
+// TraceParentMiddleware returns net/http middleware that parses the
+// traceparent header of each request and stores it in the request context.
+// Invalid headers are ignored. Add TraceParentEnricher to log the IDs:
+//
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Enrich(sloghandler.TraceParentEnricher))
+//	http.Handle("/", sloghandler.TraceParentMiddleware(app))
+func TraceParentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tp, err := ParseTraceParent(r.Header.Get("traceparent")); err == nil {
+			r = r.WithContext(WithTraceParent(r.Context(), tp))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TraceParentEnricher adds "trace_id" and "span_id" attributes from the
+// trace context in ctx, if any.
+func TraceParentEnricher(ctx context.Context, r slog.Record) []slog.Attr {
+	tp, ok := TraceParentFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{slog.String("trace_id", tp.TraceID), slog.String("span_id", tp.SpanID)}
+}
+
+// TraceParentSampled reports the sampled flag of the trace context in ctx.
+// It can be used as RequestBufferOptions.TraceSampled.
+func TraceParentSampled(ctx context.Context) (sampled, ok bool) {
+	tp, ok := TraceParentFromContext(ctx)
+	return tp.Sampled, ok
+}
@@ -0,0 +1,61 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		header string
+		want   TraceParent
+		ok     bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", TraceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true}, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", TraceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false}, true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03-extra", TraceParent{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true}, true},
+		{"", TraceParent{}, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", TraceParent{}, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", TraceParent{}, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", TraceParent{}, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", TraceParent{}, false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", TraceParent{}, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", TraceParent{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseTraceParent(tt.header)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseTraceParent(%q) = %+v, %v", tt.header, got, err)
+		}
+	}
+}
+
+func TestTraceParentMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewTextHandler(buf, nil), Enrich(TraceParentEnricher)))
+	srv := TraceParentMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sampled, ok := TraceParentSampled(r.Context()); r.Header.Get("traceparent") != "" && (!ok || !sampled) {
+			t.Errorf("TraceParentSampled = %v, %v", sampled, ok)
+		}
+		logger.InfoContext(r.Context(), "handling")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "msg=handling trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7") {
+		t.Errorf("line 0 = %q", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("line 1 = %q", lines[1])
+	}
+}
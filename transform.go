@@ -0,0 +1,164 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Transformer rewrites or drops a record before it reaches a handler.
+// It returns the record to pass on and false to drop it. Transformers that
+// add attributes to the record they were given must Clone it first.
+type Transformer interface {
+	Transform(ctx context.Context, r slog.Record) (slog.Record, bool)
+}
+
+// TransformerFunc adapts a function to a Transformer.
+type TransformerFunc func(ctx context.Context, r slog.Record) (slog.Record, bool)
+
+func (f TransformerFunc) Transform(ctx context.Context, r slog.Record) (slog.Record, bool) {
+	return f(ctx, r)
+}
+
+// TransformHandler passes each record through a chain of transformers before
+// handing it to the wrapped handler. It is the extension point for redaction,
+// enrichment and filtering.
+//
+// Attributes added with WithAttrs and groups opened with WithGroup are not
+// forwarded to the wrapped handler; they are folded into each record instead,
+// groups as nested group attributes, so transformers see every attribute.
+type TransformHandler struct {
+	base         slog.Handler
+	transformers []Transformer
+	goas         []groupOrAttrs
+}
+
+// groupOrAttrs is either a group name or attributes added by WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewTransformHandler creates a TransformHandler that applies transformers in order.
+func NewTransformHandler(h slog.Handler, transformers ...Transformer) *TransformHandler {
+	return &TransformHandler{base: h, transformers: transformers}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *TransformHandler) Unwrap() slog.Handler {
+	return h.base
+}
+
+func (h *TransformHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *TransformHandler) Handle(ctx context.Context, record slog.Record) error {
+	r := h.fold(record)
+	for _, t := range h.transformers {
+		var ok bool
+		if r, ok = t.Transform(ctx, r); !ok {
+			return nil
+		}
+	}
+	return h.base.Handle(ctx, r)
+}
+
+// fold returns record with the attributes and groups of the handler applied.
+func (h *TransformHandler) fold(record slog.Record) slog.Record {
+	if len(h.goas) == 0 {
+		return record
+	}
+	attrs := recordAttrs(record)
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		g := h.goas[i]
+		if g.group != "" {
+			if len(attrs) > 0 {
+				attrs = []slog.Attr{{Key: g.group, Value: slog.GroupValue(attrs...)}}
+			}
+		} else {
+			attrs = append(slices.Clip(g.attrs), attrs...)
+		}
+	}
+	r := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	r.AddAttrs(attrs...)
+	return r
+}
+
+func (h *TransformHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *TransformHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *TransformHandler) with(goa groupOrAttrs) *TransformHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// recordAttrs returns the attributes of r.
+func recordAttrs(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// DropRecords returns a Transformer that drops records for which drop returns true.
+func DropRecords(drop func(ctx context.Context, r slog.Record) bool) Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		return r, !drop(ctx, r)
+	})
+}
+
+// RewriteMessage returns a Transformer that replaces each message with f(msg).
+func RewriteMessage(f func(msg string) string) Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		r.Message = f(r.Message)
+		return r, true
+	})
+}
+
+// MapAttrs returns a Transformer that rewrites every attribute with f, like
+// slog.HandlerOptions.ReplaceAttr: f is called for each non-group attribute
+// with the names of its enclosing groups, and an attribute is removed when
+// f returns an empty Attr.
+func MapAttrs(f func(groups []string, a slog.Attr) slog.Attr) Transformer {
+	return TransformerFunc(func(ctx context.Context, r slog.Record) (slog.Record, bool) {
+		r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r2.AddAttrs(mapAttrs(nil, recordAttrs(r), f)...)
+		return r2, true
+	})
+}
+
+func mapAttrs(groups []string, attrs []slog.Attr, f func([]string, slog.Attr) slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			gs := groups
+			if a.Key != "" {
+				gs = append(slices.Clip(groups), a.Key)
+			}
+			if members := mapAttrs(gs, a.Value.Group(), f); len(members) > 0 {
+				out = append(out, slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)})
+			}
+			continue
+		}
+		if a = f(groups, a); !a.Equal(slog.Attr{}) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTransformHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	redact := MapAttrs(func(groups []string, a slog.Attr) slog.Attr {
+		switch a.Key {
+		case "password":
+			return slog.String(a.Key, "[REDACTED]")
+		case "debug_only":
+			return slog.Attr{}
+		}
+		return a
+	})
+	dropHealth := DropRecords(func(ctx context.Context, r slog.Record) bool {
+		return r.Message == "health check"
+	})
+	upper := RewriteMessage(strings.ToUpper)
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil), dropHealth, redact, upper))
+
+	logger.Info("health check")
+	logger.With("password", "hunter2").WithGroup("req").With("debug_only", 1, "id", 7).
+		Info("login", "user", "alice", "password", "secret")
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("expected exactly one record: %v\n%s", err, buf.String())
+	}
+	if m["msg"] != "LOGIN" || m["password"] != "[REDACTED]" {
+		t.Errorf("unexpected record %v", m)
+	}
+	req, _ := m["req"].(map[string]any)
+	if req["id"] != 7.0 || req["user"] != "alice" || req["password"] != "[REDACTED]" {
+		t.Errorf("unexpected group %v", m["req"])
+	}
+	if _, ok := req["debug_only"]; ok {
+		t.Errorf("removed attribute still present: %v", req)
+	}
+}
+
+func TestTransformHandlerEmptyGroup(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransformHandler(slog.NewJSONHandler(buf, nil))).With("a", 1).WithGroup("g")
+	logger.Info("no attrs")
+	if strings.Contains(buf.String(), `"g"`) {
+		t.Errorf("empty group should be omitted: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"a":1`) {
+		t.Errorf("missing attribute: %s", buf.String())
+	}
+}
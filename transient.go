@@ -0,0 +1,41 @@
+package sloghandler
+
+import "log/slog"
+
+// TransientKey is the key of the reserved attribute that marks a record as
+// transient. See Transient.
+const TransientKey = "transient"
+
+// Transient returns an attribute marking a record as a progress update.
+// When the text handler writes to a terminal, a transient record replaces the
+// current line instead of appending a new one, and the next record replaces
+// it in turn; other writers get normal lines. The attribute itself is not shown.
+//
+//	for i, f := range files {
+//		logger.Info("uploading", "file", f, "done", i, sloghandler.Transient())
+//	}
+//	logger.Info("upload complete")
+func Transient() slog.Attr {
+	return slog.Bool(TransientKey, true)
+}
+
+// isTransientAttr reports whether a is a transient attribute, and its value.
+func isTransientAttr(a slog.Attr) (transient, ok bool) {
+	if a.Key != TransientKey || a.Value.Kind() != slog.KindBool {
+		return false, false
+	}
+	return a.Value.Bool(), true
+}
+
+// recordTransient reports whether r is marked transient.
+func recordTransient(r slog.Record) bool {
+	transient := false
+	r.Attrs(func(a slog.Attr) bool {
+		if t, ok := isTransientAttr(a); ok {
+			transient = t
+			return false
+		}
+		return true
+	})
+	return transient
+}
@@ -0,0 +1,35 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestTransient(t *testing.T) {
+	opts := &HandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo}, Compact: true}
+
+	var tty bytes.Buffer
+	h := NewLogHandler(&tty, opts).(*logHandler)
+	h.term = &terminal{} // pretend the buffer is a terminal
+	logger := slog.New(h)
+	logger.Info("uploading", "n", 1, Transient())
+	logger.Info("uploading", "n", 2, Transient())
+	logger.Info("done")
+	logger.Info("next")
+	want := "\r\x1b[2Kuploading [n:1]" +
+		"\r\x1b[2Kuploading [n:2]" +
+		"\r\x1b[2Kdone\n" +
+		"next\n"
+	if got := tty.String(); got != want {
+		t.Errorf("terminal output\n got %q\nwant %q", got, want)
+	}
+
+	var file bytes.Buffer
+	logger = slog.New(NewLogHandler(&file, opts))
+	logger.Info("uploading", "n", 1, Transient())
+	logger.Info("done")
+	if got, want := file.String(), "uploading [n:1]\ndone\n"; got != want {
+		t.Errorf("file output\n got %q\nwant %q", got, want)
+	}
+}
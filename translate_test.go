@@ -0,0 +1,41 @@
+package sloghandler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	catalog := map[string]string{
+		"file not found": "ファイル %s が見つかりません",
+	}
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Translate: func(msg string, attrs []slog.Attr) string {
+			format, ok := catalog[msg]
+			if !ok {
+				return msg
+			}
+			for _, a := range attrs {
+				if a.Key == "path" {
+					return fmt.Sprintf(format, a.Value)
+				}
+			}
+			return msg
+		},
+	}))
+	logger.Warn("file not found", "path", "config.yaml")
+	logger.Info("started")
+
+	out := buf.String()
+	if !strings.Contains(out, "[WARN] ファイル config.yaml が見つかりません [path:config.yaml]") {
+		t.Errorf("message not translated:\n%s", out)
+	}
+	if !strings.Contains(out, "[INFO] started") {
+		t.Errorf("untranslated message changed:\n%s", out)
+	}
+}
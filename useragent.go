@@ -0,0 +1,239 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// UserAgent is the result of parsing a User-Agent header.
+type UserAgent struct {
+	// Name and Version are the browser, bot or HTTP client, e.g. "Chrome"
+	// and "124.0.6367.91".
+	Name, Version string
+	// OS and OSVersion are the operating system, e.g. "iOS" and "17.4".
+	OS, OSVersion string
+	// Device is "desktop", "mobile", "tablet", "bot" or "other".
+	Device string
+}
+
+// ParseUserAgent parses a User-Agent header with heuristics covering the
+// common browsers, operating systems, crawlers and HTTP clients. Unknown
+// parts are left empty.
+func ParseUserAgent(s string) UserAgent {
+	var ua UserAgent
+	lower := strings.ToLower(s)
+	ua.OS, ua.OSVersion = userAgentOS(s)
+
+	for _, c := range []struct{ token, name string }{
+		{"curl/", "curl"},
+		{"Wget/", "Wget"},
+		{"python-requests/", "python-requests"},
+		{"Go-http-client/", "Go-http-client"},
+		{"okhttp/", "okhttp"},
+		{"PostmanRuntime/", "Postman"},
+	} {
+		if strings.HasPrefix(s, c.token) {
+			ua.Name, ua.Version = c.name, userAgentVersion(s, c.token)
+			ua.Device = "other"
+			return ua
+		}
+	}
+	if strings.Contains(lower, "bot") || strings.Contains(lower, "crawler") ||
+		strings.Contains(lower, "spider") || strings.Contains(lower, "slurp") {
+		ua.Name, ua.Version = userAgentBot(s)
+		ua.Device = "bot"
+		return ua
+	}
+
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"EdgA/", "Edge"},
+		{"EdgiOS/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"FxiOS/", "Firefox"},
+		{"Firefox/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+	} {
+		if strings.Contains(s, b.token) {
+			ua.Name, ua.Version = b.name, userAgentVersion(s, b.token)
+			break
+		}
+	}
+	switch {
+	case ua.Name != "":
+	case strings.Contains(s, "Safari/") && strings.Contains(s, "Version/"):
+		ua.Name, ua.Version = "Safari", userAgentVersion(s, "Version/")
+	case strings.Contains(s, "MSIE "):
+		ua.Name, ua.Version = "Internet Explorer", userAgentVersion(s, "MSIE ")
+	case strings.Contains(s, "Trident/") && strings.Contains(s, "rv:"):
+		ua.Name, ua.Version = "Internet Explorer", userAgentVersion(s, "rv:")
+	}
+
+	switch {
+	case strings.Contains(s, "iPad") || strings.Contains(s, "Tablet") ||
+		(ua.OS == "Android" && !strings.Contains(s, "Mobile")):
+		ua.Device = "tablet"
+	case strings.Contains(s, "Mobi") || strings.Contains(s, "iPhone"):
+		ua.Device = "mobile"
+	case ua.OS != "" || ua.Name != "":
+		ua.Device = "desktop"
+	default:
+		ua.Device = "other"
+	}
+	return ua
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10", // also Windows 11, which kept the version number
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+func userAgentOS(s string) (name, version string) {
+	switch {
+	case strings.Contains(s, "Windows NT "):
+		v := userAgentVersion(s, "Windows NT ")
+		if w, ok := windowsVersions[v]; ok {
+			v = w
+		}
+		return "Windows", v
+	case strings.Contains(s, "iPhone OS "):
+		return "iOS", strings.ReplaceAll(userAgentVersion(s, "iPhone OS "), "_", ".")
+	case strings.Contains(s, "iPad") && strings.Contains(s, "CPU OS "):
+		return "iPadOS", strings.ReplaceAll(userAgentVersion(s, "CPU OS "), "_", ".")
+	case strings.Contains(s, "Android"):
+		return "Android", userAgentVersion(s, "Android ")
+	case strings.Contains(s, "CrOS"):
+		return "ChromeOS", ""
+	case strings.Contains(s, "Mac OS X"):
+		return "macOS", strings.ReplaceAll(userAgentVersion(s, "Mac OS X "), "_", ".")
+	case strings.Contains(s, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// userAgentVersion returns the version following token in s.
+func userAgentVersion(s, token string) string {
+	_, v, ok := strings.Cut(s, token)
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(v, " ;)"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// userAgentBot returns the name and version of the product token naming
+// a crawler, such as "Googlebot/2.1".
+func userAgentBot(s string) (name, version string) {
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ';' || r == '(' || r == ')' }) {
+		n, v, _ := strings.Cut(f, "/")
+		l := strings.ToLower(n)
+		if strings.Contains(l, "bot") || strings.Contains(l, "crawler") ||
+			strings.Contains(l, "spider") || strings.Contains(l, "slurp") {
+			return strings.TrimPrefix(n, "+"), v
+		}
+	}
+	return "", ""
+}
+
+// UserAgentOptions configures a UserAgentParser.
+type UserAgentOptions struct {
+	// Key is the attribute holding the User-Agent header.
+	// Default is "user_agent".
+	Key string
+	// CacheSize is the number of parsed User-Agent strings kept.
+	// Default is 1024. Negative disables caching.
+	CacheSize int
+}
+
+// UserAgentParser parses the User-Agent attribute of records into
+// attributes that can be aggregated, caching the results, since the same
+// few strings make up most of the traffic:
+//
+//	ua := sloghandler.NewUserAgentParser(nil)
+//	h := sloghandler.NewTransformHandler(handler, sloghandler.Enrich(ua.Enrich))
+//	// user_agent.name=Chrome user_agent.version=124.0.6367.91 user_agent.os.name=Windows
+//	// user_agent.os.version=10 user_agent.device=desktop
+type UserAgentParser struct {
+	key   string
+	cache *lruCache[string, UserAgent] // nil if caching is disabled
+}
+
+// NewUserAgentParser creates a UserAgentParser.
+func NewUserAgentParser(opts *UserAgentOptions) *UserAgentParser {
+	o := UserAgentOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Key == "" {
+		o.Key = "user_agent"
+	}
+	if o.CacheSize == 0 {
+		o.CacheSize = 1024
+	}
+	p := &UserAgentParser{key: o.Key}
+	if o.CacheSize > 0 {
+		p.cache = newLRUCache[string, UserAgent](o.CacheSize)
+	}
+	return p
+}
+
+// Parse returns ParseUserAgent(s), from the cache if s was seen recently.
+func (p *UserAgentParser) Parse(s string) UserAgent {
+	if p.cache == nil {
+		return ParseUserAgent(s)
+	}
+	if ua, ok := p.cache.get(s); ok {
+		return ua
+	}
+	ua := ParseUserAgent(s)
+	p.cache.add(s, ua)
+	return ua
+}
+
+// Stats returns the statistics of the cache.
+func (p *UserAgentParser) Stats() CacheStats {
+	if p.cache == nil {
+		return CacheStats{}
+	}
+	return p.cache.stats()
+}
+
+// Enrich adds "<key>.name", "<key>.version", "<key>.os.name",
+// "<key>.os.version" and "<key>.device" attributes parsed from the
+// top-level attribute Key of r, omitting empty values. It is an Enricher.
+func (p *UserAgentParser) Enrich(ctx context.Context, r slog.Record) []slog.Attr {
+	var s string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == p.key {
+			s = a.Value.Resolve().String()
+			return false
+		}
+		return true
+	})
+	if s == "" {
+		return nil
+	}
+	ua := p.Parse(s)
+	var attrs []slog.Attr
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(p.key+key, value))
+		}
+	}
+	add(".name", ua.Name)
+	add(".version", ua.Version)
+	add(".os.name", ua.OS)
+	add(".os.version", ua.OSVersion)
+	add(".device", ua.Device)
+	return attrs
+}
@@ -0,0 +1,94 @@
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want UserAgent
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.91 Safari/537.36",
+			UserAgent{Name: "Chrome", Version: "124.0.6367.91", OS: "Windows", OSVersion: "10", Device: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.67",
+			UserAgent{Name: "Edge", Version: "124.0.2478.67", OS: "Windows", OSVersion: "10", Device: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			UserAgent{Name: "Safari", Version: "17.4", OS: "iOS", OSVersion: "17.4", Device: "mobile"},
+		},
+		{
+			"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			UserAgent{Name: "Chrome", Version: "120.0.6099.119", OS: "iPadOS", OSVersion: "16.6", Device: "tablet"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			UserAgent{Name: "Chrome", Version: "124.0.6367.82", OS: "Android", OSVersion: "14", Device: "mobile"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Safari/537.36",
+			UserAgent{Name: "Samsung Internet", Version: "24.0", OS: "Android", OSVersion: "13", Device: "tablet"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:125.0) Gecko/20100101 Firefox/125.0",
+			UserAgent{Name: "Firefox", Version: "125.0", OS: "macOS", OSVersion: "10.15", Device: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			UserAgent{Name: "Firefox", Version: "125.0", OS: "Linux", Device: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			UserAgent{Name: "Internet Explorer", Version: "11.0", OS: "Windows", OSVersion: "7", Device: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			UserAgent{Name: "Googlebot", Version: "2.1", Device: "bot"},
+		},
+		{"curl/8.5.0", UserAgent{Name: "curl", Version: "8.5.0", Device: "other"}},
+		{"Go-http-client/1.1", UserAgent{Name: "Go-http-client", Version: "1.1", Device: "other"}},
+		{"something else", UserAgent{Device: "other"}},
+	}
+	for _, tt := range tests {
+		if got := ParseUserAgent(tt.ua); got != tt.want {
+			t.Errorf("ParseUserAgent(%q)\n got  %+v\n want %+v", tt.ua, got, tt.want)
+		}
+	}
+}
+
+func TestUserAgentParserEnrich(t *testing.T) {
+	p := NewUserAgentParser(&UserAgentOptions{Key: "ua", CacheSize: 1})
+	record := func(ua string) slog.Record {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "request", 0)
+		r.AddAttrs(slog.String("ua", ua))
+		return r
+	}
+	ctx := context.Background()
+	got := attrMap(p.Enrich(ctx, record("curl/8.5.0")))
+	want := map[string]string{"ua.name": "curl", "ua.version": "8.5.0", "ua.device": "other"}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	p.Enrich(ctx, record("curl/8.5.0"))
+	p.Enrich(ctx, record("Wget/1.21"))
+	if s := p.Stats(); s.Hits != 1 || s.Misses != 2 || s.Evictions != 1 || s.Len != 1 || s.Cap != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "no user agent", 0)
+	if attrs := p.Enrich(ctx, r); attrs != nil {
+		t.Errorf("got %v", attrs)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package sloghandler
+
+import "os"
+
+// enableVirtualTerminal reports whether the terminal f shows ANSI escape
+// sequences, which terminals outside Windows always do.
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
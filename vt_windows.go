@@ -0,0 +1,30 @@
+//go:build windows
+
+package sloghandler
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal enables the processing of ANSI escape sequences on
+// the terminal f, reporting whether it succeeded. Windows consoles before
+// Windows 10 do not support it. Cygwin and MSYS terminals such as mintty
+// handle the sequences themselves.
+func enableVirtualTerminal(f *os.File) bool {
+	if isatty.IsCygwinTerminal(f.Fd()) {
+		return true
+	}
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	mode |= windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+	return windows.SetConsoleMode(h, mode) == nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package sloghandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnableVirtualTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if enableVirtualTerminal(f) {
+		t.Error("a regular file is not a console")
+	}
+}
@@ -0,0 +1,264 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WebhookFormat selects the payload posted by a WebhookHandler.
+type WebhookFormat int
+
+const (
+	// WebhookSlack posts a Slack incoming webhook payload: {"text": "..."}.
+	WebhookSlack WebhookFormat = iota
+	// WebhookJSON posts {"text": "...", "record": {...}, "suppressed": n}
+	// for generic JSON webhooks.
+	WebhookJSON
+)
+
+// DefaultWebhookTemplate renders a record in the bracket format used by the text handler.
+const DefaultWebhookTemplate = `[{{.Level}}] {{.Message}}{{range .Attrs}} [{{.Key}}:{{.Value}}]{{end}}` +
+	`{{if .Suppressed}} ({{.Suppressed}} similar records suppressed){{end}}`
+
+// WebhookData is the value passed to WebhookOptions.Template.
+type WebhookData struct {
+	*Entry
+	// Suppressed is the number of records dropped by rate limiting since the previous notification.
+	Suppressed int
+}
+
+// WebhookOptions configures a WebhookHandler.
+type WebhookOptions struct {
+	// Level is the minimum level forwarded to the webhook.
+	// Default is slog.LevelError.
+	Level slog.Leveler
+	// Format selects the payload format. Default is WebhookSlack.
+	Format WebhookFormat
+	// Template is a text/template rendered with WebhookData to build the message text.
+	// Default is DefaultWebhookTemplate.
+	Template string
+	// Interval and Burst limit notifications to Burst per Interval.
+	// Records over the limit are dropped and reported in the next notification.
+	// Default is 1 per 10 seconds. A negative Interval disables rate limiting.
+	Interval time.Duration
+	Burst    int
+	// Header is added to every request.
+	Header http.Header
+	// Client is used to post notifications. Default is a client with a 10 second timeout.
+	Client *http.Client
+	// QueueSize is the number of notifications buffered for delivery. Default is 100.
+	QueueSize int
+	// Backpressure selects what happens to a record when the queue is full.
+	// Default is BackpressureDropNewest.
+	Backpressure Backpressure
+	// OnError is called when a notification cannot be delivered.
+	// Default writes the error to os.Stderr.
+	OnError func(error)
+	// OnHealthChange is called with the sink's Health when deliveries start
+	// failing and when they recover. See LogHealthChanges.
+	OnHealthChange func(Health)
+}
+
+// WebhookHandler is a slog.Handler that posts records to a Slack incoming
+// webhook or a generic JSON webhook. Notifications are delivered in the
+// background; call Close to deliver pending notifications before exiting.
+type WebhookHandler struct {
+	url     string
+	opts    WebhookOptions
+	tmpl    *template.Template
+	limiter *rateLimiter
+	queue   *batcher[[]byte]
+	health  *sinkHealth
+	scope   attrScope
+}
+
+// NewWebhookHandler creates a handler that posts records to url.
+func NewWebhookHandler(url string, opts *WebhookOptions) (*WebhookHandler, error) {
+	o := WebhookOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelError
+	}
+	if o.Template == "" {
+		o.Template = DefaultWebhookTemplate
+	}
+	if o.Interval == 0 {
+		o.Interval = 10 * time.Second
+	}
+	if o.Burst <= 0 {
+		o.Burst = 1
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 100
+	}
+	if o.OnError == nil {
+		o.OnError = stderrOnError("webhook")
+	}
+	tmpl, err := template.New("webhook").Parse(o.Template)
+	if err != nil {
+		return nil, fmt.Errorf("sloghandler: invalid webhook template: %w", err)
+	}
+	h := &WebhookHandler{
+		url:  url,
+		opts: o,
+		tmpl: tmpl,
+	}
+	if o.Interval > 0 {
+		h.limiter = &rateLimiter{interval: o.Interval, burst: o.Burst}
+	}
+	h.health = newSinkHealth(o.OnHealthChange)
+	h.queue = newBatcher(o.QueueSize, 1, 0, func(bodies [][]byte) {
+		for _, body := range bodies {
+			h.health.report(h.post(body), h.opts.OnError)
+		}
+	})
+	h.health.queue = h.queue
+	h.queue.policy = o.Backpressure
+	return h, nil
+}
+
+func (h *WebhookHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *WebhookHandler) Handle(ctx context.Context, record slog.Record) error {
+	suppressed := 0
+	if h.limiter != nil {
+		ok, n := h.limiter.allow(time.Now())
+		if !ok {
+			return nil
+		}
+		suppressed = n
+	}
+	body, err := h.payload(WebhookData{Entry: newEntry(record, h.scope), Suppressed: suppressed})
+	if err != nil {
+		return err
+	}
+	return h.queue.add(ctx, body)
+}
+
+func (h *WebhookHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withAttrs(attrs)
+	return &h2
+}
+
+func (h *WebhookHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.scope = h.scope.withGroup(name)
+	return &h2
+}
+
+// Flush blocks until all queued notifications have been delivered.
+func (h *WebhookHandler) Flush() error {
+	h.queue.Flush()
+	return nil
+}
+
+// Close delivers pending notifications and stops the handler.
+// Handlers derived via WithAttrs or WithGroup share the queue and are closed too.
+func (h *WebhookHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of notifications dropped because the queue was full.
+func (h *WebhookHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Health reports the delivery state of the sink.
+func (h *WebhookHandler) Health() Health {
+	return h.health.snapshot()
+}
+
+func (h *WebhookHandler) payload(data WebhookData) ([]byte, error) {
+	var text bytes.Buffer
+	if err := h.tmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("sloghandler: failed to render webhook template: %w", err)
+	}
+	switch h.opts.Format {
+	case WebhookJSON:
+		return json.Marshal(struct {
+			Text       string `json:"text"`
+			Record     *Entry `json:"record"`
+			Suppressed int    `json:"suppressed"`
+		}{text.String(), data.Entry, data.Suppressed})
+	default:
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{text.String()})
+	}
+}
+
+func (h *WebhookHandler) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range h.opts.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sloghandler: webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// rateLimiter allows up to burst events per fixed interval window and
+// counts the events it rejects.
+type rateLimiter struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	burst       int
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+// allow reports whether an event at now is allowed. When allowed, it also
+// returns the number of events suppressed since the last allowed event.
+func (l *rateLimiter) allow(now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.windowStart) >= l.interval {
+		l.windowStart = now
+		l.count = 0
+	}
+	if l.count >= l.burst {
+		l.suppressed++
+		return false, 0
+	}
+	l.count++
+	n := l.suppressed
+	l.suppressed = 0
+	return true, n
+}
+
+// stderrOnError returns the default error callback for background sinks.
+func stderrOnError(name string) func(error) {
+	return func(err error) {
+		fmt.Fprintf(os.Stderr, "sloghandler: %s: %v\n", name, err)
+	}
+}
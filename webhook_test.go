@@ -0,0 +1,88 @@
+package sloghandler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	h, err := NewWebhookHandler(ts.URL, &WebhookOptions{Interval: time.Hour, Burst: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(h).With("service", "api")
+	logger.Info("ignored")
+	logger.Error("first failure", "code", 1)
+	logger.Error("second failure")
+	logger.Error("third failure")
+	h.limiter.windowStart = time.Time{} // open a new window
+	logger.Error("after window")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"[ERROR] first failure [service:api] [code:1]",
+		"[ERROR] after window [service:api] (2 similar records suppressed)",
+	}
+	if len(texts) != len(want) {
+		t.Fatalf("got %d notifications, want %d: %q", len(texts), len(want), texts)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Errorf("notification[%d] = %q, want %q", i, texts[i], want[i])
+		}
+	}
+}
+
+func TestWebhookJSONFormat(t *testing.T) {
+	h, err := NewWebhookHandler("http://example.invalid", &WebhookOptions{
+		Format:   WebhookJSON,
+		Template: "{{.Message}}",
+		OnError:  func(error) {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	body, err := h.payload(WebhookData{Entry: &Entry{Message: "boom", Level: slog.LevelError}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Text   string         `json:"text"`
+		Record map[string]any `json:"record"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "boom" || got.Record["level"] != "ERROR" {
+		t.Errorf("unexpected payload: %s", body)
+	}
+}
+
+func TestNewWebhookHandlerInvalidTemplate(t *testing.T) {
+	if _, err := NewWebhookHandler("http://example.invalid", &WebhookOptions{Template: "{{"}); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
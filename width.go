@@ -0,0 +1,104 @@
+package sloghandler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ansiLen returns the length of the ANSI escape sequence starting at s[i],
+// or 0 if there is none. CSI sequences (ESC [ ... final byte) and OSC
+// sequences (ESC ] ... BEL or ESC \) are recognized.
+func ansiLen(s string, i int) int {
+	if i+1 >= len(s) || s[i] != '\033' {
+		return 0
+	}
+	switch s[i+1] {
+	case '[':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j - i + 1
+			}
+		}
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j - i + 1
+			}
+			if s[j] == '\033' && j+1 < len(s) && s[j+1] == '\\' {
+				return j - i + 2
+			}
+		}
+	}
+	return 0
+}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if n := ansiLen(s, i); n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// StringWidth returns the number of terminal columns s occupies. East Asian
+// wide characters count as two columns and ANSI escape sequences as none.
+func StringWidth(s string) int {
+	return runewidth.StringWidth(StripANSI(s))
+}
+
+// TruncateWidth shortens s to at most width columns, ending it with tail
+// when truncated. ANSI escape sequences are kept without counting toward the
+// width, and a reset sequence is added if s is cut after one of them.
+// Wide characters are never split.
+func TruncateWidth(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	limit := width - runewidth.StringWidth(tail)
+	if limit < 0 {
+		limit, tail = width, ""
+	}
+	var sb strings.Builder
+	w := 0
+	escaped := false
+	for i := 0; i < len(s); {
+		if n := ansiLen(s, i); n > 0 {
+			sb.WriteString(s[i : i+n])
+			escaped = true
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := runewidth.RuneWidth(r)
+		if w+rw > limit {
+			break
+		}
+		sb.WriteString(s[i : i+size])
+		w += rw
+		i += size
+	}
+	if escaped {
+		sb.WriteString("\033[0m")
+	}
+	sb.WriteString(tail)
+	return sb.String()
+}
+
+// PadWidth appends spaces to s until it occupies width columns.
+func PadWidth(s string, width int) string {
+	if n := width - StringWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"hello", 5},
+		{"こんにちは", 10},
+		{"ｱｲｳ", 3}, // half-width katakana
+		{"\033[31merror\033[0m", 5},
+		{"\033]8;;https://example.com\033\\link\033]8;;\033\\", 4},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 6, "hello…"},
+		{"日本語のログ", 7, "日本語…"},
+		{"日本語のログ", 6, "日本…"}, // a wide character is never split
+		{"\033[31merror message\033[0m", 6, "\033[31merror\033[0m…"},
+		{"hello", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := TruncateWidth(tt.s, tt.width, "…"); got != tt.want {
+			t.Errorf("TruncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerWidthOptions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(buf, &HandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		MaxLen:         8,
+		MessageWidth:   12,
+	}))
+	logger.Info("開始", "path", "/api/v1/users")
+	logger.Info("completed", "path", "/")
+	logger.With("note", "長い説明文をここに書く").Info("done")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], "[path:/api/v1…]") || !strings.Contains(lines[2], "[note:長い説…]") {
+		t.Errorf("values not truncated by width:\n%s", buf.String())
+	}
+	col := func(line string) int {
+		_, after, _ := strings.Cut(line, "] ")
+		i := strings.Index(after, " [path:")
+		return StringWidth(after[:i])
+	}
+	if a, b := col(lines[0]), col(lines[1]); a != b {
+		t.Errorf("attributes not aligned: %d != %d\n%s", a, b, buf.String())
+	}
+}
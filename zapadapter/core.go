@@ -0,0 +1,106 @@
+// Package zapadapter exposes a log/slog handler chain as a zapcore.Core, so
+// zap-based dependencies emit into the same colored, metrics-instrumented
+// pipeline as the rest of the application.
+package zapadapter
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a zapcore.Core that writes entries to a slog.Handler.
+type Core struct {
+	handler slog.Handler
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore returns a zapcore.Core that writes to h.
+func NewCore(h slog.Handler) *Core {
+	return &Core{handler: h}
+}
+
+// NewLogger returns a *zap.Logger that writes to h, with caller information enabled
+// so that handlers with AddSource report the zap call site.
+func NewLogger(h slog.Handler, opts ...zap.Option) *zap.Logger {
+	return zap.New(NewCore(h), append([]zap.Option{zap.AddCaller()}, opts...)...)
+}
+
+// Level converts a zap level to a slog level. DPanic, Panic and Fatal map
+// above ERROR (ERROR+2, ERROR+3 and ERROR+4).
+func Level(l zapcore.Level) slog.Level {
+	switch l {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	case zapcore.ErrorLevel:
+		return slog.LevelError
+	case zapcore.DPanicLevel:
+		return slog.LevelError + 2
+	case zapcore.PanicLevel:
+		return slog.LevelError + 3
+	case zapcore.FatalLevel:
+		return slog.LevelError + 4
+	default:
+		return slog.Level(l) * 4
+	}
+}
+
+func (c *Core) Enabled(l zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), Level(l))
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	return &Core{handler: c.handler.WithAttrs(Attrs(fields))}
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if ent.Caller.Defined {
+		pc = ent.Caller.PC
+	}
+	r := slog.NewRecord(ent.Time, Level(ent.Level), ent.Message, pc)
+	if ent.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", ent.LoggerName))
+	}
+	r.AddAttrs(Attrs(fields)...)
+	if ent.Stack != "" {
+		r.AddAttrs(slog.String("stacktrace", ent.Stack))
+	}
+	return c.handler.Handle(context.Background(), r)
+}
+
+// Sync is a no-op; flush the underlying handler directly if it buffers.
+func (c *Core) Sync() error {
+	return nil
+}
+
+// Attrs converts zap fields to slog attributes, preserving their order.
+// Namespaces become groups containing the fields that follow them.
+func Attrs(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(Attrs(fields[i+1:])...)})
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+	return attrs
+}
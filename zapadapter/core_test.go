@@ -0,0 +1,55 @@
+package zapadapter
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true})
+	logger := NewLogger(h).Named("db").With(zap.String("component", "pool"))
+
+	logger.Debug("ignored")
+	logger.Info("connected", zap.Int("conns", 4), zap.Duration("took", time.Second))
+	logger.Error("query failed", zap.Error(errors.New("timeout")), zap.Namespace("query"), zap.String("table", "users"))
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=INFO`,
+		`msg=connected component=pool logger=db conns=4 took=1s`,
+		`level=ERROR`,
+		`error=timeout query.table=users`,
+		`source=`,
+		`core_test.go:`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ignored") {
+		t.Error("debug entry should be filtered by the handler level")
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[zapcore.Level]slog.Level{
+		zapcore.DebugLevel: slog.LevelDebug,
+		zapcore.InfoLevel:  slog.LevelInfo,
+		zapcore.WarnLevel:  slog.LevelWarn,
+		zapcore.ErrorLevel: slog.LevelError,
+		zapcore.FatalLevel: slog.LevelError + 4,
+	}
+	for zl, want := range tests {
+		if got := Level(zl); got != want {
+			t.Errorf("Level(%v) = %v, want %v", zl, got, want)
+		}
+	}
+}
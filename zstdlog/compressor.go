@@ -0,0 +1,36 @@
+// Package zstdlog provides zstd compression for the sloghandler sinks, and
+// a zstd-compressed NDJSON file with a time index for long-term local storage.
+package zstdlog
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressor compresses with zstd. It implements sloghandler.Compressor:
+//
+//	h, err := sloghandler.NewHTTPHandler(url, &sloghandler.HTTPOptions{
+//		Compressor: zstdlog.Compressor{},
+//	})
+type Compressor struct {
+	// Level is the encoder level, e.g. zstd.SpeedFastest. Default is
+	// zstd.SpeedDefault.
+	Level zstd.EncoderLevel
+}
+
+// Encoding returns "zstd".
+func (Compressor) Encoding() string { return "zstd" }
+
+// Extension returns ".zst".
+func (Compressor) Extension() string { return ".zst" }
+
+// NewWriter returns a zstd encoder writing to w. Closing it ends the frame
+// without closing w.
+func (c Compressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if c.Level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(c.Level))
+	}
+	return zstd.NewWriter(w, opts...)
+}
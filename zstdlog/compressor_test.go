@@ -0,0 +1,41 @@
+package zstdlog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressor(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"time":"2024-01-01T00:00:00Z","level":"INFO","msg":"hello"}`+"\n"), 100)
+	for _, c := range []Compressor{{}, {Level: zstd.SpeedBestCompression}} {
+		var buf bytes.Buffer
+		zw, err := c.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() >= len(data) {
+			t.Errorf("compressed to %d bytes from %d", buf.Len(), len(data))
+		}
+		zr, err := zstd.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(zr)
+		zr.Close()
+		if err != nil || !bytes.Equal(b, data) {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+	if c := (Compressor{}); c.Encoding() != "zstd" || c.Extension() != ".zst" {
+		t.Errorf("unexpected encoding %q and extension %q", c.Encoding(), c.Extension())
+	}
+}
@@ -0,0 +1,399 @@
+package zstdlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// FileOptions configures a File and a Reader.
+type FileOptions struct {
+	// Level is the encoder level. Default is zstd.SpeedDefault.
+	Level zstd.EncoderLevel
+	// FrameSize is the uncompressed size in bytes beyond which the
+	// buffered lines are written as a frame. Default is 1 MiB.
+	FrameSize int
+	// Interval is the maximum time a line stays buffered before its frame
+	// is written. Default is 10 seconds.
+	Interval time.Duration
+	// TimeKey is the key holding the RFC 3339 time of each line. Lines
+	// without it are indexed at the time they are written. Default is
+	// "time", as written by slog.JSONHandler.
+	TimeKey string
+}
+
+func (o *FileOptions) withDefaults() FileOptions {
+	var c FileOptions
+	if o != nil {
+		c = *o
+	}
+	if c.FrameSize <= 0 {
+		c.FrameSize = 1 << 20
+	}
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.TimeKey == "" {
+		c.TimeKey = "time"
+	}
+	return c
+}
+
+// indexMagic starts an index file. Each following entry is indexEntrySize
+// bytes: the offset and size of a frame in the data file, the Unix times
+// in nanoseconds of its earliest and latest line, and its line count, all
+// little-endian 64-bit integers.
+const (
+	indexMagic     = "SLZIDX1\n"
+	indexEntrySize = 40
+)
+
+// Frame describes a zstd frame of a File.
+type Frame struct {
+	// Offset and Size locate the compressed frame in the file.
+	Offset, Size int64
+	// Start and End are the earliest and latest times of its lines.
+	Start, End time.Time
+	// Lines is the number of lines in the frame.
+	Lines int
+}
+
+func (fr *Frame) appendIndex(dst []byte) []byte {
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(fr.Offset))
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(fr.Size))
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(fr.Start.UnixNano()))
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(fr.End.UnixNano()))
+	return binary.LittleEndian.AppendUint64(dst, uint64(fr.Lines))
+}
+
+func readIndex(name string) ([]Frame, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if len(b) < len(indexMagic) || string(b[:len(indexMagic)]) != indexMagic {
+		return nil, fmt.Errorf("zstdlog: %s is not an index file", name)
+	}
+	b = b[len(indexMagic):]
+	frames := make([]Frame, 0, len(b)/indexEntrySize)
+	var end int64
+	// a partially written last entry is ignored
+	for ; len(b) >= indexEntrySize; b = b[indexEntrySize:] {
+		fr := Frame{
+			Offset: int64(binary.LittleEndian.Uint64(b)),
+			Size:   int64(binary.LittleEndian.Uint64(b[8:])),
+			Start:  time.Unix(0, int64(binary.LittleEndian.Uint64(b[16:]))),
+			End:    time.Unix(0, int64(binary.LittleEndian.Uint64(b[24:]))),
+			Lines:  int(binary.LittleEndian.Uint64(b[32:])),
+		}
+		if fr.Offset != end || fr.Size <= 0 {
+			return nil, fmt.Errorf("zstdlog: %s is corrupt", name)
+		}
+		end += fr.Size
+		frames = append(frames, fr)
+	}
+	return frames, nil
+}
+
+// File is an io.WriteCloser storing NDJSON lines, e.g. the output of
+// slog.JSONHandler, as a sequence of zstd frames. Each frame is recorded
+// in a sparse index in a sidecar file named path + ".idx", so that a
+// Reader can decompress only the frames overlapping a time range.
+//
+// The data file is a valid zstd stream: zstdcat and other tools read it
+// as a whole.
+type File struct {
+	path string
+	opts FileOptions
+
+	mu      sync.Mutex
+	data    *os.File
+	index   *os.File
+	enc     *zstd.Encoder
+	offset  int64
+	buf     []byte
+	scanned int // length of the complete lines in buf
+	frame   Frame
+	timer   *time.Timer
+	err     error // from a flush by the timer
+}
+
+// OpenFile opens path for appending, creating it and its index if needed.
+// A frame written to path after the last one of its index, e.g. by a
+// process that crashed in between, is discarded.
+func OpenFile(path string, opts *FileOptions) (*File, error) {
+	f := &File{path: path, opts: opts.withDefaults()}
+	frames, err := readIndex(path + ".idx")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if n := len(frames); n > 0 {
+		f.offset = frames[n-1].Offset + frames[n-1].Size
+	}
+	if f.data, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	if err := f.open(len(frames)); err != nil {
+		f.data.Close()
+		return nil, err
+	}
+	eopts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if f.opts.Level != 0 {
+		eopts = append(eopts, zstd.WithEncoderLevel(f.opts.Level))
+	}
+	if f.enc, err = zstd.NewWriter(nil, eopts...); err != nil {
+		f.data.Close()
+		f.index.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// open truncates the data file to the end of the last indexed frame and
+// the index to its n complete entries.
+func (f *File) open(n int) error {
+	st, err := f.data.Stat()
+	if err != nil {
+		return err
+	}
+	if n == 0 && st.Size() > 0 {
+		return fmt.Errorf("zstdlog: %s has no index", f.path)
+	}
+	if st.Size() < f.offset {
+		return fmt.Errorf("zstdlog: %s is shorter than its index", f.path)
+	}
+	if err := f.data.Truncate(f.offset); err != nil {
+		return err
+	}
+	if _, err := f.data.Seek(f.offset, io.SeekStart); err != nil {
+		return err
+	}
+	if f.index, err = os.OpenFile(f.path+".idx", os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return err
+	}
+	size := int64(len(indexMagic) + n*indexEntrySize)
+	if n == 0 {
+		_, err = f.index.WriteAt([]byte(indexMagic), 0)
+	}
+	if err == nil {
+		err = f.index.Truncate(size)
+	}
+	if err == nil {
+		_, err = f.index.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.index.Close()
+	}
+	return err
+}
+
+// Write buffers p, writing a frame once FrameSize bytes of complete lines
+// are buffered.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.data == nil {
+		return 0, os.ErrClosed
+	}
+	if err := f.err; err != nil {
+		f.err = nil
+		return 0, err
+	}
+	f.buf = append(f.buf, p...)
+	for {
+		i := bytes.IndexByte(f.buf[f.scanned:], '\n')
+		if i < 0 {
+			break
+		}
+		f.addLine(f.buf[f.scanned : f.scanned+i])
+		f.scanned += i + 1
+	}
+	if f.scanned >= f.opts.FrameSize {
+		if err := f.flush(); err != nil {
+			return 0, err
+		}
+	} else if f.frame.Lines > 0 && f.timer == nil {
+		f.timer = time.AfterFunc(f.opts.Interval, func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.data != nil {
+				f.err = f.flush()
+			}
+		})
+	}
+	return len(p), nil
+}
+
+func (f *File) addLine(line []byte) {
+	t, ok := lineTime(line, f.opts.TimeKey)
+	if !ok {
+		t = time.Now()
+	}
+	if f.frame.Lines == 0 || t.Before(f.frame.Start) {
+		f.frame.Start = t
+	}
+	if f.frame.Lines == 0 || t.After(f.frame.End) {
+		f.frame.End = t
+	}
+	f.frame.Lines++
+}
+
+// flush writes the buffered complete lines as a frame and indexes it.
+func (f *File) flush() error {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	if f.scanned == 0 {
+		return nil
+	}
+	z := f.enc.EncodeAll(f.buf[:f.scanned], nil)
+	if _, err := f.data.Write(z); err != nil {
+		return err
+	}
+	f.frame.Offset, f.frame.Size = f.offset, int64(len(z))
+	if _, err := f.index.Write(f.frame.appendIndex(nil)); err != nil {
+		return err
+	}
+	f.offset += int64(len(z))
+	f.buf = f.buf[:copy(f.buf, f.buf[f.scanned:])]
+	f.scanned = 0
+	f.frame = Frame{}
+	return nil
+}
+
+// Flush writes the buffered complete lines as a frame.
+func (f *File) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.data == nil {
+		return nil
+	}
+	return f.flush()
+}
+
+// Close writes the buffered complete lines and closes the files. An
+// incomplete last line is discarded.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.data == nil {
+		return nil
+	}
+	err := f.flush()
+	f.enc.Close()
+	if cerr := f.data.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.index.Close(); err == nil {
+		err = cerr
+	}
+	f.data = nil
+	return err
+}
+
+// lineTime returns the time of the first "key":"..." string in line.
+func lineTime(line []byte, key string) (time.Time, bool) {
+	i := bytes.Index(line, []byte(`"`+key+`":"`))
+	if i < 0 {
+		return time.Time{}, false
+	}
+	v := line[i+len(key)+4:]
+	j := bytes.IndexByte(v, '"')
+	if j < 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(v[:j]))
+	return t, err == nil
+}
+
+// Reader reads the lines of a File by time range.
+type Reader struct {
+	f       *os.File
+	frames  []Frame
+	timeKey string
+	dec     *zstd.Decoder
+}
+
+// OpenReader opens a file written by File with its index. Frames written
+// after OpenReader returns are not read. Only the TimeKey of opts is used.
+func OpenReader(path string, opts *FileOptions) (*Reader, error) {
+	frames, err := readIndex(path + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		dec.Close()
+		return nil, err
+	}
+	return &Reader{f: f, frames: frames, timeKey: opts.withDefaults().TimeKey, dec: dec}, nil
+}
+
+// Frames returns the index of the file.
+func (r *Reader) Frames() []Frame {
+	return r.frames
+}
+
+// Lines returns the lines whose time is in [start, end), without their
+// trailing newline, in the order they were written. A zero start or end
+// leaves the range open on that side. Lines without a time are returned
+// whenever their frame overlaps the range.
+//
+// Only frames overlapping the range are read. A line is valid until the
+// next iteration.
+func (r *Reader) Lines(start, end time.Time) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		var z, buf []byte
+		for _, fr := range r.frames {
+			if !start.IsZero() && fr.End.Before(start) || !end.IsZero() && !fr.Start.Before(end) {
+				continue
+			}
+			if int64(cap(z)) < fr.Size {
+				z = make([]byte, fr.Size)
+			}
+			z = z[:fr.Size]
+			if _, err := r.f.ReadAt(z, fr.Offset); err != nil {
+				yield(nil, fmt.Errorf("zstdlog: failed to read frame at %d: %w", fr.Offset, err))
+				return
+			}
+			var err error
+			if buf, err = r.dec.DecodeAll(z, buf[:0]); err != nil {
+				yield(nil, fmt.Errorf("zstdlog: failed to decode frame at %d: %w", fr.Offset, err))
+				return
+			}
+			for line := range bytes.Lines(buf) {
+				line = bytes.TrimSuffix(line, []byte("\n"))
+				if t, ok := lineTime(line, r.timeKey); ok {
+					if !start.IsZero() && t.Before(start) || !end.IsZero() && !t.Before(end) {
+						continue
+					}
+				}
+				if !yield(line, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close closes the file.
+func (r *Reader) Close() error {
+	r.dec.Close()
+	return r.f.Close()
+}
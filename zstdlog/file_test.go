@@ -0,0 +1,166 @@
+package zstdlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var testStart = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func writeLines(t *testing.T, f *File, from, n int) {
+	t.Helper()
+	for i := from; i < from+n; i++ {
+		ts := testStart.Add(time.Duration(i) * time.Minute).Format(time.RFC3339Nano)
+		if _, err := fmt.Fprintf(f, `{"time":%q,"level":"INFO","msg":"line %d"}`+"\n", ts, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readLines(t *testing.T, r *Reader, start, end time.Time) []string {
+	t.Helper()
+	var lines []string
+	for line, err := range r.Lines(start, end) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := string(line)
+		lines = append(lines, s[strings.Index(s, `"msg":"`)+7:len(s)-2])
+	}
+	return lines
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	f, err := OpenFile(path, &FileOptions{FrameSize: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 0, 20)
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// reopening appends
+	if f, err = OpenFile(path, &FileOptions{FrameSize: 500}); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 20, 10)
+	f.Write([]byte(`{"msg":"incomplete`))
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenReader(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	frames := r.Frames()
+	if len(frames) < 4 {
+		t.Fatalf("got %d frames, want at least 4", len(frames))
+	}
+	var total int
+	for _, fr := range frames {
+		total += fr.Lines
+	}
+	if total != 30 || !frames[0].Start.Equal(testStart) || !frames[len(frames)-1].End.Equal(testStart.Add(29*time.Minute)) {
+		t.Errorf("unexpected index %+v", frames)
+	}
+
+	got := readLines(t, r, testStart.Add(10*time.Minute), testStart.Add(13*time.Minute))
+	if want := []string{"line 10", "line 11", "line 12"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := readLines(t, r, time.Time{}, time.Time{}); len(got) != 30 || got[29] != "line 29" {
+		t.Errorf("got %q", got)
+	}
+	if got := readLines(t, r, testStart.Add(time.Hour), time.Time{}); len(got) != 0 {
+		t.Errorf("expected no lines after the last one, got %q", got)
+	}
+
+	// the data file is a plain zstd stream
+	b, _ := os.ReadFile(path)
+	zr, err := zstd.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if b, err := io.ReadAll(zr); err != nil || bytes.Count(b, []byte("\n")) != 30 {
+		t.Errorf("failed to decode the file as a whole: %v", err)
+	}
+}
+
+func TestFileRecover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	f, err := OpenFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 0, 3)
+	f.Close()
+
+	// an unindexed frame and a partial index entry, as left by a crash
+	data, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	data.Write([]byte("garbage"))
+	data.Close()
+	idx, _ := os.OpenFile(path+".idx", os.O_WRONLY|os.O_APPEND, 0)
+	idx.Write([]byte{1, 2, 3})
+	idx.Close()
+
+	if f, err = OpenFile(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 3, 2)
+	f.Close()
+	r, err := OpenReader(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got := readLines(t, r, time.Time{}, time.Time{}); len(got) != 5 {
+		t.Errorf("got %q, want 5 lines", got)
+	}
+
+	if err := os.Remove(path + ".idx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path, nil); err == nil {
+		t.Error("expected an error opening a file without its index")
+	}
+}
+
+func TestFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	f, err := OpenFile(path, &FileOptions{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	slog.New(slog.NewJSONHandler(f, nil)).Info("hello")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r, err := OpenReader(path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := len(r.Frames())
+		r.Close()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("buffered line was not written after Interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/fujiwara/sloghandler/zstdlog
+
+go 1.25
+
+require github.com/klauspost/compress v1.18.0